@@ -0,0 +1,32 @@
+package userprint
+
+import (
+	. "code.cloudfoundry.org/cli/cf/i18n"
+	"code.cloudfoundry.org/cli/cf/models"
+	"code.cloudfoundry.org/cli/cf/terminal"
+)
+
+// CountPrinter prints only how many users hold each of Roles.
+type CountPrinter struct {
+	UI               terminal.UI
+	UserCounter      func(guid string, role models.Role) (int, error)
+	Roles            []models.Role
+	RoleDisplayNames map[models.Role]string
+}
+
+func (p *CountPrinter) PrintUsers(guid string, username string) {
+	p.UI.Say("")
+	for _, role := range p.Roles {
+		displayName := p.RoleDisplayNames[role]
+		count, err := p.UserCounter(guid, role)
+		if err != nil {
+			p.UI.Failed(T("Failed fetching users for role {{.Role}}.\n{{.Error}}",
+				map[string]interface{}{
+					"Error": err.Error(),
+					"Role":  displayName,
+				}))
+			return
+		}
+		p.UI.Say("%s: %d", terminal.HeaderColor(displayName), count)
+	}
+}
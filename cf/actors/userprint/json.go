@@ -0,0 +1,91 @@
+package userprint
+
+import (
+	"encoding/json"
+	"sort"
+
+	. "code.cloudfoundry.org/cli/cf/i18n"
+	"code.cloudfoundry.org/cli/cf/models"
+	"code.cloudfoundry.org/cli/cf/terminal"
+)
+
+// FieldStyle is the casing of the keys JSONPrinter writes.
+type FieldStyle string
+
+const (
+	CamelCase FieldStyle = "camel"
+	SnakeCase FieldStyle = "snake"
+)
+
+// JSONPrinter prints the users holding any of Roles as a single JSON array,
+// one entry per user with every role they hold, ordered by username. Keys are
+// camelCase unless FieldStyle is SnakeCase.
+type JSONPrinter struct {
+	UI         terminal.UI
+	UserLister func(guid string, role models.Role) ([]models.UserFields, error)
+	Roles      []models.Role
+	FieldStyle FieldStyle
+}
+
+type jsonUser struct {
+	Username string   `json:"username"`
+	GUID     string   `json:"guid"`
+	Roles    []string `json:"roles"`
+	IsAdmin  bool     `json:"isAdmin"`
+}
+
+// snakeCaseJSONUser is jsonUser with snake_case keys; the fields must stay
+// identical so one converts to the other.
+type snakeCaseJSONUser struct {
+	Username string   `json:"username"`
+	GUID     string   `json:"guid"`
+	Roles    []string `json:"roles"`
+	IsAdmin  bool     `json:"is_admin"`
+}
+
+func (p *JSONPrinter) PrintUsers(guid string, username string) {
+	users := make(userCollection)
+	for _, role := range p.Roles {
+		roleUsers, err := p.UserLister(guid, role)
+		if err != nil {
+			p.UI.Failed(T("Failed fetching users for role {{.Role}}.\n{{.Error}}",
+				map[string]interface{}{
+					"Error": err.Error(),
+					"Role":  role.ToString(),
+				}))
+			return
+		}
+		for _, user := range roleUsers {
+			users.storeAppendingRole(role, user.Username, user.GUID, user.IsAdmin)
+		}
+	}
+
+	output := []jsonUser{}
+	for _, user := range users.all() {
+		output = append(output, jsonUser{
+			Username: user.Username,
+			GUID:     user.GUID,
+			Roles:    rolesToString(user.Roles),
+			IsAdmin:  user.IsAdmin,
+		})
+	}
+	sort.Slice(output, func(i, j int) bool { return output[i].Username < output[j].Username })
+
+	encoded, err := p.marshal(output)
+	if err != nil {
+		p.UI.Failed(err.Error())
+		return
+	}
+	p.UI.Say("%s", encoded)
+}
+
+func (p *JSONPrinter) marshal(users []jsonUser) ([]byte, error) {
+	if p.FieldStyle != SnakeCase {
+		return json.Marshal(users)
+	}
+	snakeCased := make([]snakeCaseJSONUser, len(users))
+	for i, user := range users {
+		snakeCased[i] = snakeCaseJSONUser(user)
+	}
+	return json.Marshal(snakeCased)
+}
@@ -18,6 +18,8 @@ type OrgUsersUIPrinter struct {
 	RoleDisplayNames map[models.Role]string
 	UserLister       func(orgGUID string, role models.Role) ([]models.UserFields, error)
 	UI               terminal.UI
+	// Detailed prints each user's identity provider origin beside them.
+	Detailed bool
 }
 
 func (p *OrgUsersUIPrinter) PrintUsers(guid string, username string) {
@@ -39,6 +41,12 @@ func (p *OrgUsersUIPrinter) PrintUsers(guid string, username string) {
 			p.UI.Say("  " + T("No {{.Role}} found", map[string]interface{}{
 				"Role": displayName,
 			}))
+		} else if p.Detailed {
+			table := p.UI.Table([]string{"  " + T("user"), T("origin")})
+			for _, user := range users {
+				table.Add("  "+user.Username, user.Origin)
+			}
+			_ = table.Print()
 		} else {
 			for _, user := range users {
 				p.UI.Say("  %s", user.Username)
@@ -2,7 +2,10 @@
 package apifakes
 
 import (
+	"context"
+	"io"
 	"sync"
+	"time"
 
 	"code.cloudfoundry.org/cli/cf/api"
 	"code.cloudfoundry.org/cli/cf/models"
@@ -18,6 +21,25 @@ type FakeUserRepository struct {
 		result1 models.UserFields
 		result2 error
 	}
+	FindByEmailStub        func(email string) (user models.UserFields, apiErr error)
+	findByEmailMutex       sync.RWMutex
+	findByEmailArgsForCall []struct {
+		email string
+	}
+	findByEmailReturns struct {
+		result1 models.UserFields
+		result2 error
+	}
+	FindByUsernameContextStub        func(ctx context.Context, username string) (user models.UserFields, apiErr error)
+	findByUsernameContextMutex       sync.RWMutex
+	findByUsernameContextArgsForCall []struct {
+		ctx      context.Context
+		username string
+	}
+	findByUsernameContextReturns struct {
+		result1 models.UserFields
+		result2 error
+	}
 	FindAllByUsernameStub        func(username string) (users []models.UserFields, apiErr error)
 	findAllByUsernameMutex       sync.RWMutex
 	findAllByUsernameArgsForCall []struct {
@@ -27,6 +49,53 @@ type FakeUserRepository struct {
 		result1 []models.UserFields
 		result2 error
 	}
+	FindAllByUsernameContextStub        func(ctx context.Context, username string) (users []models.UserFields, apiErr error)
+	findAllByUsernameContextMutex       sync.RWMutex
+	findAllByUsernameContextArgsForCall []struct {
+		ctx      context.Context
+		username string
+	}
+	findAllByUsernameContextReturns struct {
+		result1 []models.UserFields
+		result2 error
+	}
+	FindAllByUsernamePrefixStub        func(prefix string) (users []models.UserFields, apiErr error)
+	findAllByUsernamePrefixMutex       sync.RWMutex
+	findAllByUsernamePrefixArgsForCall []struct {
+		prefix string
+	}
+	findAllByUsernamePrefixReturns struct {
+		result1 []models.UserFields
+		result2 error
+	}
+	FindByUsernamesStub        func(usernames []string) (users map[string]models.UserFields, apiErr error)
+	findByUsernamesMutex       sync.RWMutex
+	findByUsernamesArgsForCall []struct {
+		usernames []string
+	}
+	findByUsernamesReturns struct {
+		result1 map[string]models.UserFields
+		result2 error
+	}
+	IsUsernameAvailableStub        func(username string) (bool, error)
+	isUsernameAvailableMutex       sync.RWMutex
+	isUsernameAvailableArgsForCall []struct {
+		username string
+	}
+	isUsernameAvailableReturns struct {
+		result1 bool
+		result2 error
+	}
+	VerifyCredentialsStub        func(username, password string) (bool, error)
+	verifyCredentialsMutex       sync.RWMutex
+	verifyCredentialsArgsForCall []struct {
+		username string
+		password string
+	}
+	verifyCredentialsReturns struct {
+		result1 bool
+		result2 error
+	}
 	ListUsersInOrgForRoleStub        func(orgGUID string, role models.Role) ([]models.UserFields, error)
 	listUsersInOrgForRoleMutex       sync.RWMutex
 	listUsersInOrgForRoleArgsForCall []struct {
@@ -37,6 +106,66 @@ type FakeUserRepository struct {
 		result1 []models.UserFields
 		result2 error
 	}
+	ListUsersInOrgForRoleContextStub        func(ctx context.Context, orgGUID string, role models.Role) ([]models.UserFields, error)
+	listUsersInOrgForRoleContextMutex       sync.RWMutex
+	listUsersInOrgForRoleContextArgsForCall []struct {
+		ctx     context.Context
+		orgGUID string
+		role    models.Role
+	}
+	listUsersInOrgForRoleContextReturns struct {
+		result1 []models.UserFields
+		result2 error
+	}
+	CountUsersInOrgForRoleStub        func(orgGUID string, role models.Role) (int, error)
+	countUsersInOrgForRoleMutex       sync.RWMutex
+	countUsersInOrgForRoleArgsForCall []struct {
+		orgGUID string
+		role    models.Role
+	}
+	countUsersInOrgForRoleReturns struct {
+		result1 int
+		result2 error
+	}
+	ListUsersInOrgForRoleFilteredStub        func(orgGUID string, role models.Role, usernameContains string) ([]models.UserFields, error)
+	listUsersInOrgForRoleFilteredMutex       sync.RWMutex
+	listUsersInOrgForRoleFilteredArgsForCall []struct {
+		orgGUID          string
+		role             models.Role
+		usernameContains string
+	}
+	listUsersInOrgForRoleFilteredReturns struct {
+		result1 []models.UserFields
+		result2 error
+	}
+	ListAllUsersInOrgStub        func(orgGUID string) (map[models.Role][]models.UserFields, error)
+	listAllUsersInOrgMutex       sync.RWMutex
+	listAllUsersInOrgArgsForCall []struct {
+		orgGUID string
+	}
+	listAllUsersInOrgReturns struct {
+		result1 map[models.Role][]models.UserFields
+		result2 error
+	}
+	ListUsersInOrgForRolesStub        func(orgGUID string, roles []models.Role) (map[models.Role][]models.UserFields, error)
+	listUsersInOrgForRolesMutex       sync.RWMutex
+	listUsersInOrgForRolesArgsForCall []struct {
+		orgGUID string
+		roles   []models.Role
+	}
+	listUsersInOrgForRolesReturns struct {
+		result1 map[models.Role][]models.UserFields
+		result2 error
+	}
+	ListUsersInSpaceForAllRolesStub        func(spaceGUID string) (map[models.Role][]models.UserFields, error)
+	listUsersInSpaceForAllRolesMutex       sync.RWMutex
+	listUsersInSpaceForAllRolesArgsForCall []struct {
+		spaceGUID string
+	}
+	listUsersInSpaceForAllRolesReturns struct {
+		result1 map[models.Role][]models.UserFields
+		result2 error
+	}
 	ListUsersInOrgForRoleWithNoUAAStub        func(orgGUID string, role models.Role) ([]models.UserFields, error)
 	listUsersInOrgForRoleWithNoUAAMutex       sync.RWMutex
 	listUsersInOrgForRoleWithNoUAAArgsForCall []struct {
@@ -47,6 +176,16 @@ type FakeUserRepository struct {
 		result1 []models.UserFields
 		result2 error
 	}
+	ListInactiveOrgUsersStub        func(orgGUID string, inactiveFor time.Duration) ([]models.UserFields, error)
+	listInactiveOrgUsersMutex       sync.RWMutex
+	listInactiveOrgUsersArgsForCall []struct {
+		orgGUID     string
+		inactiveFor time.Duration
+	}
+	listInactiveOrgUsersReturns struct {
+		result1 []models.UserFields
+		result2 error
+	}
 	ListUsersInSpaceForRoleWithNoUAAStub        func(spaceGUID string, role models.Role) ([]models.UserFields, error)
 	listUsersInSpaceForRoleWithNoUAAMutex       sync.RWMutex
 	listUsersInSpaceForRoleWithNoUAAArgsForCall []struct {
@@ -57,6 +196,140 @@ type FakeUserRepository struct {
 		result1 []models.UserFields
 		result2 error
 	}
+	ListOrgUsersWithSpaceRolesStub        func(orgGUID string) ([]models.OrgUserWithSpaceRoles, error)
+	listOrgUsersWithSpaceRolesMutex       sync.RWMutex
+	listOrgUsersWithSpaceRolesArgsForCall []struct {
+		orgGUID string
+	}
+	listOrgUsersWithSpaceRolesReturns struct {
+		result1 []models.OrgUserWithSpaceRoles
+		result2 error
+	}
+	FindDuplicateCCRegistrationsStub        func() ([]models.DuplicateUserRegistration, error)
+	findDuplicateCCRegistrationsMutex       sync.RWMutex
+	findDuplicateCCRegistrationsArgsForCall []struct{}
+	findDuplicateCCRegistrationsReturns     struct {
+		result1 []models.DuplicateUserRegistration
+		result2 error
+	}
+	FindUnmatchedUsersStub        func() (models.UnmatchedUsers, error)
+	findUnmatchedUsersMutex       sync.RWMutex
+	findUnmatchedUsersArgsForCall []struct{}
+	findUnmatchedUsersReturns     struct {
+		result1 models.UnmatchedUsers
+		result2 error
+	}
+	FindRolelessUsersStub        func() ([]models.UserFields, error)
+	findRolelessUsersMutex       sync.RWMutex
+	findRolelessUsersArgsForCall []struct{}
+	findRolelessUsersReturns     struct {
+		result1 []models.UserFields
+		result2 error
+	}
+	GetUserAccessSummaryStub        func(userGUID string, resolveNames bool) (models.UserAccessSummary, error)
+	getUserAccessSummaryMutex       sync.RWMutex
+	getUserAccessSummaryArgsForCall []struct {
+		userGUID     string
+		resolveNames bool
+	}
+	getUserAccessSummaryReturns struct {
+		result1 models.UserAccessSummary
+		result2 error
+	}
+	GetUserMembershipsStub        func(userGUID, orgGUID string) (models.UserMemberships, error)
+	getUserMembershipsMutex       sync.RWMutex
+	getUserMembershipsArgsForCall []struct {
+		userGUID string
+		orgGUID  string
+	}
+	getUserMembershipsReturns struct {
+		result1 models.UserMemberships
+		result2 error
+	}
+	GetUserRolesInOrgStub        func(userGUID, orgGUID string) ([]models.Role, error)
+	getUserRolesInOrgMutex       sync.RWMutex
+	getUserRolesInOrgArgsForCall []struct {
+		userGUID string
+		orgGUID  string
+	}
+	getUserRolesInOrgReturns struct {
+		result1 []models.Role
+		result2 error
+	}
+	CopyRolesStub        func(fromUserGUID, toUserGUID, orgGUID string) ([]models.RoleChangeResult, error)
+	copyRolesMutex       sync.RWMutex
+	copyRolesArgsForCall []struct {
+		fromUserGUID string
+		toUserGUID   string
+		orgGUID      string
+	}
+	copyRolesReturns struct {
+		result1 []models.RoleChangeResult
+		result2 error
+	}
+	CopySpaceRolesStub        func(fromUserGUID, toUserGUID, spaceGUID, orgGUID string) ([]models.RoleChangeResult, error)
+	copySpaceRolesMutex       sync.RWMutex
+	copySpaceRolesArgsForCall []struct {
+		fromUserGUID string
+		toUserGUID   string
+		spaceGUID    string
+		orgGUID      string
+	}
+	copySpaceRolesReturns struct {
+		result1 []models.RoleChangeResult
+		result2 error
+	}
+	ExportUsersSCIMStub        func(w io.Writer) error
+	exportUsersSCIMMutex       sync.RWMutex
+	exportUsersSCIMArgsForCall []struct {
+		w io.Writer
+	}
+	exportUsersSCIMReturns struct {
+		result1 error
+	}
+	ExportFoundationRBACStub        func(w io.Writer) error
+	exportFoundationRBACMutex       sync.RWMutex
+	exportFoundationRBACArgsForCall []struct {
+		w io.Writer
+	}
+	exportFoundationRBACReturns struct {
+		result1 error
+	}
+	EachRolelessUserStub        func(cb func(models.UserFields) bool) error
+	eachRolelessUserMutex       sync.RWMutex
+	eachRolelessUserArgsForCall []struct {
+		cb func(models.UserFields) bool
+	}
+	eachRolelessUserReturns struct {
+		result1 error
+	}
+	ListAllUsersStub        func(cb func(models.UserFields) bool) error
+	listAllUsersMutex       sync.RWMutex
+	listAllUsersArgsForCall []struct {
+		cb func(models.UserFields) bool
+	}
+	listAllUsersReturns struct {
+		result1 error
+	}
+	ListCFUsersInUAAGroupStub        func(groupName string) ([]models.UserFields, error)
+	listCFUsersInUAAGroupMutex       sync.RWMutex
+	listCFUsersInUAAGroupArgsForCall []struct {
+		groupName string
+	}
+	listCFUsersInUAAGroupReturns struct {
+		result1 []models.UserFields
+		result2 error
+	}
+	DiffSpaceUsersStub        func(spaceAGUID, spaceBGUID string) ([]models.SpaceRoleDiff, error)
+	diffSpaceUsersMutex       sync.RWMutex
+	diffSpaceUsersArgsForCall []struct {
+		spaceAGUID string
+		spaceBGUID string
+	}
+	diffSpaceUsersReturns struct {
+		result1 []models.SpaceRoleDiff
+		result2 error
+	}
 	CreateStub        func(username, password string) (apiErr error)
 	createMutex       sync.RWMutex
 	createArgsForCall []struct {
@@ -66,6 +339,35 @@ type FakeUserRepository struct {
 	createReturns struct {
 		result1 error
 	}
+	CreateWithEmailStub        func(username, password, email string) (apiErr error)
+	createWithEmailMutex       sync.RWMutex
+	createWithEmailArgsForCall []struct {
+		username string
+		password string
+		email    string
+	}
+	createWithEmailReturns struct {
+		result1 error
+	}
+	CreateWithOriginStub        func(username, password, origin string) (apiErr error)
+	createWithOriginMutex       sync.RWMutex
+	createWithOriginArgsForCall []struct {
+		username string
+		password string
+		origin   string
+	}
+	createWithOriginReturns struct {
+		result1 error
+	}
+	CreateBulkStub        func(users []models.UserCreateRequest) ([]models.UserCreateResult, error)
+	createBulkMutex       sync.RWMutex
+	createBulkArgsForCall []struct {
+		users []models.UserCreateRequest
+	}
+	createBulkReturns struct {
+		result1 []models.UserCreateResult
+		result2 error
+	}
 	DeleteStub        func(userGUID string) (apiErr error)
 	deleteMutex       sync.RWMutex
 	deleteArgsForCall []struct {
@@ -74,6 +376,33 @@ type FakeUserRepository struct {
 	deleteReturns struct {
 		result1 error
 	}
+	DeleteAsyncStub        func(userGUID string) (apiErr error)
+	deleteAsyncMutex       sync.RWMutex
+	deleteAsyncArgsForCall []struct {
+		userGUID string
+	}
+	deleteAsyncReturns struct {
+		result1 error
+	}
+	RenameUserStub        func(userGUID, newUsername string) (apiErr error)
+	renameUserMutex       sync.RWMutex
+	renameUserArgsForCall []struct {
+		userGUID    string
+		newUsername string
+	}
+	renameUserReturns struct {
+		result1 error
+	}
+	UpdatePasswordStub        func(userGUID, oldPassword, newPassword string) (apiErr error)
+	updatePasswordMutex       sync.RWMutex
+	updatePasswordArgsForCall []struct {
+		userGUID    string
+		oldPassword string
+		newPassword string
+	}
+	updatePasswordReturns struct {
+		result1 error
+	}
 	SetOrgRoleByGUIDStub        func(userGUID, orgGUID string, role models.Role) (apiErr error)
 	setOrgRoleByGUIDMutex       sync.RWMutex
 	setOrgRoleByGUIDArgsForCall []struct {
@@ -114,6 +443,56 @@ type FakeUserRepository struct {
 	unsetOrgRoleByUsernameReturns struct {
 		result1 error
 	}
+	UnsetOrgRoleBulkStub        func(userGUIDs []string, orgGUID string, role models.Role) ([]models.RoleChangeResult, error)
+	unsetOrgRoleBulkMutex       sync.RWMutex
+	unsetOrgRoleBulkArgsForCall []struct {
+		userGUIDs []string
+		orgGUID   string
+		role      models.Role
+	}
+	unsetOrgRoleBulkReturns struct {
+		result1 []models.RoleChangeResult
+		result2 error
+	}
+	UnsetOrgRoleBulkContextStub        func(ctx context.Context, userGUIDs []string, orgGUID string, role models.Role) ([]models.RoleChangeResult, error)
+	unsetOrgRoleBulkContextMutex       sync.RWMutex
+	unsetOrgRoleBulkContextArgsForCall []struct {
+		ctx       context.Context
+		userGUIDs []string
+		orgGUID   string
+		role      models.Role
+	}
+	unsetOrgRoleBulkContextReturns struct {
+		result1 []models.RoleChangeResult
+		result2 error
+	}
+	PreviewOrgRoleChangeStub        func(userGUID, username, orgGUID string, role models.Role, set bool) ([]models.RequestPreview, error)
+	previewOrgRoleChangeMutex       sync.RWMutex
+	previewOrgRoleChangeArgsForCall []struct {
+		userGUID string
+		username string
+		orgGUID  string
+		role     models.Role
+		set      bool
+	}
+	previewOrgRoleChangeReturns struct {
+		result1 []models.RequestPreview
+		result2 error
+	}
+	PreviewSpaceRoleChangeStub        func(userGUID, username, spaceGUID, orgGUID string, role models.Role, set bool) ([]models.RequestPreview, error)
+	previewSpaceRoleChangeMutex       sync.RWMutex
+	previewSpaceRoleChangeArgsForCall []struct {
+		userGUID  string
+		username  string
+		spaceGUID string
+		orgGUID   string
+		role      models.Role
+		set       bool
+	}
+	previewSpaceRoleChangeReturns struct {
+		result1 []models.RequestPreview
+		result2 error
+	}
 	SetSpaceRoleByGUIDStub        func(userGUID, spaceGUID, orgGUID string, role models.Role) (apiErr error)
 	setSpaceRoleByGUIDMutex       sync.RWMutex
 	setSpaceRoleByGUIDArgsForCall []struct {
@@ -136,6 +515,30 @@ type FakeUserRepository struct {
 	setSpaceRoleByUsernameReturns struct {
 		result1 error
 	}
+	SetSpaceRolesByGUIDStub        func(userGUID, spaceGUID, orgGUID string, roles []models.Role) ([]models.RoleChangeResult, error)
+	setSpaceRolesByGUIDMutex       sync.RWMutex
+	setSpaceRolesByGUIDArgsForCall []struct {
+		userGUID  string
+		spaceGUID string
+		orgGUID   string
+		roles     []models.Role
+	}
+	setSpaceRolesByGUIDReturns struct {
+		result1 []models.RoleChangeResult
+		result2 error
+	}
+	SetSpaceRolesByUsernameStub        func(username, spaceGUID, orgGUID string, roles []models.Role) ([]models.RoleChangeResult, error)
+	setSpaceRolesByUsernameMutex       sync.RWMutex
+	setSpaceRolesByUsernameArgsForCall []struct {
+		username  string
+		spaceGUID string
+		orgGUID   string
+		roles     []models.Role
+	}
+	setSpaceRolesByUsernameReturns struct {
+		result1 []models.RoleChangeResult
+		result2 error
+	}
 	UnsetSpaceRoleByGUIDStub        func(userGUID, spaceGUID string, role models.Role) (apiErr error)
 	unsetSpaceRoleByGUIDMutex       sync.RWMutex
 	unsetSpaceRoleByGUIDArgsForCall []struct {
@@ -156,6 +559,81 @@ type FakeUserRepository struct {
 	unsetSpaceRoleByUsernameReturns struct {
 		result1 error
 	}
+	SetOrgRoleByGUIDAsyncStub        func(userGUID, orgGUID string, role models.Role) (job api.RoleJob, apiErr error)
+	setOrgRoleByGUIDAsyncMutex       sync.RWMutex
+	setOrgRoleByGUIDAsyncArgsForCall []struct {
+		userGUID string
+		orgGUID  string
+		role     models.Role
+	}
+	setOrgRoleByGUIDAsyncReturns struct {
+		result1 api.RoleJob
+		result2 error
+	}
+	UnsetOrgRoleByGUIDAsyncStub        func(userGUID, orgGUID string, role models.Role) (job api.RoleJob, apiErr error)
+	unsetOrgRoleByGUIDAsyncMutex       sync.RWMutex
+	unsetOrgRoleByGUIDAsyncArgsForCall []struct {
+		userGUID string
+		orgGUID  string
+		role     models.Role
+	}
+	unsetOrgRoleByGUIDAsyncReturns struct {
+		result1 api.RoleJob
+		result2 error
+	}
+	SetSpaceRoleByGUIDAsyncStub        func(userGUID, spaceGUID, orgGUID string, role models.Role) (job api.RoleJob, apiErr error)
+	setSpaceRoleByGUIDAsyncMutex       sync.RWMutex
+	setSpaceRoleByGUIDAsyncArgsForCall []struct {
+		userGUID  string
+		spaceGUID string
+		orgGUID   string
+		role      models.Role
+	}
+	setSpaceRoleByGUIDAsyncReturns struct {
+		result1 api.RoleJob
+		result2 error
+	}
+	SpaceRoleETagStub        func(spaceGUID string, role models.Role) (string, error)
+	spaceRoleETagMutex       sync.RWMutex
+	spaceRoleETagArgsForCall []struct {
+		spaceGUID string
+		role      models.Role
+	}
+	spaceRoleETagReturns struct {
+		result1 string
+		result2 error
+	}
+	SetSpaceRoleByGUIDIfMatchStub        func(userGUID, spaceGUID, orgGUID string, role models.Role, etag string) (apiErr error)
+	setSpaceRoleByGUIDIfMatchMutex       sync.RWMutex
+	setSpaceRoleByGUIDIfMatchArgsForCall []struct {
+		userGUID  string
+		spaceGUID string
+		orgGUID   string
+		role      models.Role
+		etag      string
+	}
+	setSpaceRoleByGUIDIfMatchReturns struct {
+		result1 error
+	}
+	UnsetSpaceRoleByGUIDAsyncStub        func(userGUID, spaceGUID string, role models.Role) (job api.RoleJob, apiErr error)
+	unsetSpaceRoleByGUIDAsyncMutex       sync.RWMutex
+	unsetSpaceRoleByGUIDAsyncArgsForCall []struct {
+		userGUID  string
+		spaceGUID string
+		role      models.Role
+	}
+	unsetSpaceRoleByGUIDAsyncReturns struct {
+		result1 api.RoleJob
+		result2 error
+	}
+	WaitForRoleJobStub        func(job api.RoleJob) (apiErr error)
+	waitForRoleJobMutex       sync.RWMutex
+	waitForRoleJobArgsForCall []struct {
+		job api.RoleJob
+	}
+	waitForRoleJobReturns struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -194,6 +672,75 @@ func (fake *FakeUserRepository) FindByUsernameReturns(result1 models.UserFields,
 	}{result1, result2}
 }
 
+func (fake *FakeUserRepository) FindByEmail(email string) (user models.UserFields, apiErr error) {
+	fake.findByEmailMutex.Lock()
+	fake.findByEmailArgsForCall = append(fake.findByEmailArgsForCall, struct {
+		email string
+	}{email})
+	fake.recordInvocation("FindByEmail", []interface{}{email})
+	fake.findByEmailMutex.Unlock()
+	if fake.FindByEmailStub != nil {
+		return fake.FindByEmailStub(email)
+	} else {
+		return fake.findByEmailReturns.result1, fake.findByEmailReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) FindByEmailCallCount() int {
+	fake.findByEmailMutex.RLock()
+	defer fake.findByEmailMutex.RUnlock()
+	return len(fake.findByEmailArgsForCall)
+}
+
+func (fake *FakeUserRepository) FindByEmailArgsForCall(i int) string {
+	fake.findByEmailMutex.RLock()
+	defer fake.findByEmailMutex.RUnlock()
+	return fake.findByEmailArgsForCall[i].email
+}
+
+func (fake *FakeUserRepository) FindByEmailReturns(result1 models.UserFields, result2 error) {
+	fake.FindByEmailStub = nil
+	fake.findByEmailReturns = struct {
+		result1 models.UserFields
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) FindByUsernameContext(ctx context.Context, username string) (user models.UserFields, apiErr error) {
+	fake.findByUsernameContextMutex.Lock()
+	fake.findByUsernameContextArgsForCall = append(fake.findByUsernameContextArgsForCall, struct {
+		ctx      context.Context
+		username string
+	}{ctx, username})
+	fake.recordInvocation("FindByUsernameContext", []interface{}{ctx, username})
+	fake.findByUsernameContextMutex.Unlock()
+	if fake.FindByUsernameContextStub != nil {
+		return fake.FindByUsernameContextStub(ctx, username)
+	} else {
+		return fake.findByUsernameContextReturns.result1, fake.findByUsernameContextReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) FindByUsernameContextCallCount() int {
+	fake.findByUsernameContextMutex.RLock()
+	defer fake.findByUsernameContextMutex.RUnlock()
+	return len(fake.findByUsernameContextArgsForCall)
+}
+
+func (fake *FakeUserRepository) FindByUsernameContextArgsForCall(i int) (context.Context, string) {
+	fake.findByUsernameContextMutex.RLock()
+	defer fake.findByUsernameContextMutex.RUnlock()
+	return fake.findByUsernameContextArgsForCall[i].ctx, fake.findByUsernameContextArgsForCall[i].username
+}
+
+func (fake *FakeUserRepository) FindByUsernameContextReturns(result1 models.UserFields, result2 error) {
+	fake.FindByUsernameContextStub = nil
+	fake.findByUsernameContextReturns = struct {
+		result1 models.UserFields
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeUserRepository) FindAllByUsername(username string) (users []models.UserFields, apiErr error) {
 	fake.findAllByUsernameMutex.Lock()
 	fake.findAllByUsernameArgsForCall = append(fake.findAllByUsernameArgsForCall, struct {
@@ -228,30 +775,207 @@ func (fake *FakeUserRepository) FindAllByUsernameReturns(result1 []models.UserFi
 	}{result1, result2}
 }
 
-func (fake *FakeUserRepository) ListUsersInOrgForRole(orgGUID string, role models.Role) ([]models.UserFields, error) {
-	fake.listUsersInOrgForRoleMutex.Lock()
-	fake.listUsersInOrgForRoleArgsForCall = append(fake.listUsersInOrgForRoleArgsForCall, struct {
-		orgGUID string
-		role    models.Role
-	}{orgGUID, role})
-	fake.recordInvocation("ListUsersInOrgForRole", []interface{}{orgGUID, role})
-	fake.listUsersInOrgForRoleMutex.Unlock()
-	if fake.ListUsersInOrgForRoleStub != nil {
-		return fake.ListUsersInOrgForRoleStub(orgGUID, role)
+func (fake *FakeUserRepository) FindAllByUsernameContext(ctx context.Context, username string) (users []models.UserFields, apiErr error) {
+	fake.findAllByUsernameContextMutex.Lock()
+	fake.findAllByUsernameContextArgsForCall = append(fake.findAllByUsernameContextArgsForCall, struct {
+		ctx      context.Context
+		username string
+	}{ctx, username})
+	fake.recordInvocation("FindAllByUsernameContext", []interface{}{ctx, username})
+	fake.findAllByUsernameContextMutex.Unlock()
+	if fake.FindAllByUsernameContextStub != nil {
+		return fake.FindAllByUsernameContextStub(ctx, username)
 	} else {
-		return fake.listUsersInOrgForRoleReturns.result1, fake.listUsersInOrgForRoleReturns.result2
+		return fake.findAllByUsernameContextReturns.result1, fake.findAllByUsernameContextReturns.result2
 	}
 }
 
-func (fake *FakeUserRepository) ListUsersInOrgForRoleCallCount() int {
-	fake.listUsersInOrgForRoleMutex.RLock()
-	defer fake.listUsersInOrgForRoleMutex.RUnlock()
-	return len(fake.listUsersInOrgForRoleArgsForCall)
+func (fake *FakeUserRepository) FindAllByUsernameContextCallCount() int {
+	fake.findAllByUsernameContextMutex.RLock()
+	defer fake.findAllByUsernameContextMutex.RUnlock()
+	return len(fake.findAllByUsernameContextArgsForCall)
 }
 
-func (fake *FakeUserRepository) ListUsersInOrgForRoleArgsForCall(i int) (string, models.Role) {
-	fake.listUsersInOrgForRoleMutex.RLock()
-	defer fake.listUsersInOrgForRoleMutex.RUnlock()
+func (fake *FakeUserRepository) FindAllByUsernameContextArgsForCall(i int) (context.Context, string) {
+	fake.findAllByUsernameContextMutex.RLock()
+	defer fake.findAllByUsernameContextMutex.RUnlock()
+	return fake.findAllByUsernameContextArgsForCall[i].ctx, fake.findAllByUsernameContextArgsForCall[i].username
+}
+
+func (fake *FakeUserRepository) FindAllByUsernameContextReturns(result1 []models.UserFields, result2 error) {
+	fake.FindAllByUsernameContextStub = nil
+	fake.findAllByUsernameContextReturns = struct {
+		result1 []models.UserFields
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) FindAllByUsernamePrefix(prefix string) (users []models.UserFields, apiErr error) {
+	fake.findAllByUsernamePrefixMutex.Lock()
+	fake.findAllByUsernamePrefixArgsForCall = append(fake.findAllByUsernamePrefixArgsForCall, struct {
+		prefix string
+	}{prefix})
+	fake.recordInvocation("FindAllByUsernamePrefix", []interface{}{prefix})
+	fake.findAllByUsernamePrefixMutex.Unlock()
+	if fake.FindAllByUsernamePrefixStub != nil {
+		return fake.FindAllByUsernamePrefixStub(prefix)
+	} else {
+		return fake.findAllByUsernamePrefixReturns.result1, fake.findAllByUsernamePrefixReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) FindAllByUsernamePrefixCallCount() int {
+	fake.findAllByUsernamePrefixMutex.RLock()
+	defer fake.findAllByUsernamePrefixMutex.RUnlock()
+	return len(fake.findAllByUsernamePrefixArgsForCall)
+}
+
+func (fake *FakeUserRepository) FindAllByUsernamePrefixArgsForCall(i int) string {
+	fake.findAllByUsernamePrefixMutex.RLock()
+	defer fake.findAllByUsernamePrefixMutex.RUnlock()
+	return fake.findAllByUsernamePrefixArgsForCall[i].prefix
+}
+
+func (fake *FakeUserRepository) FindAllByUsernamePrefixReturns(result1 []models.UserFields, result2 error) {
+	fake.FindAllByUsernamePrefixStub = nil
+	fake.findAllByUsernamePrefixReturns = struct {
+		result1 []models.UserFields
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) FindByUsernames(usernames []string) (users map[string]models.UserFields, apiErr error) {
+	var usernamesCopy []string
+	if usernames != nil {
+		usernamesCopy = make([]string, len(usernames))
+		copy(usernamesCopy, usernames)
+	}
+	fake.findByUsernamesMutex.Lock()
+	fake.findByUsernamesArgsForCall = append(fake.findByUsernamesArgsForCall, struct {
+		usernames []string
+	}{usernamesCopy})
+	fake.recordInvocation("FindByUsernames", []interface{}{usernamesCopy})
+	fake.findByUsernamesMutex.Unlock()
+	if fake.FindByUsernamesStub != nil {
+		return fake.FindByUsernamesStub(usernames)
+	} else {
+		return fake.findByUsernamesReturns.result1, fake.findByUsernamesReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) FindByUsernamesCallCount() int {
+	fake.findByUsernamesMutex.RLock()
+	defer fake.findByUsernamesMutex.RUnlock()
+	return len(fake.findByUsernamesArgsForCall)
+}
+
+func (fake *FakeUserRepository) FindByUsernamesArgsForCall(i int) []string {
+	fake.findByUsernamesMutex.RLock()
+	defer fake.findByUsernamesMutex.RUnlock()
+	return fake.findByUsernamesArgsForCall[i].usernames
+}
+
+func (fake *FakeUserRepository) FindByUsernamesReturns(result1 map[string]models.UserFields, result2 error) {
+	fake.FindByUsernamesStub = nil
+	fake.findByUsernamesReturns = struct {
+		result1 map[string]models.UserFields
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) IsUsernameAvailable(username string) (bool, error) {
+	fake.isUsernameAvailableMutex.Lock()
+	fake.isUsernameAvailableArgsForCall = append(fake.isUsernameAvailableArgsForCall, struct {
+		username string
+	}{username})
+	fake.recordInvocation("IsUsernameAvailable", []interface{}{username})
+	fake.isUsernameAvailableMutex.Unlock()
+	if fake.IsUsernameAvailableStub != nil {
+		return fake.IsUsernameAvailableStub(username)
+	} else {
+		return fake.isUsernameAvailableReturns.result1, fake.isUsernameAvailableReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) IsUsernameAvailableCallCount() int {
+	fake.isUsernameAvailableMutex.RLock()
+	defer fake.isUsernameAvailableMutex.RUnlock()
+	return len(fake.isUsernameAvailableArgsForCall)
+}
+
+func (fake *FakeUserRepository) IsUsernameAvailableArgsForCall(i int) string {
+	fake.isUsernameAvailableMutex.RLock()
+	defer fake.isUsernameAvailableMutex.RUnlock()
+	return fake.isUsernameAvailableArgsForCall[i].username
+}
+
+func (fake *FakeUserRepository) IsUsernameAvailableReturns(result1 bool, result2 error) {
+	fake.IsUsernameAvailableStub = nil
+	fake.isUsernameAvailableReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) VerifyCredentials(username string, password string) (bool, error) {
+	fake.verifyCredentialsMutex.Lock()
+	fake.verifyCredentialsArgsForCall = append(fake.verifyCredentialsArgsForCall, struct {
+		username string
+		password string
+	}{username, password})
+	fake.recordInvocation("VerifyCredentials", []interface{}{username, password})
+	fake.verifyCredentialsMutex.Unlock()
+	if fake.VerifyCredentialsStub != nil {
+		return fake.VerifyCredentialsStub(username, password)
+	} else {
+		return fake.verifyCredentialsReturns.result1, fake.verifyCredentialsReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) VerifyCredentialsCallCount() int {
+	fake.verifyCredentialsMutex.RLock()
+	defer fake.verifyCredentialsMutex.RUnlock()
+	return len(fake.verifyCredentialsArgsForCall)
+}
+
+func (fake *FakeUserRepository) VerifyCredentialsArgsForCall(i int) (string, string) {
+	fake.verifyCredentialsMutex.RLock()
+	defer fake.verifyCredentialsMutex.RUnlock()
+	return fake.verifyCredentialsArgsForCall[i].username, fake.verifyCredentialsArgsForCall[i].password
+}
+
+func (fake *FakeUserRepository) VerifyCredentialsReturns(result1 bool, result2 error) {
+	fake.VerifyCredentialsStub = nil
+	fake.verifyCredentialsReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) ListUsersInOrgForRole(orgGUID string, role models.Role) ([]models.UserFields, error) {
+	fake.listUsersInOrgForRoleMutex.Lock()
+	fake.listUsersInOrgForRoleArgsForCall = append(fake.listUsersInOrgForRoleArgsForCall, struct {
+		orgGUID string
+		role    models.Role
+	}{orgGUID, role})
+	fake.recordInvocation("ListUsersInOrgForRole", []interface{}{orgGUID, role})
+	fake.listUsersInOrgForRoleMutex.Unlock()
+	if fake.ListUsersInOrgForRoleStub != nil {
+		return fake.ListUsersInOrgForRoleStub(orgGUID, role)
+	} else {
+		return fake.listUsersInOrgForRoleReturns.result1, fake.listUsersInOrgForRoleReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) ListUsersInOrgForRoleCallCount() int {
+	fake.listUsersInOrgForRoleMutex.RLock()
+	defer fake.listUsersInOrgForRoleMutex.RUnlock()
+	return len(fake.listUsersInOrgForRoleArgsForCall)
+}
+
+func (fake *FakeUserRepository) ListUsersInOrgForRoleArgsForCall(i int) (string, models.Role) {
+	fake.listUsersInOrgForRoleMutex.RLock()
+	defer fake.listUsersInOrgForRoleMutex.RUnlock()
 	return fake.listUsersInOrgForRoleArgsForCall[i].orgGUID, fake.listUsersInOrgForRoleArgsForCall[i].role
 }
 
@@ -263,6 +987,221 @@ func (fake *FakeUserRepository) ListUsersInOrgForRoleReturns(result1 []models.Us
 	}{result1, result2}
 }
 
+func (fake *FakeUserRepository) ListUsersInOrgForRoleContext(ctx context.Context, orgGUID string, role models.Role) ([]models.UserFields, error) {
+	fake.listUsersInOrgForRoleContextMutex.Lock()
+	fake.listUsersInOrgForRoleContextArgsForCall = append(fake.listUsersInOrgForRoleContextArgsForCall, struct {
+		ctx     context.Context
+		orgGUID string
+		role    models.Role
+	}{ctx, orgGUID, role})
+	fake.recordInvocation("ListUsersInOrgForRoleContext", []interface{}{ctx, orgGUID, role})
+	fake.listUsersInOrgForRoleContextMutex.Unlock()
+	if fake.ListUsersInOrgForRoleContextStub != nil {
+		return fake.ListUsersInOrgForRoleContextStub(ctx, orgGUID, role)
+	} else {
+		return fake.listUsersInOrgForRoleContextReturns.result1, fake.listUsersInOrgForRoleContextReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) ListUsersInOrgForRoleContextCallCount() int {
+	fake.listUsersInOrgForRoleContextMutex.RLock()
+	defer fake.listUsersInOrgForRoleContextMutex.RUnlock()
+	return len(fake.listUsersInOrgForRoleContextArgsForCall)
+}
+
+func (fake *FakeUserRepository) ListUsersInOrgForRoleContextArgsForCall(i int) (context.Context, string, models.Role) {
+	fake.listUsersInOrgForRoleContextMutex.RLock()
+	defer fake.listUsersInOrgForRoleContextMutex.RUnlock()
+	return fake.listUsersInOrgForRoleContextArgsForCall[i].ctx, fake.listUsersInOrgForRoleContextArgsForCall[i].orgGUID, fake.listUsersInOrgForRoleContextArgsForCall[i].role
+}
+
+func (fake *FakeUserRepository) ListUsersInOrgForRoleContextReturns(result1 []models.UserFields, result2 error) {
+	fake.ListUsersInOrgForRoleContextStub = nil
+	fake.listUsersInOrgForRoleContextReturns = struct {
+		result1 []models.UserFields
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) CountUsersInOrgForRole(orgGUID string, role models.Role) (int, error) {
+	fake.countUsersInOrgForRoleMutex.Lock()
+	fake.countUsersInOrgForRoleArgsForCall = append(fake.countUsersInOrgForRoleArgsForCall, struct {
+		orgGUID string
+		role    models.Role
+	}{orgGUID, role})
+	fake.recordInvocation("CountUsersInOrgForRole", []interface{}{orgGUID, role})
+	fake.countUsersInOrgForRoleMutex.Unlock()
+	if fake.CountUsersInOrgForRoleStub != nil {
+		return fake.CountUsersInOrgForRoleStub(orgGUID, role)
+	} else {
+		return fake.countUsersInOrgForRoleReturns.result1, fake.countUsersInOrgForRoleReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) CountUsersInOrgForRoleCallCount() int {
+	fake.countUsersInOrgForRoleMutex.RLock()
+	defer fake.countUsersInOrgForRoleMutex.RUnlock()
+	return len(fake.countUsersInOrgForRoleArgsForCall)
+}
+
+func (fake *FakeUserRepository) CountUsersInOrgForRoleArgsForCall(i int) (string, models.Role) {
+	fake.countUsersInOrgForRoleMutex.RLock()
+	defer fake.countUsersInOrgForRoleMutex.RUnlock()
+	return fake.countUsersInOrgForRoleArgsForCall[i].orgGUID, fake.countUsersInOrgForRoleArgsForCall[i].role
+}
+
+func (fake *FakeUserRepository) CountUsersInOrgForRoleReturns(result1 int, result2 error) {
+	fake.CountUsersInOrgForRoleStub = nil
+	fake.countUsersInOrgForRoleReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) ListUsersInOrgForRoleFiltered(orgGUID string, role models.Role, usernameContains string) ([]models.UserFields, error) {
+	fake.listUsersInOrgForRoleFilteredMutex.Lock()
+	fake.listUsersInOrgForRoleFilteredArgsForCall = append(fake.listUsersInOrgForRoleFilteredArgsForCall, struct {
+		orgGUID          string
+		role             models.Role
+		usernameContains string
+	}{orgGUID, role, usernameContains})
+	fake.recordInvocation("ListUsersInOrgForRoleFiltered", []interface{}{orgGUID, role, usernameContains})
+	fake.listUsersInOrgForRoleFilteredMutex.Unlock()
+	if fake.ListUsersInOrgForRoleFilteredStub != nil {
+		return fake.ListUsersInOrgForRoleFilteredStub(orgGUID, role, usernameContains)
+	} else {
+		return fake.listUsersInOrgForRoleFilteredReturns.result1, fake.listUsersInOrgForRoleFilteredReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) ListUsersInOrgForRoleFilteredCallCount() int {
+	fake.listUsersInOrgForRoleFilteredMutex.RLock()
+	defer fake.listUsersInOrgForRoleFilteredMutex.RUnlock()
+	return len(fake.listUsersInOrgForRoleFilteredArgsForCall)
+}
+
+func (fake *FakeUserRepository) ListUsersInOrgForRoleFilteredArgsForCall(i int) (string, models.Role, string) {
+	fake.listUsersInOrgForRoleFilteredMutex.RLock()
+	defer fake.listUsersInOrgForRoleFilteredMutex.RUnlock()
+	return fake.listUsersInOrgForRoleFilteredArgsForCall[i].orgGUID, fake.listUsersInOrgForRoleFilteredArgsForCall[i].role, fake.listUsersInOrgForRoleFilteredArgsForCall[i].usernameContains
+}
+
+func (fake *FakeUserRepository) ListUsersInOrgForRoleFilteredReturns(result1 []models.UserFields, result2 error) {
+	fake.ListUsersInOrgForRoleFilteredStub = nil
+	fake.listUsersInOrgForRoleFilteredReturns = struct {
+		result1 []models.UserFields
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) ListAllUsersInOrg(orgGUID string) (map[models.Role][]models.UserFields, error) {
+	fake.listAllUsersInOrgMutex.Lock()
+	fake.listAllUsersInOrgArgsForCall = append(fake.listAllUsersInOrgArgsForCall, struct {
+		orgGUID string
+	}{orgGUID})
+	fake.recordInvocation("ListAllUsersInOrg", []interface{}{orgGUID})
+	fake.listAllUsersInOrgMutex.Unlock()
+	if fake.ListAllUsersInOrgStub != nil {
+		return fake.ListAllUsersInOrgStub(orgGUID)
+	} else {
+		return fake.listAllUsersInOrgReturns.result1, fake.listAllUsersInOrgReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) ListAllUsersInOrgCallCount() int {
+	fake.listAllUsersInOrgMutex.RLock()
+	defer fake.listAllUsersInOrgMutex.RUnlock()
+	return len(fake.listAllUsersInOrgArgsForCall)
+}
+
+func (fake *FakeUserRepository) ListAllUsersInOrgArgsForCall(i int) string {
+	fake.listAllUsersInOrgMutex.RLock()
+	defer fake.listAllUsersInOrgMutex.RUnlock()
+	return fake.listAllUsersInOrgArgsForCall[i].orgGUID
+}
+
+func (fake *FakeUserRepository) ListAllUsersInOrgReturns(result1 map[models.Role][]models.UserFields, result2 error) {
+	fake.ListAllUsersInOrgStub = nil
+	fake.listAllUsersInOrgReturns = struct {
+		result1 map[models.Role][]models.UserFields
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) ListUsersInOrgForRoles(orgGUID string, roles []models.Role) (map[models.Role][]models.UserFields, error) {
+	var rolesCopy []models.Role
+	if roles != nil {
+		rolesCopy = make([]models.Role, len(roles))
+		copy(rolesCopy, roles)
+	}
+	fake.listUsersInOrgForRolesMutex.Lock()
+	fake.listUsersInOrgForRolesArgsForCall = append(fake.listUsersInOrgForRolesArgsForCall, struct {
+		orgGUID string
+		roles   []models.Role
+	}{orgGUID, rolesCopy})
+	fake.recordInvocation("ListUsersInOrgForRoles", []interface{}{orgGUID, rolesCopy})
+	fake.listUsersInOrgForRolesMutex.Unlock()
+	if fake.ListUsersInOrgForRolesStub != nil {
+		return fake.ListUsersInOrgForRolesStub(orgGUID, roles)
+	} else {
+		return fake.listUsersInOrgForRolesReturns.result1, fake.listUsersInOrgForRolesReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) ListUsersInOrgForRolesCallCount() int {
+	fake.listUsersInOrgForRolesMutex.RLock()
+	defer fake.listUsersInOrgForRolesMutex.RUnlock()
+	return len(fake.listUsersInOrgForRolesArgsForCall)
+}
+
+func (fake *FakeUserRepository) ListUsersInOrgForRolesArgsForCall(i int) (string, []models.Role) {
+	fake.listUsersInOrgForRolesMutex.RLock()
+	defer fake.listUsersInOrgForRolesMutex.RUnlock()
+	return fake.listUsersInOrgForRolesArgsForCall[i].orgGUID, fake.listUsersInOrgForRolesArgsForCall[i].roles
+}
+
+func (fake *FakeUserRepository) ListUsersInOrgForRolesReturns(result1 map[models.Role][]models.UserFields, result2 error) {
+	fake.ListUsersInOrgForRolesStub = nil
+	fake.listUsersInOrgForRolesReturns = struct {
+		result1 map[models.Role][]models.UserFields
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) ListUsersInSpaceForAllRoles(spaceGUID string) (map[models.Role][]models.UserFields, error) {
+	fake.listUsersInSpaceForAllRolesMutex.Lock()
+	fake.listUsersInSpaceForAllRolesArgsForCall = append(fake.listUsersInSpaceForAllRolesArgsForCall, struct {
+		spaceGUID string
+	}{spaceGUID})
+	fake.recordInvocation("ListUsersInSpaceForAllRoles", []interface{}{spaceGUID})
+	fake.listUsersInSpaceForAllRolesMutex.Unlock()
+	if fake.ListUsersInSpaceForAllRolesStub != nil {
+		return fake.ListUsersInSpaceForAllRolesStub(spaceGUID)
+	} else {
+		return fake.listUsersInSpaceForAllRolesReturns.result1, fake.listUsersInSpaceForAllRolesReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) ListUsersInSpaceForAllRolesCallCount() int {
+	fake.listUsersInSpaceForAllRolesMutex.RLock()
+	defer fake.listUsersInSpaceForAllRolesMutex.RUnlock()
+	return len(fake.listUsersInSpaceForAllRolesArgsForCall)
+}
+
+func (fake *FakeUserRepository) ListUsersInSpaceForAllRolesArgsForCall(i int) string {
+	fake.listUsersInSpaceForAllRolesMutex.RLock()
+	defer fake.listUsersInSpaceForAllRolesMutex.RUnlock()
+	return fake.listUsersInSpaceForAllRolesArgsForCall[i].spaceGUID
+}
+
+func (fake *FakeUserRepository) ListUsersInSpaceForAllRolesReturns(result1 map[models.Role][]models.UserFields, result2 error) {
+	fake.ListUsersInSpaceForAllRolesStub = nil
+	fake.listUsersInSpaceForAllRolesReturns = struct {
+		result1 map[models.Role][]models.UserFields
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeUserRepository) ListUsersInOrgForRoleWithNoUAA(orgGUID string, role models.Role) ([]models.UserFields, error) {
 	fake.listUsersInOrgForRoleWithNoUAAMutex.Lock()
 	fake.listUsersInOrgForRoleWithNoUAAArgsForCall = append(fake.listUsersInOrgForRoleWithNoUAAArgsForCall, struct {
@@ -298,6 +1237,41 @@ func (fake *FakeUserRepository) ListUsersInOrgForRoleWithNoUAAReturns(result1 []
 	}{result1, result2}
 }
 
+func (fake *FakeUserRepository) ListInactiveOrgUsers(orgGUID string, inactiveFor time.Duration) ([]models.UserFields, error) {
+	fake.listInactiveOrgUsersMutex.Lock()
+	fake.listInactiveOrgUsersArgsForCall = append(fake.listInactiveOrgUsersArgsForCall, struct {
+		orgGUID     string
+		inactiveFor time.Duration
+	}{orgGUID, inactiveFor})
+	fake.recordInvocation("ListInactiveOrgUsers", []interface{}{orgGUID, inactiveFor})
+	fake.listInactiveOrgUsersMutex.Unlock()
+	if fake.ListInactiveOrgUsersStub != nil {
+		return fake.ListInactiveOrgUsersStub(orgGUID, inactiveFor)
+	} else {
+		return fake.listInactiveOrgUsersReturns.result1, fake.listInactiveOrgUsersReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) ListInactiveOrgUsersCallCount() int {
+	fake.listInactiveOrgUsersMutex.RLock()
+	defer fake.listInactiveOrgUsersMutex.RUnlock()
+	return len(fake.listInactiveOrgUsersArgsForCall)
+}
+
+func (fake *FakeUserRepository) ListInactiveOrgUsersArgsForCall(i int) (string, time.Duration) {
+	fake.listInactiveOrgUsersMutex.RLock()
+	defer fake.listInactiveOrgUsersMutex.RUnlock()
+	return fake.listInactiveOrgUsersArgsForCall[i].orgGUID, fake.listInactiveOrgUsersArgsForCall[i].inactiveFor
+}
+
+func (fake *FakeUserRepository) ListInactiveOrgUsersReturns(result1 []models.UserFields, result2 error) {
+	fake.ListInactiveOrgUsersStub = nil
+	fake.listInactiveOrgUsersReturns = struct {
+		result1 []models.UserFields
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeUserRepository) ListUsersInSpaceForRoleWithNoUAA(spaceGUID string, role models.Role) ([]models.UserFields, error) {
 	fake.listUsersInSpaceForRoleWithNoUAAMutex.Lock()
 	fake.listUsersInSpaceForRoleWithNoUAAArgsForCall = append(fake.listUsersInSpaceForRoleWithNoUAAArgsForCall, struct {
@@ -333,6 +1307,497 @@ func (fake *FakeUserRepository) ListUsersInSpaceForRoleWithNoUAAReturns(result1
 	}{result1, result2}
 }
 
+func (fake *FakeUserRepository) ListOrgUsersWithSpaceRoles(orgGUID string) ([]models.OrgUserWithSpaceRoles, error) {
+	fake.listOrgUsersWithSpaceRolesMutex.Lock()
+	fake.listOrgUsersWithSpaceRolesArgsForCall = append(fake.listOrgUsersWithSpaceRolesArgsForCall, struct {
+		orgGUID string
+	}{orgGUID})
+	fake.recordInvocation("ListOrgUsersWithSpaceRoles", []interface{}{orgGUID})
+	fake.listOrgUsersWithSpaceRolesMutex.Unlock()
+	if fake.ListOrgUsersWithSpaceRolesStub != nil {
+		return fake.ListOrgUsersWithSpaceRolesStub(orgGUID)
+	} else {
+		return fake.listOrgUsersWithSpaceRolesReturns.result1, fake.listOrgUsersWithSpaceRolesReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) ListOrgUsersWithSpaceRolesCallCount() int {
+	fake.listOrgUsersWithSpaceRolesMutex.RLock()
+	defer fake.listOrgUsersWithSpaceRolesMutex.RUnlock()
+	return len(fake.listOrgUsersWithSpaceRolesArgsForCall)
+}
+
+func (fake *FakeUserRepository) ListOrgUsersWithSpaceRolesArgsForCall(i int) string {
+	fake.listOrgUsersWithSpaceRolesMutex.RLock()
+	defer fake.listOrgUsersWithSpaceRolesMutex.RUnlock()
+	return fake.listOrgUsersWithSpaceRolesArgsForCall[i].orgGUID
+}
+
+func (fake *FakeUserRepository) ListOrgUsersWithSpaceRolesReturns(result1 []models.OrgUserWithSpaceRoles, result2 error) {
+	fake.ListOrgUsersWithSpaceRolesStub = nil
+	fake.listOrgUsersWithSpaceRolesReturns = struct {
+		result1 []models.OrgUserWithSpaceRoles
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) FindDuplicateCCRegistrations() ([]models.DuplicateUserRegistration, error) {
+	fake.findDuplicateCCRegistrationsMutex.Lock()
+	fake.findDuplicateCCRegistrationsArgsForCall = append(fake.findDuplicateCCRegistrationsArgsForCall, struct{}{})
+	fake.recordInvocation("FindDuplicateCCRegistrations", []interface{}{})
+	fake.findDuplicateCCRegistrationsMutex.Unlock()
+	if fake.FindDuplicateCCRegistrationsStub != nil {
+		return fake.FindDuplicateCCRegistrationsStub()
+	} else {
+		return fake.findDuplicateCCRegistrationsReturns.result1, fake.findDuplicateCCRegistrationsReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) FindDuplicateCCRegistrationsCallCount() int {
+	fake.findDuplicateCCRegistrationsMutex.RLock()
+	defer fake.findDuplicateCCRegistrationsMutex.RUnlock()
+	return len(fake.findDuplicateCCRegistrationsArgsForCall)
+}
+
+func (fake *FakeUserRepository) FindDuplicateCCRegistrationsReturns(result1 []models.DuplicateUserRegistration, result2 error) {
+	fake.FindDuplicateCCRegistrationsStub = nil
+	fake.findDuplicateCCRegistrationsReturns = struct {
+		result1 []models.DuplicateUserRegistration
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) FindUnmatchedUsers() (models.UnmatchedUsers, error) {
+	fake.findUnmatchedUsersMutex.Lock()
+	fake.findUnmatchedUsersArgsForCall = append(fake.findUnmatchedUsersArgsForCall, struct{}{})
+	fake.recordInvocation("FindUnmatchedUsers", []interface{}{})
+	fake.findUnmatchedUsersMutex.Unlock()
+	if fake.FindUnmatchedUsersStub != nil {
+		return fake.FindUnmatchedUsersStub()
+	} else {
+		return fake.findUnmatchedUsersReturns.result1, fake.findUnmatchedUsersReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) FindUnmatchedUsersCallCount() int {
+	fake.findUnmatchedUsersMutex.RLock()
+	defer fake.findUnmatchedUsersMutex.RUnlock()
+	return len(fake.findUnmatchedUsersArgsForCall)
+}
+
+func (fake *FakeUserRepository) FindUnmatchedUsersReturns(result1 models.UnmatchedUsers, result2 error) {
+	fake.FindUnmatchedUsersStub = nil
+	fake.findUnmatchedUsersReturns = struct {
+		result1 models.UnmatchedUsers
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) FindRolelessUsers() ([]models.UserFields, error) {
+	fake.findRolelessUsersMutex.Lock()
+	fake.findRolelessUsersArgsForCall = append(fake.findRolelessUsersArgsForCall, struct{}{})
+	fake.recordInvocation("FindRolelessUsers", []interface{}{})
+	fake.findRolelessUsersMutex.Unlock()
+	if fake.FindRolelessUsersStub != nil {
+		return fake.FindRolelessUsersStub()
+	} else {
+		return fake.findRolelessUsersReturns.result1, fake.findRolelessUsersReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) FindRolelessUsersCallCount() int {
+	fake.findRolelessUsersMutex.RLock()
+	defer fake.findRolelessUsersMutex.RUnlock()
+	return len(fake.findRolelessUsersArgsForCall)
+}
+
+func (fake *FakeUserRepository) FindRolelessUsersReturns(result1 []models.UserFields, result2 error) {
+	fake.FindRolelessUsersStub = nil
+	fake.findRolelessUsersReturns = struct {
+		result1 []models.UserFields
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) GetUserAccessSummary(userGUID string, resolveNames bool) (models.UserAccessSummary, error) {
+	fake.getUserAccessSummaryMutex.Lock()
+	fake.getUserAccessSummaryArgsForCall = append(fake.getUserAccessSummaryArgsForCall, struct {
+		userGUID     string
+		resolveNames bool
+	}{userGUID, resolveNames})
+	fake.recordInvocation("GetUserAccessSummary", []interface{}{userGUID, resolveNames})
+	fake.getUserAccessSummaryMutex.Unlock()
+	if fake.GetUserAccessSummaryStub != nil {
+		return fake.GetUserAccessSummaryStub(userGUID, resolveNames)
+	} else {
+		return fake.getUserAccessSummaryReturns.result1, fake.getUserAccessSummaryReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) GetUserAccessSummaryCallCount() int {
+	fake.getUserAccessSummaryMutex.RLock()
+	defer fake.getUserAccessSummaryMutex.RUnlock()
+	return len(fake.getUserAccessSummaryArgsForCall)
+}
+
+func (fake *FakeUserRepository) GetUserAccessSummaryArgsForCall(i int) (string, bool) {
+	fake.getUserAccessSummaryMutex.RLock()
+	defer fake.getUserAccessSummaryMutex.RUnlock()
+	return fake.getUserAccessSummaryArgsForCall[i].userGUID, fake.getUserAccessSummaryArgsForCall[i].resolveNames
+}
+
+func (fake *FakeUserRepository) GetUserAccessSummaryReturns(result1 models.UserAccessSummary, result2 error) {
+	fake.GetUserAccessSummaryStub = nil
+	fake.getUserAccessSummaryReturns = struct {
+		result1 models.UserAccessSummary
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) GetUserMemberships(userGUID string, orgGUID string) (models.UserMemberships, error) {
+	fake.getUserMembershipsMutex.Lock()
+	fake.getUserMembershipsArgsForCall = append(fake.getUserMembershipsArgsForCall, struct {
+		userGUID string
+		orgGUID  string
+	}{userGUID, orgGUID})
+	fake.recordInvocation("GetUserMemberships", []interface{}{userGUID, orgGUID})
+	fake.getUserMembershipsMutex.Unlock()
+	if fake.GetUserMembershipsStub != nil {
+		return fake.GetUserMembershipsStub(userGUID, orgGUID)
+	} else {
+		return fake.getUserMembershipsReturns.result1, fake.getUserMembershipsReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) GetUserMembershipsCallCount() int {
+	fake.getUserMembershipsMutex.RLock()
+	defer fake.getUserMembershipsMutex.RUnlock()
+	return len(fake.getUserMembershipsArgsForCall)
+}
+
+func (fake *FakeUserRepository) GetUserMembershipsArgsForCall(i int) (string, string) {
+	fake.getUserMembershipsMutex.RLock()
+	defer fake.getUserMembershipsMutex.RUnlock()
+	return fake.getUserMembershipsArgsForCall[i].userGUID, fake.getUserMembershipsArgsForCall[i].orgGUID
+}
+
+func (fake *FakeUserRepository) GetUserMembershipsReturns(result1 models.UserMemberships, result2 error) {
+	fake.GetUserMembershipsStub = nil
+	fake.getUserMembershipsReturns = struct {
+		result1 models.UserMemberships
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) GetUserRolesInOrg(userGUID string, orgGUID string) ([]models.Role, error) {
+	fake.getUserRolesInOrgMutex.Lock()
+	fake.getUserRolesInOrgArgsForCall = append(fake.getUserRolesInOrgArgsForCall, struct {
+		userGUID string
+		orgGUID  string
+	}{userGUID, orgGUID})
+	fake.recordInvocation("GetUserRolesInOrg", []interface{}{userGUID, orgGUID})
+	fake.getUserRolesInOrgMutex.Unlock()
+	if fake.GetUserRolesInOrgStub != nil {
+		return fake.GetUserRolesInOrgStub(userGUID, orgGUID)
+	} else {
+		return fake.getUserRolesInOrgReturns.result1, fake.getUserRolesInOrgReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) GetUserRolesInOrgCallCount() int {
+	fake.getUserRolesInOrgMutex.RLock()
+	defer fake.getUserRolesInOrgMutex.RUnlock()
+	return len(fake.getUserRolesInOrgArgsForCall)
+}
+
+func (fake *FakeUserRepository) GetUserRolesInOrgArgsForCall(i int) (string, string) {
+	fake.getUserRolesInOrgMutex.RLock()
+	defer fake.getUserRolesInOrgMutex.RUnlock()
+	return fake.getUserRolesInOrgArgsForCall[i].userGUID, fake.getUserRolesInOrgArgsForCall[i].orgGUID
+}
+
+func (fake *FakeUserRepository) GetUserRolesInOrgReturns(result1 []models.Role, result2 error) {
+	fake.GetUserRolesInOrgStub = nil
+	fake.getUserRolesInOrgReturns = struct {
+		result1 []models.Role
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) CopyRoles(fromUserGUID string, toUserGUID string, orgGUID string) ([]models.RoleChangeResult, error) {
+	fake.copyRolesMutex.Lock()
+	fake.copyRolesArgsForCall = append(fake.copyRolesArgsForCall, struct {
+		fromUserGUID string
+		toUserGUID   string
+		orgGUID      string
+	}{fromUserGUID, toUserGUID, orgGUID})
+	fake.recordInvocation("CopyRoles", []interface{}{fromUserGUID, toUserGUID, orgGUID})
+	fake.copyRolesMutex.Unlock()
+	if fake.CopyRolesStub != nil {
+		return fake.CopyRolesStub(fromUserGUID, toUserGUID, orgGUID)
+	} else {
+		return fake.copyRolesReturns.result1, fake.copyRolesReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) CopyRolesCallCount() int {
+	fake.copyRolesMutex.RLock()
+	defer fake.copyRolesMutex.RUnlock()
+	return len(fake.copyRolesArgsForCall)
+}
+
+func (fake *FakeUserRepository) CopyRolesArgsForCall(i int) (string, string, string) {
+	fake.copyRolesMutex.RLock()
+	defer fake.copyRolesMutex.RUnlock()
+	return fake.copyRolesArgsForCall[i].fromUserGUID, fake.copyRolesArgsForCall[i].toUserGUID, fake.copyRolesArgsForCall[i].orgGUID
+}
+
+func (fake *FakeUserRepository) CopyRolesReturns(result1 []models.RoleChangeResult, result2 error) {
+	fake.CopyRolesStub = nil
+	fake.copyRolesReturns = struct {
+		result1 []models.RoleChangeResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) CopySpaceRoles(fromUserGUID string, toUserGUID string, spaceGUID string, orgGUID string) ([]models.RoleChangeResult, error) {
+	fake.copySpaceRolesMutex.Lock()
+	fake.copySpaceRolesArgsForCall = append(fake.copySpaceRolesArgsForCall, struct {
+		fromUserGUID string
+		toUserGUID   string
+		spaceGUID    string
+		orgGUID      string
+	}{fromUserGUID, toUserGUID, spaceGUID, orgGUID})
+	fake.recordInvocation("CopySpaceRoles", []interface{}{fromUserGUID, toUserGUID, spaceGUID, orgGUID})
+	fake.copySpaceRolesMutex.Unlock()
+	if fake.CopySpaceRolesStub != nil {
+		return fake.CopySpaceRolesStub(fromUserGUID, toUserGUID, spaceGUID, orgGUID)
+	} else {
+		return fake.copySpaceRolesReturns.result1, fake.copySpaceRolesReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) CopySpaceRolesCallCount() int {
+	fake.copySpaceRolesMutex.RLock()
+	defer fake.copySpaceRolesMutex.RUnlock()
+	return len(fake.copySpaceRolesArgsForCall)
+}
+
+func (fake *FakeUserRepository) CopySpaceRolesArgsForCall(i int) (string, string, string, string) {
+	fake.copySpaceRolesMutex.RLock()
+	defer fake.copySpaceRolesMutex.RUnlock()
+	return fake.copySpaceRolesArgsForCall[i].fromUserGUID, fake.copySpaceRolesArgsForCall[i].toUserGUID, fake.copySpaceRolesArgsForCall[i].spaceGUID, fake.copySpaceRolesArgsForCall[i].orgGUID
+}
+
+func (fake *FakeUserRepository) CopySpaceRolesReturns(result1 []models.RoleChangeResult, result2 error) {
+	fake.CopySpaceRolesStub = nil
+	fake.copySpaceRolesReturns = struct {
+		result1 []models.RoleChangeResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) ExportUsersSCIM(w io.Writer) error {
+	fake.exportUsersSCIMMutex.Lock()
+	fake.exportUsersSCIMArgsForCall = append(fake.exportUsersSCIMArgsForCall, struct {
+		w io.Writer
+	}{w})
+	fake.recordInvocation("ExportUsersSCIM", []interface{}{w})
+	fake.exportUsersSCIMMutex.Unlock()
+	if fake.ExportUsersSCIMStub != nil {
+		return fake.ExportUsersSCIMStub(w)
+	} else {
+		return fake.exportUsersSCIMReturns.result1
+	}
+}
+
+func (fake *FakeUserRepository) ExportUsersSCIMCallCount() int {
+	fake.exportUsersSCIMMutex.RLock()
+	defer fake.exportUsersSCIMMutex.RUnlock()
+	return len(fake.exportUsersSCIMArgsForCall)
+}
+
+func (fake *FakeUserRepository) ExportUsersSCIMArgsForCall(i int) io.Writer {
+	fake.exportUsersSCIMMutex.RLock()
+	defer fake.exportUsersSCIMMutex.RUnlock()
+	return fake.exportUsersSCIMArgsForCall[i].w
+}
+
+func (fake *FakeUserRepository) ExportUsersSCIMReturns(result1 error) {
+	fake.ExportUsersSCIMStub = nil
+	fake.exportUsersSCIMReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) ExportFoundationRBAC(w io.Writer) error {
+	fake.exportFoundationRBACMutex.Lock()
+	fake.exportFoundationRBACArgsForCall = append(fake.exportFoundationRBACArgsForCall, struct {
+		w io.Writer
+	}{w})
+	fake.recordInvocation("ExportFoundationRBAC", []interface{}{w})
+	fake.exportFoundationRBACMutex.Unlock()
+	if fake.ExportFoundationRBACStub != nil {
+		return fake.ExportFoundationRBACStub(w)
+	} else {
+		return fake.exportFoundationRBACReturns.result1
+	}
+}
+
+func (fake *FakeUserRepository) ExportFoundationRBACCallCount() int {
+	fake.exportFoundationRBACMutex.RLock()
+	defer fake.exportFoundationRBACMutex.RUnlock()
+	return len(fake.exportFoundationRBACArgsForCall)
+}
+
+func (fake *FakeUserRepository) ExportFoundationRBACArgsForCall(i int) io.Writer {
+	fake.exportFoundationRBACMutex.RLock()
+	defer fake.exportFoundationRBACMutex.RUnlock()
+	return fake.exportFoundationRBACArgsForCall[i].w
+}
+
+func (fake *FakeUserRepository) ExportFoundationRBACReturns(result1 error) {
+	fake.ExportFoundationRBACStub = nil
+	fake.exportFoundationRBACReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) EachRolelessUser(cb func(models.UserFields) bool) error {
+	fake.eachRolelessUserMutex.Lock()
+	fake.eachRolelessUserArgsForCall = append(fake.eachRolelessUserArgsForCall, struct {
+		cb func(models.UserFields) bool
+	}{cb})
+	fake.recordInvocation("EachRolelessUser", []interface{}{cb})
+	fake.eachRolelessUserMutex.Unlock()
+	if fake.EachRolelessUserStub != nil {
+		return fake.EachRolelessUserStub(cb)
+	} else {
+		return fake.eachRolelessUserReturns.result1
+	}
+}
+
+func (fake *FakeUserRepository) EachRolelessUserCallCount() int {
+	fake.eachRolelessUserMutex.RLock()
+	defer fake.eachRolelessUserMutex.RUnlock()
+	return len(fake.eachRolelessUserArgsForCall)
+}
+
+func (fake *FakeUserRepository) EachRolelessUserArgsForCall(i int) func(models.UserFields) bool {
+	fake.eachRolelessUserMutex.RLock()
+	defer fake.eachRolelessUserMutex.RUnlock()
+	return fake.eachRolelessUserArgsForCall[i].cb
+}
+
+func (fake *FakeUserRepository) EachRolelessUserReturns(result1 error) {
+	fake.EachRolelessUserStub = nil
+	fake.eachRolelessUserReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) ListAllUsers(cb func(models.UserFields) bool) error {
+	fake.listAllUsersMutex.Lock()
+	fake.listAllUsersArgsForCall = append(fake.listAllUsersArgsForCall, struct {
+		cb func(models.UserFields) bool
+	}{cb})
+	fake.recordInvocation("ListAllUsers", []interface{}{cb})
+	fake.listAllUsersMutex.Unlock()
+	if fake.ListAllUsersStub != nil {
+		return fake.ListAllUsersStub(cb)
+	} else {
+		return fake.listAllUsersReturns.result1
+	}
+}
+
+func (fake *FakeUserRepository) ListAllUsersCallCount() int {
+	fake.listAllUsersMutex.RLock()
+	defer fake.listAllUsersMutex.RUnlock()
+	return len(fake.listAllUsersArgsForCall)
+}
+
+func (fake *FakeUserRepository) ListAllUsersArgsForCall(i int) func(models.UserFields) bool {
+	fake.listAllUsersMutex.RLock()
+	defer fake.listAllUsersMutex.RUnlock()
+	return fake.listAllUsersArgsForCall[i].cb
+}
+
+func (fake *FakeUserRepository) ListAllUsersReturns(result1 error) {
+	fake.ListAllUsersStub = nil
+	fake.listAllUsersReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) ListCFUsersInUAAGroup(groupName string) ([]models.UserFields, error) {
+	fake.listCFUsersInUAAGroupMutex.Lock()
+	fake.listCFUsersInUAAGroupArgsForCall = append(fake.listCFUsersInUAAGroupArgsForCall, struct {
+		groupName string
+	}{groupName})
+	fake.recordInvocation("ListCFUsersInUAAGroup", []interface{}{groupName})
+	fake.listCFUsersInUAAGroupMutex.Unlock()
+	if fake.ListCFUsersInUAAGroupStub != nil {
+		return fake.ListCFUsersInUAAGroupStub(groupName)
+	} else {
+		return fake.listCFUsersInUAAGroupReturns.result1, fake.listCFUsersInUAAGroupReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) ListCFUsersInUAAGroupCallCount() int {
+	fake.listCFUsersInUAAGroupMutex.RLock()
+	defer fake.listCFUsersInUAAGroupMutex.RUnlock()
+	return len(fake.listCFUsersInUAAGroupArgsForCall)
+}
+
+func (fake *FakeUserRepository) ListCFUsersInUAAGroupArgsForCall(i int) string {
+	fake.listCFUsersInUAAGroupMutex.RLock()
+	defer fake.listCFUsersInUAAGroupMutex.RUnlock()
+	return fake.listCFUsersInUAAGroupArgsForCall[i].groupName
+}
+
+func (fake *FakeUserRepository) ListCFUsersInUAAGroupReturns(result1 []models.UserFields, result2 error) {
+	fake.ListCFUsersInUAAGroupStub = nil
+	fake.listCFUsersInUAAGroupReturns = struct {
+		result1 []models.UserFields
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) DiffSpaceUsers(spaceAGUID string, spaceBGUID string) ([]models.SpaceRoleDiff, error) {
+	fake.diffSpaceUsersMutex.Lock()
+	fake.diffSpaceUsersArgsForCall = append(fake.diffSpaceUsersArgsForCall, struct {
+		spaceAGUID string
+		spaceBGUID string
+	}{spaceAGUID, spaceBGUID})
+	fake.recordInvocation("DiffSpaceUsers", []interface{}{spaceAGUID, spaceBGUID})
+	fake.diffSpaceUsersMutex.Unlock()
+	if fake.DiffSpaceUsersStub != nil {
+		return fake.DiffSpaceUsersStub(spaceAGUID, spaceBGUID)
+	} else {
+		return fake.diffSpaceUsersReturns.result1, fake.diffSpaceUsersReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) DiffSpaceUsersCallCount() int {
+	fake.diffSpaceUsersMutex.RLock()
+	defer fake.diffSpaceUsersMutex.RUnlock()
+	return len(fake.diffSpaceUsersArgsForCall)
+}
+
+func (fake *FakeUserRepository) DiffSpaceUsersArgsForCall(i int) (string, string) {
+	fake.diffSpaceUsersMutex.RLock()
+	defer fake.diffSpaceUsersMutex.RUnlock()
+	return fake.diffSpaceUsersArgsForCall[i].spaceAGUID, fake.diffSpaceUsersArgsForCall[i].spaceBGUID
+}
+
+func (fake *FakeUserRepository) DiffSpaceUsersReturns(result1 []models.SpaceRoleDiff, result2 error) {
+	fake.DiffSpaceUsersStub = nil
+	fake.diffSpaceUsersReturns = struct {
+		result1 []models.SpaceRoleDiff
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeUserRepository) Create(username string, password string) (apiErr error) {
 	fake.createMutex.Lock()
 	fake.createArgsForCall = append(fake.createArgsForCall, struct {
@@ -348,23 +1813,132 @@ func (fake *FakeUserRepository) Create(username string, password string) (apiErr
 	}
 }
 
-func (fake *FakeUserRepository) CreateCallCount() int {
-	fake.createMutex.RLock()
-	defer fake.createMutex.RUnlock()
-	return len(fake.createArgsForCall)
+func (fake *FakeUserRepository) CreateCallCount() int {
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	return len(fake.createArgsForCall)
+}
+
+func (fake *FakeUserRepository) CreateArgsForCall(i int) (string, string) {
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	return fake.createArgsForCall[i].username, fake.createArgsForCall[i].password
+}
+
+func (fake *FakeUserRepository) CreateReturns(result1 error) {
+	fake.CreateStub = nil
+	fake.createReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) CreateWithEmail(username string, password string, email string) (apiErr error) {
+	fake.createWithEmailMutex.Lock()
+	fake.createWithEmailArgsForCall = append(fake.createWithEmailArgsForCall, struct {
+		username string
+		password string
+		email    string
+	}{username, password, email})
+	fake.recordInvocation("CreateWithEmail", []interface{}{username, password, email})
+	fake.createWithEmailMutex.Unlock()
+	if fake.CreateWithEmailStub != nil {
+		return fake.CreateWithEmailStub(username, password, email)
+	} else {
+		return fake.createWithEmailReturns.result1
+	}
+}
+
+func (fake *FakeUserRepository) CreateWithEmailCallCount() int {
+	fake.createWithEmailMutex.RLock()
+	defer fake.createWithEmailMutex.RUnlock()
+	return len(fake.createWithEmailArgsForCall)
+}
+
+func (fake *FakeUserRepository) CreateWithEmailArgsForCall(i int) (string, string, string) {
+	fake.createWithEmailMutex.RLock()
+	defer fake.createWithEmailMutex.RUnlock()
+	return fake.createWithEmailArgsForCall[i].username, fake.createWithEmailArgsForCall[i].password, fake.createWithEmailArgsForCall[i].email
+}
+
+func (fake *FakeUserRepository) CreateWithEmailReturns(result1 error) {
+	fake.CreateWithEmailStub = nil
+	fake.createWithEmailReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) CreateWithOrigin(username string, password string, origin string) (apiErr error) {
+	fake.createWithOriginMutex.Lock()
+	fake.createWithOriginArgsForCall = append(fake.createWithOriginArgsForCall, struct {
+		username string
+		password string
+		origin   string
+	}{username, password, origin})
+	fake.recordInvocation("CreateWithOrigin", []interface{}{username, password, origin})
+	fake.createWithOriginMutex.Unlock()
+	if fake.CreateWithOriginStub != nil {
+		return fake.CreateWithOriginStub(username, password, origin)
+	} else {
+		return fake.createWithOriginReturns.result1
+	}
+}
+
+func (fake *FakeUserRepository) CreateWithOriginCallCount() int {
+	fake.createWithOriginMutex.RLock()
+	defer fake.createWithOriginMutex.RUnlock()
+	return len(fake.createWithOriginArgsForCall)
+}
+
+func (fake *FakeUserRepository) CreateWithOriginArgsForCall(i int) (string, string, string) {
+	fake.createWithOriginMutex.RLock()
+	defer fake.createWithOriginMutex.RUnlock()
+	return fake.createWithOriginArgsForCall[i].username, fake.createWithOriginArgsForCall[i].password, fake.createWithOriginArgsForCall[i].origin
+}
+
+func (fake *FakeUserRepository) CreateWithOriginReturns(result1 error) {
+	fake.CreateWithOriginStub = nil
+	fake.createWithOriginReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) CreateBulk(users []models.UserCreateRequest) ([]models.UserCreateResult, error) {
+	var usersCopy []models.UserCreateRequest
+	if users != nil {
+		usersCopy = make([]models.UserCreateRequest, len(users))
+		copy(usersCopy, users)
+	}
+	fake.createBulkMutex.Lock()
+	fake.createBulkArgsForCall = append(fake.createBulkArgsForCall, struct {
+		users []models.UserCreateRequest
+	}{usersCopy})
+	fake.recordInvocation("CreateBulk", []interface{}{usersCopy})
+	fake.createBulkMutex.Unlock()
+	if fake.CreateBulkStub != nil {
+		return fake.CreateBulkStub(users)
+	} else {
+		return fake.createBulkReturns.result1, fake.createBulkReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) CreateBulkCallCount() int {
+	fake.createBulkMutex.RLock()
+	defer fake.createBulkMutex.RUnlock()
+	return len(fake.createBulkArgsForCall)
 }
 
-func (fake *FakeUserRepository) CreateArgsForCall(i int) (string, string) {
-	fake.createMutex.RLock()
-	defer fake.createMutex.RUnlock()
-	return fake.createArgsForCall[i].username, fake.createArgsForCall[i].password
+func (fake *FakeUserRepository) CreateBulkArgsForCall(i int) []models.UserCreateRequest {
+	fake.createBulkMutex.RLock()
+	defer fake.createBulkMutex.RUnlock()
+	return fake.createBulkArgsForCall[i].users
 }
 
-func (fake *FakeUserRepository) CreateReturns(result1 error) {
-	fake.CreateStub = nil
-	fake.createReturns = struct {
-		result1 error
-	}{result1}
+func (fake *FakeUserRepository) CreateBulkReturns(result1 []models.UserCreateResult, result2 error) {
+	fake.CreateBulkStub = nil
+	fake.createBulkReturns = struct {
+		result1 []models.UserCreateResult
+		result2 error
+	}{result1, result2}
 }
 
 func (fake *FakeUserRepository) Delete(userGUID string) (apiErr error) {
@@ -400,6 +1974,108 @@ func (fake *FakeUserRepository) DeleteReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeUserRepository) DeleteAsync(userGUID string) (apiErr error) {
+	fake.deleteAsyncMutex.Lock()
+	fake.deleteAsyncArgsForCall = append(fake.deleteAsyncArgsForCall, struct {
+		userGUID string
+	}{userGUID})
+	fake.recordInvocation("DeleteAsync", []interface{}{userGUID})
+	fake.deleteAsyncMutex.Unlock()
+	if fake.DeleteAsyncStub != nil {
+		return fake.DeleteAsyncStub(userGUID)
+	} else {
+		return fake.deleteAsyncReturns.result1
+	}
+}
+
+func (fake *FakeUserRepository) DeleteAsyncCallCount() int {
+	fake.deleteAsyncMutex.RLock()
+	defer fake.deleteAsyncMutex.RUnlock()
+	return len(fake.deleteAsyncArgsForCall)
+}
+
+func (fake *FakeUserRepository) DeleteAsyncArgsForCall(i int) string {
+	fake.deleteAsyncMutex.RLock()
+	defer fake.deleteAsyncMutex.RUnlock()
+	return fake.deleteAsyncArgsForCall[i].userGUID
+}
+
+func (fake *FakeUserRepository) DeleteAsyncReturns(result1 error) {
+	fake.DeleteAsyncStub = nil
+	fake.deleteAsyncReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) RenameUser(userGUID string, newUsername string) (apiErr error) {
+	fake.renameUserMutex.Lock()
+	fake.renameUserArgsForCall = append(fake.renameUserArgsForCall, struct {
+		userGUID    string
+		newUsername string
+	}{userGUID, newUsername})
+	fake.recordInvocation("RenameUser", []interface{}{userGUID, newUsername})
+	fake.renameUserMutex.Unlock()
+	if fake.RenameUserStub != nil {
+		return fake.RenameUserStub(userGUID, newUsername)
+	} else {
+		return fake.renameUserReturns.result1
+	}
+}
+
+func (fake *FakeUserRepository) RenameUserCallCount() int {
+	fake.renameUserMutex.RLock()
+	defer fake.renameUserMutex.RUnlock()
+	return len(fake.renameUserArgsForCall)
+}
+
+func (fake *FakeUserRepository) RenameUserArgsForCall(i int) (string, string) {
+	fake.renameUserMutex.RLock()
+	defer fake.renameUserMutex.RUnlock()
+	return fake.renameUserArgsForCall[i].userGUID, fake.renameUserArgsForCall[i].newUsername
+}
+
+func (fake *FakeUserRepository) RenameUserReturns(result1 error) {
+	fake.RenameUserStub = nil
+	fake.renameUserReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) UpdatePassword(userGUID string, oldPassword string, newPassword string) (apiErr error) {
+	fake.updatePasswordMutex.Lock()
+	fake.updatePasswordArgsForCall = append(fake.updatePasswordArgsForCall, struct {
+		userGUID    string
+		oldPassword string
+		newPassword string
+	}{userGUID, oldPassword, newPassword})
+	fake.recordInvocation("UpdatePassword", []interface{}{userGUID, oldPassword, newPassword})
+	fake.updatePasswordMutex.Unlock()
+	if fake.UpdatePasswordStub != nil {
+		return fake.UpdatePasswordStub(userGUID, oldPassword, newPassword)
+	} else {
+		return fake.updatePasswordReturns.result1
+	}
+}
+
+func (fake *FakeUserRepository) UpdatePasswordCallCount() int {
+	fake.updatePasswordMutex.RLock()
+	defer fake.updatePasswordMutex.RUnlock()
+	return len(fake.updatePasswordArgsForCall)
+}
+
+func (fake *FakeUserRepository) UpdatePasswordArgsForCall(i int) (string, string, string) {
+	fake.updatePasswordMutex.RLock()
+	defer fake.updatePasswordMutex.RUnlock()
+	return fake.updatePasswordArgsForCall[i].userGUID, fake.updatePasswordArgsForCall[i].oldPassword, fake.updatePasswordArgsForCall[i].newPassword
+}
+
+func (fake *FakeUserRepository) UpdatePasswordReturns(result1 error) {
+	fake.UpdatePasswordStub = nil
+	fake.updatePasswordReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeUserRepository) SetOrgRoleByGUID(userGUID string, orgGUID string, role models.Role) (apiErr error) {
 	fake.setOrgRoleByGUIDMutex.Lock()
 	fake.setOrgRoleByGUIDArgsForCall = append(fake.setOrgRoleByGUIDArgsForCall, struct {
@@ -540,6 +2216,166 @@ func (fake *FakeUserRepository) UnsetOrgRoleByUsernameReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeUserRepository) UnsetOrgRoleBulk(userGUIDs []string, orgGUID string, role models.Role) ([]models.RoleChangeResult, error) {
+	var userGUIDsCopy []string
+	if userGUIDs != nil {
+		userGUIDsCopy = make([]string, len(userGUIDs))
+		copy(userGUIDsCopy, userGUIDs)
+	}
+	fake.unsetOrgRoleBulkMutex.Lock()
+	fake.unsetOrgRoleBulkArgsForCall = append(fake.unsetOrgRoleBulkArgsForCall, struct {
+		userGUIDs []string
+		orgGUID   string
+		role      models.Role
+	}{userGUIDsCopy, orgGUID, role})
+	fake.recordInvocation("UnsetOrgRoleBulk", []interface{}{userGUIDsCopy, orgGUID, role})
+	fake.unsetOrgRoleBulkMutex.Unlock()
+	if fake.UnsetOrgRoleBulkStub != nil {
+		return fake.UnsetOrgRoleBulkStub(userGUIDs, orgGUID, role)
+	} else {
+		return fake.unsetOrgRoleBulkReturns.result1, fake.unsetOrgRoleBulkReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) UnsetOrgRoleBulkCallCount() int {
+	fake.unsetOrgRoleBulkMutex.RLock()
+	defer fake.unsetOrgRoleBulkMutex.RUnlock()
+	return len(fake.unsetOrgRoleBulkArgsForCall)
+}
+
+func (fake *FakeUserRepository) UnsetOrgRoleBulkArgsForCall(i int) ([]string, string, models.Role) {
+	fake.unsetOrgRoleBulkMutex.RLock()
+	defer fake.unsetOrgRoleBulkMutex.RUnlock()
+	return fake.unsetOrgRoleBulkArgsForCall[i].userGUIDs, fake.unsetOrgRoleBulkArgsForCall[i].orgGUID, fake.unsetOrgRoleBulkArgsForCall[i].role
+}
+
+func (fake *FakeUserRepository) UnsetOrgRoleBulkReturns(result1 []models.RoleChangeResult, result2 error) {
+	fake.UnsetOrgRoleBulkStub = nil
+	fake.unsetOrgRoleBulkReturns = struct {
+		result1 []models.RoleChangeResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) UnsetOrgRoleBulkContext(ctx context.Context, userGUIDs []string, orgGUID string, role models.Role) ([]models.RoleChangeResult, error) {
+	var userGUIDsCopy []string
+	if userGUIDs != nil {
+		userGUIDsCopy = make([]string, len(userGUIDs))
+		copy(userGUIDsCopy, userGUIDs)
+	}
+	fake.unsetOrgRoleBulkContextMutex.Lock()
+	fake.unsetOrgRoleBulkContextArgsForCall = append(fake.unsetOrgRoleBulkContextArgsForCall, struct {
+		ctx       context.Context
+		userGUIDs []string
+		orgGUID   string
+		role      models.Role
+	}{ctx, userGUIDsCopy, orgGUID, role})
+	fake.recordInvocation("UnsetOrgRoleBulkContext", []interface{}{ctx, userGUIDsCopy, orgGUID, role})
+	fake.unsetOrgRoleBulkContextMutex.Unlock()
+	if fake.UnsetOrgRoleBulkContextStub != nil {
+		return fake.UnsetOrgRoleBulkContextStub(ctx, userGUIDs, orgGUID, role)
+	} else {
+		return fake.unsetOrgRoleBulkContextReturns.result1, fake.unsetOrgRoleBulkContextReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) UnsetOrgRoleBulkContextCallCount() int {
+	fake.unsetOrgRoleBulkContextMutex.RLock()
+	defer fake.unsetOrgRoleBulkContextMutex.RUnlock()
+	return len(fake.unsetOrgRoleBulkContextArgsForCall)
+}
+
+func (fake *FakeUserRepository) UnsetOrgRoleBulkContextArgsForCall(i int) (context.Context, []string, string, models.Role) {
+	fake.unsetOrgRoleBulkContextMutex.RLock()
+	defer fake.unsetOrgRoleBulkContextMutex.RUnlock()
+	return fake.unsetOrgRoleBulkContextArgsForCall[i].ctx, fake.unsetOrgRoleBulkContextArgsForCall[i].userGUIDs, fake.unsetOrgRoleBulkContextArgsForCall[i].orgGUID, fake.unsetOrgRoleBulkContextArgsForCall[i].role
+}
+
+func (fake *FakeUserRepository) UnsetOrgRoleBulkContextReturns(result1 []models.RoleChangeResult, result2 error) {
+	fake.UnsetOrgRoleBulkContextStub = nil
+	fake.unsetOrgRoleBulkContextReturns = struct {
+		result1 []models.RoleChangeResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) PreviewOrgRoleChange(userGUID string, username string, orgGUID string, role models.Role, set bool) ([]models.RequestPreview, error) {
+	fake.previewOrgRoleChangeMutex.Lock()
+	fake.previewOrgRoleChangeArgsForCall = append(fake.previewOrgRoleChangeArgsForCall, struct {
+		userGUID string
+		username string
+		orgGUID  string
+		role     models.Role
+		set      bool
+	}{userGUID, username, orgGUID, role, set})
+	fake.recordInvocation("PreviewOrgRoleChange", []interface{}{userGUID, username, orgGUID, role, set})
+	fake.previewOrgRoleChangeMutex.Unlock()
+	if fake.PreviewOrgRoleChangeStub != nil {
+		return fake.PreviewOrgRoleChangeStub(userGUID, username, orgGUID, role, set)
+	} else {
+		return fake.previewOrgRoleChangeReturns.result1, fake.previewOrgRoleChangeReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) PreviewOrgRoleChangeCallCount() int {
+	fake.previewOrgRoleChangeMutex.RLock()
+	defer fake.previewOrgRoleChangeMutex.RUnlock()
+	return len(fake.previewOrgRoleChangeArgsForCall)
+}
+
+func (fake *FakeUserRepository) PreviewOrgRoleChangeArgsForCall(i int) (string, string, string, models.Role, bool) {
+	fake.previewOrgRoleChangeMutex.RLock()
+	defer fake.previewOrgRoleChangeMutex.RUnlock()
+	return fake.previewOrgRoleChangeArgsForCall[i].userGUID, fake.previewOrgRoleChangeArgsForCall[i].username, fake.previewOrgRoleChangeArgsForCall[i].orgGUID, fake.previewOrgRoleChangeArgsForCall[i].role, fake.previewOrgRoleChangeArgsForCall[i].set
+}
+
+func (fake *FakeUserRepository) PreviewOrgRoleChangeReturns(result1 []models.RequestPreview, result2 error) {
+	fake.PreviewOrgRoleChangeStub = nil
+	fake.previewOrgRoleChangeReturns = struct {
+		result1 []models.RequestPreview
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) PreviewSpaceRoleChange(userGUID string, username string, spaceGUID string, orgGUID string, role models.Role, set bool) ([]models.RequestPreview, error) {
+	fake.previewSpaceRoleChangeMutex.Lock()
+	fake.previewSpaceRoleChangeArgsForCall = append(fake.previewSpaceRoleChangeArgsForCall, struct {
+		userGUID  string
+		username  string
+		spaceGUID string
+		orgGUID   string
+		role      models.Role
+		set       bool
+	}{userGUID, username, spaceGUID, orgGUID, role, set})
+	fake.recordInvocation("PreviewSpaceRoleChange", []interface{}{userGUID, username, spaceGUID, orgGUID, role, set})
+	fake.previewSpaceRoleChangeMutex.Unlock()
+	if fake.PreviewSpaceRoleChangeStub != nil {
+		return fake.PreviewSpaceRoleChangeStub(userGUID, username, spaceGUID, orgGUID, role, set)
+	} else {
+		return fake.previewSpaceRoleChangeReturns.result1, fake.previewSpaceRoleChangeReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) PreviewSpaceRoleChangeCallCount() int {
+	fake.previewSpaceRoleChangeMutex.RLock()
+	defer fake.previewSpaceRoleChangeMutex.RUnlock()
+	return len(fake.previewSpaceRoleChangeArgsForCall)
+}
+
+func (fake *FakeUserRepository) PreviewSpaceRoleChangeArgsForCall(i int) (string, string, string, string, models.Role, bool) {
+	fake.previewSpaceRoleChangeMutex.RLock()
+	defer fake.previewSpaceRoleChangeMutex.RUnlock()
+	return fake.previewSpaceRoleChangeArgsForCall[i].userGUID, fake.previewSpaceRoleChangeArgsForCall[i].username, fake.previewSpaceRoleChangeArgsForCall[i].spaceGUID, fake.previewSpaceRoleChangeArgsForCall[i].orgGUID, fake.previewSpaceRoleChangeArgsForCall[i].role, fake.previewSpaceRoleChangeArgsForCall[i].set
+}
+
+func (fake *FakeUserRepository) PreviewSpaceRoleChangeReturns(result1 []models.RequestPreview, result2 error) {
+	fake.PreviewSpaceRoleChangeStub = nil
+	fake.previewSpaceRoleChangeReturns = struct {
+		result1 []models.RequestPreview
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeUserRepository) SetSpaceRoleByGUID(userGUID string, spaceGUID string, orgGUID string, role models.Role) (apiErr error) {
 	fake.setSpaceRoleByGUIDMutex.Lock()
 	fake.setSpaceRoleByGUIDArgsForCall = append(fake.setSpaceRoleByGUIDArgsForCall, struct {
@@ -612,6 +2448,90 @@ func (fake *FakeUserRepository) SetSpaceRoleByUsernameReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeUserRepository) SetSpaceRolesByGUID(userGUID string, spaceGUID string, orgGUID string, roles []models.Role) ([]models.RoleChangeResult, error) {
+	var rolesCopy []models.Role
+	if roles != nil {
+		rolesCopy = make([]models.Role, len(roles))
+		copy(rolesCopy, roles)
+	}
+	fake.setSpaceRolesByGUIDMutex.Lock()
+	fake.setSpaceRolesByGUIDArgsForCall = append(fake.setSpaceRolesByGUIDArgsForCall, struct {
+		userGUID  string
+		spaceGUID string
+		orgGUID   string
+		roles     []models.Role
+	}{userGUID, spaceGUID, orgGUID, rolesCopy})
+	fake.recordInvocation("SetSpaceRolesByGUID", []interface{}{userGUID, spaceGUID, orgGUID, rolesCopy})
+	fake.setSpaceRolesByGUIDMutex.Unlock()
+	if fake.SetSpaceRolesByGUIDStub != nil {
+		return fake.SetSpaceRolesByGUIDStub(userGUID, spaceGUID, orgGUID, roles)
+	} else {
+		return fake.setSpaceRolesByGUIDReturns.result1, fake.setSpaceRolesByGUIDReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) SetSpaceRolesByGUIDCallCount() int {
+	fake.setSpaceRolesByGUIDMutex.RLock()
+	defer fake.setSpaceRolesByGUIDMutex.RUnlock()
+	return len(fake.setSpaceRolesByGUIDArgsForCall)
+}
+
+func (fake *FakeUserRepository) SetSpaceRolesByGUIDArgsForCall(i int) (string, string, string, []models.Role) {
+	fake.setSpaceRolesByGUIDMutex.RLock()
+	defer fake.setSpaceRolesByGUIDMutex.RUnlock()
+	return fake.setSpaceRolesByGUIDArgsForCall[i].userGUID, fake.setSpaceRolesByGUIDArgsForCall[i].spaceGUID, fake.setSpaceRolesByGUIDArgsForCall[i].orgGUID, fake.setSpaceRolesByGUIDArgsForCall[i].roles
+}
+
+func (fake *FakeUserRepository) SetSpaceRolesByGUIDReturns(result1 []models.RoleChangeResult, result2 error) {
+	fake.SetSpaceRolesByGUIDStub = nil
+	fake.setSpaceRolesByGUIDReturns = struct {
+		result1 []models.RoleChangeResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) SetSpaceRolesByUsername(username string, spaceGUID string, orgGUID string, roles []models.Role) ([]models.RoleChangeResult, error) {
+	var rolesCopy []models.Role
+	if roles != nil {
+		rolesCopy = make([]models.Role, len(roles))
+		copy(rolesCopy, roles)
+	}
+	fake.setSpaceRolesByUsernameMutex.Lock()
+	fake.setSpaceRolesByUsernameArgsForCall = append(fake.setSpaceRolesByUsernameArgsForCall, struct {
+		username  string
+		spaceGUID string
+		orgGUID   string
+		roles     []models.Role
+	}{username, spaceGUID, orgGUID, rolesCopy})
+	fake.recordInvocation("SetSpaceRolesByUsername", []interface{}{username, spaceGUID, orgGUID, rolesCopy})
+	fake.setSpaceRolesByUsernameMutex.Unlock()
+	if fake.SetSpaceRolesByUsernameStub != nil {
+		return fake.SetSpaceRolesByUsernameStub(username, spaceGUID, orgGUID, roles)
+	} else {
+		return fake.setSpaceRolesByUsernameReturns.result1, fake.setSpaceRolesByUsernameReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) SetSpaceRolesByUsernameCallCount() int {
+	fake.setSpaceRolesByUsernameMutex.RLock()
+	defer fake.setSpaceRolesByUsernameMutex.RUnlock()
+	return len(fake.setSpaceRolesByUsernameArgsForCall)
+}
+
+func (fake *FakeUserRepository) SetSpaceRolesByUsernameArgsForCall(i int) (string, string, string, []models.Role) {
+	fake.setSpaceRolesByUsernameMutex.RLock()
+	defer fake.setSpaceRolesByUsernameMutex.RUnlock()
+	return fake.setSpaceRolesByUsernameArgsForCall[i].username, fake.setSpaceRolesByUsernameArgsForCall[i].spaceGUID, fake.setSpaceRolesByUsernameArgsForCall[i].orgGUID, fake.setSpaceRolesByUsernameArgsForCall[i].roles
+}
+
+func (fake *FakeUserRepository) SetSpaceRolesByUsernameReturns(result1 []models.RoleChangeResult, result2 error) {
+	fake.SetSpaceRolesByUsernameStub = nil
+	fake.setSpaceRolesByUsernameReturns = struct {
+		result1 []models.RoleChangeResult
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeUserRepository) UnsetSpaceRoleByGUID(userGUID string, spaceGUID string, role models.Role) (apiErr error) {
 	fake.unsetSpaceRoleByGUIDMutex.Lock()
 	fake.unsetSpaceRoleByGUIDArgsForCall = append(fake.unsetSpaceRoleByGUIDArgsForCall, struct {
@@ -682,23 +2602,343 @@ func (fake *FakeUserRepository) UnsetSpaceRoleByUsernameReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeUserRepository) SetOrgRoleByGUIDAsync(userGUID string, orgGUID string, role models.Role) (job api.RoleJob, apiErr error) {
+	fake.setOrgRoleByGUIDAsyncMutex.Lock()
+	fake.setOrgRoleByGUIDAsyncArgsForCall = append(fake.setOrgRoleByGUIDAsyncArgsForCall, struct {
+		userGUID string
+		orgGUID  string
+		role     models.Role
+	}{userGUID, orgGUID, role})
+	fake.recordInvocation("SetOrgRoleByGUIDAsync", []interface{}{userGUID, orgGUID, role})
+	fake.setOrgRoleByGUIDAsyncMutex.Unlock()
+	if fake.SetOrgRoleByGUIDAsyncStub != nil {
+		return fake.SetOrgRoleByGUIDAsyncStub(userGUID, orgGUID, role)
+	} else {
+		return fake.setOrgRoleByGUIDAsyncReturns.result1, fake.setOrgRoleByGUIDAsyncReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) SetOrgRoleByGUIDAsyncCallCount() int {
+	fake.setOrgRoleByGUIDAsyncMutex.RLock()
+	defer fake.setOrgRoleByGUIDAsyncMutex.RUnlock()
+	return len(fake.setOrgRoleByGUIDAsyncArgsForCall)
+}
+
+func (fake *FakeUserRepository) SetOrgRoleByGUIDAsyncArgsForCall(i int) (string, string, models.Role) {
+	fake.setOrgRoleByGUIDAsyncMutex.RLock()
+	defer fake.setOrgRoleByGUIDAsyncMutex.RUnlock()
+	return fake.setOrgRoleByGUIDAsyncArgsForCall[i].userGUID, fake.setOrgRoleByGUIDAsyncArgsForCall[i].orgGUID, fake.setOrgRoleByGUIDAsyncArgsForCall[i].role
+}
+
+func (fake *FakeUserRepository) SetOrgRoleByGUIDAsyncReturns(result1 api.RoleJob, result2 error) {
+	fake.SetOrgRoleByGUIDAsyncStub = nil
+	fake.setOrgRoleByGUIDAsyncReturns = struct {
+		result1 api.RoleJob
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) UnsetOrgRoleByGUIDAsync(userGUID string, orgGUID string, role models.Role) (job api.RoleJob, apiErr error) {
+	fake.unsetOrgRoleByGUIDAsyncMutex.Lock()
+	fake.unsetOrgRoleByGUIDAsyncArgsForCall = append(fake.unsetOrgRoleByGUIDAsyncArgsForCall, struct {
+		userGUID string
+		orgGUID  string
+		role     models.Role
+	}{userGUID, orgGUID, role})
+	fake.recordInvocation("UnsetOrgRoleByGUIDAsync", []interface{}{userGUID, orgGUID, role})
+	fake.unsetOrgRoleByGUIDAsyncMutex.Unlock()
+	if fake.UnsetOrgRoleByGUIDAsyncStub != nil {
+		return fake.UnsetOrgRoleByGUIDAsyncStub(userGUID, orgGUID, role)
+	} else {
+		return fake.unsetOrgRoleByGUIDAsyncReturns.result1, fake.unsetOrgRoleByGUIDAsyncReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) UnsetOrgRoleByGUIDAsyncCallCount() int {
+	fake.unsetOrgRoleByGUIDAsyncMutex.RLock()
+	defer fake.unsetOrgRoleByGUIDAsyncMutex.RUnlock()
+	return len(fake.unsetOrgRoleByGUIDAsyncArgsForCall)
+}
+
+func (fake *FakeUserRepository) UnsetOrgRoleByGUIDAsyncArgsForCall(i int) (string, string, models.Role) {
+	fake.unsetOrgRoleByGUIDAsyncMutex.RLock()
+	defer fake.unsetOrgRoleByGUIDAsyncMutex.RUnlock()
+	return fake.unsetOrgRoleByGUIDAsyncArgsForCall[i].userGUID, fake.unsetOrgRoleByGUIDAsyncArgsForCall[i].orgGUID, fake.unsetOrgRoleByGUIDAsyncArgsForCall[i].role
+}
+
+func (fake *FakeUserRepository) UnsetOrgRoleByGUIDAsyncReturns(result1 api.RoleJob, result2 error) {
+	fake.UnsetOrgRoleByGUIDAsyncStub = nil
+	fake.unsetOrgRoleByGUIDAsyncReturns = struct {
+		result1 api.RoleJob
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) SetSpaceRoleByGUIDAsync(userGUID string, spaceGUID string, orgGUID string, role models.Role) (job api.RoleJob, apiErr error) {
+	fake.setSpaceRoleByGUIDAsyncMutex.Lock()
+	fake.setSpaceRoleByGUIDAsyncArgsForCall = append(fake.setSpaceRoleByGUIDAsyncArgsForCall, struct {
+		userGUID  string
+		spaceGUID string
+		orgGUID   string
+		role      models.Role
+	}{userGUID, spaceGUID, orgGUID, role})
+	fake.recordInvocation("SetSpaceRoleByGUIDAsync", []interface{}{userGUID, spaceGUID, orgGUID, role})
+	fake.setSpaceRoleByGUIDAsyncMutex.Unlock()
+	if fake.SetSpaceRoleByGUIDAsyncStub != nil {
+		return fake.SetSpaceRoleByGUIDAsyncStub(userGUID, spaceGUID, orgGUID, role)
+	} else {
+		return fake.setSpaceRoleByGUIDAsyncReturns.result1, fake.setSpaceRoleByGUIDAsyncReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) SetSpaceRoleByGUIDAsyncCallCount() int {
+	fake.setSpaceRoleByGUIDAsyncMutex.RLock()
+	defer fake.setSpaceRoleByGUIDAsyncMutex.RUnlock()
+	return len(fake.setSpaceRoleByGUIDAsyncArgsForCall)
+}
+
+func (fake *FakeUserRepository) SetSpaceRoleByGUIDAsyncArgsForCall(i int) (string, string, string, models.Role) {
+	fake.setSpaceRoleByGUIDAsyncMutex.RLock()
+	defer fake.setSpaceRoleByGUIDAsyncMutex.RUnlock()
+	return fake.setSpaceRoleByGUIDAsyncArgsForCall[i].userGUID, fake.setSpaceRoleByGUIDAsyncArgsForCall[i].spaceGUID, fake.setSpaceRoleByGUIDAsyncArgsForCall[i].orgGUID, fake.setSpaceRoleByGUIDAsyncArgsForCall[i].role
+}
+
+func (fake *FakeUserRepository) SetSpaceRoleByGUIDAsyncReturns(result1 api.RoleJob, result2 error) {
+	fake.SetSpaceRoleByGUIDAsyncStub = nil
+	fake.setSpaceRoleByGUIDAsyncReturns = struct {
+		result1 api.RoleJob
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) SpaceRoleETag(spaceGUID string, role models.Role) (string, error) {
+	fake.spaceRoleETagMutex.Lock()
+	fake.spaceRoleETagArgsForCall = append(fake.spaceRoleETagArgsForCall, struct {
+		spaceGUID string
+		role      models.Role
+	}{spaceGUID, role})
+	fake.recordInvocation("SpaceRoleETag", []interface{}{spaceGUID, role})
+	fake.spaceRoleETagMutex.Unlock()
+	if fake.SpaceRoleETagStub != nil {
+		return fake.SpaceRoleETagStub(spaceGUID, role)
+	} else {
+		return fake.spaceRoleETagReturns.result1, fake.spaceRoleETagReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) SpaceRoleETagCallCount() int {
+	fake.spaceRoleETagMutex.RLock()
+	defer fake.spaceRoleETagMutex.RUnlock()
+	return len(fake.spaceRoleETagArgsForCall)
+}
+
+func (fake *FakeUserRepository) SpaceRoleETagArgsForCall(i int) (string, models.Role) {
+	fake.spaceRoleETagMutex.RLock()
+	defer fake.spaceRoleETagMutex.RUnlock()
+	return fake.spaceRoleETagArgsForCall[i].spaceGUID, fake.spaceRoleETagArgsForCall[i].role
+}
+
+func (fake *FakeUserRepository) SpaceRoleETagReturns(result1 string, result2 error) {
+	fake.SpaceRoleETagStub = nil
+	fake.spaceRoleETagReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) SetSpaceRoleByGUIDIfMatch(userGUID string, spaceGUID string, orgGUID string, role models.Role, etag string) (apiErr error) {
+	fake.setSpaceRoleByGUIDIfMatchMutex.Lock()
+	fake.setSpaceRoleByGUIDIfMatchArgsForCall = append(fake.setSpaceRoleByGUIDIfMatchArgsForCall, struct {
+		userGUID  string
+		spaceGUID string
+		orgGUID   string
+		role      models.Role
+		etag      string
+	}{userGUID, spaceGUID, orgGUID, role, etag})
+	fake.recordInvocation("SetSpaceRoleByGUIDIfMatch", []interface{}{userGUID, spaceGUID, orgGUID, role, etag})
+	fake.setSpaceRoleByGUIDIfMatchMutex.Unlock()
+	if fake.SetSpaceRoleByGUIDIfMatchStub != nil {
+		return fake.SetSpaceRoleByGUIDIfMatchStub(userGUID, spaceGUID, orgGUID, role, etag)
+	} else {
+		return fake.setSpaceRoleByGUIDIfMatchReturns.result1
+	}
+}
+
+func (fake *FakeUserRepository) SetSpaceRoleByGUIDIfMatchCallCount() int {
+	fake.setSpaceRoleByGUIDIfMatchMutex.RLock()
+	defer fake.setSpaceRoleByGUIDIfMatchMutex.RUnlock()
+	return len(fake.setSpaceRoleByGUIDIfMatchArgsForCall)
+}
+
+func (fake *FakeUserRepository) SetSpaceRoleByGUIDIfMatchArgsForCall(i int) (string, string, string, models.Role, string) {
+	fake.setSpaceRoleByGUIDIfMatchMutex.RLock()
+	defer fake.setSpaceRoleByGUIDIfMatchMutex.RUnlock()
+	return fake.setSpaceRoleByGUIDIfMatchArgsForCall[i].userGUID, fake.setSpaceRoleByGUIDIfMatchArgsForCall[i].spaceGUID, fake.setSpaceRoleByGUIDIfMatchArgsForCall[i].orgGUID, fake.setSpaceRoleByGUIDIfMatchArgsForCall[i].role, fake.setSpaceRoleByGUIDIfMatchArgsForCall[i].etag
+}
+
+func (fake *FakeUserRepository) SetSpaceRoleByGUIDIfMatchReturns(result1 error) {
+	fake.SetSpaceRoleByGUIDIfMatchStub = nil
+	fake.setSpaceRoleByGUIDIfMatchReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) UnsetSpaceRoleByGUIDAsync(userGUID string, spaceGUID string, role models.Role) (job api.RoleJob, apiErr error) {
+	fake.unsetSpaceRoleByGUIDAsyncMutex.Lock()
+	fake.unsetSpaceRoleByGUIDAsyncArgsForCall = append(fake.unsetSpaceRoleByGUIDAsyncArgsForCall, struct {
+		userGUID  string
+		spaceGUID string
+		role      models.Role
+	}{userGUID, spaceGUID, role})
+	fake.recordInvocation("UnsetSpaceRoleByGUIDAsync", []interface{}{userGUID, spaceGUID, role})
+	fake.unsetSpaceRoleByGUIDAsyncMutex.Unlock()
+	if fake.UnsetSpaceRoleByGUIDAsyncStub != nil {
+		return fake.UnsetSpaceRoleByGUIDAsyncStub(userGUID, spaceGUID, role)
+	} else {
+		return fake.unsetSpaceRoleByGUIDAsyncReturns.result1, fake.unsetSpaceRoleByGUIDAsyncReturns.result2
+	}
+}
+
+func (fake *FakeUserRepository) UnsetSpaceRoleByGUIDAsyncCallCount() int {
+	fake.unsetSpaceRoleByGUIDAsyncMutex.RLock()
+	defer fake.unsetSpaceRoleByGUIDAsyncMutex.RUnlock()
+	return len(fake.unsetSpaceRoleByGUIDAsyncArgsForCall)
+}
+
+func (fake *FakeUserRepository) UnsetSpaceRoleByGUIDAsyncArgsForCall(i int) (string, string, models.Role) {
+	fake.unsetSpaceRoleByGUIDAsyncMutex.RLock()
+	defer fake.unsetSpaceRoleByGUIDAsyncMutex.RUnlock()
+	return fake.unsetSpaceRoleByGUIDAsyncArgsForCall[i].userGUID, fake.unsetSpaceRoleByGUIDAsyncArgsForCall[i].spaceGUID, fake.unsetSpaceRoleByGUIDAsyncArgsForCall[i].role
+}
+
+func (fake *FakeUserRepository) UnsetSpaceRoleByGUIDAsyncReturns(result1 api.RoleJob, result2 error) {
+	fake.UnsetSpaceRoleByGUIDAsyncStub = nil
+	fake.unsetSpaceRoleByGUIDAsyncReturns = struct {
+		result1 api.RoleJob
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) WaitForRoleJob(job api.RoleJob) (apiErr error) {
+	fake.waitForRoleJobMutex.Lock()
+	fake.waitForRoleJobArgsForCall = append(fake.waitForRoleJobArgsForCall, struct {
+		job api.RoleJob
+	}{job})
+	fake.recordInvocation("WaitForRoleJob", []interface{}{job})
+	fake.waitForRoleJobMutex.Unlock()
+	if fake.WaitForRoleJobStub != nil {
+		return fake.WaitForRoleJobStub(job)
+	} else {
+		return fake.waitForRoleJobReturns.result1
+	}
+}
+
+func (fake *FakeUserRepository) WaitForRoleJobCallCount() int {
+	fake.waitForRoleJobMutex.RLock()
+	defer fake.waitForRoleJobMutex.RUnlock()
+	return len(fake.waitForRoleJobArgsForCall)
+}
+
+func (fake *FakeUserRepository) WaitForRoleJobArgsForCall(i int) api.RoleJob {
+	fake.waitForRoleJobMutex.RLock()
+	defer fake.waitForRoleJobMutex.RUnlock()
+	return fake.waitForRoleJobArgsForCall[i].job
+}
+
+func (fake *FakeUserRepository) WaitForRoleJobReturns(result1 error) {
+	fake.WaitForRoleJobStub = nil
+	fake.waitForRoleJobReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeUserRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.findByUsernameMutex.RLock()
 	defer fake.findByUsernameMutex.RUnlock()
+	fake.findByEmailMutex.RLock()
+	defer fake.findByEmailMutex.RUnlock()
+	fake.findByUsernameContextMutex.RLock()
+	defer fake.findByUsernameContextMutex.RUnlock()
 	fake.findAllByUsernameMutex.RLock()
 	defer fake.findAllByUsernameMutex.RUnlock()
+	fake.findAllByUsernameContextMutex.RLock()
+	defer fake.findAllByUsernameContextMutex.RUnlock()
+	fake.findAllByUsernamePrefixMutex.RLock()
+	defer fake.findAllByUsernamePrefixMutex.RUnlock()
+	fake.findByUsernamesMutex.RLock()
+	defer fake.findByUsernamesMutex.RUnlock()
+	fake.isUsernameAvailableMutex.RLock()
+	defer fake.isUsernameAvailableMutex.RUnlock()
+	fake.verifyCredentialsMutex.RLock()
+	defer fake.verifyCredentialsMutex.RUnlock()
 	fake.listUsersInOrgForRoleMutex.RLock()
 	defer fake.listUsersInOrgForRoleMutex.RUnlock()
+	fake.listUsersInOrgForRoleContextMutex.RLock()
+	defer fake.listUsersInOrgForRoleContextMutex.RUnlock()
+	fake.countUsersInOrgForRoleMutex.RLock()
+	defer fake.countUsersInOrgForRoleMutex.RUnlock()
+	fake.listUsersInOrgForRoleFilteredMutex.RLock()
+	defer fake.listUsersInOrgForRoleFilteredMutex.RUnlock()
+	fake.listAllUsersInOrgMutex.RLock()
+	defer fake.listAllUsersInOrgMutex.RUnlock()
+	fake.listUsersInOrgForRolesMutex.RLock()
+	defer fake.listUsersInOrgForRolesMutex.RUnlock()
+	fake.listUsersInSpaceForAllRolesMutex.RLock()
+	defer fake.listUsersInSpaceForAllRolesMutex.RUnlock()
 	fake.listUsersInOrgForRoleWithNoUAAMutex.RLock()
 	defer fake.listUsersInOrgForRoleWithNoUAAMutex.RUnlock()
+	fake.listInactiveOrgUsersMutex.RLock()
+	defer fake.listInactiveOrgUsersMutex.RUnlock()
 	fake.listUsersInSpaceForRoleWithNoUAAMutex.RLock()
 	defer fake.listUsersInSpaceForRoleWithNoUAAMutex.RUnlock()
+	fake.listOrgUsersWithSpaceRolesMutex.RLock()
+	defer fake.listOrgUsersWithSpaceRolesMutex.RUnlock()
+	fake.findDuplicateCCRegistrationsMutex.RLock()
+	defer fake.findDuplicateCCRegistrationsMutex.RUnlock()
+	fake.findUnmatchedUsersMutex.RLock()
+	defer fake.findUnmatchedUsersMutex.RUnlock()
+	fake.findRolelessUsersMutex.RLock()
+	defer fake.findRolelessUsersMutex.RUnlock()
+	fake.getUserAccessSummaryMutex.RLock()
+	defer fake.getUserAccessSummaryMutex.RUnlock()
+	fake.getUserMembershipsMutex.RLock()
+	defer fake.getUserMembershipsMutex.RUnlock()
+	fake.getUserRolesInOrgMutex.RLock()
+	defer fake.getUserRolesInOrgMutex.RUnlock()
+	fake.copyRolesMutex.RLock()
+	defer fake.copyRolesMutex.RUnlock()
+	fake.copySpaceRolesMutex.RLock()
+	defer fake.copySpaceRolesMutex.RUnlock()
+	fake.exportUsersSCIMMutex.RLock()
+	defer fake.exportUsersSCIMMutex.RUnlock()
+	fake.exportFoundationRBACMutex.RLock()
+	defer fake.exportFoundationRBACMutex.RUnlock()
+	fake.eachRolelessUserMutex.RLock()
+	defer fake.eachRolelessUserMutex.RUnlock()
+	fake.listAllUsersMutex.RLock()
+	defer fake.listAllUsersMutex.RUnlock()
+	fake.listCFUsersInUAAGroupMutex.RLock()
+	defer fake.listCFUsersInUAAGroupMutex.RUnlock()
+	fake.diffSpaceUsersMutex.RLock()
+	defer fake.diffSpaceUsersMutex.RUnlock()
 	fake.createMutex.RLock()
 	defer fake.createMutex.RUnlock()
+	fake.createWithEmailMutex.RLock()
+	defer fake.createWithEmailMutex.RUnlock()
+	fake.createWithOriginMutex.RLock()
+	defer fake.createWithOriginMutex.RUnlock()
+	fake.createBulkMutex.RLock()
+	defer fake.createBulkMutex.RUnlock()
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
+	fake.deleteAsyncMutex.RLock()
+	defer fake.deleteAsyncMutex.RUnlock()
+	fake.renameUserMutex.RLock()
+	defer fake.renameUserMutex.RUnlock()
+	fake.updatePasswordMutex.RLock()
+	defer fake.updatePasswordMutex.RUnlock()
 	fake.setOrgRoleByGUIDMutex.RLock()
 	defer fake.setOrgRoleByGUIDMutex.RUnlock()
 	fake.setOrgRoleByUsernameMutex.RLock()
@@ -707,14 +2947,40 @@ func (fake *FakeUserRepository) Invocations() map[string][][]interface{} {
 	defer fake.unsetOrgRoleByGUIDMutex.RUnlock()
 	fake.unsetOrgRoleByUsernameMutex.RLock()
 	defer fake.unsetOrgRoleByUsernameMutex.RUnlock()
+	fake.unsetOrgRoleBulkMutex.RLock()
+	defer fake.unsetOrgRoleBulkMutex.RUnlock()
+	fake.unsetOrgRoleBulkContextMutex.RLock()
+	defer fake.unsetOrgRoleBulkContextMutex.RUnlock()
+	fake.previewOrgRoleChangeMutex.RLock()
+	defer fake.previewOrgRoleChangeMutex.RUnlock()
+	fake.previewSpaceRoleChangeMutex.RLock()
+	defer fake.previewSpaceRoleChangeMutex.RUnlock()
 	fake.setSpaceRoleByGUIDMutex.RLock()
 	defer fake.setSpaceRoleByGUIDMutex.RUnlock()
 	fake.setSpaceRoleByUsernameMutex.RLock()
 	defer fake.setSpaceRoleByUsernameMutex.RUnlock()
+	fake.setSpaceRolesByGUIDMutex.RLock()
+	defer fake.setSpaceRolesByGUIDMutex.RUnlock()
+	fake.setSpaceRolesByUsernameMutex.RLock()
+	defer fake.setSpaceRolesByUsernameMutex.RUnlock()
 	fake.unsetSpaceRoleByGUIDMutex.RLock()
 	defer fake.unsetSpaceRoleByGUIDMutex.RUnlock()
 	fake.unsetSpaceRoleByUsernameMutex.RLock()
 	defer fake.unsetSpaceRoleByUsernameMutex.RUnlock()
+	fake.setOrgRoleByGUIDAsyncMutex.RLock()
+	defer fake.setOrgRoleByGUIDAsyncMutex.RUnlock()
+	fake.unsetOrgRoleByGUIDAsyncMutex.RLock()
+	defer fake.unsetOrgRoleByGUIDAsyncMutex.RUnlock()
+	fake.setSpaceRoleByGUIDAsyncMutex.RLock()
+	defer fake.setSpaceRoleByGUIDAsyncMutex.RUnlock()
+	fake.spaceRoleETagMutex.RLock()
+	defer fake.spaceRoleETagMutex.RUnlock()
+	fake.setSpaceRoleByGUIDIfMatchMutex.RLock()
+	defer fake.setSpaceRoleByGUIDIfMatchMutex.RUnlock()
+	fake.unsetSpaceRoleByGUIDAsyncMutex.RLock()
+	defer fake.unsetSpaceRoleByGUIDAsyncMutex.RUnlock()
+	fake.waitForRoleJobMutex.RLock()
+	defer fake.waitForRoleJobMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/errors"
 	"code.cloudfoundry.org/cli/cf/net"
 )
 
@@ -46,3 +47,14 @@ func (repo RemoteInfoRepository) getCCAPIInfo(endpoint string) (*coreconfig.CCIn
 
 	return serverResponse, nil
 }
+
+// PingUAA checks that the UAA at uaaEndpoint answers requests, so commands
+// can fail clearly before doing any work. Any HTTP response counts as an
+// answer; a request that gets none returns a UAAUnreachableError.
+func (repo RemoteInfoRepository) PingUAA(uaaEndpoint string) error {
+	err := repo.gateway.GetResource(uaaEndpoint+"/info", &map[string]interface{}{})
+	if _, answered := err.(errors.HTTPError); err == nil || answered {
+		return nil
+	}
+	return errors.NewUAAUnreachableError(uaaEndpoint, err)
+}
@@ -12,6 +12,7 @@ import (
 
 	. "code.cloudfoundry.org/cli/cf/api"
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/errors"
 	"code.cloudfoundry.org/cli/cf/models"
 	"code.cloudfoundry.org/cli/cf/net"
 	"code.cloudfoundry.org/cli/cf/terminal/terminalfakes"
@@ -170,4 +171,36 @@ var _ = Describe("Endpoints Repository", func() {
 			})
 		})
 	})
+
+	Describe("PingUAA", func() {
+		It("succeeds when UAA answers", func() {
+			var path string
+			testServerFn = func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				fmt.Fprintln(w, `{"app": {"version": "4.7.0"}}`)
+			}
+
+			Expect(repo.PingUAA(testServer.URL)).To(Succeed())
+			Expect(path).To(Equal("/info"))
+		})
+
+		It("succeeds when UAA answers with an error", func() {
+			testServerFn = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			}
+
+			Expect(repo.PingUAA(testServer.URL)).To(Succeed())
+		})
+
+		It("says UAA is unreachable when nothing answers", func() {
+			closedServer := httptest.NewTLSServer(http.NotFoundHandler())
+			closedURL := closedServer.URL
+			closedServer.Close()
+
+			err := repo.PingUAA(closedURL)
+			Expect(err).To(BeAssignableToTypeOf(&errors.UAAUnreachableError{}))
+			Expect(err.Error()).To(ContainSubstring("UAA endpoint " + closedURL + " is unreachable"))
+			Expect(errors.ExitCode(err)).To(Equal(errors.ExitCodeBackend))
+		})
+	})
 })
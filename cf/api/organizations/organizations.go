@@ -79,7 +79,7 @@ func (repo CloudControllerOrganizationRepository) FindByName(name string) (org m
 		})
 
 	if apiErr == nil && !found {
-		apiErr = errors.NewModelNotFoundError("Organization", name)
+		apiErr = errors.NewNotFoundError(errors.OrgResource, name)
 	}
 
 	return
@@ -3,6 +3,7 @@ package api
 import (
 	"crypto/tls"
 	"net/http"
+	"os"
 	"strconv"
 	"time"
 
@@ -80,8 +81,17 @@ const noaaRetryDefaultTimeout = 5 * time.Second
 
 func NewRepositoryLocator(config coreconfig.ReadWriter, gatewaysByName map[string]net.Gateway, logger trace.Printer, envDialTimeout string) (loc RepositoryLocator) {
 	cloudControllerGateway := gatewaysByName["cloud-controller"]
+	cloudControllerGateway.ResultsPerPage = int(config.ResultsPerPage())
+	if cloudControllerGateway.ResultsPerPage == 0 {
+		cloudControllerGateway.ResultsPerPage = net.MaxResultsPerPage
+	}
+	cloudControllerGateway.MaxRetries = net.DefaultMaxRetries
+	cloudControllerGateway.ReadRetries = net.DefaultReadRetries
 	routingAPIGateway := gatewaysByName["routing-api"]
+	routingAPIGateway.MaxRetries = net.DefaultMaxRetries
+	routingAPIGateway.ReadRetries = net.DefaultReadRetries
 	uaaGateway := gatewaysByName["uaa"]
+	uaaGateway.ReadRetries = net.DefaultReadRetries
 	loc.authRepo = authentication.NewUAARepository(uaaGateway, config, net.NewRequestDumper(logger))
 
 	// ensure gateway refreshers are set before passing them by value to repositories
@@ -129,7 +139,14 @@ func NewRepositoryLocator(config coreconfig.ReadWriter, gatewaysByName map[strin
 	loc.serviceSummaryRepo = NewCloudControllerServiceSummaryRepository(config, cloudControllerGateway)
 	loc.spaceRepo = spaces.NewCloudControllerSpaceRepository(config, cloudControllerGateway)
 	loc.userProvidedServiceInstanceRepo = NewCCUserProvidedServiceInstanceRepository(config, cloudControllerGateway)
-	loc.userRepo = NewCloudControllerUserRepository(config, uaaGateway, cloudControllerGateway)
+	var userRepoOptions []UserRepositoryOption
+	if uaaDialTimeout := config.UAADialTimeout(); uaaDialTimeout > 0 {
+		userRepoOptions = append(userRepoOptions, WithUAADialTimeout(time.Duration(uaaDialTimeout)*time.Second))
+	}
+	loc.userRepo = NewCloudControllerUserRepository(config, uaaGateway, cloudControllerGateway, userRepoOptions...)
+	if os.Getenv("CF_DEBUG_REPO") != "" {
+		loc.userRepo = NewLoggingUserRepository(loc.userRepo, os.Stderr)
+	}
 	loc.buildpackRepo = NewCloudControllerBuildpackRepository(config, cloudControllerGateway)
 	loc.buildpackBitsRepo = NewCloudControllerBuildpackBitsRepository(config, cloudControllerGateway, appfiles.ApplicationZipper{})
 	loc.securityGroupRepo = securitygroups.NewSecurityGroupRepo(config, cloudControllerGateway)
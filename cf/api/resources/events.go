@@ -26,6 +26,16 @@ type EventResourceNewV2 struct {
 	}
 }
 
+// SpaceEventResource is an event read only for when it happened and in
+// which space.
+type SpaceEventResource struct {
+	Resource
+	Entity struct {
+		Timestamp time.Time
+		SpaceGUID string `json:"space_guid"`
+	}
+}
+
 type EventResourceOldV2 struct {
 	Resource
 	Entity struct {
@@ -0,0 +1,22 @@
+package resources
+
+// V3IsolationSegmentResources is a page of /v3/isolation_segments.
+type V3IsolationSegmentResources struct {
+	Pagination struct {
+		Next *struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"pagination"`
+	Resources []struct {
+		GUID string `json:"guid"`
+		Name string `json:"name"`
+	} `json:"resources"`
+}
+
+// V3ToManyRelationship lists the GUIDs on the other side of a v3
+// relationship, such as the spaces an isolation segment is assigned to.
+type V3ToManyRelationship struct {
+	Data []struct {
+		GUID string `json:"guid"`
+	} `json:"data"`
+}
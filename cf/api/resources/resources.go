@@ -1,8 +1,10 @@
 package resources
 
 type Metadata struct {
-	GUID string `json:"guid"`
-	URL  string `json:"url,omitempty"`
+	GUID      string `json:"guid"`
+	URL       string `json:"url,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
 }
 
 type Resource struct {
@@ -1,6 +1,10 @@
 package resources
 
-import "code.cloudfoundry.org/cli/cf/models"
+import (
+	"strings"
+
+	"code.cloudfoundry.org/cli/cf/models"
+)
 
 type SpaceResource struct {
 	Resource
@@ -10,6 +14,7 @@ type SpaceResource struct {
 type SpaceEntity struct {
 	Name             string
 	Organization     OrganizationResource
+	OrganizationGUID string                `json:"organization_guid"`
 	Applications     []ApplicationResource `json:"apps"`
 	Domains          []DomainResource
 	ServiceInstances []ServiceInstanceResource `json:"service_instances"`
@@ -44,6 +49,32 @@ func (resource SpaceResource) ToModel() (space models.Space) {
 	}
 
 	space.Organization = resource.Entity.Organization.ToFields()
+	if space.Organization.GUID == "" {
+		space.Organization.GUID = resource.Entity.OrganizationGUID
+	}
 	space.SpaceQuotaGUID = resource.Entity.SpaceQuotaGUID
+	space.CreatedAt = resource.Metadata.CreatedAt
+	return
+}
+
+type SpaceSummaryResource struct {
+	Apps []struct {
+		Memory    int64
+		Instances int
+		State     string
+	}
+	Services []struct {
+		GUID string
+	}
+}
+
+func (resource SpaceSummaryResource) ToModel() (summary models.SpaceSummary) {
+	summary.AppsCount = len(resource.Apps)
+	summary.ServicesCount = len(resource.Services)
+	for _, app := range resource.Apps {
+		if strings.EqualFold(app.State, "STARTED") {
+			summary.MemoryUsage += app.Memory * int64(app.Instances)
+		}
+	}
 	return
 }
@@ -1,6 +1,10 @@
 package resources
 
-import "code.cloudfoundry.org/cli/cf/models"
+import (
+	"time"
+
+	"code.cloudfoundry.org/cli/cf/models"
+)
 
 type UserResource struct {
 	Resource
@@ -12,19 +16,100 @@ type UserEntity struct {
 	Admin bool
 }
 
+// PaginatedResourceCount reads only the size of a paginated CC list.
+type PaginatedResourceCount struct {
+	TotalResults int `json:"total_results"`
+}
+
+// UAAPagination is the paging information on a page of UAA users.
+type UAAPagination struct {
+	TotalResults int    `json:"totalResults"`
+	StartIndex   int    `json:"startIndex"`
+	ItemsPerPage int    `json:"itemsPerPage"`
+	NextCursor   string `json:"nextCursor"`
+}
+
 type UAAUserResources struct {
+	UAAPagination
+	Resources []struct {
+		ID            string
+		Username      string
+		Groups        []UAAUserGroup
+		Origin        string
+		Active        bool
+		Emails        []UAAUserResourceEmail
+		LastLogonTime int64
+		Meta          struct {
+			Created string `json:"created"`
+		}
+	}
+}
+
+// UAAClientResources is a page of UAA OAuth clients.
+type UAAClientResources struct {
+	UAAPagination
 	Resources []struct {
-		ID       string
-		Username string
+		ClientID string `json:"client_id"`
 	}
 }
 
+type UAAUserGroup struct {
+	Display string `json:"display"`
+}
+
+type UAAGroupResources struct {
+	Resources []struct {
+		ID          string
+		DisplayName string
+		Members     []UAAGroupMember
+	}
+}
+
+type UAAGroupMember struct {
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+// V3RoleResources is a page of /v3/roles with the role holders included.
+type V3RoleResources struct {
+	Pagination struct {
+		Next *struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"pagination"`
+	Resources []struct {
+		Relationships struct {
+			User struct {
+				Data struct {
+					GUID string `json:"guid"`
+				} `json:"data"`
+			} `json:"user"`
+		} `json:"relationships"`
+	} `json:"resources"`
+	Included struct {
+		Users []struct {
+			GUID     string `json:"guid"`
+			Username string `json:"username"`
+			Origin   string `json:"origin"`
+		} `json:"users"`
+	} `json:"included"`
+}
+
+// ToFields also carries over the Cloud Controller's created_at and
+// updated_at timestamps, leaving either zero when it is missing.
 func (resource UserResource) ToFields() models.UserFields {
-	return models.UserFields{
+	fields := models.UserFields{
 		GUID:     resource.Metadata.GUID,
 		IsAdmin:  resource.Entity.Admin,
 		Username: resource.Entity.Name,
 	}
+	if createdAt, err := time.Parse(time.RFC3339, resource.Metadata.CreatedAt); err == nil {
+		fields.CreatedAt = createdAt
+	}
+	if updatedAt, err := time.Parse(time.RFC3339, resource.Metadata.UpdatedAt); err == nil {
+		fields.UpdatedAt = updatedAt
+	}
+	return fields
 }
 
 type UAAUserResourceEmail struct {
@@ -39,22 +124,61 @@ type UAAUserResourceName struct {
 type UAAUserResource struct {
 	Username string                 `json:"userName"`
 	Emails   []UAAUserResourceEmail `json:"emails"`
-	Password string                 `json:"password"`
+	Password string                 `json:"password,omitempty"`
 	Name     UAAUserResourceName    `json:"name"`
+	Origin   string                 `json:"origin,omitempty"`
 }
 
 func NewUAAUserResource(username, password string) UAAUserResource {
+	return NewUAAUserResourceWithEmail(username, password, username)
+}
+
+// NewUAAUserResourceWithEmail is a user whose email is given apart from its
+// username. The email also stands in for the user's name.
+func NewUAAUserResourceWithEmail(username, password, email string) UAAUserResource {
 	return UAAUserResource{
 		Username: username,
-		Emails:   []UAAUserResourceEmail{{Value: username}},
+		Emails:   []UAAUserResourceEmail{{Value: email}},
 		Password: password,
 		Name: UAAUserResourceName{
-			GivenName:  username,
-			FamilyName: username,
+			GivenName:  email,
+			FamilyName: email,
 		},
 	}
 }
 
+// NewUAAUserResourceWithOrigin is a user whose identity is held by the
+// external identity provider origin, so it has no UAA password.
+func NewUAAUserResourceWithOrigin(username, email, origin string) UAAUserResource {
+	resource := NewUAAUserResourceWithEmail(username, "", email)
+	resource.Origin = origin
+	return resource
+}
+
+// SCIMUser is a UAA user in the SCIM core schema. It has no password, as
+// UAA never returns one.
+type SCIMUser struct {
+	Schemas    []string               `json:"schemas"`
+	UserName   string                 `json:"userName"`
+	Name       UAAUserResourceName    `json:"name"`
+	Emails     []UAAUserResourceEmail `json:"emails"`
+	Origin     string                 `json:"origin,omitempty"`
+	ExternalID string                 `json:"externalId,omitempty"`
+	Active     bool                   `json:"active"`
+}
+
+type UAASCIMUserResources struct {
+	UAAPagination
+	Resources []SCIMUser
+}
+
+// UAAUserVersion reads the version UAA requires in If-Match on updates.
+type UAAUserVersion struct {
+	Meta struct {
+		Version int `json:"version"`
+	} `json:"meta"`
+}
+
 type UAAUserFields struct {
 	ID string
 }
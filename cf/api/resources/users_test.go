@@ -0,0 +1,45 @@
+package resources_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "code.cloudfoundry.org/cli/cf/api/resources"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Users", func() {
+	Describe("ToFields", func() {
+		It("carries over the created and updated timestamps", func() {
+			var resource UserResource
+			err := json.Unmarshal([]byte(`{
+				"metadata": {
+					"guid": "user-guid",
+					"created_at": "2016-03-01T10:00:00Z",
+					"updated_at": "2017-04-02T11:30:00Z"
+				},
+				"entity": {"username": "alice", "admin": true}
+			}`), &resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			fields := resource.ToFields()
+			Expect(fields.GUID).To(Equal("user-guid"))
+			Expect(fields.Username).To(Equal("alice"))
+			Expect(fields.IsAdmin).To(BeTrue())
+			Expect(fields.CreatedAt).To(Equal(time.Date(2016, 3, 1, 10, 0, 0, 0, time.UTC)))
+			Expect(fields.UpdatedAt).To(Equal(time.Date(2017, 4, 2, 11, 30, 0, 0, time.UTC)))
+		})
+
+		It("leaves the timestamps zero when the response omits them", func() {
+			var resource UserResource
+			err := json.Unmarshal([]byte(`{"metadata": {"guid": "user-guid"}, "entity": {}}`), &resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			fields := resource.ToFields()
+			Expect(fields.CreatedAt.IsZero()).To(BeTrue())
+			Expect(fields.UpdatedAt.IsZero()).To(BeTrue())
+		})
+	})
+})
@@ -3,8 +3,11 @@ package spaces
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
+	"sort"
 	"strings"
+	"time"
 
 	"code.cloudfoundry.org/cli/cf/api/resources"
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
@@ -18,6 +21,11 @@ import (
 type SpaceRepository interface {
 	ListSpaces(func(models.Space) bool) error
 	ListSpacesFromOrg(orgGUID string, spaceFunc func(models.Space) bool) error
+	ListAllSpaces(spaceFunc func(models.Space) bool) error
+	ListAccessibleSpaces() ([]models.Space, error)
+	ListRecentSpaces(limit int) ([]models.Space, error)
+	ListIsolationSegmentNames(spaceGUIDs []string) (map[string]string, error)
+	GetSpaceSummary(spaceGUID string) (models.SpaceSummary, error)
 	FindByName(name string) (space models.Space, apiErr error)
 	FindByNameInOrg(name, orgGUID string) (space models.Space, apiErr error)
 	Create(name string, orgGUID string, spaceQuotaGUID string) (space models.Space, apiErr error)
@@ -57,10 +65,151 @@ func (repo CloudControllerSpaceRepository) ListSpacesFromOrg(orgGUID string, cal
 		})
 }
 
+// ListAllSpaces lists every space visible to the user, across all orgs. Only
+// the GUID of each space's organization is populated.
+func (repo CloudControllerSpaceRepository) ListAllSpaces(callback func(models.Space) bool) error {
+	return repo.gateway.ListPaginatedResources(
+		repo.config.APIEndpoint(),
+		"/v2/spaces?order-by=name",
+		resources.SpaceResource{},
+		func(resource interface{}) bool {
+			return callback(resource.(resources.SpaceResource).ToModel())
+		})
+}
+
+// ListAccessibleSpaces returns the spaces the current token may see. The
+// Cloud Controller scopes the listing, so this works for client credentials
+// tokens as well as users.
+func (repo CloudControllerSpaceRepository) ListAccessibleSpaces() ([]models.Space, error) {
+	spaces := []models.Space{}
+	err := repo.ListAllSpaces(func(space models.Space) bool {
+		spaces = append(spaces, space)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return spaces, nil
+}
+
+// ListRecentSpaces returns up to limit spaces the current user has acted in,
+// most recent first, going by the user's Cloud Controller events. Spaces the
+// user can no longer see are left out.
+func (repo CloudControllerSpaceRepository) ListRecentSpaces(limit int) ([]models.Space, error) {
+	lastActive := map[string]time.Time{}
+	var spaceGUIDs []string
+	err := repo.gateway.ListPaginatedResources(
+		repo.config.APIEndpoint(),
+		fmt.Sprintf("/v2/events?order-direction=desc&results-per-page=100&q=actor:%s", repo.config.UserGUID()),
+		resources.SpaceEventResource{},
+		func(resource interface{}) bool {
+			event := resource.(resources.SpaceEventResource)
+			spaceGUID := event.Entity.SpaceGUID
+			if spaceGUID == "" {
+				return true
+			}
+			if _, seen := lastActive[spaceGUID]; !seen {
+				spaceGUIDs = append(spaceGUIDs, spaceGUID)
+			}
+			if event.Entity.Timestamp.After(lastActive[spaceGUID]) {
+				lastActive[spaceGUID] = event.Entity.Timestamp
+			}
+			return len(spaceGUIDs) < limit
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(spaceGUIDs, func(i, j int) bool {
+		return lastActive[spaceGUIDs[i]].After(lastActive[spaceGUIDs[j]])
+	})
+
+	spaces := []models.Space{}
+	for _, spaceGUID := range spaceGUIDs {
+		resource := resources.SpaceResource{}
+		err = repo.gateway.GetResource(fmt.Sprintf("%s/v2/spaces/%s", repo.config.APIEndpoint(), spaceGUID), &resource)
+		if httpErr, ok := err.(errors.HTTPError); ok && httpErr.StatusCode() == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		spaces = append(spaces, resource.ToModel())
+	}
+	return spaces, nil
+}
+
+// isolationSegmentBatchSize is how many space GUIDs go in each isolation
+// segment query, keeping the URL a reasonable length.
+const isolationSegmentBatchSize = 50
+
+// ListIsolationSegmentNames maps each of the given spaces that has an
+// isolation segment assigned to the segment's name. Spaces without one are
+// left out.
+func (repo CloudControllerSpaceRepository) ListIsolationSegmentNames(spaceGUIDs []string) (map[string]string, error) {
+	wanted := map[string]bool{}
+	for _, spaceGUID := range spaceGUIDs {
+		wanted[spaceGUID] = true
+	}
+
+	segmentNames := map[string]string{}
+	for start := 0; start < len(spaceGUIDs); start += isolationSegmentBatchSize {
+		end := start + isolationSegmentBatchSize
+		if end > len(spaceGUIDs) {
+			end = len(spaceGUIDs)
+		}
+
+		path := fmt.Sprintf("%s/v3/isolation_segments?space_guids=%s", repo.config.APIEndpoint(), strings.Join(spaceGUIDs[start:end], ","))
+		for path != "" {
+			page := new(resources.V3IsolationSegmentResources)
+			err := repo.gateway.GetResource(path, page)
+			if err != nil {
+				return nil, err
+			}
+			for _, segment := range page.Resources {
+				segmentNames[segment.GUID] = segment.Name
+			}
+
+			path = ""
+			if page.Pagination.Next != nil {
+				path = page.Pagination.Next.Href
+			}
+		}
+	}
+
+	spaceSegments := map[string]string{}
+	for segmentGUID, segmentName := range segmentNames {
+		relationship := new(resources.V3ToManyRelationship)
+		err := repo.gateway.GetResource(fmt.Sprintf("%s/v3/isolation_segments/%s/relationships/spaces", repo.config.APIEndpoint(), segmentGUID), relationship)
+		if err != nil {
+			return nil, err
+		}
+		for _, space := range relationship.Data {
+			if wanted[space.GUID] {
+				spaceSegments[space.GUID] = segmentName
+			}
+		}
+	}
+	return spaceSegments, nil
+}
+
+// GetSpaceSummary counts the apps and service instances in a space, and the
+// memory its started apps use, from the Cloud Controller's space summary.
+func (repo CloudControllerSpaceRepository) GetSpaceSummary(spaceGUID string) (models.SpaceSummary, error) {
+	resource := new(resources.SpaceSummaryResource)
+	err := repo.gateway.GetResource(fmt.Sprintf("%s/v2/spaces/%s/summary", repo.config.APIEndpoint(), spaceGUID), resource)
+	if err != nil {
+		return models.SpaceSummary{}, err
+	}
+	return resource.ToModel(), nil
+}
+
 func (repo CloudControllerSpaceRepository) FindByName(name string) (space models.Space, apiErr error) {
 	return repo.FindByNameInOrg(name, repo.config.OrganizationFields().GUID)
 }
 
+// FindByNameInOrg finds a space by name using the Cloud Controller's name
+// filter, returning a ModelNotFoundError when the org has no such space.
 func (repo CloudControllerSpaceRepository) FindByNameInOrg(name, orgGUID string) (space models.Space, apiErr error) {
 	foundSpace := false
 	apiErr = repo.gateway.ListPaginatedResources(
@@ -73,8 +222,8 @@ func (repo CloudControllerSpaceRepository) FindByNameInOrg(name, orgGUID string)
 			return false
 		})
 
-	if !foundSpace {
-		apiErr = errors.NewModelNotFoundError("Space", name)
+	if apiErr == nil && !foundSpace {
+		apiErr = errors.NewNotFoundError(errors.SpaceResource, name)
 	}
 
 	return
@@ -212,6 +212,300 @@ var _ = Describe("Space Repository", func() {
 		})
 	})
 
+	Describe("ListAllSpaces", func() {
+		var (
+			ccServer *ghttp.Server
+			repo     CloudControllerSpaceRepository
+		)
+
+		BeforeEach(func() {
+			ccServer = ghttp.NewServer()
+			configRepo := testconfig.NewRepositoryWithDefaults()
+			configRepo.SetAPIEndpoint(ccServer.URL())
+			gateway := net.NewCloudControllerGateway(configRepo, time.Now, new(terminalfakes.FakeUI), new(tracefakes.FakePrinter), "")
+			repo = NewCloudControllerSpaceRepository(configRepo, gateway)
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/spaces", "order-by=name"),
+					ghttp.RespondWith(http.StatusOK, `{
+						"resources": [
+							{
+								"metadata": { "guid": "space1-guid" },
+								"entity": { "name": "Alpha", "organization_guid": "org1-guid" }
+							},
+							{
+								"metadata": { "guid": "space2-guid" },
+								"entity": { "name": "Beta", "organization_guid": "org2-guid" }
+							}
+						]
+					}`),
+				),
+			)
+		})
+
+		AfterEach(func() {
+			ccServer.Close()
+		})
+
+		It("lists the spaces of every org with their org GUIDs", func() {
+			spaces := []models.Space{}
+			apiErr := repo.ListAllSpaces(func(space models.Space) bool {
+				spaces = append(spaces, space)
+				return true
+			})
+
+			Expect(apiErr).NotTo(HaveOccurred())
+			Expect(spaces).To(HaveLen(2))
+			Expect(spaces[0].Name).To(Equal("Alpha"))
+			Expect(spaces[0].Organization.GUID).To(Equal("org1-guid"))
+			Expect(spaces[1].Name).To(Equal("Beta"))
+			Expect(spaces[1].Organization.GUID).To(Equal("org2-guid"))
+		})
+	})
+
+	Describe("ListAccessibleSpaces", func() {
+		var (
+			ccServer *ghttp.Server
+			repo     CloudControllerSpaceRepository
+		)
+
+		BeforeEach(func() {
+			ccServer = ghttp.NewServer()
+			configRepo := testconfig.NewRepositoryWithDefaults()
+			configRepo.SetAPIEndpoint(ccServer.URL())
+			configRepo.SetUAAOAuthClient("ci-client")
+			configRepo.SetAccessToken("bearer ci-client-token")
+			gateway := net.NewCloudControllerGateway(configRepo, time.Now, new(terminalfakes.FakeUI), new(tracefakes.FakePrinter), "")
+			repo = NewCloudControllerSpaceRepository(configRepo, gateway)
+		})
+
+		AfterEach(func() {
+			ccServer.Close()
+		})
+
+		Context("when the client can access some spaces", func() {
+			BeforeEach(func() {
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/v2/spaces", "order-by=name"),
+						ghttp.VerifyHeader(http.Header{
+							"Authorization": []string{"bearer ci-client-token"},
+						}),
+						ghttp.RespondWith(http.StatusOK, `{
+							"resources": [
+								{
+									"metadata": { "guid": "staging-guid" },
+									"entity": { "name": "staging", "organization_guid": "org-guid" }
+								}
+							]
+						}`),
+					),
+				)
+			})
+
+			It("returns only the spaces the token may see", func() {
+				spaces, err := repo.ListAccessibleSpaces()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spaces).To(HaveLen(1))
+				Expect(spaces[0].Name).To(Equal("staging"))
+				Expect(spaces[0].Organization.GUID).To(Equal("org-guid"))
+			})
+		})
+
+		Context("when the client cannot access any spaces", func() {
+			BeforeEach(func() {
+				ccServer.AppendHandlers(
+					ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+				)
+			})
+
+			It("returns an empty list", func() {
+				spaces, err := repo.ListAccessibleSpaces()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spaces).NotTo(BeNil())
+				Expect(spaces).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("ListRecentSpaces", func() {
+		var (
+			ccServer *ghttp.Server
+			repo     CloudControllerSpaceRepository
+		)
+
+		BeforeEach(func() {
+			ccServer = ghttp.NewServer()
+			configRepo := testconfig.NewRepositoryWithDefaults()
+			configRepo.SetAPIEndpoint(ccServer.URL())
+			gateway := net.NewCloudControllerGateway(configRepo, time.Now, new(terminalfakes.FakeUI), new(tracefakes.FakePrinter), "")
+			repo = NewCloudControllerSpaceRepository(configRepo, gateway)
+		})
+
+		AfterEach(func() {
+			ccServer.Close()
+		})
+
+		Context("when the user has been active in several spaces", func() {
+			BeforeEach(func() {
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/v2/events", "order-direction=desc&results-per-page=100&q=actor:my-user-guid"),
+						ghttp.RespondWith(http.StatusOK, `{"resources": [
+							{"metadata": {"guid": "event-1"}, "entity": {"timestamp": "2017-06-01T10:00:00Z", "space_guid": "dev-guid"}},
+							{"metadata": {"guid": "event-2"}, "entity": {"timestamp": "2017-06-03T10:00:00Z", "space_guid": "prod-guid"}},
+							{"metadata": {"guid": "event-3"}, "entity": {"timestamp": "2017-05-01T10:00:00Z", "space_guid": "dev-guid"}},
+							{"metadata": {"guid": "event-4"}, "entity": {"timestamp": "2017-04-01T10:00:00Z"}},
+							{"metadata": {"guid": "event-5"}, "entity": {"timestamp": "2017-03-01T10:00:00Z", "space_guid": "deleted-guid"}}
+						]}`),
+					),
+				)
+				ccServer.RouteToHandler("GET", "/v2/spaces/prod-guid", ghttp.RespondWith(http.StatusOK,
+					`{"metadata": {"guid": "prod-guid"}, "entity": {"name": "prod", "organization_guid": "org-guid"}}`))
+				ccServer.RouteToHandler("GET", "/v2/spaces/dev-guid", ghttp.RespondWith(http.StatusOK,
+					`{"metadata": {"guid": "dev-guid"}, "entity": {"name": "dev", "organization_guid": "org-guid"}}`))
+				ccServer.RouteToHandler("GET", "/v2/spaces/deleted-guid", ghttp.RespondWith(http.StatusNotFound,
+					`{"code": 40004, "description": "The app space could not be found", "error_code": "CF-SpaceNotFound"}`))
+			})
+
+			It("returns the spaces by most recent activity, skipping ones that are gone", func() {
+				spaces, err := repo.ListRecentSpaces(10)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spaces).To(HaveLen(2))
+				Expect(spaces[0].Name).To(Equal("prod"))
+				Expect(spaces[1].Name).To(Equal("dev"))
+			})
+
+			It("stops at the limit", func() {
+				spaces, err := repo.ListRecentSpaces(1)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spaces).To(HaveLen(1))
+				Expect(spaces[0].Name).To(Equal("dev"))
+			})
+		})
+
+		Context("when the user has no activity", func() {
+			BeforeEach(func() {
+				ccServer.AppendHandlers(
+					ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+				)
+			})
+
+			It("returns an empty list", func() {
+				spaces, err := repo.ListRecentSpaces(10)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spaces).NotTo(BeNil())
+				Expect(spaces).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("ListIsolationSegmentNames", func() {
+		var (
+			ccServer *ghttp.Server
+			repo     CloudControllerSpaceRepository
+		)
+
+		BeforeEach(func() {
+			ccServer = ghttp.NewServer()
+			configRepo := testconfig.NewRepositoryWithDefaults()
+			configRepo.SetAPIEndpoint(ccServer.URL())
+			gateway := net.NewCloudControllerGateway(configRepo, time.Now, new(terminalfakes.FakeUI), new(tracefakes.FakePrinter), "")
+			repo = NewCloudControllerSpaceRepository(configRepo, gateway)
+
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v3/isolation_segments", "space_guids=dev-guid,prod-guid,other-guid"),
+					ghttp.RespondWith(http.StatusOK, `{"pagination": {"next": null}, "resources": [
+						{"guid": "seg-guid", "name": "segment-one"}
+					]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v3/isolation_segments/seg-guid/relationships/spaces"),
+					ghttp.RespondWith(http.StatusOK, `{"data": [
+						{"guid": "prod-guid"},
+						{"guid": "unrelated-guid"}
+					]}`),
+				),
+			)
+		})
+
+		AfterEach(func() {
+			ccServer.Close()
+		})
+
+		It("maps each space with a segment to the segment's name", func() {
+			names, err := repo.ListIsolationSegmentNames([]string{"dev-guid", "prod-guid", "other-guid"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal(map[string]string{"prod-guid": "segment-one"}))
+		})
+	})
+
+	Describe("GetSpaceSummary", func() {
+		var (
+			ccServer *ghttp.Server
+			repo     CloudControllerSpaceRepository
+		)
+
+		BeforeEach(func() {
+			ccServer = ghttp.NewServer()
+			configRepo := testconfig.NewRepositoryWithDefaults()
+			configRepo.SetAPIEndpoint(ccServer.URL())
+			gateway := net.NewCloudControllerGateway(configRepo, time.Now, new(terminalfakes.FakeUI), new(tracefakes.FakePrinter), "")
+			repo = NewCloudControllerSpaceRepository(configRepo, gateway)
+		})
+
+		AfterEach(func() {
+			ccServer.Close()
+		})
+
+		Context("when the space has apps and services", func() {
+			BeforeEach(func() {
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/v2/spaces/dev-guid/summary"),
+						ghttp.RespondWith(http.StatusOK, `{
+							"guid": "dev-guid",
+							"name": "dev",
+							"apps": [
+								{"guid": "app-1", "memory": 256, "instances": 2, "state": "STARTED"},
+								{"guid": "app-2", "memory": 1024, "instances": 1, "state": "STOPPED"},
+								{"guid": "app-3", "memory": 128, "instances": 1, "state": "STARTED"}
+							],
+							"services": [
+								{"guid": "service-1"},
+								{"guid": "service-2"}
+							]
+						}`),
+					),
+				)
+			})
+
+			It("counts them and totals the memory of started instances", func() {
+				summary, err := repo.GetSpaceSummary("dev-guid")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(summary).To(Equal(models.SpaceSummary{
+					AppsCount:     3,
+					ServicesCount: 2,
+					MemoryUsage:   640,
+				}))
+			})
+		})
+
+		Context("when the summary cannot be fetched", func() {
+			BeforeEach(func() {
+				ccServer.AppendHandlers(
+					ghttp.RespondWith(http.StatusNotFound, `{"code": 40004, "description": "The app space could not be found", "error_code": "CF-SpaceNotFound"}`),
+				)
+			})
+
+			It("returns the error", func() {
+				_, err := repo.GetSpaceSummary("dev-guid")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
 	Describe("finding spaces by name", func() {
 		It("returns the space", func() {
 			testSpacesFindByNameWithOrg("my-org-guid",
@@ -244,6 +538,26 @@ var _ = Describe("Space Repository", func() {
 				},
 			)
 		})
+
+		It("returns the error rather than 'not found' when the lookup fails", func() {
+			request := apifakes.NewCloudControllerTestRequest(testnet.TestRequest{
+				Method: "GET",
+				Path:   "/v2/organizations/another-org-guid/spaces?q=name%3Aspace1",
+				Response: testnet.TestResponse{
+					Status: http.StatusForbidden,
+					Body:   `{"code": 10003, "description": "You are not authorized to perform the requested action", "error_code": "CF-NotAuthorized"}`,
+				},
+			})
+
+			ts, handler, repo := createSpacesRepo(request)
+			defer ts.Close()
+
+			_, apiErr := repo.FindByNameInOrg("Space1", "another-org-guid")
+			Expect(handler).To(HaveAllRequestsCalled())
+			Expect(apiErr).To(HaveOccurred())
+			Expect(apiErr).NotTo(BeAssignableToTypeOf(&errors.ModelNotFoundError{}))
+			Expect(apiErr.(errors.HTTPError).StatusCode()).To(Equal(http.StatusForbidden))
+		})
 	})
 
 	It("creates spaces without a space-quota", func() {
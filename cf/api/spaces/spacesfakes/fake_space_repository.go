@@ -26,6 +26,48 @@ type FakeSpaceRepository struct {
 	listSpacesFromOrgReturns struct {
 		result1 error
 	}
+	ListAllSpacesStub        func(spaceFunc func(models.Space) bool) error
+	listAllSpacesMutex       sync.RWMutex
+	listAllSpacesArgsForCall []struct {
+		spaceFunc func(models.Space) bool
+	}
+	listAllSpacesReturns struct {
+		result1 error
+	}
+	ListAccessibleSpacesStub        func() ([]models.Space, error)
+	listAccessibleSpacesMutex       sync.RWMutex
+	listAccessibleSpacesArgsForCall []struct{}
+	listAccessibleSpacesReturns     struct {
+		result1 []models.Space
+		result2 error
+	}
+	ListRecentSpacesStub        func(limit int) ([]models.Space, error)
+	listRecentSpacesMutex       sync.RWMutex
+	listRecentSpacesArgsForCall []struct {
+		limit int
+	}
+	listRecentSpacesReturns struct {
+		result1 []models.Space
+		result2 error
+	}
+	ListIsolationSegmentNamesStub        func(spaceGUIDs []string) (map[string]string, error)
+	listIsolationSegmentNamesMutex       sync.RWMutex
+	listIsolationSegmentNamesArgsForCall []struct {
+		spaceGUIDs []string
+	}
+	listIsolationSegmentNamesReturns struct {
+		result1 map[string]string
+		result2 error
+	}
+	GetSpaceSummaryStub        func(spaceGUID string) (models.SpaceSummary, error)
+	getSpaceSummaryMutex       sync.RWMutex
+	getSpaceSummaryArgsForCall []struct {
+		spaceGUID string
+	}
+	getSpaceSummaryReturns struct {
+		result1 models.SpaceSummary
+		result2 error
+	}
 	FindByNameStub        func(name string) (space models.Space, apiErr error)
 	findByNameMutex       sync.RWMutex
 	findByNameArgsForCall []struct {
@@ -153,6 +195,172 @@ func (fake *FakeSpaceRepository) ListSpacesFromOrgReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeSpaceRepository) ListAllSpaces(spaceFunc func(models.Space) bool) error {
+	fake.listAllSpacesMutex.Lock()
+	fake.listAllSpacesArgsForCall = append(fake.listAllSpacesArgsForCall, struct {
+		spaceFunc func(models.Space) bool
+	}{spaceFunc})
+	fake.recordInvocation("ListAllSpaces", []interface{}{spaceFunc})
+	fake.listAllSpacesMutex.Unlock()
+	if fake.ListAllSpacesStub != nil {
+		return fake.ListAllSpacesStub(spaceFunc)
+	} else {
+		return fake.listAllSpacesReturns.result1
+	}
+}
+
+func (fake *FakeSpaceRepository) ListAllSpacesCallCount() int {
+	fake.listAllSpacesMutex.RLock()
+	defer fake.listAllSpacesMutex.RUnlock()
+	return len(fake.listAllSpacesArgsForCall)
+}
+
+func (fake *FakeSpaceRepository) ListAllSpacesArgsForCall(i int) func(models.Space) bool {
+	fake.listAllSpacesMutex.RLock()
+	defer fake.listAllSpacesMutex.RUnlock()
+	return fake.listAllSpacesArgsForCall[i].spaceFunc
+}
+
+func (fake *FakeSpaceRepository) ListAllSpacesReturns(result1 error) {
+	fake.ListAllSpacesStub = nil
+	fake.listAllSpacesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSpaceRepository) ListAccessibleSpaces() ([]models.Space, error) {
+	fake.listAccessibleSpacesMutex.Lock()
+	fake.listAccessibleSpacesArgsForCall = append(fake.listAccessibleSpacesArgsForCall, struct{}{})
+	fake.recordInvocation("ListAccessibleSpaces", []interface{}{})
+	fake.listAccessibleSpacesMutex.Unlock()
+	if fake.ListAccessibleSpacesStub != nil {
+		return fake.ListAccessibleSpacesStub()
+	} else {
+		return fake.listAccessibleSpacesReturns.result1, fake.listAccessibleSpacesReturns.result2
+	}
+}
+
+func (fake *FakeSpaceRepository) ListAccessibleSpacesCallCount() int {
+	fake.listAccessibleSpacesMutex.RLock()
+	defer fake.listAccessibleSpacesMutex.RUnlock()
+	return len(fake.listAccessibleSpacesArgsForCall)
+}
+
+func (fake *FakeSpaceRepository) ListAccessibleSpacesReturns(result1 []models.Space, result2 error) {
+	fake.ListAccessibleSpacesStub = nil
+	fake.listAccessibleSpacesReturns = struct {
+		result1 []models.Space
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpaceRepository) ListRecentSpaces(limit int) ([]models.Space, error) {
+	fake.listRecentSpacesMutex.Lock()
+	fake.listRecentSpacesArgsForCall = append(fake.listRecentSpacesArgsForCall, struct {
+		limit int
+	}{limit})
+	fake.recordInvocation("ListRecentSpaces", []interface{}{limit})
+	fake.listRecentSpacesMutex.Unlock()
+	if fake.ListRecentSpacesStub != nil {
+		return fake.ListRecentSpacesStub(limit)
+	} else {
+		return fake.listRecentSpacesReturns.result1, fake.listRecentSpacesReturns.result2
+	}
+}
+
+func (fake *FakeSpaceRepository) ListRecentSpacesCallCount() int {
+	fake.listRecentSpacesMutex.RLock()
+	defer fake.listRecentSpacesMutex.RUnlock()
+	return len(fake.listRecentSpacesArgsForCall)
+}
+
+func (fake *FakeSpaceRepository) ListRecentSpacesArgsForCall(i int) int {
+	fake.listRecentSpacesMutex.RLock()
+	defer fake.listRecentSpacesMutex.RUnlock()
+	return fake.listRecentSpacesArgsForCall[i].limit
+}
+
+func (fake *FakeSpaceRepository) ListRecentSpacesReturns(result1 []models.Space, result2 error) {
+	fake.ListRecentSpacesStub = nil
+	fake.listRecentSpacesReturns = struct {
+		result1 []models.Space
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpaceRepository) ListIsolationSegmentNames(spaceGUIDs []string) (map[string]string, error) {
+	var spaceGUIDsCopy []string
+	if spaceGUIDs != nil {
+		spaceGUIDsCopy = make([]string, len(spaceGUIDs))
+		copy(spaceGUIDsCopy, spaceGUIDs)
+	}
+	fake.listIsolationSegmentNamesMutex.Lock()
+	fake.listIsolationSegmentNamesArgsForCall = append(fake.listIsolationSegmentNamesArgsForCall, struct {
+		spaceGUIDs []string
+	}{spaceGUIDsCopy})
+	fake.recordInvocation("ListIsolationSegmentNames", []interface{}{spaceGUIDsCopy})
+	fake.listIsolationSegmentNamesMutex.Unlock()
+	if fake.ListIsolationSegmentNamesStub != nil {
+		return fake.ListIsolationSegmentNamesStub(spaceGUIDs)
+	} else {
+		return fake.listIsolationSegmentNamesReturns.result1, fake.listIsolationSegmentNamesReturns.result2
+	}
+}
+
+func (fake *FakeSpaceRepository) ListIsolationSegmentNamesCallCount() int {
+	fake.listIsolationSegmentNamesMutex.RLock()
+	defer fake.listIsolationSegmentNamesMutex.RUnlock()
+	return len(fake.listIsolationSegmentNamesArgsForCall)
+}
+
+func (fake *FakeSpaceRepository) ListIsolationSegmentNamesArgsForCall(i int) []string {
+	fake.listIsolationSegmentNamesMutex.RLock()
+	defer fake.listIsolationSegmentNamesMutex.RUnlock()
+	return fake.listIsolationSegmentNamesArgsForCall[i].spaceGUIDs
+}
+
+func (fake *FakeSpaceRepository) ListIsolationSegmentNamesReturns(result1 map[string]string, result2 error) {
+	fake.ListIsolationSegmentNamesStub = nil
+	fake.listIsolationSegmentNamesReturns = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSpaceRepository) GetSpaceSummary(spaceGUID string) (models.SpaceSummary, error) {
+	fake.getSpaceSummaryMutex.Lock()
+	fake.getSpaceSummaryArgsForCall = append(fake.getSpaceSummaryArgsForCall, struct {
+		spaceGUID string
+	}{spaceGUID})
+	fake.recordInvocation("GetSpaceSummary", []interface{}{spaceGUID})
+	fake.getSpaceSummaryMutex.Unlock()
+	if fake.GetSpaceSummaryStub != nil {
+		return fake.GetSpaceSummaryStub(spaceGUID)
+	} else {
+		return fake.getSpaceSummaryReturns.result1, fake.getSpaceSummaryReturns.result2
+	}
+}
+
+func (fake *FakeSpaceRepository) GetSpaceSummaryCallCount() int {
+	fake.getSpaceSummaryMutex.RLock()
+	defer fake.getSpaceSummaryMutex.RUnlock()
+	return len(fake.getSpaceSummaryArgsForCall)
+}
+
+func (fake *FakeSpaceRepository) GetSpaceSummaryArgsForCall(i int) string {
+	fake.getSpaceSummaryMutex.RLock()
+	defer fake.getSpaceSummaryMutex.RUnlock()
+	return fake.getSpaceSummaryArgsForCall[i].spaceGUID
+}
+
+func (fake *FakeSpaceRepository) GetSpaceSummaryReturns(result1 models.SpaceSummary, result2 error) {
+	fake.GetSpaceSummaryStub = nil
+	fake.getSpaceSummaryReturns = struct {
+		result1 models.SpaceSummary
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeSpaceRepository) FindByName(name string) (space models.Space, apiErr error) {
 	fake.findByNameMutex.Lock()
 	fake.findByNameArgsForCall = append(fake.findByNameArgsForCall, struct {
@@ -366,6 +574,16 @@ func (fake *FakeSpaceRepository) Invocations() map[string][][]interface{} {
 	defer fake.listSpacesMutex.RUnlock()
 	fake.listSpacesFromOrgMutex.RLock()
 	defer fake.listSpacesFromOrgMutex.RUnlock()
+	fake.listAllSpacesMutex.RLock()
+	defer fake.listAllSpacesMutex.RUnlock()
+	fake.listAccessibleSpacesMutex.RLock()
+	defer fake.listAccessibleSpacesMutex.RUnlock()
+	fake.listRecentSpacesMutex.RLock()
+	defer fake.listRecentSpacesMutex.RUnlock()
+	fake.listIsolationSegmentNamesMutex.RLock()
+	defer fake.listIsolationSegmentNamesMutex.RUnlock()
+	fake.getSpaceSummaryMutex.RLock()
+	defer fake.getSpaceSummaryMutex.RUnlock()
 	fake.findByNameMutex.RLock()
 	defer fake.findByNameMutex.RUnlock()
 	fake.findByNameInOrgMutex.RLock()
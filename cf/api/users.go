@@ -2,13 +2,23 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/mail"
 	neturl "net/url"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
+	"code.cloudfoundry.org/cli/cf"
 	"code.cloudfoundry.org/cli/cf/api/resources"
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
 	"code.cloudfoundry.org/cli/cf/errors"
@@ -30,6 +40,60 @@ var spaceRoleToPathMap = map[models.Role]string{
 	models.RoleSpaceAuditor:   "auditors",
 }
 
+var orgRoleToV3TypeMap = map[models.Role]string{
+	models.RoleOrgUser:        "organization_user",
+	models.RoleOrgManager:     "organization_manager",
+	models.RoleBillingManager: "organization_billing_manager",
+	models.RoleOrgAuditor:     "organization_auditor",
+}
+
+var spaceRoleToV3TypeMap = map[models.Role]string{
+	models.RoleSpaceManager:   "space_manager",
+	models.RoleSpaceDeveloper: "space_developer",
+	models.RoleSpaceAuditor:   "space_auditor",
+}
+
+var orgRoles = []models.Role{
+	models.RoleOrgUser,
+	models.RoleOrgManager,
+	models.RoleBillingManager,
+	models.RoleOrgAuditor,
+}
+
+var spaceRoles = []models.Role{
+	models.RoleSpaceManager,
+	models.RoleSpaceDeveloper,
+	models.RoleSpaceAuditor,
+}
+
+// ValidateRoleMaps checks that every org and space role maps to a distinct,
+// non-empty Cloud Controller path. It is meant to be called once at startup.
+func ValidateRoleMaps() error {
+	err := ValidateRolePaths("org", orgRoleToPathMap)
+	if err != nil {
+		return err
+	}
+	return ValidateRolePaths("space", spaceRoleToPathMap)
+}
+
+// ValidateRolePaths checks a single role to path map. The kind is only used
+// in error messages.
+func ValidateRolePaths(kind string, rolePaths map[models.Role]string) error {
+	rolesByPath := map[string]models.Role{}
+	for role, path := range rolePaths {
+		if path == "" {
+			return errors.New(T("{{.Kind}} role {{.Role}} has no path",
+				map[string]interface{}{"Kind": kind, "Role": role.ToString()}))
+		}
+		if other, found := rolesByPath[path]; found {
+			return errors.New(T("{{.Kind}} roles {{.Role}} and {{.OtherRole}} share the path {{.Path}}",
+				map[string]interface{}{"Kind": kind, "Role": role.ToString(), "OtherRole": other.ToString(), "Path": path}))
+		}
+		rolesByPath[path] = role
+	}
+	return nil
+}
+
 type apiErrResponse struct {
 	Code        int    `json:"code,omitempty"`
 	ErrorCode   string `json:"error_code,omitempty"`
@@ -40,37 +104,547 @@ type apiErrResponse struct {
 
 type UserRepository interface {
 	FindByUsername(username string) (user models.UserFields, apiErr error)
+	FindByEmail(email string) (user models.UserFields, apiErr error)
+	FindByUsernameContext(ctx context.Context, username string) (user models.UserFields, apiErr error)
 	FindAllByUsername(username string) (users []models.UserFields, apiErr error)
+	FindAllByUsernameContext(ctx context.Context, username string) (users []models.UserFields, apiErr error)
+	FindAllByUsernamePrefix(prefix string) (users []models.UserFields, apiErr error)
+	FindByUsernames(usernames []string) (users map[string]models.UserFields, apiErr error)
+	IsUsernameAvailable(username string) (bool, error)
+	VerifyCredentials(username, password string) (bool, error)
 	ListUsersInOrgForRole(orgGUID string, role models.Role) ([]models.UserFields, error)
+	ListUsersInOrgForRoleContext(ctx context.Context, orgGUID string, role models.Role) ([]models.UserFields, error)
+	CountUsersInOrgForRole(orgGUID string, role models.Role) (int, error)
+	ListUsersInOrgForRoleFiltered(orgGUID string, role models.Role, usernameContains string) ([]models.UserFields, error)
+	ListAllUsersInOrg(orgGUID string) (map[models.Role][]models.UserFields, error)
+	ListUsersInOrgForRoles(orgGUID string, roles []models.Role) (map[models.Role][]models.UserFields, error)
+	ListUsersInSpaceForAllRoles(spaceGUID string) (map[models.Role][]models.UserFields, error)
 	ListUsersInOrgForRoleWithNoUAA(orgGUID string, role models.Role) ([]models.UserFields, error)
+	ListInactiveOrgUsers(orgGUID string, inactiveFor time.Duration) ([]models.UserFields, error)
 	ListUsersInSpaceForRoleWithNoUAA(spaceGUID string, role models.Role) ([]models.UserFields, error)
+	ListOrgUsersWithSpaceRoles(orgGUID string) ([]models.OrgUserWithSpaceRoles, error)
+	FindDuplicateCCRegistrations() ([]models.DuplicateUserRegistration, error)
+	FindUnmatchedUsers() (models.UnmatchedUsers, error)
+	FindRolelessUsers() ([]models.UserFields, error)
+	GetUserAccessSummary(userGUID string, resolveNames bool) (models.UserAccessSummary, error)
+	GetUserMemberships(userGUID, orgGUID string) (models.UserMemberships, error)
+	GetUserRolesInOrg(userGUID, orgGUID string) ([]models.Role, error)
+	CopyRoles(fromUserGUID, toUserGUID, orgGUID string) ([]models.RoleChangeResult, error)
+	CopySpaceRoles(fromUserGUID, toUserGUID, spaceGUID, orgGUID string) ([]models.RoleChangeResult, error)
+	ExportUsersSCIM(w io.Writer) error
+	ExportFoundationRBAC(w io.Writer) error
+	EachRolelessUser(cb func(models.UserFields) bool) error
+	ListAllUsers(cb func(models.UserFields) bool) error
+	ListCFUsersInUAAGroup(groupName string) ([]models.UserFields, error)
+	DiffSpaceUsers(spaceAGUID, spaceBGUID string) ([]models.SpaceRoleDiff, error)
 	Create(username, password string) (apiErr error)
+	CreateWithEmail(username, password, email string) (apiErr error)
+	CreateWithOrigin(username, password, origin string) (apiErr error)
+	CreateBulk(users []models.UserCreateRequest) ([]models.UserCreateResult, error)
 	Delete(userGUID string) (apiErr error)
+	DeleteAsync(userGUID string) (apiErr error)
+	RenameUser(userGUID, newUsername string) (apiErr error)
+	UpdatePassword(userGUID, oldPassword, newPassword string) (apiErr error)
 	SetOrgRoleByGUID(userGUID, orgGUID string, role models.Role) (apiErr error)
 	SetOrgRoleByUsername(username, orgGUID string, role models.Role) (apiErr error)
 	UnsetOrgRoleByGUID(userGUID, orgGUID string, role models.Role) (apiErr error)
 	UnsetOrgRoleByUsername(username, orgGUID string, role models.Role) (apiErr error)
+	UnsetOrgRoleBulk(userGUIDs []string, orgGUID string, role models.Role) ([]models.RoleChangeResult, error)
+	UnsetOrgRoleBulkContext(ctx context.Context, userGUIDs []string, orgGUID string, role models.Role) ([]models.RoleChangeResult, error)
+	PreviewOrgRoleChange(userGUID, username, orgGUID string, role models.Role, set bool) ([]models.RequestPreview, error)
+	PreviewSpaceRoleChange(userGUID, username, spaceGUID, orgGUID string, role models.Role, set bool) ([]models.RequestPreview, error)
 	SetSpaceRoleByGUID(userGUID, spaceGUID, orgGUID string, role models.Role) (apiErr error)
 	SetSpaceRoleByUsername(username, spaceGUID, orgGUID string, role models.Role) (apiErr error)
+	SetSpaceRolesByGUID(userGUID, spaceGUID, orgGUID string, roles []models.Role) ([]models.RoleChangeResult, error)
+	SetSpaceRolesByUsername(username, spaceGUID, orgGUID string, roles []models.Role) ([]models.RoleChangeResult, error)
 	UnsetSpaceRoleByGUID(userGUID, spaceGUID string, role models.Role) (apiErr error)
 	UnsetSpaceRoleByUsername(userGUID, spaceGUID string, role models.Role) (apiErr error)
+	SetOrgRoleByGUIDAsync(userGUID, orgGUID string, role models.Role) (job RoleJob, apiErr error)
+	UnsetOrgRoleByGUIDAsync(userGUID, orgGUID string, role models.Role) (job RoleJob, apiErr error)
+	SetSpaceRoleByGUIDAsync(userGUID, spaceGUID, orgGUID string, role models.Role) (job RoleJob, apiErr error)
+	SpaceRoleETag(spaceGUID string, role models.Role) (string, error)
+	SetSpaceRoleByGUIDIfMatch(userGUID, spaceGUID, orgGUID string, role models.Role, etag string) (apiErr error)
+	UnsetSpaceRoleByGUIDAsync(userGUID, spaceGUID string, role models.Role) (job RoleJob, apiErr error)
+	WaitForRoleJob(job RoleJob) (apiErr error)
+}
+
+// RoleJob is a handle on a role change the Cloud Controller is applying
+// asynchronously. The zero value means the change has already taken effect.
+type RoleJob struct {
+	URL string
+}
+
+func (job RoleJob) Pending() bool {
+	return job.URL != ""
+}
+
+// AdminResolver decides whether a user is an admin. The user carries the
+// admin flag from their Cloud Controller record; uaaGroups holds the names of
+// the UAA groups they belong to and is only populated when the user was
+// joined with UAA.
+type AdminResolver interface {
+	IsAdmin(user models.UserFields, uaaGroups []string) bool
+}
+
+// CCFlagAdminResolver treats the Cloud Controller admin flag as the sole
+// source of truth. It is the default AdminResolver.
+type CCFlagAdminResolver struct{}
+
+func (CCFlagAdminResolver) IsAdmin(user models.UserFields, uaaGroups []string) bool {
+	return user.IsAdmin
+}
+
+// CCAdminFlagReader is implemented by AdminResolvers that need the Cloud
+// Controller admin flag on users listed through /v3/roles. /v3/roles has no
+// such flag, so it is only looked up, one user at a time, for resolvers that
+// ask for it; otherwise those users carry no flag.
+type CCAdminFlagReader interface {
+	ReadsCCAdminFlag() bool
 }
 
 type CloudControllerUserRepository struct {
-	config     coreconfig.Reader
-	uaaGateway net.Gateway
-	ccGateway  net.Gateway
+	config        coreconfig.Reader
+	uaaGateway    net.Gateway
+	ccGateway     net.Gateway
+	dryRun        bool
+	jobTimeout    time.Duration
+	adminResolver AdminResolver
+	lookupCache   *userLookupCache
+	metrics       MetricsCollector
+	verboseErrors bool
+	maxURLLength  int
+	batchSize     int
+	serverOrder   bool
+	warnOrphans   bool
+	excludeUser   ServiceAccountMatcher
+	onBehalfOf    string
+	transform     func(models.UserFields) models.UserFields
+	spaceRoleURL  SpaceRolePathTemplate
+	customSpaces  bool
+	lastLogon     bool
+	createdAt     bool
+	postCreate    func(models.UserFields) error
+}
+
+// DefaultMaxUAAFilterURLLength keeps UAA user lookups comfortably below the
+// URL limits of common proxies.
+const DefaultMaxUAAFilterURLLength = 2000
+
+// DefaultUAAFilterBatchSize is how many users are looked up per UAA request
+// unless the URL length limit is reached first.
+const DefaultUAAFilterBatchSize = 50
+
+type userLookupCache struct {
+	mutex sync.Mutex
+	users map[string][]models.UserFields
+}
+
+type noCacheKey struct{}
+
+// WithNoCache returns a context that makes the context-aware repository
+// methods skip any cached result and fetch fresh data for that call.
+func WithNoCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+func noCache(ctx context.Context) bool {
+	skip, _ := ctx.Value(noCacheKey{}).(bool)
+	return skip
+}
+
+// UserRepositoryOption configures optional behaviour of a
+// CloudControllerUserRepository.
+type UserRepositoryOption func(*CloudControllerUserRepository)
+
+// WithDryRun makes Create, Delete and the role changing methods validate
+// their arguments and return without sending any request.
+func WithDryRun() UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.dryRun = true
+	}
+}
+
+// WithJobTimeout overrides the configured async timeout when waiting for
+// role change jobs.
+func WithJobTimeout(timeout time.Duration) UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.jobTimeout = timeout
+	}
+}
+
+// WithJobPollingInterval sets how long to wait between polls of a role
+// change job.
+func WithJobPollingInterval(interval time.Duration) UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.ccGateway.PollingThrottle = interval
+	}
+}
+
+// WithAdminResolver replaces the default CC flag based admin check. UAA
+// group membership is requested from UAA so the resolver can consult it.
+func WithAdminResolver(resolver AdminResolver) UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.adminResolver = resolver
+	}
+}
+
+// WithUserLookupCache remembers the result of each username lookup for the
+// lifetime of the repository.
+func WithUserLookupCache() UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.lookupCache = &userLookupCache{users: map[string][]models.UserFields{}}
+	}
+}
+
+// MetricsCollector receives the counters emitted by the user repository:
+// operations_total, labelled by method and outcome, and uaa_calls_total.
+type MetricsCollector interface {
+	IncrementCounter(name string, labels map[string]string)
+}
+
+type noopMetricsCollector struct{}
+
+func (noopMetricsCollector) IncrementCounter(string, map[string]string) {}
+
+// WithMetricsCollector reports a count of every repository call, and of
+// every request made to UAA, to the given collector.
+func WithMetricsCollector(collector MetricsCollector) UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.metrics = collector
+	}
+}
+
+// WithVerboseErrors attaches the sanitized status and body of the server
+// response to errors returned by Create, Delete and the role changing
+// methods, for pasting into support tickets.
+func WithVerboseErrors() UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.verboseErrors = true
+	}
+}
+
+// WithMaxUAAFilterURLLength sets the longest UAA lookup URL the repository
+// will send. Filters that would exceed it are split over several requests.
+func WithMaxUAAFilterURLLength(length int) UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.maxURLLength = length
+	}
+}
+
+// WithUAAFilterBatchSize sets the most users looked up in a single UAA
+// request.
+func WithUAAFilterBatchSize(size int) UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.batchSize = size
+	}
+}
+
+// WithResultsPerPage sets the page size asked of the Cloud Controller when
+// listing users. It does not change how many users are looked up in each UAA
+// request; see WithUAAFilterBatchSize.
+func WithResultsPerPage(perPage int) UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.ccGateway.ResultsPerPage = perPage
+	}
+}
+
+// WithServerOrder makes the list methods return users in the order the
+// servers sent them instead of sorted by username.
+func WithServerOrder() UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.serverOrder = true
+	}
+}
+
+// WithOnBehalfOf sends the given actor in an X-On-Behalf-Of header with
+// every mutating request, so server audit logs can credit the real actor.
+func WithOnBehalfOf(actor string) UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.onBehalfOf = actor
+	}
+}
+
+// SpaceRolePathTemplate builds the CC path, relative to the API endpoint, of
+// the users holding a role in a space. rolePath is the role's segment from
+// spaceRoleToPathMap, such as "managers".
+type SpaceRolePathTemplate func(spaceGUID, rolePath string) string
+
+// FlatSpaceRolePath is the standard /v2/spaces/:guid/:role layout.
+func FlatSpaceRolePath(spaceGUID, rolePath string) string {
+	return fmt.Sprintf("/v2/spaces/%s/%s", spaceGUID, rolePath)
+}
+
+// WithSpaceRolePathTemplate builds space role paths with template, for
+// Cloud Controllers that lay out spaces differently.
+func WithSpaceRolePathTemplate(template SpaceRolePathTemplate) UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.spaceRoleURL = template
+		repo.customSpaces = true
+	}
+}
+
+// WithBackoffStrategy sets how long the repository's gateways wait between
+// read retries.
+func WithBackoffStrategy(backoff net.BackoffStrategy) UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.ccGateway.ReadRetryBackoff = backoff
+		repo.uaaGateway.ReadRetryBackoff = backoff
+	}
+}
+
+// WithTransportTimeouts sets the dial and TLS handshake timeouts of the
+// repository's gateways, so slow connections fail on their own timeout.
+func WithTransportTimeouts(dial, tlsHandshake time.Duration) UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.ccGateway.SetTimeouts(dial, tlsHandshake)
+		repo.uaaGateway.SetTimeouts(dial, tlsHandshake)
+	}
+}
+
+// WithUAADialTimeout gives the repository's UAA gateway its own dial
+// timeout, leaving the Cloud Controller gateway's as it is.
+func WithUAADialTimeout(dial time.Duration) UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.uaaGateway.SetTimeouts(dial, repo.uaaGateway.TLSHandshakeTimeout)
+	}
+}
+
+// WithTokenExpiryCheck makes the repository check the exp claim of the
+// access token before every request and fail with a TokenExpiredError,
+// rather than sending a request the server will reject. Tokens that are not
+// JWTs, or carry no exp claim, are sent as usual.
+func WithTokenExpiryCheck() UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		check := tokenExpiryCheck(repo.config, time.Now)
+		repo.ccGateway.BeforeRequest = check
+		repo.uaaGateway.BeforeRequest = check
+	}
+}
+
+func tokenExpiryCheck(config coreconfig.Reader, now func() time.Time) func() error {
+	return func() error {
+		expiry := coreconfig.NewTokenInfo(config.AccessToken()).Expiry
+		if expiry == 0 {
+			return nil
+		}
+		expiredAt := time.Unix(expiry, 0)
+		if !now().Before(expiredAt) {
+			return errors.NewTokenExpiredError(expiredAt)
+		}
+		return nil
+	}
+}
+
+// WithCreationTimestamps requests each user's UAA metadata so listed users
+// carry the time their UAA account was created in UAACreatedAt. CreatedAt
+// stays the Cloud Controller's timestamp.
+func WithCreationTimestamps() UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.createdAt = true
+	}
+}
+
+// WithPostCreateHook calls hook with each user the repository creates, once
+// both UAA and the Cloud Controller have it.
+func WithPostCreateHook(hook func(models.UserFields) error) UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.postCreate = hook
+	}
+}
+
+// PostCreateHookError is returned when the user was created but the post
+// create hook failed. The user is not removed.
+type PostCreateHookError struct {
+	Err error
+}
+
+func (err *PostCreateHookError) Error() string {
+	return T("User was created, but the post create hook failed: {{.Err}}",
+		map[string]interface{}{"Err": err.Err.Error()})
+}
+
+// UAARollbackError is returned when the Cloud Controller record of a new
+// user could not be created and the UAA user made for it could not be
+// removed either, leaving that UAA user orphaned.
+type UAARollbackError struct {
+	Username    string
+	GUID        string
+	Err         error
+	RollbackErr error
+}
+
+func (err *UAARollbackError) Error() string {
+	return T("Creating user {{.Username}} in the Cloud Controller failed: {{.Err}}. Removing the UAA user {{.GUID}} also failed, so it was left behind: {{.RollbackErr}}",
+		map[string]interface{}{
+			"Username":    err.Username,
+			"GUID":        err.GUID,
+			"Err":         err.Err.Error(),
+			"RollbackErr": err.RollbackErr.Error(),
+		})
+}
+
+// UAAUserReusedWarning is returned by Create when UAA already had the user
+// but the Cloud Controller did not, and only the Cloud Controller record was
+// created for the existing UAA user.
+type UAAUserReusedWarning struct {
+	Username string
+}
+
+func (warning *UAAUserReusedWarning) Error() string {
+	return T("User {{.Username}} already existed in UAA and was reused; only its Cloud Controller record was created",
+		map[string]interface{}{"Username": warning.Username})
+}
+
+// WithUserTransform applies transform to every user the listing methods
+// return, before they are sorted.
+func WithUserTransform(transform func(models.UserFields) models.UserFields) UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.transform = transform
+	}
+}
+
+// PartialUAALookupWarning is returned alongside the listed users when some,
+// but not all, of the batched UAA lookups failed. The users from the failed
+// batches are returned as the Cloud Controller knows them, usually without
+// a username.
+type PartialUAALookupWarning struct {
+	Count int
+	Err   error
+}
+
+func (warning *PartialUAALookupWarning) Error() string {
+	return T("{{.Count}} user(s) could not be looked up in UAA: {{.Err}}",
+		map[string]interface{}{"Count": warning.Count, "Err": warning.Err.Error()})
 }
 
-func NewCloudControllerUserRepository(config coreconfig.Reader, uaaGateway net.Gateway, ccGateway net.Gateway) (repo CloudControllerUserRepository) {
+// RoleListingError is returned when the holders of one or more roles could
+// not be listed. Roles holds the failed roles in the order they were asked
+// for, and Errs the error for each.
+type RoleListingError struct {
+	Roles []models.Role
+	Errs  map[models.Role]error
+}
+
+func (err *RoleListingError) Error() string {
+	message := T("Failed fetching users for roles:")
+	for _, role := range err.Roles {
+		message += fmt.Sprintf("\n  %s: %s", role.ToString(), err.Errs[role].Error())
+	}
+	return message
+}
+
+// InconsistentResultsWarning is returned alongside the listed users when
+// the Cloud Controller's count of them differs markedly from the number
+// collected, which happens when users are added or removed while the pages
+// are read. Some users may have been skipped or listed twice.
+type InconsistentResultsWarning struct {
+	Expected  int
+	Collected int
+}
+
+func (warning *InconsistentResultsWarning) Error() string {
+	return T("Results may be inconsistent: expected {{.Expected}} user(s) but collected {{.Collected}}",
+		map[string]interface{}{"Expected": warning.Expected, "Collected": warning.Collected})
+}
+
+// resultsInconsistent reports whether collected is off from expected by at
+// least one percent. An expected count of zero means none was reported.
+func resultsInconsistent(expected, collected int) bool {
+	if expected == 0 {
+		return false
+	}
+	diff := expected - collected
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff > 0 && diff*100 >= expected
+}
+
+// UnresolvedUsersWarning is returned alongside the listed users when some
+// Cloud Controller users could not be found in UAA.
+type UnresolvedUsersWarning struct {
+	Count int
+}
+
+func (warning *UnresolvedUsersWarning) Error() string {
+	return T("{{.Count}} user association(s) could not be resolved in UAA",
+		map[string]interface{}{"Count": warning.Count})
+}
+
+// isWarning reports whether err is one of the warnings returned alongside
+// usable results, or after the operation took effect, rather than a failure.
+func isWarning(err error) bool {
+	switch err.(type) {
+	case *InconsistentResultsWarning, *PartialUAALookupWarning, *UnresolvedUsersWarning, *UAAUserReusedWarning:
+		return true
+	}
+	return false
+}
+
+// WithUnresolvedUsersWarning makes ListUsersInOrgForRole return an
+// UnresolvedUsersWarning, along with the users it did resolve, when some
+// Cloud Controller users are missing from UAA. By default they are dropped
+// silently.
+func WithUnresolvedUsersWarning() UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.warnOrphans = true
+	}
+}
+
+// ServiceAccountMatcher reports whether a user is a service account rather
+// than a person.
+type ServiceAccountMatcher func(user models.UserFields) bool
+
+// NewServiceAccountMatcher matches users from any of the given UAA origins
+// or whose username matches namePattern. Either may be empty.
+func NewServiceAccountMatcher(origins []string, namePattern *regexp.Regexp) ServiceAccountMatcher {
+	return func(user models.UserFields) bool {
+		for _, origin := range origins {
+			if user.Origin == origin {
+				return true
+			}
+		}
+		return namePattern != nil && namePattern.MatchString(user.Username)
+	}
+}
+
+// WithoutServiceAccounts drops service accounts, and users without an email
+// address, from the users listed with their UAA details.
+func WithoutServiceAccounts(matcher ServiceAccountMatcher) UserRepositoryOption {
+	return func(repo *CloudControllerUserRepository) {
+		repo.excludeUser = func(user models.UserFields) bool {
+			return user.Email == "" || (matcher != nil && matcher(user))
+		}
+	}
+}
+
+func NewCloudControllerUserRepository(config coreconfig.Reader, uaaGateway net.Gateway, ccGateway net.Gateway, opts ...UserRepositoryOption) (repo CloudControllerUserRepository) {
 	repo.config = config
 	repo.uaaGateway = uaaGateway
 	repo.ccGateway = ccGateway
+	repo.adminResolver = CCFlagAdminResolver{}
+	repo.metrics = noopMetricsCollector{}
+	repo.maxURLLength = DefaultMaxUAAFilterURLLength
+	repo.batchSize = DefaultUAAFilterBatchSize
+	repo.spaceRoleURL = FlatSpaceRolePath
+	for _, opt := range opts {
+		opt(&repo)
+	}
 	return
 }
 
 func (repo CloudControllerUserRepository) FindByUsername(username string) (user models.UserFields, apiErr error) {
-	users, apiErr := repo.FindAllByUsername(username)
+	defer repo.observe("FindByUsername", &apiErr)
+	return repo.findByUsername(context.Background(), username)
+}
+
+func (repo CloudControllerUserRepository) FindByUsernameContext(ctx context.Context, username string) (user models.UserFields, apiErr error) {
+	defer repo.observe("FindByUsernameContext", &apiErr)
+	return repo.findByUsername(ctx, username)
+}
+
+func (repo CloudControllerUserRepository) findByUsername(ctx context.Context, username string) (user models.UserFields, apiErr error) {
+	users, apiErr := repo.findAllByUsernameContext(ctx, username)
 	if apiErr != nil {
 		return user, apiErr
 	}
@@ -79,14 +653,200 @@ func (repo CloudControllerUserRepository) FindByUsername(username string) (user
 	return user, nil
 }
 
+// FindByEmail finds the UAA user whose email is email, for users whose
+// username differs from their email. When several users share the email,
+// the first UAA returns is used, as with FindByUsername.
+func (repo CloudControllerUserRepository) FindByEmail(email string) (user models.UserFields, apiErr error) {
+	defer repo.observe("FindByEmail", &apiErr)
+
+	uaaEndpoint, apiErr := repo.getAuthEndpoint()
+	if apiErr != nil {
+		return user, apiErr
+	}
+
+	emailFilter := neturl.QueryEscape(fmt.Sprintf(`emails.value eq %s`, scimString(email)))
+	path := fmt.Sprintf("%s/Users?attributes=%s&filter=%s", uaaEndpoint, repo.uaaUserAttributes(), emailFilter)
+	users, apiErr := repo.updateOrFindUsersWithUAAPath([]models.UserFields{}, path)
+	if httpErr, ok := apiErr.(errors.HTTPError); ok && httpErr.StatusCode() == 403 {
+		return user, errors.NewAccessDeniedError()
+	}
+	if apiErr != nil {
+		return user, apiErr
+	}
+	if len(users) == 0 {
+		return user, errors.NewNotFoundError(errors.UserResource, email)
+	}
+	return users[0], nil
+}
+
 func (repo CloudControllerUserRepository) FindAllByUsername(username string) (users []models.UserFields, apiErr error) {
+	defer repo.observe("FindAllByUsername", &apiErr)
+	return repo.findAllByUsernameContext(context.Background(), username)
+}
+
+// FindAllByUsernameContext looks up users by username. When the repository
+// caches lookups, a context from WithNoCache forces a fresh UAA request.
+func (repo CloudControllerUserRepository) FindAllByUsernameContext(ctx context.Context, username string) (users []models.UserFields, apiErr error) {
+	defer repo.observe("FindAllByUsernameContext", &apiErr)
+	return repo.findAllByUsernameContext(ctx, username)
+}
+
+// FindAllByUsernamePrefix returns the users whose usernames start with
+// prefix, for suggesting a user when no username matches exactly. It fails
+// with a ModelNotFoundError when there are none.
+func (repo CloudControllerUserRepository) FindAllByUsernamePrefix(prefix string) (users []models.UserFields, err error) {
+	defer repo.observe("FindAllByUsernamePrefix", &err)
+
+	uaaEndpoint, err := repo.getAuthEndpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	prefixFilter := neturl.QueryEscape(fmt.Sprintf(`userName sw %s`, scimString(prefix)))
+	path := fmt.Sprintf("%s/Users?attributes=%s&filter=%s", uaaEndpoint, repo.uaaUserAttributes(), prefixFilter)
+	users, err = repo.updateOrFindUsersWithUAAPath([]models.UserFields{}, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, errors.NewNotFoundError(errors.UserResource, prefix)
+	}
+	return users, nil
+}
+
+// FindByUsernames looks all of usernames up in UAA with as few requests as
+// the batch limits allow. The result is keyed by the usernames as given, and
+// usernames UAA does not know are left out rather than failing the lookup.
+// When a username matches more than one UAA user, the first UAA returns is
+// kept, as with FindByUsername.
+func (repo CloudControllerUserRepository) FindByUsernames(usernames []string) (users map[string]models.UserFields, apiErr error) {
+	defer repo.observe("FindByUsernames", &apiErr)
+
+	users = map[string]models.UserFields{}
+	if len(usernames) == 0 {
+		return users, nil
+	}
+
+	uaaEndpoint, apiErr := repo.getAuthEndpoint()
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	requested := map[string][]string{}
+	filters := []string{}
+	for _, username := range usernames {
+		key := strings.ToLower(username)
+		requested[key] = append(requested[key], username)
+		filters = append(filters, fmt.Sprintf(`userName eq %s`, scimString(username)))
+	}
+
+	found, apiErr := repo.updateOrFindUsersWithUAAFilters([]models.UserFields{}, uaaEndpoint, filters)
+	if warning, ok := apiErr.(*PartialUAALookupWarning); ok {
+		apiErr = warning.Err
+	}
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	for _, user := range found {
+		for _, username := range requested[strings.ToLower(user.Username)] {
+			if _, seen := users[username]; !seen {
+				users[username] = user
+			}
+		}
+	}
+	return users, nil
+}
+
+func (repo CloudControllerUserRepository) findAllByUsernameContext(ctx context.Context, username string) (users []models.UserFields, apiErr error) {
+	if repo.lookupCache != nil && !noCache(ctx) {
+		repo.lookupCache.mutex.Lock()
+		cached, found := repo.lookupCache.users[username]
+		repo.lookupCache.mutex.Unlock()
+		if found {
+			return cached, nil
+		}
+	}
+
+	users, apiErr = repo.findAllByUsername(username)
+	if apiErr == nil && repo.lookupCache != nil {
+		repo.lookupCache.mutex.Lock()
+		repo.lookupCache.users[username] = users
+		repo.lookupCache.mutex.Unlock()
+	}
+	return users, apiErr
+}
+
+// IsUsernameAvailable reports whether no UAA user, from any origin, has the
+// given username. It always asks UAA, bypassing the lookup cache, since it
+// gates user creation.
+func (repo CloudControllerUserRepository) IsUsernameAvailable(username string) (available bool, err error) {
+	defer repo.observe("IsUsernameAvailable", &err)
+
+	users, err := repo.findAllByUsername(username)
+	if _, notFound := err.(*errors.ModelNotFoundError); notFound {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return len(users) == 0, nil
+}
+
+// VerifyCredentials reports whether UAA accepts a password grant for the
+// user. The issued token is discarded. Rejected credentials are not an
+// error; any other failure is.
+func (repo CloudControllerUserRepository) VerifyCredentials(username, password string) (valid bool, err error) {
+	defer repo.observe("VerifyCredentials", &err)
+
+	uaaEndpoint, err := repo.getAuthEndpoint()
+	if err != nil {
+		return false, err
+	}
+
+	data := neturl.Values{
+		"grant_type": {"password"},
+		"username":   {username},
+		"password":   {password},
+		"scope":      {""},
+	}
+	clientAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte(repo.config.UAAOAuthClient()+":"+repo.config.UAAOAuthClientSecret()))
+
+	// The password grant authenticates with the client credentials, not the
+	// access token, so an expired session must not stop it.
+	gateway := repo.uaaGateway
+	gateway.BeforeRequest = nil
+
+	request, err := gateway.NewRequest("POST", uaaEndpoint+"/oauth/token", clientAuth, strings.NewReader(data.Encode()))
+	if err != nil {
+		return false, err
+	}
+	request.HTTPReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := gateway.PerformRequest(request)
+	repo.countUAACall()
+	if response != nil && response.Body != nil {
+		_ = response.Body.Close()
+	}
+	if httpErr, ok := err.(errors.HTTPError); ok {
+		if httpErr.StatusCode() == http.StatusUnauthorized || httpErr.ErrorCode() == "invalid_grant" {
+			return false, nil
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (repo CloudControllerUserRepository) findAllByUsername(username string) (users []models.UserFields, apiErr error) {
 	uaaEndpoint, apiErr := repo.getAuthEndpoint()
 	if apiErr != nil {
 		return users, apiErr
 	}
 
-	usernameFilter := neturl.QueryEscape(fmt.Sprintf(`userName Eq "%s"`, username))
-	path := fmt.Sprintf("%s/Users?attributes=id,userName&filter=%s", uaaEndpoint, usernameFilter)
+	usernameFilter := neturl.QueryEscape(fmt.Sprintf(`userName Eq %s`, scimString(username)))
+	path := fmt.Sprintf("%s/Users?attributes=%s&filter=%s", uaaEndpoint, repo.uaaUserAttributes(), usernameFilter)
 	users, apiErr = repo.updateOrFindUsersWithUAAPath([]models.UserFields{}, path)
 
 	if apiErr != nil {
@@ -98,198 +858,2087 @@ func (repo CloudControllerUserRepository) FindAllByUsername(username string) (us
 		}
 		return users, apiErr
 	} else if len(users) == 0 {
-		return users, errors.NewModelNotFoundError("User", username)
+		return users, errors.NewNotFoundError(errors.UserResource, username)
 	}
 
 	return users, apiErr
 }
 
 func (repo CloudControllerUserRepository) ListUsersInOrgForRole(orgGUID string, roleName models.Role) (users []models.UserFields, apiErr error) {
-	return repo.listUsersWithPath(fmt.Sprintf("/v2/organizations/%s/%s", orgGUID, orgRoleToPathMap[roleName]))
+	defer repo.observe("ListUsersInOrgForRole", &apiErr)
+	return repo.listUsersInOrgForRole(context.Background(), orgGUID, roleName)
 }
 
-func (repo CloudControllerUserRepository) ListUsersInOrgForRoleWithNoUAA(orgGUID string, roleName models.Role) (users []models.UserFields, apiErr error) {
-	return repo.listUsersWithPathWithNoUAA(fmt.Sprintf("/v2/organizations/%s/%s", orgGUID, orgRoleToPathMap[roleName]))
+// CountUsersInOrgForRole returns how many users hold the org role. It reads
+// the total from a single one-result page and does no UAA lookup.
+func (repo CloudControllerUserRepository) CountUsersInOrgForRole(orgGUID string, role models.Role) (_ int, err error) {
+	defer repo.observe("CountUsersInOrgForRole", &err)
+
+	rolePath, found := orgRoleToPathMap[role]
+	if !found {
+		return 0, errors.NewInvalidInputError(T("Invalid Role {{.Role}}",
+			map[string]interface{}{"Role": role}))
+	}
+
+	path := fmt.Sprintf("%s/v2/organizations/%s/%s?results-per-page=1", repo.config.APIEndpoint(), orgGUID, rolePath)
+	response := new(resources.PaginatedResourceCount)
+	err = repo.ccGateway.GetResource(path, response)
+	if err != nil {
+		return 0, err
+	}
+	return response.TotalResults, nil
 }
 
-func (repo CloudControllerUserRepository) ListUsersInSpaceForRoleWithNoUAA(spaceGUID string, roleName models.Role) (users []models.UserFields, apiErr error) {
-	return repo.listUsersWithPathWithNoUAA(fmt.Sprintf("/v2/spaces/%s/%s", spaceGUID, spaceRoleToPathMap[roleName]))
+// ListUsersInOrgForRoleContext stops listing when ctx is cancelled and
+// returns the users listed so far with the context's error.
+func (repo CloudControllerUserRepository) ListUsersInOrgForRoleContext(ctx context.Context, orgGUID string, roleName models.Role) (users []models.UserFields, apiErr error) {
+	defer repo.observe("ListUsersInOrgForRoleContext", &apiErr)
+	return repo.listUsersInOrgForRole(ctx, orgGUID, roleName)
 }
 
-func (repo CloudControllerUserRepository) listUsersWithPathWithNoUAA(path string) (users []models.UserFields, apiErr error) {
-	apiErr = repo.ccGateway.ListPaginatedResources(
-		repo.config.APIEndpoint(),
-		path,
-		resources.UserResource{},
-		func(resource interface{}) bool {
-			user := resource.(resources.UserResource).ToFields()
-			users = append(users, user)
-			return true
+// ListUsersInOrgForRoleFiltered lists the holders of the role whose
+// username contains usernameContains, ignoring case. UAA does the matching,
+// so only the matching users are fetched from it.
+func (repo CloudControllerUserRepository) ListUsersInOrgForRoleFiltered(orgGUID string, roleName models.Role, usernameContains string) (users []models.UserFields, apiErr error) {
+	defer repo.observe("ListUsersInOrgForRoleFiltered", &apiErr)
+	if usernameContains == "" {
+		return repo.listUsersInOrgForRole(context.Background(), orgGUID, roleName)
+	}
+
+	defer repo.sortUsers(&users)
+	defer repo.transformUsers(&users)
+	if repo.useV3Roles("organization_guids") && repo.excludeUser == nil {
+		all, err := repo.listUsersWithV3Roles(context.Background(), "organization_guids", orgGUID, roleName)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range all {
+			if strings.Contains(strings.ToLower(user.Username), strings.ToLower(usernameContains)) {
+				users = append(users, user)
+			}
+		}
+		return users, nil
+	}
+
+	users, _, apiErr = repo.listUsersWithPathMatching(
+		context.Background(),
+		fmt.Sprintf("/v2/organizations/%s/%s", orgGUID, orgRoleToPathMap[roleName]),
+		fmt.Sprintf(`userName co %s`, scimString(usernameContains)),
+	)
+	if _, inconsistent := apiErr.(*InconsistentResultsWarning); inconsistent {
+		apiErr = nil
+	}
+	return users, apiErr
+}
+
+func (repo CloudControllerUserRepository) listUsersInOrgForRole(ctx context.Context, orgGUID string, roleName models.Role) (users []models.UserFields, apiErr error) {
+	defer repo.sortUsers(&users)
+	defer repo.transformUsers(&users)
+	if repo.useV3Roles("organization_guids") && repo.excludeUser == nil {
+		return repo.listUsersWithV3Roles(ctx, "organization_guids", orgGUID, roleName)
+	}
+	users, unresolved, apiErr := repo.listUsersWithPathCountingUnresolved(ctx, fmt.Sprintf("/v2/organizations/%s/%s", orgGUID, orgRoleToPathMap[roleName]))
+	if apiErr == nil && unresolved > 0 && repo.warnOrphans {
+		apiErr = &UnresolvedUsersWarning{Count: unresolved}
+	}
+	return users, apiErr
+}
+
+// ListAllUsersInOrg returns the holders of every org role, keyed by role.
+// The roles are listed from the Cloud Controller concurrently, and the
+// usernames for all of them are then looked up in UAA together.
+func (repo CloudControllerUserRepository) ListAllUsersInOrg(orgGUID string) (_ map[models.Role][]models.UserFields, err error) {
+	defer repo.observe("ListAllUsersInOrg", &err)
+	return repo.listUsersInOrgForRoles(orgGUID, orgRoles)
+}
+
+// ListUsersInOrgForRoles is ListAllUsersInOrg for just the given roles.
+func (repo CloudControllerUserRepository) ListUsersInOrgForRoles(orgGUID string, roles []models.Role) (_ map[models.Role][]models.UserFields, err error) {
+	defer repo.observe("ListUsersInOrgForRoles", &err)
+	return repo.listUsersInOrgForRoles(orgGUID, roles)
+}
+
+func (repo CloudControllerUserRepository) listUsersInOrgForRoles(orgGUID string, roles []models.Role) (map[models.Role][]models.UserFields, error) {
+	usersByRole, err := repo.listAllUsersForRoles(roles, "organization_guids", orgGUID, func(role models.Role) string {
+		return fmt.Sprintf("/v2/organizations/%s/%s", orgGUID, orgRoleToPathMap[role])
+	})
+	for role := range usersByRole {
+		users := usersByRole[role]
+		repo.transformUsers(&users)
+		repo.sortUsers(&users)
+	}
+	return usersByRole, err
+}
+
+// ListUsersInSpaceForAllRoles returns the holders of every space role, keyed
+// by role, as ListAllUsersInOrg does for orgs. Each role's users are kept in
+// the order the Cloud Controller listed them.
+func (repo CloudControllerUserRepository) ListUsersInSpaceForAllRoles(spaceGUID string) (_ map[models.Role][]models.UserFields, err error) {
+	defer repo.observe("ListUsersInSpaceForAllRoles", &err)
+
+	usersByRole, err := repo.listAllUsersForRoles(spaceRoles, "space_guids", spaceGUID, func(role models.Role) string {
+		return repo.spaceRoleURL(spaceGUID, spaceRoleToPathMap[role])
+	})
+	for role := range usersByRole {
+		users := usersByRole[role]
+		repo.transformUsers(&users)
+	}
+	return usersByRole, err
+}
+
+// listAllUsersForRoles lists the holders of each role from the Cloud
+// Controller at rolePath(role), or from /v3/roles filtered by v3Filter when
+// supported, and looks all of their usernames up in UAA at once.
+func (repo CloudControllerUserRepository) listAllUsersForRoles(
+	roles []models.Role,
+	v3Filter string,
+	guid string,
+	rolePath func(models.Role) string,
+) (map[models.Role][]models.UserFields, error) {
+	if repo.useV3Roles(v3Filter) && repo.excludeUser == nil {
+		return listRolesConcurrently(roles, func(role models.Role) ([]models.UserFields, error) {
+			return repo.listUsersWithV3Roles(context.Background(), v3Filter, guid, role)
+		})
+	}
+
+	usersByRole, err := listRolesConcurrently(roles, func(role models.Role) ([]models.UserFields, error) {
+		return repo.listUsersWithPathWithNoUAA(context.Background(), rolePath(role))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ccUsers []models.UserFields
+	var guidFilters []string
+	listed := map[string]bool{}
+	for _, role := range roles {
+		for _, user := range usersByRole[role] {
+			if !listed[user.GUID] {
+				listed[user.GUID] = true
+				ccUsers = append(ccUsers, user)
+				guidFilters = append(guidFilters, fmt.Sprintf(`ID eq %s`, scimString(user.GUID)))
+			}
+		}
+	}
+	if len(ccUsers) == 0 {
+		return usersByRole, nil
+	}
+
+	uaaEndpoint, err := repo.getAuthEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := repo.updateOrFindUsersWithUAAFilters(ccUsers, uaaEndpoint, guidFilters)
+	if _, partial := err.(*PartialUAALookupWarning); err != nil && !partial {
+		return nil, err
+	}
+	byGUID := map[string]models.UserFields{}
+	for _, user := range resolved {
+		if repo.excludeUser == nil || !repo.excludeUser(user) {
+			byGUID[user.GUID] = user
+		}
+	}
+
+	for role, users := range usersByRole {
+		holders := []models.UserFields{}
+		for _, user := range users {
+			if resolvedUser, found := byGUID[user.GUID]; found {
+				holders = append(holders, resolvedUser)
+			}
+		}
+		usersByRole[role] = holders
+	}
+	return usersByRole, err
+}
+
+// maxConcurrentRoleListings is the most roles listed from the Cloud
+// Controller at once.
+const maxConcurrentRoleListings = 4
+
+// listRolesConcurrently calls list for each role on a bounded pool of
+// goroutines and collects the users keyed by role. Every role is listed even
+// when some fail; the failures are returned together as a RoleListingError.
+func listRolesConcurrently(roles []models.Role, list func(models.Role) ([]models.UserFields, error)) (map[models.Role][]models.UserFields, error) {
+	workers := maxConcurrentRoleListings
+	if len(roles) < workers {
+		workers = len(roles)
+	}
+
+	queue := make(chan models.Role, len(roles))
+	for _, role := range roles {
+		queue <- role
+	}
+	close(queue)
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	usersByRole := map[models.Role][]models.UserFields{}
+	failures := map[models.Role]error{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for role := range queue {
+				users, err := list(role)
+				mutex.Lock()
+				if err != nil {
+					failures[role] = err
+				} else {
+					usersByRole[role] = users
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		failed := &RoleListingError{Errs: failures}
+		for _, role := range roles {
+			if _, found := failures[role]; found {
+				failed.Roles = append(failed.Roles, role)
+			}
+		}
+		return nil, failed
+	}
+	return usersByRole, nil
+}
+
+// ListInactiveOrgUsers returns the org's users who have not logged in to UAA
+// within inactiveFor, including those who have never logged in.
+func (repo CloudControllerUserRepository) ListInactiveOrgUsers(orgGUID string, inactiveFor time.Duration) (users []models.UserFields, err error) {
+	defer repo.observe("ListInactiveOrgUsers", &err)
+	defer repo.sortUsers(&users)
+	defer repo.transformUsers(&users)
+
+	repo.lastLogon = true
+	orgUsers, err := repo.listUsersWithPath(context.Background(), fmt.Sprintf("/v2/organizations/%s/%s", orgGUID, orgRoleToPathMap[models.RoleOrgUser]))
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-inactiveFor)
+	users = []models.UserFields{}
+	for _, user := range orgUsers {
+		if user.LastLogon.IsZero() || user.LastLogon.Before(cutoff) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (repo CloudControllerUserRepository) ListUsersInOrgForRoleWithNoUAA(orgGUID string, roleName models.Role) (users []models.UserFields, apiErr error) {
+	defer repo.observe("ListUsersInOrgForRoleWithNoUAA", &apiErr)
+	defer repo.sortUsers(&users)
+	defer repo.transformUsers(&users)
+	if repo.useV3Roles("organization_guids") {
+		return repo.listUsersWithV3Roles(context.Background(), "organization_guids", orgGUID, roleName)
+	}
+	return repo.listUsersWithPathWithNoUAA(context.Background(), fmt.Sprintf("/v2/organizations/%s/%s", orgGUID, orgRoleToPathMap[roleName]))
+}
+
+func (repo CloudControllerUserRepository) ListUsersInSpaceForRoleWithNoUAA(spaceGUID string, roleName models.Role) (users []models.UserFields, apiErr error) {
+	defer repo.observe("ListUsersInSpaceForRoleWithNoUAA", &apiErr)
+	defer repo.sortUsers(&users)
+	defer repo.transformUsers(&users)
+	return repo.listSpaceUsersForRole(spaceGUID, roleName)
+}
+
+func (repo CloudControllerUserRepository) listSpaceUsersForRole(spaceGUID string, roleName models.Role) ([]models.UserFields, error) {
+	if repo.useV3Roles("space_guids") {
+		return repo.listUsersWithV3Roles(context.Background(), "space_guids", spaceGUID, roleName)
+	}
+	return repo.listUsersWithPathWithNoUAA(context.Background(), repo.spaceRoleURL(spaceGUID, spaceRoleToPathMap[roleName]))
+}
+
+// DiffSpaceUsers compares the holders of each space role in two spaces. Only
+// roles whose holders differ are returned.
+func (repo CloudControllerUserRepository) DiffSpaceUsers(spaceAGUID, spaceBGUID string) (_ []models.SpaceRoleDiff, err error) {
+	defer repo.observe("DiffSpaceUsers", &err)
+
+	var diffs []models.SpaceRoleDiff
+	for _, role := range spaceRoles {
+		usersA, err := repo.listSpaceUsersForRole(spaceAGUID, role)
+		if err != nil {
+			return nil, err
+		}
+		usersB, err := repo.listSpaceUsersForRole(spaceBGUID, role)
+		if err != nil {
+			return nil, err
+		}
+
+		diff := models.SpaceRoleDiff{
+			Role:    role,
+			OnlyInA: usersMissingFrom(usersA, usersB),
+			OnlyInB: usersMissingFrom(usersB, usersA),
+		}
+		if len(diff.OnlyInA) > 0 || len(diff.OnlyInB) > 0 {
+			repo.sortUsers(&diff.OnlyInA)
+			repo.sortUsers(&diff.OnlyInB)
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs, nil
+}
+
+func usersMissingFrom(users, others []models.UserFields) []models.UserFields {
+	otherGUIDs := map[string]bool{}
+	for _, user := range others {
+		otherGUIDs[user.GUID] = true
+	}
+
+	var missing []models.UserFields
+	for _, user := range users {
+		if !otherGUIDs[user.GUID] {
+			missing = append(missing, user)
+		}
+	}
+	return missing
+}
+
+// ListOrgUsersWithSpaceRoles returns every member of the org along with the
+// roles they hold in each of its spaces. Role membership is fetched once per
+// space and role rather than once per user.
+func (repo CloudControllerUserRepository) ListOrgUsersWithSpaceRoles(orgGUID string) (_ []models.OrgUserWithSpaceRoles, err error) {
+	defer repo.observe("ListOrgUsersWithSpaceRoles", &err)
+
+	orgUsers, err := repo.listUsersWithPath(context.Background(), fmt.Sprintf("/v2/organizations/%s/%s", orgGUID, orgRoleToPathMap[models.RoleOrgUser]))
+	if err != nil {
+		return nil, err
+	}
+
+	var spaces []models.SpaceFields
+	err = repo.ccGateway.ListPaginatedResources(
+		repo.config.APIEndpoint(),
+		fmt.Sprintf("/v2/organizations/%s/spaces", orgGUID),
+		resources.SpaceResource{},
+		func(resource interface{}) bool {
+			spaces = append(spaces, resource.(resources.SpaceResource).ToFields())
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	rolesByUser := map[string][]models.UserSpaceRoles{}
+	for _, space := range spaces {
+		spaceRolesByUser := map[string][]models.Role{}
+		var userOrder []string
+		for _, role := range spaceRoles {
+			users, err := repo.listUsersWithPathWithNoUAA(context.Background(), repo.spaceRoleURL(space.GUID, spaceRoleToPathMap[role]))
+			if err != nil {
+				return nil, err
+			}
+			for _, user := range users {
+				if _, seen := spaceRolesByUser[user.GUID]; !seen {
+					userOrder = append(userOrder, user.GUID)
+				}
+				spaceRolesByUser[user.GUID] = append(spaceRolesByUser[user.GUID], role)
+			}
+		}
+
+		for _, userGUID := range userOrder {
+			rolesByUser[userGUID] = append(rolesByUser[userGUID], models.UserSpaceRoles{
+				Space: space,
+				Roles: spaceRolesByUser[userGUID],
+			})
+		}
+	}
+
+	result := make([]models.OrgUserWithSpaceRoles, 0, len(orgUsers))
+	for _, user := range orgUsers {
+		result = append(result, models.OrgUserWithSpaceRoles{
+			UserFields: user,
+			Spaces:     rolesByUser[user.GUID],
+		})
+	}
+	return result, nil
+}
+
+// roleAssociation is the /v2/users/:guid association listing the orgs or
+// spaces in which a user holds role.
+type roleAssociation struct {
+	path string
+	role models.Role
+}
+
+var orgAssociationRoles = []roleAssociation{
+	{"organizations", models.RoleOrgUser},
+	{"managed_organizations", models.RoleOrgManager},
+	{"billing_managed_organizations", models.RoleBillingManager},
+	{"audited_organizations", models.RoleOrgAuditor},
+}
+
+var spaceAssociationRoles = []roleAssociation{
+	{"managed_spaces", models.RoleSpaceManager},
+	{"spaces", models.RoleSpaceDeveloper},
+	{"audited_spaces", models.RoleSpaceAuditor},
+}
+
+// GetUserAccessSummary lists every org and space role the user holds. With
+// resolveNames, the orgs of space roles are looked up by name, once per org;
+// otherwise, and for orgs that cannot be looked up, the GUID stands in for
+// the name.
+func (repo CloudControllerUserRepository) GetUserAccessSummary(userGUID string, resolveNames bool) (summary models.UserAccessSummary, err error) {
+	defer repo.observe("GetUserAccessSummary", &err)
+
+	summary.UserGUID = userGUID
+	orgNames := map[string]string{}
+	for _, association := range orgAssociationRoles {
+		err = repo.ccGateway.ListPaginatedResources(
+			repo.config.APIEndpoint(),
+			fmt.Sprintf("/v2/users/%s/%s", userGUID, association.path),
+			resources.OrganizationResource{},
+			func(resource interface{}) bool {
+				org := resource.(resources.OrganizationResource).ToFields()
+				orgNames[org.GUID] = org.Name
+				summary.Grants = append(summary.Grants, models.AccessGrant{
+					Role:    association.role,
+					OrgGUID: org.GUID,
+					OrgName: org.Name,
+				})
+				return true
+			})
+		if err != nil {
+			return models.UserAccessSummary{}, err
+		}
+	}
+
+	for _, association := range spaceAssociationRoles {
+		err = repo.ccGateway.ListPaginatedResources(
+			repo.config.APIEndpoint(),
+			fmt.Sprintf("/v2/users/%s/%s", userGUID, association.path),
+			resources.SpaceResource{},
+			func(resource interface{}) bool {
+				space := resource.(resources.SpaceResource).ToModel()
+				summary.Grants = append(summary.Grants, models.AccessGrant{
+					Role:      association.role,
+					OrgGUID:   space.Organization.GUID,
+					OrgName:   space.Organization.Name,
+					SpaceGUID: space.GUID,
+					SpaceName: space.Name,
+				})
+				return true
+			})
+		if err != nil {
+			return models.UserAccessSummary{}, err
+		}
+	}
+
+	for i, grant := range summary.Grants {
+		if grant.OrgName == "" {
+			summary.Grants[i].OrgName = repo.orgName(grant.OrgGUID, orgNames, resolveNames)
+		}
+		if grant.SpaceName == "" {
+			summary.Grants[i].SpaceName = grant.SpaceGUID
+		}
+	}
+	return summary, nil
+}
+
+// GetUserMemberships lists the orgs the user belongs to, each with the
+// user's roles in it and in its spaces, ordered by name. A non-empty orgGUID
+// limits the listing to that org; space associations are then filtered by
+// the Cloud Controller rather than read in full.
+func (repo CloudControllerUserRepository) GetUserMemberships(userGUID, orgGUID string) (memberships models.UserMemberships, err error) {
+	defer repo.observe("GetUserMemberships", &err)
+
+	memberships.UserGUID = userGUID
+	orgs := map[string]*models.OrgMembership{}
+	orgMembership := func(guid, name string) *models.OrgMembership {
+		org, found := orgs[guid]
+		if !found {
+			org = &models.OrgMembership{Org: models.OrganizationFields{GUID: guid, Name: name}}
+			orgs[guid] = org
+		}
+		if org.Org.Name == "" {
+			org.Org.Name = name
+		}
+		return org
+	}
+
+	for _, association := range orgAssociationRoles {
+		err = repo.ccGateway.ListPaginatedResources(
+			repo.config.APIEndpoint(),
+			fmt.Sprintf("/v2/users/%s/%s", userGUID, association.path),
+			resources.OrganizationResource{},
+			func(resource interface{}) bool {
+				fields := resource.(resources.OrganizationResource).ToFields()
+				if orgGUID == "" || fields.GUID == orgGUID {
+					org := orgMembership(fields.GUID, fields.Name)
+					org.Roles = append(org.Roles, association.role)
+				}
+				return true
+			})
+		if err != nil {
+			return models.UserMemberships{}, err
+		}
+	}
+
+	query := ""
+	if orgGUID != "" {
+		query = "?q=organization_guid:" + neturl.QueryEscape(orgGUID)
+	}
+	spaces := map[string]map[string]*models.SpaceMembership{}
+	for _, association := range spaceAssociationRoles {
+		err = repo.ccGateway.ListPaginatedResources(
+			repo.config.APIEndpoint(),
+			fmt.Sprintf("/v2/users/%s/%s%s", userGUID, association.path, query),
+			resources.SpaceResource{},
+			func(resource interface{}) bool {
+				space := resource.(resources.SpaceResource).ToModel()
+				org := orgMembership(space.Organization.GUID, space.Organization.Name)
+				if spaces[org.Org.GUID] == nil {
+					spaces[org.Org.GUID] = map[string]*models.SpaceMembership{}
+				}
+				membership, found := spaces[org.Org.GUID][space.GUID]
+				if !found {
+					membership = &models.SpaceMembership{Space: space.SpaceFields}
+					spaces[org.Org.GUID][space.GUID] = membership
+				}
+				membership.Roles = append(membership.Roles, association.role)
+				return true
+			})
+		if err != nil {
+			return models.UserMemberships{}, err
+		}
+	}
+
+	orgNames := map[string]string{}
+	for guid, org := range orgs {
+		if org.Org.Name == "" {
+			org.Org.Name = repo.orgName(guid, orgNames, true)
+		}
+		for _, space := range spaces[guid] {
+			org.Spaces = append(org.Spaces, *space)
+		}
+		sort.Slice(org.Spaces, func(i, j int) bool {
+			return org.Spaces[i].Space.Name < org.Spaces[j].Space.Name
+		})
+		memberships.Orgs = append(memberships.Orgs, *org)
+	}
+	sort.Slice(memberships.Orgs, func(i, j int) bool {
+		return memberships.Orgs[i].Org.Name < memberships.Orgs[j].Org.Name
+	})
+	return memberships, nil
+}
+
+// GetUserRolesInOrg returns the org roles the user holds in the org, read
+// from the user's own org associations. A user with no roles in the org gets
+// an empty slice.
+func (repo CloudControllerUserRepository) GetUserRolesInOrg(userGUID, orgGUID string) (roles []models.Role, err error) {
+	defer repo.observe("GetUserRolesInOrg", &err)
+	return repo.userRolesIn(userGUID, orgGUID, orgAssociationRoles)
+}
+
+// userRolesIn returns the roles among associations that the user holds in
+// the org or space with guid.
+func (repo CloudControllerUserRepository) userRolesIn(userGUID, guid string, associations []roleAssociation) ([]models.Role, error) {
+	roles := []models.Role{}
+	for _, association := range associations {
+		found := false
+		err := repo.ccGateway.ListPaginatedResources(
+			repo.config.APIEndpoint(),
+			fmt.Sprintf("/v2/users/%s/%s", userGUID, association.path),
+			resources.Resource{},
+			func(resource interface{}) bool {
+				found = resource.(resources.Resource).Metadata.GUID == guid
+				return !found
+			})
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			roles = append(roles, association.role)
+		}
+	}
+	return roles, nil
+}
+
+// CopyRoles gives toUserGUID every org role fromUserGUID holds in the org,
+// leaving fromUserGUID's roles as they are. There is one result per role
+// copied; roles the target already holds are reported unchanged and not
+// sent. A role that cannot be given is reported in its result's Err, and
+// the rest are still copied.
+func (repo CloudControllerUserRepository) CopyRoles(fromUserGUID, toUserGUID, orgGUID string) (_ []models.RoleChangeResult, err error) {
+	defer repo.observe("CopyRoles", &err)
+	repo = repo.forMutation()
+
+	return repo.copyRoles(fromUserGUID, toUserGUID, orgGUID, orgAssociationRoles, func(role models.Role) (RoleJob, error) {
+		return repo.setOrgRoleByGUIDAsync(toUserGUID, orgGUID, role)
+	})
+}
+
+// CopySpaceRoles is CopyRoles for the space roles fromUserGUID holds in the
+// space. The target is made a member of the org first, as needed.
+func (repo CloudControllerUserRepository) CopySpaceRoles(fromUserGUID, toUserGUID, spaceGUID, orgGUID string) (_ []models.RoleChangeResult, err error) {
+	defer repo.observe("CopySpaceRoles", &err)
+	repo = repo.forMutation()
+
+	return repo.copyRoles(fromUserGUID, toUserGUID, spaceGUID, spaceAssociationRoles, func(role models.Role) (RoleJob, error) {
+		return repo.setSpaceRoleByGUIDAsync(toUserGUID, spaceGUID, orgGUID, role, "")
+	})
+}
+
+func (repo CloudControllerUserRepository) copyRoles(
+	fromUserGUID string,
+	toUserGUID string,
+	guid string,
+	associations []roleAssociation,
+	setRole func(models.Role) (RoleJob, error),
+) ([]models.RoleChangeResult, error) {
+	roles, err := repo.userRolesIn(fromUserGUID, guid, associations)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return []models.RoleChangeResult{}, nil
+	}
+
+	held, err := repo.userRolesIn(toUserGUID, guid, associations)
+	if err != nil {
+		return nil, err
+	}
+	alreadyHeld := map[models.Role]bool{}
+	for _, role := range held {
+		alreadyHeld[role] = true
+	}
+
+	results := make([]models.RoleChangeResult, 0, len(roles))
+	for _, role := range roles {
+		result := models.RoleChangeResult{UserGUID: toUserGUID, Role: role}
+		if !alreadyHeld[role] {
+			job, setErr := setRole(role)
+			if setErr == nil {
+				setErr = repo.waitForRoleJob(job)
+			}
+			result.Changed = setErr == nil
+			result.Err = setErr
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// orgName returns the org's name from cache, looking it up the first time
+// it is asked for when resolve is set.
+func (repo CloudControllerUserRepository) orgName(orgGUID string, cache map[string]string, resolve bool) string {
+	if name, found := cache[orgGUID]; found {
+		return name
+	}
+
+	cache[orgGUID] = orgGUID
+	if resolve {
+		org := resources.OrganizationResource{}
+		err := repo.ccGateway.GetResource(fmt.Sprintf("%s/v2/organizations/%s", repo.config.APIEndpoint(), orgGUID), &org)
+		if err == nil && org.Entity.Name != "" {
+			cache[orgGUID] = org.Entity.Name
+		}
+	}
+	return cache[orgGUID]
+}
+
+// scimCoreSchema is the schema UAA expects on imported users.
+const scimCoreSchema = "urn:scim:schemas:core:1.0"
+
+// ExportUsersSCIM writes every UAA user to w as a SCIM user object, one per
+// line, ready to be posted to another UAA's /Users endpoint. Users are
+// written a page at a time rather than collected first.
+func (repo CloudControllerUserRepository) ExportUsersSCIM(w io.Writer) (err error) {
+	defer repo.observe("ExportUsersSCIM", &err)
+
+	uaaEndpoint, err := repo.getAuthEndpoint()
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	path := fmt.Sprintf("%s/Users?attributes=userName,name,emails,origin,externalId,active&count=500", uaaEndpoint)
+	pagePath := path
+	for pagePath != "" {
+		page := new(resources.UAASCIMUserResources)
+		err = repo.uaaGateway.GetResource(pagePath, page)
+		repo.countUAACall()
+		if err != nil {
+			return err
+		}
+
+		for _, user := range page.Resources {
+			user.Schemas = []string{scimCoreSchema}
+			if err = encoder.Encode(user); err != nil {
+				return err
+			}
+		}
+
+		pagePath = nextUAAPagePath(path, page.UAAPagination, len(page.Resources))
+	}
+	return nil
+}
+
+// ListAllUsers calls cb with every UAA user, whether or not the Cloud
+// Controller knows them, reading /Users a page at a time. It stops paging
+// when cb returns false.
+func (repo CloudControllerUserRepository) ListAllUsers(cb func(models.UserFields) bool) (err error) {
+	defer repo.observe("ListAllUsers", &err)
+
+	uaaEndpoint, err := repo.getAuthEndpoint()
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("%s/Users?attributes=id,userName,origin,active&count=500", uaaEndpoint)
+	pagePath := path
+	for pagePath != "" {
+		page := new(resources.UAAUserResources)
+		err = repo.uaaGateway.GetResource(pagePath, page)
+		repo.countUAACall()
+		if err != nil {
+			return err
+		}
+
+		for _, resource := range page.Resources {
+			user := models.UserFields{
+				GUID:     resource.ID,
+				Username: resource.Username,
+				Origin:   resource.Origin,
+				Active:   resource.Active,
+			}
+			if !cb(user) {
+				return nil
+			}
+		}
+
+		pagePath = nextUAAPagePath(path, page.UAAPagination, len(page.Resources))
+	}
+	return nil
+}
+
+// rbacRecord is one org or space in a foundation RBAC export, with the
+// holders of each of its roles keyed by the role's Cloud Controller path.
+type rbacRecord struct {
+	Type             string                `json:"type"`
+	GUID             string                `json:"guid"`
+	Name             string                `json:"name"`
+	OrganizationGUID string                `json:"organization_guid,omitempty"`
+	Roles            map[string][]rbacUser `json:"roles"`
+}
+
+type rbacUser struct {
+	GUID     string `json:"guid"`
+	Username string `json:"username"`
+}
+
+// ExportFoundationRBAC writes every org and space, each followed by its
+// spaces, to w as one JSON object per line listing the holders of each role.
+// It needs an admin token to see the whole foundation. Orgs and spaces are
+// written as they are read, so memory use does not grow with the
+// foundation. When UAA cannot name some users, they are written as the Cloud
+// Controller knows them and a PartialUAALookupWarning is returned once the
+// export is complete.
+func (repo CloudControllerUserRepository) ExportFoundationRBAC(w io.Writer) (err error) {
+	defer repo.observe("ExportFoundationRBAC", &err)
+
+	export := rbacExport{repo: repo, encoder: json.NewEncoder(w)}
+	var orgErr error
+	err = repo.ccGateway.ListPaginatedResources(
+		repo.config.APIEndpoint(),
+		"/v2/organizations",
+		resources.OrganizationResource{},
+		func(resource interface{}) bool {
+			orgErr = export.org(resource.(resources.OrganizationResource).ToFields())
+			return orgErr == nil
+		})
+	if err == nil {
+		err = orgErr
+	}
+	if err == nil && export.warning != nil {
+		err = export.warning
+	}
+	return err
+}
+
+type rbacExport struct {
+	repo    CloudControllerUserRepository
+	encoder *json.Encoder
+	warning *PartialUAALookupWarning
+}
+
+func (export *rbacExport) org(org models.OrganizationFields) error {
+	record := rbacRecord{Type: "organization", GUID: org.GUID, Name: org.Name, Roles: map[string][]rbacUser{}}
+	for _, role := range orgRoles {
+		path := orgRoleToPathMap[role]
+		users, err := export.users(fmt.Sprintf("/v2/organizations/%s/%s", org.GUID, path))
+		if err != nil {
+			return err
+		}
+		record.Roles[path] = users
+	}
+	if err := export.encoder.Encode(record); err != nil {
+		return err
+	}
+
+	var spaceErr error
+	err := export.repo.ccGateway.ListPaginatedResources(
+		export.repo.config.APIEndpoint(),
+		fmt.Sprintf("/v2/organizations/%s/spaces", org.GUID),
+		resources.SpaceResource{},
+		func(resource interface{}) bool {
+			spaceErr = export.space(org.GUID, resource.(resources.SpaceResource).ToFields())
+			return spaceErr == nil
+		})
+	if err != nil {
+		return err
+	}
+	return spaceErr
+}
+
+func (export *rbacExport) space(orgGUID string, space models.SpaceFields) error {
+	record := rbacRecord{Type: "space", GUID: space.GUID, Name: space.Name, OrganizationGUID: orgGUID, Roles: map[string][]rbacUser{}}
+	for _, role := range spaceRoles {
+		path := spaceRoleToPathMap[role]
+		users, err := export.users(export.repo.spaceRoleURL(space.GUID, path))
+		if err != nil {
+			return err
+		}
+		record.Roles[path] = users
+	}
+	return export.encoder.Encode(record)
+}
+
+// users lists the holders of one role. If UAA cannot be reached at all, the
+// usernames the Cloud Controller has are used instead.
+func (export *rbacExport) users(path string) ([]rbacUser, error) {
+	users, err := export.repo.listUsersWithPath(context.Background(), path)
+	if warning, partial := err.(*PartialUAALookupWarning); partial {
+		export.warn(warning.Count, warning.Err)
+	} else if err != nil {
+		uaaErr := err
+		users, err = export.repo.listUsersWithPathWithNoUAA(context.Background(), path)
+		if err != nil {
+			return nil, err
+		}
+		if len(users) > 0 {
+			export.warn(len(users), uaaErr)
+		}
+	}
+
+	holders := make([]rbacUser, 0, len(users))
+	for _, user := range users {
+		holders = append(holders, rbacUser{GUID: user.GUID, Username: user.Username})
+	}
+	return holders, nil
+}
+
+func (export *rbacExport) warn(count int, err error) {
+	if export.warning == nil {
+		export.warning = &PartialUAALookupWarning{Err: err}
+	}
+	export.warning.Count += count
+}
+
+// userAssociationPaths are the CC user relations that hold an org or space
+// role.
+var userAssociationPaths = []string{
+	"organizations",
+	"managed_organizations",
+	"billing_managed_organizations",
+	"audited_organizations",
+	"spaces",
+	"managed_spaces",
+	"audited_spaces",
+}
+
+// FindRolelessUsers returns every Cloud Controller user that holds no org or
+// space role. It checks each user in turn, so it is slow on large
+// foundations; EachRolelessUser can stop early.
+func (repo CloudControllerUserRepository) FindRolelessUsers() (_ []models.UserFields, err error) {
+	defer repo.observe("FindRolelessUsers", &err)
+
+	users := []models.UserFields{}
+	err = repo.eachRolelessUser(func(user models.UserFields) bool {
+		users = append(users, user)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// EachRolelessUser calls cb with each roleless user as it is found, and
+// stops paging when cb returns false.
+func (repo CloudControllerUserRepository) EachRolelessUser(cb func(models.UserFields) bool) (err error) {
+	defer repo.observe("EachRolelessUser", &err)
+	return repo.eachRolelessUser(cb)
+}
+
+func (repo CloudControllerUserRepository) eachRolelessUser(cb func(models.UserFields) bool) error {
+	var checkErr error
+	err := repo.ccGateway.ListPaginatedResources(
+		repo.config.APIEndpoint(),
+		"/v2/users",
+		resources.UserResource{},
+		func(resource interface{}) bool {
+			user := resource.(resources.UserResource).ToFields()
+			var hasRole bool
+			hasRole, checkErr = repo.hasAnyRole(user.GUID)
+			if checkErr != nil {
+				return false
+			}
+			if hasRole {
+				return true
+			}
+			return cb(repo.transformUser(user))
+		})
+	if checkErr != nil {
+		return checkErr
+	}
+	return err
+}
+
+func (repo CloudControllerUserRepository) hasAnyRole(userGUID string) (bool, error) {
+	for _, association := range userAssociationPaths {
+		path := fmt.Sprintf("%s/v2/users/%s/%s?results-per-page=1", repo.config.APIEndpoint(), userGUID, association)
+		response := new(resources.PaginatedResourceCount)
+		err := repo.ccGateway.GetResource(path, response)
+		if err != nil {
+			return false, err
+		}
+		if response.TotalResults > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// FindDuplicateCCRegistrations pages through every Cloud Controller user and
+// reports the UAA users that more than one CC user resolves to. A CC user
+// resolves to the UAA user sharing its GUID, or failing that, its username.
+func (repo CloudControllerUserRepository) FindDuplicateCCRegistrations() (_ []models.DuplicateUserRegistration, err error) {
+	defer repo.observe("FindDuplicateCCRegistrations", &err)
+
+	ccUsers, err := repo.listUsersWithPathWithNoUAA(context.Background(), "/v2/users")
+	if err != nil {
+		return nil, err
+	}
+	if len(ccUsers) == 0 {
+		return nil, nil
+	}
+
+	uaaEndpoint, err := repo.getAuthEndpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	filters := []string{}
+	for _, user := range ccUsers {
+		filters = append(filters, fmt.Sprintf(`ID eq %s`, scimString(user.GUID)))
+		if user.Username != "" {
+			filters = append(filters, fmt.Sprintf(`userName eq %s`, scimString(user.Username)))
+		}
+	}
+	uaaUsers, err := repo.updateOrFindUsersWithUAAFilters([]models.UserFields{}, uaaEndpoint, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	uaaIDsByGUID := map[string]string{}
+	uaaIDsByUsername := map[string]string{}
+	usernamesByUAAID := map[string]string{}
+	for _, user := range uaaUsers {
+		uaaIDsByGUID[user.GUID] = user.GUID
+		uaaIDsByUsername[user.Username] = user.GUID
+		usernamesByUAAID[user.GUID] = user.Username
+	}
+
+	var uaaIDs []string
+	ccGUIDsByUAAID := map[string][]string{}
+	for _, user := range ccUsers {
+		uaaID, found := uaaIDsByGUID[user.GUID]
+		if !found {
+			uaaID, found = uaaIDsByUsername[user.Username]
+		}
+		if !found {
+			continue
+		}
+		if _, seen := ccGUIDsByUAAID[uaaID]; !seen {
+			uaaIDs = append(uaaIDs, uaaID)
+		}
+		ccGUIDsByUAAID[uaaID] = append(ccGUIDsByUAAID[uaaID], user.GUID)
+	}
+
+	var duplicates []models.DuplicateUserRegistration
+	for _, uaaID := range uaaIDs {
+		if len(ccGUIDsByUAAID[uaaID]) < 2 {
+			continue
+		}
+		duplicates = append(duplicates, models.DuplicateUserRegistration{
+			UAAID:    uaaID,
+			Username: usernamesByUAAID[uaaID],
+			CCGUIDs:  ccGUIDsByUAAID[uaaID],
+		})
+	}
+	return duplicates, nil
+}
+
+// unmatchedUserGracePeriod is how old a UAA user must be before
+// FindUnmatchedUsers reports it, so that a user create-user has written to
+// UAA but not yet to the Cloud Controller is left alone.
+const unmatchedUserGracePeriod = 10 * time.Minute
+
+// unmatchedUserProtectedGroups are the UAA groups whose members
+// FindUnmatchedUsers never reports, such as UAA's own admin.
+var unmatchedUserProtectedGroups = map[string]bool{
+	"uaa.admin":              true,
+	"cloud_controller.admin": true,
+}
+
+// FindUnmatchedUsers pages through every Cloud Controller user and every UAA
+// user and returns those whose GUID only one side knows, as left behind by
+// DeleteAsync. Only UAA users from the uaa origin that are not admins and
+// are older than a short grace period are reported, and Cloud Controller
+// users whose GUID is a UAA client, such as client credential principals,
+// are not. Delete removes either kind.
+func (repo CloudControllerUserRepository) FindUnmatchedUsers() (_ models.UnmatchedUsers, err error) {
+	defer repo.observe("FindUnmatchedUsers", &err)
+
+	ccUsers, err := repo.listUsersWithPathWithNoUAA(context.Background(), "/v2/users")
+	if err != nil {
+		return models.UnmatchedUsers{}, err
+	}
+
+	uaaEndpoint, err := repo.getAuthEndpoint()
+	if err != nil {
+		return models.UnmatchedUsers{}, err
+	}
+
+	inUAA := map[string]bool{}
+	var uaaOnlyCandidates []models.UserFields
+	cutoff := time.Now().Add(-unmatchedUserGracePeriod)
+	path := fmt.Sprintf("%s/Users?attributes=id,userName,origin,groups,meta&count=500", uaaEndpoint)
+	pagePath := path
+	for pagePath != "" {
+		page := new(resources.UAAUserResources)
+		err = repo.uaaGateway.GetResource(pagePath, page)
+		repo.countUAACall()
+		if err != nil {
+			return models.UnmatchedUsers{}, err
+		}
+		for _, resource := range page.Resources {
+			inUAA[resource.ID] = true
+			if resource.Origin != UAAOrigin || inProtectedGroup(resource.Groups) {
+				continue
+			}
+			created, parseErr := time.Parse(time.RFC3339, resource.Meta.Created)
+			if parseErr != nil || created.After(cutoff) {
+				continue
+			}
+			uaaOnlyCandidates = append(uaaOnlyCandidates, models.UserFields{GUID: resource.ID, Username: resource.Username, Origin: resource.Origin})
+		}
+		pagePath = nextUAAPagePath(path, page.UAAPagination, len(page.Resources))
+	}
+
+	clients := map[string]bool{}
+	path = fmt.Sprintf("%s/oauth/clients?count=500", uaaEndpoint)
+	pagePath = path
+	for pagePath != "" {
+		page := new(resources.UAAClientResources)
+		err = repo.uaaGateway.GetResource(pagePath, page)
+		repo.countUAACall()
+		if err != nil {
+			return models.UnmatchedUsers{}, err
+		}
+		for _, resource := range page.Resources {
+			clients[resource.ClientID] = true
+		}
+		pagePath = nextUAAPagePath(path, page.UAAPagination, len(page.Resources))
+	}
+
+	inCC := map[string]bool{}
+	for _, user := range ccUsers {
+		inCC[user.GUID] = true
+	}
+
+	unmatched := models.UnmatchedUsers{}
+	for _, user := range ccUsers {
+		if !inUAA[user.GUID] && !clients[user.GUID] {
+			unmatched.CCOnly = append(unmatched.CCOnly, user)
+		}
+	}
+	for _, user := range uaaOnlyCandidates {
+		if !inCC[user.GUID] {
+			unmatched.UAAOnly = append(unmatched.UAAOnly, user)
+		}
+	}
+	return unmatched, nil
+}
+
+func inProtectedGroup(groups []resources.UAAUserGroup) bool {
+	for _, group := range groups {
+		if unmatchedUserProtectedGroups[group.Display] {
+			return true
+		}
+	}
+	return false
+}
+
+// ListCFUsersInUAAGroup returns the Cloud Controller users that are direct
+// members of the named UAA group, such as one mapped to an external LDAP
+// group. Nested groups are not expanded.
+func (repo CloudControllerUserRepository) ListCFUsersInUAAGroup(groupName string) (_ []models.UserFields, err error) {
+	defer repo.observe("ListCFUsersInUAAGroup", &err)
+
+	uaaEndpoint, err := repo.getAuthEndpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	groupFilter := neturl.QueryEscape(fmt.Sprintf(`displayName eq %s`, scimString(groupName)))
+	groups := new(resources.UAAGroupResources)
+	err = repo.uaaGateway.GetResource(fmt.Sprintf("%s/Groups?filter=%s", uaaEndpoint, groupFilter), groups)
+	repo.countUAACall()
+	if err != nil {
+		return nil, err
+	}
+	if len(groups.Resources) == 0 {
+		return nil, errors.NewNotFoundError(errors.GroupResource, groupName)
+	}
+
+	memberIDs := map[string]bool{}
+	for _, member := range groups.Resources[0].Members {
+		if member.Type == "USER" {
+			memberIDs[member.Value] = true
+		}
+	}
+
+	users := []models.UserFields{}
+	if len(memberIDs) == 0 {
+		return users, nil
+	}
+
+	ccUsers, err := repo.listUsersWithPathWithNoUAA(context.Background(), "/v2/users")
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range ccUsers {
+		if memberIDs[user.GUID] {
+			users = append(users, repo.transformUser(user))
+		}
+	}
+	return users, nil
+}
+
+// useV3Roles reports whether role holders filtered by filter can be listed
+// through /v3/roles. Spaces laid out by a custom SpaceRolePathTemplate are
+// only reachable through their v2 paths.
+func (repo CloudControllerUserRepository) useV3Roles(filter string) bool {
+	if !repo.config.IsMinAPIVersion(cf.V3RolesMinimumAPIVersion) {
+		return false
+	}
+	return filter != "space_guids" || !repo.customSpaces
+}
+
+// listUsersWithV3Roles lists the holders of role in the org or space guid
+// through /v3/roles, which includes their usernames and so needs no UAA
+// lookup. /v3/roles has no admin flag; see CCAdminFlagReader.
+func (repo CloudControllerUserRepository) listUsersWithV3Roles(ctx context.Context, filter, guid string, role models.Role) (users []models.UserFields, apiErr error) {
+	var roleType string
+	var found bool
+	if filter == "space_guids" {
+		roleType, found = spaceRoleToV3TypeMap[role]
+	} else {
+		roleType, found = orgRoleToV3TypeMap[role]
+	}
+	if !found {
+		return nil, errors.NewInvalidInputError(T("Invalid Role {{.Role}}",
+			map[string]interface{}{"Role": role.ToString()}))
+	}
+
+	url := fmt.Sprintf("%s/v3/roles?%s=%s&types=%s&include=user", repo.config.APIEndpoint(), filter, guid, roleType)
+	for url != "" {
+		if apiErr = ctx.Err(); apiErr != nil {
+			return users, apiErr
+		}
+		page := new(resources.V3RoleResources)
+		apiErr = repo.ccGateway.GetResource(url, page)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+
+		included := map[string]models.UserFields{}
+		for _, user := range page.Included.Users {
+			included[user.GUID] = models.UserFields{GUID: user.GUID, Username: user.Username, Origin: user.Origin}
+		}
+		for _, role := range page.Resources {
+			user, found := included[role.Relationships.User.Data.GUID]
+			if !found {
+				user.GUID = role.Relationships.User.Data.GUID
+			}
+			users = append(users, user)
+		}
+
+		url = ""
+		if page.Pagination.Next != nil {
+			url = page.Pagination.Next.Href
+		}
+	}
+
+	reader, readsFlag := repo.adminResolver.(CCAdminFlagReader)
+	readsFlag = readsFlag && reader.ReadsCCAdminFlag()
+	for i := range users {
+		if readsFlag {
+			users[i].IsAdmin, apiErr = repo.ccAdmin(ctx, users[i].GUID)
+			if apiErr != nil {
+				return nil, apiErr
+			}
+		}
+		users[i].IsAdmin = repo.adminResolver.IsAdmin(users[i], nil)
+	}
+	return users, nil
+}
+
+// ccAdmin reads the Cloud Controller admin flag of the user with userGUID.
+func (repo CloudControllerUserRepository) ccAdmin(ctx context.Context, userGUID string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	user := new(resources.UserResource)
+	err := repo.ccGateway.GetResource(fmt.Sprintf("%s/v2/users/%s", repo.config.APIEndpoint(), userGUID), user)
+	if err != nil {
+		return false, err
+	}
+	return user.Entity.Admin, nil
+}
+
+func (repo CloudControllerUserRepository) listUsersWithPathWithNoUAA(ctx context.Context, path string) (users []models.UserFields, apiErr error) {
+	apiErr = repo.ccGateway.ListPaginatedResourcesWithContext(
+		ctx,
+		repo.config.APIEndpoint(),
+		path,
+		resources.UserResource{},
+		func(resource interface{}) bool {
+			user := resource.(resources.UserResource).ToFields()
+			user.IsAdmin = repo.adminResolver.IsAdmin(user, nil)
+			users = append(users, user)
+			return true
+		})
+	if apiErr != nil {
+		return
+	}
+
+	return
+}
+
+func (repo CloudControllerUserRepository) listUsersWithPath(ctx context.Context, path string) (users []models.UserFields, apiErr error) {
+	users, _, apiErr = repo.listUsersWithPathCountingUnresolved(ctx, path)
+	if isWarning(apiErr) {
+		apiErr = nil
+	}
+	return
+}
+
+// listUsersWithPathCountingUnresolved also reports how many of the Cloud
+// Controller users had no matching UAA user. When ctx is cancelled, the
+// users listed so far are returned, without UAA details, along with the
+// context's error.
+func (repo CloudControllerUserRepository) listUsersWithPathCountingUnresolved(ctx context.Context, path string) (users []models.UserFields, unresolved int, apiErr error) {
+	return repo.listUsersWithPathMatching(ctx, path, "")
+}
+
+// listUsersWithPathMatching is listUsersWithPathCountingUnresolved that,
+// given a UAA filter in uaaMatch, keeps only the users UAA says match it.
+// Users left out by the filter are not counted as unresolved.
+func (repo CloudControllerUserRepository) listUsersWithPathMatching(ctx context.Context, path string, uaaMatch string) (users []models.UserFields, unresolved int, apiErr error) {
+	guidFilters := []string{}
+
+	total, apiErr := repo.ccGateway.ListPaginatedResourcesWithTotalContext(
+		ctx,
+		repo.config.APIEndpoint(),
+		path,
+		resources.UserResource{},
+		func(resource interface{}) bool {
+			user := resource.(resources.UserResource).ToFields()
+			users = append(users, user)
+			guidFilters = append(guidFilters, fmt.Sprintf(`ID eq %s`, scimString(user.GUID)))
+			return true
 		})
 	if apiErr != nil {
 		return
 	}
+	var inconsistency error
+	if resultsInconsistent(total, len(users)) {
+		inconsistency = &InconsistentResultsWarning{Expected: total, Collected: len(users)}
+	}
+	defer func() {
+		if apiErr == nil {
+			apiErr = inconsistency
+		}
+	}()
+
+	if len(guidFilters) == 0 {
+		return
+	}
+	if apiErr = ctx.Err(); apiErr != nil {
+		return
+	}
+
+	uaaEndpoint, apiErr := repo.getAuthEndpoint()
+	if apiErr != nil {
+		return
+	}
+
+	ccUsers := users
+	users, apiErr = repo.updateOrFindUsersMatchingUAAFilters(ccUsers, uaaEndpoint, guidFilters, uaaMatch)
+	if _, partial := apiErr.(*PartialUAALookupWarning); apiErr != nil && !partial {
+		return
+	}
+	if uaaMatch == "" {
+		unresolved = len(ccUsers) - len(users)
+	} else {
+		users = onlyUsersIn(users, ccUsers)
+	}
+
+	if repo.excludeUser != nil {
+		people := []models.UserFields{}
+		for _, user := range users {
+			if !repo.excludeUser(user) {
+				people = append(people, user)
+			}
+		}
+		users = people
+	}
+	return
+}
+
+// onlyUsersIn returns the users whose GUID is also in others.
+func onlyUsersIn(users, others []models.UserFields) []models.UserFields {
+	guids := map[string]bool{}
+	for _, user := range others {
+		guids[user.GUID] = true
+	}
+	kept := []models.UserFields{}
+	for _, user := range users {
+		if guids[user.GUID] {
+			kept = append(kept, user)
+		}
+	}
+	return kept
+}
+
+// updateOrFindUsersWithUAAFilters ORs the filters together into batches of
+// at most the batch size that fit under the maximum URL length, sends one
+// UAA request per batch and merges the results, each user once.
+func (repo CloudControllerUserRepository) updateOrFindUsersWithUAAFilters(ccUsers []models.UserFields, uaaEndpoint string, filters []string) (updatedUsers []models.UserFields, apiErr error) {
+	return repo.updateOrFindUsersMatchingUAAFilters(ccUsers, uaaEndpoint, filters, "")
+}
+
+// updateOrFindUsersMatchingUAAFilters is updateOrFindUsersWithUAAFilters
+// that ANDs match, when given, onto each batch. Users whose batch fails
+// cannot be checked against match, so any failure is returned as is.
+func (repo CloudControllerUserRepository) updateOrFindUsersMatchingUAAFilters(ccUsers []models.UserFields, uaaEndpoint string, filters []string, match string) (updatedUsers []models.UserFields, apiErr error) {
+	prefix := fmt.Sprintf("%s/Users?attributes=%s&filter=", uaaEndpoint, repo.uaaUserAttributes())
+	usersURL := func(batch []string) string {
+		filter := strings.Join(batch, " or ")
+		if match != "" {
+			filter = "(" + filter + ") and " + match
+		}
+		return prefix + neturl.QueryEscape(filter)
+	}
+
+	batches := [][]string{}
+	batched := map[string]bool{}
+	for _, filter := range filters {
+		if batched[filter] {
+			continue
+		}
+		batched[filter] = true
+
+		last := len(batches) - 1
+		if last >= 0 && (repo.batchSize <= 0 || len(batches[last]) < repo.batchSize) &&
+			len(usersURL(batches[last]))+len(neturl.QueryEscape(" or "+filter)) <= repo.maxURLLength {
+			batches[last] = append(batches[last], filter)
+			continue
+		}
+		batches = append(batches, []string{filter})
+	}
+
+	var firstErr error
+	failedFilters := map[string]bool{}
+	found := map[string]bool{}
+	for _, batch := range batches {
+		users, err := repo.updateOrFindUsersWithUAAPath(ccUsers, usersURL(batch))
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			for _, filter := range batch {
+				failedFilters[filter] = true
+			}
+			continue
+		}
+		for _, user := range users {
+			if !found[user.GUID] {
+				found[user.GUID] = true
+				updatedUsers = append(updatedUsers, user)
+			}
+		}
+	}
+	if firstErr == nil {
+		return updatedUsers, nil
+	}
+	if len(failedFilters) == len(batched) || match != "" {
+		return nil, firstErr
+	}
+
+	warning := &PartialUAALookupWarning{Err: firstErr}
+	for _, user := range ccUsers {
+		if failedFilters[fmt.Sprintf(`ID eq %s`, scimString(user.GUID))] && !found[user.GUID] {
+			found[user.GUID] = true
+			updatedUsers = append(updatedUsers, user)
+			warning.Count++
+		}
+	}
+	return updatedUsers, warning
+}
+
+// updateOrFindUsersWithUAAPath walks every page of the UAA users at path.
+// It follows the cursor UAA returns when it has one, since offsets shift
+// when users are added or removed mid-walk, and falls back to startIndex
+// paging otherwise. A user seen on an earlier page is not returned twice.
+func (repo CloudControllerUserRepository) updateOrFindUsersWithUAAPath(ccUsers []models.UserFields, path string) (updatedUsers []models.UserFields, apiErr error) {
+	seen := map[string]bool{}
+	pagePath := path
+	for pagePath != "" {
+		uaaResponse := new(resources.UAAUserResources)
+		apiErr = repo.uaaGateway.GetResource(pagePath, uaaResponse)
+		repo.countUAACall()
+		if timeoutErr, ok := apiErr.(*errors.NetworkTimeoutError); ok {
+			return nil, errors.New(T("Timed out waiting for UAA at {{.Host}} while looking up users; the Cloud Controller responded.\n{{.Err}}",
+				map[string]interface{}{"Host": timeoutErr.Host, "Err": timeoutErr.Err.Error()}))
+		}
+		if apiErr != nil {
+			return nil, apiErr
+		}
+
+		for _, user := range repo.usersFromUAAPage(ccUsers, uaaResponse) {
+			if seen[user.GUID] {
+				continue
+			}
+			seen[user.GUID] = true
+			updatedUsers = append(updatedUsers, user)
+		}
+
+		pagePath = nextUAAPagePath(path, uaaResponse.UAAPagination, len(uaaResponse.Resources))
+	}
+	return
+}
+
+func nextUAAPagePath(path string, page resources.UAAPagination, pageLength int) string {
+	if pageLength == 0 {
+		return ""
+	}
+	if page.NextCursor != "" {
+		return path + "&cursor=" + neturl.QueryEscape(page.NextCursor)
+	}
+	nextIndex := page.StartIndex + pageLength
+	if page.StartIndex == 0 || nextIndex > page.TotalResults {
+		return ""
+	}
+	return fmt.Sprintf("%s&startIndex=%d", path, nextIndex)
+}
+
+func (repo CloudControllerUserRepository) usersFromUAAPage(ccUsers []models.UserFields, uaaResponse *resources.UAAUserResources) (users []models.UserFields) {
+	for _, uaaResource := range uaaResponse.Resources {
+		var ccUserFields models.UserFields
+
+		for _, u := range ccUsers {
+			if u.GUID == uaaResource.ID {
+				ccUserFields = u
+				break
+			}
+		}
+
+		groups := make([]string, 0, len(uaaResource.Groups))
+		for _, group := range uaaResource.Groups {
+			groups = append(groups, group.Display)
+		}
+
+		user := models.UserFields{
+			GUID:      uaaResource.ID,
+			Username:  uaaResource.Username,
+			IsAdmin:   ccUserFields.IsAdmin,
+			Origin:    uaaResource.Origin,
+			CreatedAt: ccUserFields.CreatedAt,
+			UpdatedAt: ccUserFields.UpdatedAt,
+		}
+		if len(uaaResource.Emails) > 0 {
+			user.Email = uaaResource.Emails[0].Value
+		}
+		if uaaResource.LastLogonTime > 0 {
+			user.LastLogon = time.Unix(0, uaaResource.LastLogonTime*int64(time.Millisecond))
+		}
+		if created, parseErr := time.Parse(time.RFC3339, uaaResource.Meta.Created); parseErr == nil {
+			user.UAACreatedAt = created
+		}
+		user.IsAdmin = repo.adminResolver.IsAdmin(user, groups)
+		users = append(users, user)
+	}
+	return
+}
+
+func (repo CloudControllerUserRepository) uaaUserAttributes() string {
+	attributes := "id,userName,origin"
+	if _, isDefault := repo.adminResolver.(CCFlagAdminResolver); !isDefault {
+		attributes += ",groups"
+	}
+	if repo.excludeUser != nil {
+		attributes += ",emails"
+	}
+	if repo.lastLogon {
+		attributes += ",lastLogonTime"
+	}
+	if repo.createdAt {
+		attributes += ",meta"
+	}
+	return attributes
+}
+
+// Create creates a user whose email is their username. A username that looks
+// like an email address must be a valid one.
+func (repo CloudControllerUserRepository) Create(username, password string) (err error) {
+	defer repo.observe("Create", &err)
+	repo = repo.forMutation()
+
+	if err = validateUsername(username, UAAOrigin); err != nil {
+		return err
+	}
+	email, err := emailForUsername(username)
+	if err != nil {
+		return err
+	}
+	return repo.create(resources.NewUAAUserResourceWithEmail(username, password, email))
+}
+
+// UAAOrigin is the origin of users whose passwords UAA itself holds.
+const UAAOrigin = "uaa"
+
+// CreateWithOrigin creates a user as Create does, but mapped to a user in
+// the external identity provider origin. Such users have no UAA password,
+// so password is ignored unless origin is empty or UAAOrigin.
+func (repo CloudControllerUserRepository) CreateWithOrigin(username, password, origin string) (err error) {
+	defer repo.observe("CreateWithOrigin", &err)
+	repo = repo.forMutation()
+
+	if err = validateUsername(username, origin); err != nil {
+		return err
+	}
+	email, err := emailForUsername(username)
+	if err != nil {
+		return err
+	}
+	if origin == "" || origin == UAAOrigin {
+		return repo.create(resources.NewUAAUserResourceWithEmail(username, password, email))
+	}
+	return repo.create(resources.NewUAAUserResourceWithOrigin(username, email, origin))
+}
+
+// maxUsernameLength is the longest username UAA stores.
+const maxUsernameLength = 255
+
+// uaaUsernamePattern matches the usernames UAA accepts for its own users.
+// Users from an external origin are named by that identity provider.
+var uaaUsernamePattern = regexp.MustCompile(`^[\p{L}+0-9+\-_.@'!]+$`)
+
+// validateUsername rejects a username UAA would refuse, so that the error
+// is clear and no request is sent.
+func validateUsername(username, origin string) error {
+	if strings.TrimSpace(username) == "" {
+		return errors.NewInvalidInputError(T("Username must not be empty"))
+	}
+	if utf8.RuneCountInString(username) > maxUsernameLength {
+		return errors.NewInvalidInputError(T("Username must be at most {{.Max}} characters long",
+			map[string]interface{}{"Max": maxUsernameLength}))
+	}
+	if (origin == "" || origin == UAAOrigin) && !uaaUsernamePattern.MatchString(username) {
+		return errors.NewInvalidInputError(T("Username {{.Username}} may only contain letters, digits and the characters + - _ . @ ' !",
+			map[string]interface{}{"Username": username}))
+	}
+	return nil
+}
+
+func emailForUsername(username string) (string, error) {
+	if strings.Contains(username, "@") {
+		return normalizeEmail(username)
+	}
+	return username, nil
+}
+
+func (repo CloudControllerUserRepository) CreateWithEmail(username, password, email string) (err error) {
+	defer repo.observe("CreateWithEmail", &err)
+	repo = repo.forMutation()
+
+	if err = validateUsername(username, UAAOrigin); err != nil {
+		return err
+	}
+	email, err = normalizeEmail(email)
+	if err != nil {
+		return err
+	}
+	return repo.create(resources.NewUAAUserResourceWithEmail(username, password, email))
+}
+
+func (repo CloudControllerUserRepository) create(resource resources.UAAUserResource) (err error) {
+	if repo.dryRun {
+		return nil
+	}
+
+	uaaEndpoint, err := repo.getAuthEndpoint()
+	if err != nil {
+		return
+	}
+
+	_, err = repo.createUser(uaaEndpoint, resource, false)
+	if _, exists := err.(*errors.ModelAlreadyExistsError); !exists {
+		return err
+	}
+
+	existing, findErr := repo.findByUsername(context.Background(), resource.Username)
+	if findErr != nil {
+		return err
+	}
+	_, ccErr := repo.createCCUser(uaaEndpoint, existing.GUID, resource, false)
+	switch ccErr := ccErr.(type) {
+	case nil:
+		return &UAAUserReusedWarning{Username: resource.Username}
+	case errors.HTTPError:
+		if ccErr.StatusCode() < http.StatusInternalServerError {
+			return err
+		}
+	}
+	return ccErr
+}
+
+// CreateBulk creates each user in turn as Create would, resolving the UAA
+// endpoint once. Failures for individual users are reported in their result
+// and do not stop the others; a user whose Cloud Controller record cannot
+// be created is removed from UAA again. The returned error is only for a
+// problem that prevents any user being attempted.
+func (repo CloudControllerUserRepository) CreateBulk(users []models.UserCreateRequest) (_ []models.UserCreateResult, err error) {
+	defer repo.observe("CreateBulk", &err)
+	repo = repo.forMutation()
+
+	uaaEndpoint := ""
+	if !repo.dryRun {
+		uaaEndpoint, err = repo.getAuthEndpoint()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]models.UserCreateResult, 0, len(users))
+	for _, request := range users {
+		var user models.UserFields
+		var email string
+		createErr := validateUsername(request.Username, UAAOrigin)
+		if createErr == nil {
+			email, createErr = emailForUsername(request.Username)
+		}
+		switch {
+		case createErr != nil:
+		case repo.dryRun:
+			user = models.UserFields{Username: request.Username, Email: email}
+		default:
+			user, createErr = repo.createUser(uaaEndpoint, resources.NewUAAUserResourceWithEmail(request.Username, request.Password, email), true)
+		}
+		results = append(results, models.UserCreateResult{User: user, Err: createErr})
+	}
+	return results, nil
+}
+
+// createUser creates the user in UAA and then the Cloud Controller. With
+// rollback, a UAA user whose Cloud Controller record cannot be created is
+// deleted so it is not left orphaned.
+func (repo CloudControllerUserRepository) createUser(uaaEndpoint string, resource resources.UAAUserResource, rollback bool) (user models.UserFields, err error) {
+	path := "/Users"
+	body, err := json.Marshal(resource)
+
+	if err != nil {
+		return
+	}
+
+	createUserResponse := &resources.UAAUserFields{}
+	err = repo.uaaGateway.CreateResource(uaaEndpoint, path, bytes.NewReader(body), createUserResponse)
+	repo.countUAACall()
+	switch httpErr := err.(type) {
+	case nil:
+	case errors.HTTPError:
+		if httpErr.StatusCode() == http.StatusConflict {
+			err = errors.NewModelAlreadyExistsError("user", resource.Username)
+			return
+		}
+		return
+	default:
+		return
+	}
+
+	return repo.createCCUser(uaaEndpoint, createUserResponse.ID, resource, rollback)
+}
+
+// createCCUser creates the Cloud Controller record for the UAA user with
+// the given GUID and runs the post create hook.
+func (repo CloudControllerUserRepository) createCCUser(uaaEndpoint, guid string, resource resources.UAAUserResource, rollback bool) (user models.UserFields, err error) {
+	body, err := json.Marshal(resources.Metadata{
+		GUID: guid,
+	})
+
+	if err != nil {
+		return
+	}
+
+	err = repo.ccGateway.CreateResource(repo.config.APIEndpoint(), "/v2/users", bytes.NewReader(body))
+	if err != nil {
+		if rollback {
+			rollbackErr := repo.uaaGateway.DeleteResource(uaaEndpoint, fmt.Sprintf("/Users/%s", guid))
+			repo.countUAACall()
+			if rollbackErr != nil {
+				err = &UAARollbackError{Username: resource.Username, GUID: guid, Err: err, RollbackErr: rollbackErr}
+			}
+		}
+		return
+	}
+
+	user = models.UserFields{
+		GUID:     guid,
+		Username: resource.Username,
+		Email:    resource.Emails[0].Value,
+		Origin:   resource.Origin,
+	}
+	if repo.postCreate == nil {
+		return user, nil
+	}
+	if hookErr := repo.postCreate(user); hookErr != nil {
+		return user, &PostCreateHookError{Err: hookErr}
+	}
+	return user, nil
+}
+
+func (repo CloudControllerUserRepository) Delete(userGUID string) (apiErr error) {
+	defer repo.observe("Delete", &apiErr)
+	repo = repo.forMutation()
+
+	if repo.dryRun {
+		return nil
+	}
+
+	path := fmt.Sprintf("/v2/users/%s", userGUID)
+
+	apiErr = repo.ccGateway.DeleteResource(repo.config.APIEndpoint(), path)
+
+	if httpErr, ok := apiErr.(errors.HTTPError); ok && httpErr.ErrorCode() != errors.UserNotFound {
+		return
+	}
+	uaaEndpoint, apiErr := repo.getAuthEndpoint()
+	if apiErr != nil {
+		return
+	}
+
+	path = fmt.Sprintf("/Users/%s", userGUID)
+	apiErr = repo.uaaGateway.DeleteResource(uaaEndpoint, path)
+	repo.countUAACall()
+	if httpErr, ok := apiErr.(errors.HTTPError); ok && httpErr.StatusCode() == http.StatusNotFound {
+		return nil
+	}
+	return apiErr
+}
+
+// DeleteAsync queues the deletion of the user's Cloud Controller record and
+// returns without waiting for the job or touching UAA. FindUnmatchedUsers
+// finds the UAA users left behind this way.
+func (repo CloudControllerUserRepository) DeleteAsync(userGUID string) (err error) {
+	defer repo.observe("DeleteAsync", &err)
+	repo = repo.forMutation()
+
+	if repo.dryRun {
+		return nil
+	}
+
+	path := fmt.Sprintf("%s/v2/users/%s?async=true", repo.config.APIEndpoint(), userGUID)
+	request, err := repo.ccGateway.NewRequest("DELETE", path, repo.config.AccessToken(), nil)
+	if err != nil {
+		return err
+	}
+	_, err = repo.ccGateway.PerformRequest(request)
+	if httpErr, ok := err.(errors.HTTPError); ok && httpErr.ErrorCode() == errors.UserNotFound {
+		return nil
+	}
+	return err
+}
+
+// RenameUser changes a user's UAA username. It fails with a
+// ModelAlreadyExistsError if another user already has the name. The Cloud
+// Controller user is keyed by GUID, so it needs no change.
+func (repo CloudControllerUserRepository) RenameUser(userGUID, newUsername string) (err error) {
+	defer repo.observe("RenameUser", &err)
+	repo = repo.forMutation()
+
+	existing, err := repo.findAllByUsername(newUsername)
+	if _, notFound := err.(*errors.ModelNotFoundError); !notFound && err != nil {
+		return err
+	}
+	for _, user := range existing {
+		if user.GUID != userGUID {
+			return errors.NewModelAlreadyExistsError("user", newUsername)
+		}
+	}
+	if repo.dryRun {
+		return nil
+	}
+
+	uaaEndpoint, err := repo.getAuthEndpoint()
+	if err != nil {
+		return err
+	}
+	userURL := fmt.Sprintf("%s/Users/%s", uaaEndpoint, userGUID)
+
+	version := resources.UAAUserVersion{}
+	err = repo.uaaGateway.GetResource(userURL, &version)
+	repo.countUAACall()
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]string{"userName": newUsername})
+	if err != nil {
+		return err
+	}
+	request, err := repo.uaaGateway.NewRequest("PATCH", userURL, repo.config.AccessToken(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.HTTPReq.Header.Set("If-Match", strconv.Itoa(version.Meta.Version))
 
-	return
+	response, err := repo.uaaGateway.PerformRequest(request)
+	repo.countUAACall()
+	if httpErr, ok := err.(errors.HTTPError); ok && httpErr.StatusCode() == http.StatusConflict {
+		return errors.NewModelAlreadyExistsError("user", newUsername)
+	}
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
 }
 
-func (repo CloudControllerUserRepository) listUsersWithPath(path string) (users []models.UserFields, apiErr error) {
-	guidFilters := []string{}
+// passwordResetScopes are the scopes, any one of which lets a token set a
+// user's password without knowing the old one.
+var passwordResetScopes = []string{"uaa.admin", "password.write"}
 
-	apiErr = repo.ccGateway.ListPaginatedResources(
-		repo.config.APIEndpoint(),
-		path,
-		resources.UserResource{},
-		func(resource interface{}) bool {
-			user := resource.(resources.UserResource).ToFields()
-			users = append(users, user)
-			guidFilters = append(guidFilters, fmt.Sprintf(`ID eq "%s"`, user.GUID))
-			return true
-		})
-	if apiErr != nil {
-		return
-	}
+// UpdatePassword changes a user's UAA password. With an empty oldPassword it
+// resets the password instead, which fails with a MissingScopeError unless
+// the access token has one of the passwordResetScopes.
+func (repo CloudControllerUserRepository) UpdatePassword(userGUID, oldPassword, newPassword string) (err error) {
+	defer repo.observe("UpdatePassword", &err)
+	repo = repo.forMutation()
 
-	if len(guidFilters) == 0 {
-		return
+	if oldPassword == "" && !tokenHasAnyScope(repo.config.AccessToken(), passwordResetScopes) {
+		return errors.NewMissingScopeError(passwordResetScopes...)
 	}
-
-	uaaEndpoint, apiErr := repo.getAuthEndpoint()
-	if apiErr != nil {
-		return
+	if repo.dryRun {
+		return nil
 	}
 
-	filter := strings.Join(guidFilters, " or ")
-	usersURL := fmt.Sprintf("%s/Users?attributes=id,userName&filter=%s", uaaEndpoint, neturl.QueryEscape(filter))
-	users, apiErr = repo.updateOrFindUsersWithUAAPath(users, usersURL)
-	return
-}
+	uaaEndpoint, err := repo.getAuthEndpoint()
+	if err != nil {
+		return err
+	}
 
-func (repo CloudControllerUserRepository) updateOrFindUsersWithUAAPath(ccUsers []models.UserFields, path string) (updatedUsers []models.UserFields, apiErr error) {
-	uaaResponse := new(resources.UAAUserResources)
-	apiErr = repo.uaaGateway.GetResource(path, uaaResponse)
-	if apiErr != nil {
-		return
+	password := map[string]string{"password": newPassword}
+	if oldPassword != "" {
+		password["oldPassword"] = oldPassword
+	}
+	body, err := json.Marshal(password)
+	if err != nil {
+		return err
 	}
 
-	for _, uaaResource := range uaaResponse.Resources {
-		var ccUserFields models.UserFields
+	path := fmt.Sprintf("/Users/%s/password", userGUID)
+	err = repo.uaaGateway.UpdateResource(uaaEndpoint, path, bytes.NewReader(body))
+	repo.countUAACall()
+	return err
+}
 
-		for _, u := range ccUsers {
-			if u.GUID == uaaResource.ID {
-				ccUserFields = u
-				break
+func tokenHasAnyScope(accessToken string, scopes []string) bool {
+	for _, granted := range coreconfig.NewTokenInfo(accessToken).Scope {
+		for _, scope := range scopes {
+			if granted == scope {
+				return true
 			}
 		}
-
-		updatedUsers = append(updatedUsers, models.UserFields{
-			GUID:     uaaResource.ID,
-			Username: uaaResource.Username,
-			IsAdmin:  ccUserFields.IsAdmin,
-		})
 	}
-	return
+	return false
 }
 
-func (repo CloudControllerUserRepository) Create(username, password string) (err error) {
-	uaaEndpoint, err := repo.getAuthEndpoint()
+func (repo CloudControllerUserRepository) SetOrgRoleByGUID(userGUID string, orgGUID string, role models.Role) (err error) {
+	defer repo.observe("SetOrgRoleByGUID", &err)
+	repo = repo.forMutation()
+
+	job, err := repo.setOrgRoleByGUIDAsync(userGUID, orgGUID, role)
 	if err != nil {
 		return
 	}
+	return repo.waitForRoleJob(job)
+}
 
-	path := "/Users"
-	body, err := json.Marshal(resources.NewUAAUserResource(username, password))
+func (repo CloudControllerUserRepository) SetOrgRoleByGUIDAsync(userGUID string, orgGUID string, role models.Role) (job RoleJob, err error) {
+	defer repo.observe("SetOrgRoleByGUIDAsync", &err)
+	repo = repo.forMutation()
+	return repo.setOrgRoleByGUIDAsync(userGUID, orgGUID, role)
+}
 
+func (repo CloudControllerUserRepository) setOrgRoleByGUIDAsync(userGUID string, orgGUID string, role models.Role) (job RoleJob, err error) {
+	path, err := userGUIDPath(repo.config.APIEndpoint(), userGUID, orgGUID, role)
 	if err != nil {
 		return
 	}
-
-	createUserResponse := &resources.UAAUserFields{}
-	err = repo.uaaGateway.CreateResource(uaaEndpoint, path, bytes.NewReader(body), createUserResponse)
-	switch httpErr := err.(type) {
-	case nil:
-	case errors.HTTPError:
-		if httpErr.StatusCode() == http.StatusConflict {
-			err = errors.NewModelAlreadyExistsError("user", username)
-			return
-		}
-		return
-	default:
+	if repo.dryRun {
 		return
 	}
-
-	path = "/v2/users"
-	body, err = json.Marshal(resources.Metadata{
-		GUID: createUserResponse.ID,
-	})
-
+	job, err = repo.startRoleChange("PUT", path, nil)
 	if err != nil {
 		return
 	}
-
-	return repo.ccGateway.CreateResource(repo.config.APIEndpoint(), path, bytes.NewReader(body))
+	return job, repo.assocUserWithOrgByUserGUID(userGUID, orgGUID)
 }
 
-func (repo CloudControllerUserRepository) Delete(userGUID string) (apiErr error) {
-	path := fmt.Sprintf("/v2/users/%s", userGUID)
-
-	apiErr = repo.ccGateway.DeleteResource(repo.config.APIEndpoint(), path)
+func (repo CloudControllerUserRepository) UnsetOrgRoleByGUID(userGUID, orgGUID string, role models.Role) (err error) {
+	defer repo.observe("UnsetOrgRoleByGUID", &err)
+	repo = repo.forMutation()
 
-	if httpErr, ok := apiErr.(errors.HTTPError); ok && httpErr.ErrorCode() != errors.UserNotFound {
-		return
-	}
-	uaaEndpoint, apiErr := repo.getAuthEndpoint()
-	if apiErr != nil {
+	job, err := repo.unsetOrgRoleByGUIDAsync(userGUID, orgGUID, role)
+	if err != nil {
 		return
 	}
+	return repo.waitForRoleJob(job)
+}
 
-	path = fmt.Sprintf("/Users/%s", userGUID)
-	return repo.uaaGateway.DeleteResource(uaaEndpoint, path)
+func (repo CloudControllerUserRepository) UnsetOrgRoleByGUIDAsync(userGUID, orgGUID string, role models.Role) (job RoleJob, err error) {
+	defer repo.observe("UnsetOrgRoleByGUIDAsync", &err)
+	repo = repo.forMutation()
+	return repo.unsetOrgRoleByGUIDAsync(userGUID, orgGUID, role)
 }
 
-func (repo CloudControllerUserRepository) SetOrgRoleByGUID(userGUID string, orgGUID string, role models.Role) (err error) {
+func (repo CloudControllerUserRepository) unsetOrgRoleByGUIDAsync(userGUID, orgGUID string, role models.Role) (job RoleJob, err error) {
 	path, err := userGUIDPath(repo.config.APIEndpoint(), userGUID, orgGUID, role)
 	if err != nil {
 		return
 	}
-	err = repo.callAPI("PUT", path, nil)
-	if err != nil {
+	if repo.dryRun {
 		return
 	}
-	return repo.assocUserWithOrgByUserGUID(userGUID, orgGUID)
+	return repo.startRoleChange("DELETE", path, nil)
 }
 
-func (repo CloudControllerUserRepository) UnsetOrgRoleByGUID(userGUID, orgGUID string, role models.Role) (err error) {
-	path, err := userGUIDPath(repo.config.APIEndpoint(), userGUID, orgGUID, role)
-	if err != nil {
-		return
+// UnsetOrgRoleBulk removes role in the org from each user in turn. A user
+// who does not hold the role is not an error. Failures for individual users
+// are reported in their result; the returned error is only for a request
+// that could not be attempted at all.
+func (repo CloudControllerUserRepository) UnsetOrgRoleBulk(userGUIDs []string, orgGUID string, role models.Role) (_ []models.RoleChangeResult, err error) {
+	defer repo.observe("UnsetOrgRoleBulk", &err)
+	return repo.unsetOrgRoleBulk(context.Background(), userGUIDs, orgGUID, role)
+}
+
+// UnsetOrgRoleBulkContext is UnsetOrgRoleBulk stopping early once ctx is
+// done. The change in flight is finished, and the results gathered so far are
+// returned along with the context's error.
+func (repo CloudControllerUserRepository) UnsetOrgRoleBulkContext(ctx context.Context, userGUIDs []string, orgGUID string, role models.Role) (_ []models.RoleChangeResult, err error) {
+	defer repo.observe("UnsetOrgRoleBulkContext", &err)
+	return repo.unsetOrgRoleBulk(ctx, userGUIDs, orgGUID, role)
+}
+
+func (repo CloudControllerUserRepository) unsetOrgRoleBulk(ctx context.Context, userGUIDs []string, orgGUID string, role models.Role) (_ []models.RoleChangeResult, err error) {
+	repo = repo.forMutation()
+
+	if _, err = rolePath(role); err != nil {
+		return nil, err
+	}
+
+	results := make([]models.RoleChangeResult, 0, len(userGUIDs))
+	for _, userGUID := range userGUIDs {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		result := models.RoleChangeResult{UserGUID: userGUID, Changed: true}
+		job, unsetErr := repo.unsetOrgRoleByGUIDAsync(userGUID, orgGUID, role)
+		if unsetErr == nil {
+			unsetErr = repo.waitForRoleJob(job)
+		}
+		if httpErr, ok := unsetErr.(errors.HTTPError); ok && httpErr.StatusCode() == http.StatusNotFound {
+			result.Changed = false
+			unsetErr = nil
+		}
+		result.Err = unsetErr
+		if unsetErr != nil {
+			result.Changed = false
+		}
+		results = append(results, result)
 	}
-	return repo.callAPI("DELETE", path, nil)
+	return results, nil
 }
 
-func (repo CloudControllerUserRepository) UnsetOrgRoleByUsername(username, orgGUID string, role models.Role) error {
+func (repo CloudControllerUserRepository) UnsetOrgRoleByUsername(username, orgGUID string, role models.Role) (err error) {
+	defer repo.observe("UnsetOrgRoleByUsername", &err)
+	repo = repo.forMutation()
+
 	rolePath, err := rolePath(role)
 	if err != nil {
 		return err
 	}
+	if repo.dryRun {
+		return nil
+	}
 
 	path := fmt.Sprintf("%s/v2/organizations/%s/%s", repo.config.APIEndpoint(), orgGUID, rolePath)
 
 	return repo.callAPI("DELETE", path, usernamePayload(username))
 }
 
-func (repo CloudControllerUserRepository) UnsetSpaceRoleByUsername(username, spaceGUID string, role models.Role) error {
-	rolePath := spaceRoleToPathMap[role]
-	path := fmt.Sprintf("%s/v2/spaces/%s/%s", repo.config.APIEndpoint(), spaceGUID, rolePath)
+func (repo CloudControllerUserRepository) UnsetSpaceRoleByUsername(username, spaceGUID string, role models.Role) (err error) {
+	defer repo.observe("UnsetSpaceRoleByUsername", &err)
+	repo = repo.forMutation()
+
+	rolePath, err := repo.checkSpaceRole(spaceGUID, role)
+	if err != nil {
+		return err
+	}
+	if repo.dryRun {
+		return nil
+	}
+	path := repo.config.APIEndpoint() + rolePath
 
 	return repo.callAPI("DELETE", path, usernamePayload(username))
 }
 
-func (repo CloudControllerUserRepository) SetOrgRoleByUsername(username string, orgGUID string, role models.Role) error {
+func (repo CloudControllerUserRepository) SetOrgRoleByUsername(username string, orgGUID string, role models.Role) (err error) {
+	defer repo.observe("SetOrgRoleByUsername", &err)
+	repo = repo.forMutation()
+
 	rolePath, err := rolePath(role)
 	if err != nil {
 		return err
 	}
+	if repo.dryRun {
+		return nil
+	}
+	if !repo.config.IsMinAPIVersion(cf.SetRolesByUsernameMinimumAPIVersion) {
+		user, err := repo.findByUsername(context.Background(), username)
+		if err != nil {
+			return err
+		}
+		job, err := repo.setOrgRoleByGUIDAsync(user.GUID, orgGUID, role)
+		if err != nil {
+			return err
+		}
+		return repo.waitForRoleJob(job)
+	}
 
 	path := fmt.Sprintf("%s/v2/organizations/%s/%s", repo.config.APIEndpoint(), orgGUID, rolePath)
 	err = repo.callAPI("PUT", path, usernamePayload(username))
@@ -300,15 +2949,119 @@ func (repo CloudControllerUserRepository) SetOrgRoleByUsername(username string,
 }
 
 func (repo CloudControllerUserRepository) callAPI(verb, path string, body io.ReadSeeker) (err error) {
-	request, err := repo.ccGateway.NewRequest(verb, path, repo.config.AccessToken(), body)
+	job, err := repo.startRoleChange(verb, path, body)
 	if err != nil {
 		return
 	}
-	_, err = repo.ccGateway.PerformRequest(request)
+	return repo.waitForRoleJob(job)
+}
+
+// startRoleChange performs a role change request and, when the Cloud
+// Controller accepts it as a background job, returns a handle on that job.
+func (repo CloudControllerUserRepository) startRoleChange(verb, path string, body io.ReadSeeker) (RoleJob, error) {
+	request, err := repo.ccGateway.NewRequest(verb, path, repo.config.AccessToken(), body)
 	if err != nil {
-		return
+		return RoleJob{}, err
 	}
-	return
+	response, err := repo.ccGateway.PerformRequest(request)
+	if err != nil {
+		return RoleJob{}, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusAccepted {
+		return RoleJob{}, nil
+	}
+
+	asyncResource := net.AsyncResource{}
+	if json.NewDecoder(response.Body).Decode(&asyncResource) != nil {
+		return RoleJob{}, nil
+	}
+	if !strings.Contains(asyncResource.Metadata.URL, "/jobs/") {
+		return RoleJob{}, nil
+	}
+	return RoleJob{URL: asyncResource.Metadata.URL}, nil
+}
+
+func (repo CloudControllerUserRepository) WaitForRoleJob(job RoleJob) (err error) {
+	defer repo.observe("WaitForRoleJob", &err)
+	return repo.waitForRoleJob(job)
+}
+
+func (repo CloudControllerUserRepository) waitForRoleJob(job RoleJob) error {
+	if !job.Pending() {
+		return nil
+	}
+
+	timeout := repo.jobTimeout
+	if timeout == 0 {
+		timeout = repo.ccGateway.AsyncTimeout()
+	}
+	return repo.ccGateway.WaitForJob(repo.config.APIEndpoint(), job.URL, timeout)
+}
+
+// PreviewOrgRoleChange returns the requests that setting, or with set false
+// unsetting, the org role would send, without sending them. The user is
+// addressed by GUID when userGUID is given and by username otherwise, as the
+// ByGUID and ByUsername methods do.
+func (repo CloudControllerUserRepository) PreviewOrgRoleChange(userGUID, username, orgGUID string, role models.Role, set bool) ([]models.RequestPreview, error) {
+	rolePath, err := rolePath(role)
+	if err != nil {
+		return nil, err
+	}
+
+	orgURL := fmt.Sprintf("%s/v2/organizations/%s", repo.config.APIEndpoint(), orgGUID)
+	if userGUID != "" {
+		roleRequest := models.RequestPreview{URL: fmt.Sprintf("%s/%s/%s", orgURL, rolePath, userGUID)}
+		if !set {
+			roleRequest.Method = "DELETE"
+			return []models.RequestPreview{roleRequest}, nil
+		}
+		roleRequest.Method = "PUT"
+		return []models.RequestPreview{
+			roleRequest,
+			{Method: "PUT", URL: fmt.Sprintf("%s/users/%s", orgURL, userGUID)},
+		}, nil
+	}
+
+	body := usernameBody(username)
+	roleRequest := models.RequestPreview{URL: fmt.Sprintf("%s/%s", orgURL, rolePath), Body: body}
+	if !set {
+		roleRequest.Method = "DELETE"
+		return []models.RequestPreview{roleRequest}, nil
+	}
+	roleRequest.Method = "PUT"
+	return []models.RequestPreview{
+		roleRequest,
+		{Method: "PUT", URL: orgURL + "/users", Body: body},
+	}, nil
+}
+
+// PreviewSpaceRoleChange is PreviewOrgRoleChange for space roles. Setting a
+// space role first makes the user a member of the space's org.
+func (repo CloudControllerUserRepository) PreviewSpaceRoleChange(userGUID, username, spaceGUID, orgGUID string, role models.Role, set bool) ([]models.RequestPreview, error) {
+	rolePath, err := repo.checkSpaceRole(spaceGUID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	orgUsersURL := fmt.Sprintf("%s/v2/organizations/%s/users", repo.config.APIEndpoint(), orgGUID)
+	roleRequest := models.RequestPreview{URL: repo.config.APIEndpoint() + rolePath}
+	orgRequest := models.RequestPreview{Method: "PUT", URL: orgUsersURL}
+	if userGUID != "" {
+		roleRequest.URL += "/" + userGUID
+		orgRequest.URL += "/" + userGUID
+	} else {
+		roleRequest.Body = usernameBody(username)
+		orgRequest.Body = roleRequest.Body
+	}
+
+	if !set {
+		roleRequest.Method = "DELETE"
+		return []models.RequestPreview{roleRequest}, nil
+	}
+	roleRequest.Method = "PUT"
+	return []models.RequestPreview{orgRequest, roleRequest}, nil
 }
 
 func userGUIDPath(apiEndpoint, userGUID, orgGUID string, role models.Role) (string, error) {
@@ -320,27 +3073,110 @@ func userGUIDPath(apiEndpoint, userGUID, orgGUID string, role models.Role) (stri
 	return fmt.Sprintf("%s/v2/organizations/%s/%s/%s", apiEndpoint, orgGUID, rolePath, userGUID), nil
 }
 
-func (repo CloudControllerUserRepository) SetSpaceRoleByGUID(userGUID, spaceGUID, orgGUID string, role models.Role) error {
+func (repo CloudControllerUserRepository) SetSpaceRoleByGUID(userGUID, spaceGUID, orgGUID string, role models.Role) (err error) {
+	defer repo.observe("SetSpaceRoleByGUID", &err)
+	repo = repo.forMutation()
+
+	job, err := repo.setSpaceRoleByGUIDAsync(userGUID, spaceGUID, orgGUID, role, "")
+	if err != nil {
+		return err
+	}
+	return repo.waitForRoleJob(job)
+}
+
+func (repo CloudControllerUserRepository) SetSpaceRoleByGUIDAsync(userGUID, spaceGUID, orgGUID string, role models.Role) (job RoleJob, err error) {
+	defer repo.observe("SetSpaceRoleByGUIDAsync", &err)
+	repo = repo.forMutation()
+	return repo.setSpaceRoleByGUIDAsync(userGUID, spaceGUID, orgGUID, role, "")
+}
+
+// SpaceRoleETag reads the ETag of the list of the space's role holders, to
+// be passed to SetSpaceRoleByGUIDIfMatch. It is empty when the Cloud
+// Controller sends none.
+func (repo CloudControllerUserRepository) SpaceRoleETag(spaceGUID string, role models.Role) (etag string, err error) {
+	defer repo.observe("SpaceRoleETag", &err)
+
 	rolePath, found := spaceRoleToPathMap[role]
 	if !found {
-		return fmt.Errorf(T("Invalid Role {{.Role}}", map[string]interface{}{"Role": role}))
+		return "", errors.NewInvalidInputError(T("Invalid Role {{.Role}}", map[string]interface{}{"Role": role}))
 	}
 
-	err := repo.assocUserWithOrgByUserGUID(userGUID, orgGUID)
+	request, err := repo.ccGateway.NewRequest("GET", repo.config.APIEndpoint()+repo.spaceRoleURL(spaceGUID, rolePath), repo.config.AccessToken(), nil)
+	if err != nil {
+		return "", err
+	}
+	response, err := repo.ccGateway.PerformRequest(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	return response.Header.Get("ETag"), nil
+}
+
+// SetSpaceRoleByGUIDIfMatch is SetSpaceRoleByGUID sending etag, from
+// SpaceRoleETag, in If-Match on the space role request. When the role's
+// holders have changed since, it fails with an
+// errors.HTTPPreconditionFailedError and the role is not set. An empty etag
+// makes it the same as SetSpaceRoleByGUID.
+func (repo CloudControllerUserRepository) SetSpaceRoleByGUIDIfMatch(userGUID, spaceGUID, orgGUID string, role models.Role, etag string) (err error) {
+	defer repo.observe("SetSpaceRoleByGUIDIfMatch", &err)
+	repo = repo.forMutation()
+
+	job, err := repo.setSpaceRoleByGUIDAsync(userGUID, spaceGUID, orgGUID, role, etag)
 	if err != nil {
 		return err
 	}
+	return repo.waitForRoleJob(job)
+}
+
+// setSpaceRoleByGUIDAsync starts giving the user role in the space, sending
+// a non-empty etag in If-Match on the space role request.
+func (repo CloudControllerUserRepository) setSpaceRoleByGUIDAsync(userGUID, spaceGUID, orgGUID string, role models.Role, etag string) (RoleJob, error) {
+	rolePath, found := spaceRoleToPathMap[role]
+	if !found {
+		return RoleJob{}, errors.NewInvalidInputError(T("Invalid Role {{.Role}}", map[string]interface{}{"Role": role}))
+	}
+	if repo.dryRun {
+		return RoleJob{}, nil
+	}
+
+	err := repo.assocUserWithOrgByUserGUID(userGUID, orgGUID)
+	if err != nil {
+		return RoleJob{}, err
+	}
 
-	path := fmt.Sprintf("/v2/spaces/%s/%s/%s", spaceGUID, rolePath, userGUID)
+	path := fmt.Sprintf("%s%s/%s", repo.config.APIEndpoint(), repo.spaceRoleURL(spaceGUID, rolePath), userGUID)
 
-	return repo.ccGateway.UpdateResource(repo.config.APIEndpoint(), path, nil)
+	// Only the space role request is conditional; the org association made
+	// above is not covered by the ETag.
+	if etag != "" {
+		repo.ccGateway = repo.ccGateway.WithHeader("If-Match", etag)
+	}
+	return repo.startRoleChange("PUT", path, nil)
 }
 
 func (repo CloudControllerUserRepository) SetSpaceRoleByUsername(username, spaceGUID, orgGUID string, role models.Role) (apiErr error) {
+	defer repo.observe("SetSpaceRoleByUsername", &apiErr)
+	repo = repo.forMutation()
+
 	rolePath, apiErr := repo.checkSpaceRole(spaceGUID, role)
 	if apiErr != nil {
 		return
 	}
+	if repo.dryRun {
+		return
+	}
+	if !repo.config.IsMinAPIVersion(cf.SetRolesByUsernameMinimumAPIVersion) {
+		user, err := repo.findByUsername(context.Background(), username)
+		if err != nil {
+			return err
+		}
+		job, err := repo.setSpaceRoleByGUIDAsync(user.GUID, spaceGUID, orgGUID, role, "")
+		if err != nil {
+			return err
+		}
+		return repo.waitForRoleJob(job)
+	}
 
 	setOrgRoleErr := apiErrResponse{}
 	apiErr = repo.assocUserWithOrgByUsername(username, orgGUID, &setOrgRoleErr)
@@ -351,47 +3187,224 @@ func (repo CloudControllerUserRepository) SetSpaceRoleByUsername(username, space
 		return
 	}
 
+	return repo.putSpaceRoleByUsername(username, rolePath)
+}
+
+// putSpaceRoleByUsername gives the user the space role at rolePath, once
+// they are a member of the space's org.
+func (repo CloudControllerUserRepository) putSpaceRoleByUsername(username, rolePath string) error {
 	setSpaceRoleErr := apiErrResponse{}
-	apiErr = repo.ccGateway.UpdateResourceSync(repo.config.APIEndpoint(), rolePath, usernamePayload(username), &setSpaceRoleErr)
+	err := repo.ccGateway.UpdateResourceSync(repo.config.APIEndpoint(), rolePath, usernamePayload(username), &setSpaceRoleErr)
 	if setSpaceRoleErr.Code == 1002 {
 		return errors.New(T("Server error, error code: 1002, message: cannot set space role because user is not part of the org"))
 	}
 
-	return apiErr
+	return err
+}
+
+// SetSpaceRolesByGUID gives the user each of roles in the space, making them
+// a member of the org only once. Every role is attempted even when an earlier
+// one fails; the results give the outcome of each, in order. The error is
+// only for failures before any role is set.
+func (repo CloudControllerUserRepository) SetSpaceRolesByGUID(userGUID, spaceGUID, orgGUID string, roles []models.Role) (_ []models.RoleChangeResult, err error) {
+	defer repo.observe("SetSpaceRolesByGUID", &err)
+	repo = repo.forMutation()
+
+	rolePaths, err := repo.checkSpaceRoles(spaceGUID, roles)
+	if err != nil || repo.dryRun {
+		return nil, err
+	}
+	return repo.setSpaceRolesByGUID(userGUID, orgGUID, roles, rolePaths)
+}
+
+func (repo CloudControllerUserRepository) setSpaceRolesByGUID(userGUID, orgGUID string, roles []models.Role, rolePaths []string) ([]models.RoleChangeResult, error) {
+	err := repo.assocUserWithOrgByUserGUID(userGUID, orgGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.RoleChangeResult, len(roles))
+	for i, role := range roles {
+		job, roleErr := repo.startRoleChange("PUT", fmt.Sprintf("%s%s/%s", repo.config.APIEndpoint(), rolePaths[i], userGUID), nil)
+		if roleErr == nil {
+			roleErr = repo.waitForRoleJob(job)
+		}
+		results[i] = models.RoleChangeResult{UserGUID: userGUID, Role: role, Changed: roleErr == nil, Err: roleErr}
+	}
+	return results, nil
+}
+
+// SetSpaceRolesByUsername is SetSpaceRolesByGUID for a user given by name.
+func (repo CloudControllerUserRepository) SetSpaceRolesByUsername(username, spaceGUID, orgGUID string, roles []models.Role) (_ []models.RoleChangeResult, err error) {
+	defer repo.observe("SetSpaceRolesByUsername", &err)
+	repo = repo.forMutation()
+
+	rolePaths, err := repo.checkSpaceRoles(spaceGUID, roles)
+	if err != nil || repo.dryRun {
+		return nil, err
+	}
+	if !repo.config.IsMinAPIVersion(cf.SetRolesByUsernameMinimumAPIVersion) {
+		user, err := repo.findByUsername(context.Background(), username)
+		if err != nil {
+			return nil, err
+		}
+		return repo.setSpaceRolesByGUID(user.GUID, orgGUID, roles, rolePaths)
+	}
+
+	setOrgRoleErr := apiErrResponse{}
+	err = repo.assocUserWithOrgByUsername(username, orgGUID, &setOrgRoleErr)
+	if err != nil && setOrgRoleErr.Code != 10003 {
+		return nil, err
+	}
+
+	results := make([]models.RoleChangeResult, len(roles))
+	for i, role := range roles {
+		roleErr := repo.putSpaceRoleByUsername(username, rolePaths[i])
+		results[i] = models.RoleChangeResult{Role: role, Changed: roleErr == nil, Err: roleErr}
+	}
+	return results, nil
+}
+
+func (repo CloudControllerUserRepository) UnsetSpaceRoleByGUID(userGUID, spaceGUID string, role models.Role) (err error) {
+	defer repo.observe("UnsetSpaceRoleByGUID", &err)
+	repo = repo.forMutation()
+
+	job, err := repo.unsetSpaceRoleByGUIDAsync(userGUID, spaceGUID, role)
+	if err != nil {
+		return err
+	}
+	return repo.waitForRoleJob(job)
 }
 
-func (repo CloudControllerUserRepository) UnsetSpaceRoleByGUID(userGUID, spaceGUID string, role models.Role) error {
+func (repo CloudControllerUserRepository) UnsetSpaceRoleByGUIDAsync(userGUID, spaceGUID string, role models.Role) (job RoleJob, err error) {
+	defer repo.observe("UnsetSpaceRoleByGUIDAsync", &err)
+	repo = repo.forMutation()
+	return repo.unsetSpaceRoleByGUIDAsync(userGUID, spaceGUID, role)
+}
+
+func (repo CloudControllerUserRepository) unsetSpaceRoleByGUIDAsync(userGUID, spaceGUID string, role models.Role) (RoleJob, error) {
 	rolePath, found := spaceRoleToPathMap[role]
 	if !found {
-		return fmt.Errorf(T("Invalid Role {{.Role}}", map[string]interface{}{"Role": role}))
+		return RoleJob{}, errors.NewInvalidInputError(T("Invalid Role {{.Role}}", map[string]interface{}{"Role": role}))
 	}
-	apiURL := fmt.Sprintf("/v2/spaces/%s/%s/%s", spaceGUID, rolePath, userGUID)
+	if repo.dryRun {
+		return RoleJob{}, nil
+	}
+	apiURL := fmt.Sprintf("%s%s/%s", repo.config.APIEndpoint(), repo.spaceRoleURL(spaceGUID, rolePath), userGUID)
 
-	return repo.ccGateway.DeleteResource(repo.config.APIEndpoint(), apiURL)
+	return repo.startRoleChange("DELETE", apiURL, nil)
 }
 
+// checkSpaceRole returns the space role path for role, or an empty path and
+// an error when role is not a space role.
 func (repo CloudControllerUserRepository) checkSpaceRole(spaceGUID string, role models.Role) (string, error) {
-	var apiErr error
-
 	rolePath, found := spaceRoleToPathMap[role]
-
 	if !found {
-		apiErr = fmt.Errorf(T("Invalid Role {{.Role}}",
+		return "", errors.NewInvalidInputError(T("Invalid Role {{.Role}}",
 			map[string]interface{}{"Role": role}))
 	}
 
-	apiPath := fmt.Sprintf("/v2/spaces/%s/%s", spaceGUID, rolePath)
-	return apiPath, apiErr
+	return repo.spaceRoleURL(spaceGUID, rolePath), nil
+}
+
+// checkSpaceRoles is checkSpaceRole for each of roles, failing on the first
+// that is not a space role.
+func (repo CloudControllerUserRepository) checkSpaceRoles(spaceGUID string, roles []models.Role) ([]string, error) {
+	rolePaths := make([]string, len(roles))
+	for i, role := range roles {
+		rolePath, err := repo.checkSpaceRole(spaceGUID, role)
+		if err != nil {
+			return nil, err
+		}
+		rolePaths[i] = rolePath
+	}
+	return rolePaths, nil
 }
 
 func (repo CloudControllerUserRepository) assocUserWithOrgByUsername(username, orgGUID string, resource interface{}) (apiErr error) {
 	path := fmt.Sprintf("/v2/organizations/%s/users", orgGUID)
-	return repo.ccGateway.UpdateResourceSync(repo.config.APIEndpoint(), path, usernamePayload(username), resource)
+	return ignoreAlreadyAssociated(repo.ccGateway.UpdateResourceSync(repo.config.APIEndpoint(), path, usernamePayload(username), resource))
 }
 
 func (repo CloudControllerUserRepository) assocUserWithOrgByUserGUID(userGUID, orgGUID string) (apiErr error) {
 	path := fmt.Sprintf("/v2/organizations/%s/users/%s", orgGUID, userGUID)
-	return repo.ccGateway.UpdateResource(repo.config.APIEndpoint(), path, nil)
+	return ignoreAlreadyAssociated(repo.ccGateway.UpdateResource(repo.config.APIEndpoint(), path, nil))
+}
+
+// alreadyAssociatedPattern is the description of the 422 UnprocessableEntity
+// a Cloud Controller returns when the user already has the org user role.
+// The Cloud Controller has no error code of its own for it.
+var alreadyAssociatedPattern = regexp.MustCompile(`^User '[^']*' already has 'organization_user' role in organization '[^']*'\.$`)
+
+// ignoreAlreadyAssociated treats the error stricter Cloud Controllers return
+// for a user who is already in the org as success, so setting a role twice
+// succeeds both times. Any other failure, including other
+// UnprocessableEntity errors, is returned.
+func ignoreAlreadyAssociated(err error) error {
+	httpErr, ok := err.(errors.HTTPError)
+	if ok && httpErr.StatusCode() == http.StatusUnprocessableEntity &&
+		httpErr.ErrorCode() == errors.UnprocessableEntity &&
+		alreadyAssociatedPattern.MatchString(httpErr.Description()) {
+		return nil
+	}
+	return err
+}
+
+func (repo CloudControllerUserRepository) forMutation() CloudControllerUserRepository {
+	if repo.verboseErrors {
+		repo.ccGateway.RawErrorResponses = true
+		repo.uaaGateway.RawErrorResponses = true
+	}
+	if repo.onBehalfOf != "" {
+		repo.ccGateway = repo.ccGateway.WithHeader("X-On-Behalf-Of", repo.onBehalfOf)
+		repo.uaaGateway = repo.uaaGateway.WithHeader("X-On-Behalf-Of", repo.onBehalfOf)
+	}
+	return repo
+}
+
+func (repo CloudControllerUserRepository) transformUser(user models.UserFields) models.UserFields {
+	if repo.transform == nil {
+		return user
+	}
+	return repo.transform(user)
+}
+
+func (repo CloudControllerUserRepository) transformUsers(users *[]models.UserFields) {
+	for i := range *users {
+		(*users)[i] = repo.transformUser((*users)[i])
+	}
+}
+
+// sortUsers orders users case-insensitively by username unless the
+// repository preserves server order.
+func (repo CloudControllerUserRepository) sortUsers(users *[]models.UserFields) {
+	if repo.serverOrder {
+		return
+	}
+	sort.SliceStable(*users, func(i, j int) bool {
+		return strings.ToLower((*users)[i].Username) < strings.ToLower((*users)[j].Username)
+	})
+}
+
+func (repo CloudControllerUserRepository) observe(method string, err *error) {
+	outcome := "success"
+	if *err != nil && !isWarning(*err) {
+		outcome = "failure"
+	}
+	repo.metrics.IncrementCounter("operations_total", map[string]string{
+		"method":  method,
+		"outcome": outcome,
+	})
+}
+
+func (repo CloudControllerUserRepository) countUAACall() {
+	repo.metrics.IncrementCounter("uaa_calls_total", nil)
+}
+
+// scimString quotes value for use in a SCIM filter, backslash-escaping any
+// backslashes and double quotes in it as the SCIM spec requires.
+func scimString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
 }
 
 func (repo CloudControllerUserRepository) getAuthEndpoint() (string, error) {
@@ -402,16 +3415,30 @@ func (repo CloudControllerUserRepository) getAuthEndpoint() (string, error) {
 	return uaaEndpoint, nil
 }
 
+// normalizeEmail checks that email is a single bare address, such as
+// "user@example.com", and returns it without surrounding whitespace.
+func normalizeEmail(email string) (string, error) {
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil || address.Name != "" || address.Address != strings.TrimSpace(email) {
+		return "", errors.New(T("Invalid email address {{.Email}}", map[string]interface{}{"Email": email}))
+	}
+	return address.Address, nil
+}
+
 func rolePath(role models.Role) (string, error) {
 	path, found := orgRoleToPathMap[role]
 
 	if !found {
-		return "", fmt.Errorf(T("Invalid Role {{.Role}}",
+		return "", errors.NewInvalidInputError(T("Invalid Role {{.Role}}",
 			map[string]interface{}{"Role": role}))
 	}
 	return path, nil
 }
 
 func usernamePayload(username string) *strings.Reader {
-	return strings.NewReader(`{"username": "` + username + `"}`)
+	return strings.NewReader(usernameBody(username))
+}
+
+func usernameBody(username string) string {
+	return `{"username": "` + username + `"}`
 }
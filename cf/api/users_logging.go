@@ -0,0 +1,455 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/cli/cf/models"
+)
+
+// redacted stands in for passwords in logged arguments.
+const redacted = "[REDACTED]"
+
+// LoggingUserRepository is a UserRepository that writes a line of JSON for
+// every call to the repository it wraps, giving the method, its arguments and
+// the error it returned. Passwords are logged as [REDACTED]; contexts,
+// writers and callbacks are left out. Return values are passed through
+// untouched.
+type LoggingUserRepository struct {
+	repo UserRepository
+	out  io.Writer
+	mu   *sync.Mutex
+}
+
+// NewLoggingUserRepository logs the calls made to repo to out.
+func NewLoggingUserRepository(repo UserRepository, out io.Writer) LoggingUserRepository {
+	return LoggingUserRepository{repo: repo, out: out, mu: new(sync.Mutex)}
+}
+
+type repositoryCall struct {
+	Time       string                 `json:"time"`
+	Repository string                 `json:"repository"`
+	Method     string                 `json:"method"`
+	Args       map[string]interface{} `json:"args,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	Warning    string                 `json:"warning,omitempty"`
+}
+
+func (repo LoggingUserRepository) log(method string, args map[string]interface{}, err error) {
+	call := repositoryCall{
+		Time:       time.Now().UTC().Format(time.RFC3339Nano),
+		Repository: "UserRepository",
+		Method:     method,
+		Args:       args,
+	}
+	if isWarning(err) {
+		call.Warning = err.Error()
+	} else if err != nil {
+		call.Error = err.Error()
+	}
+
+	line, marshalErr := json.Marshal(call)
+	if marshalErr != nil {
+		return
+	}
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	_, _ = repo.out.Write(append(line, '\n'))
+}
+
+func roleNames(roles []models.Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.ToString())
+	}
+	return names
+}
+
+func createRequestUsernames(requests []models.UserCreateRequest) []string {
+	usernames := make([]string, 0, len(requests))
+	for _, request := range requests {
+		usernames = append(usernames, request.Username)
+	}
+	return usernames
+}
+
+func (repo LoggingUserRepository) FindByUsername(username string) (models.UserFields, error) {
+	user, err := repo.repo.FindByUsername(username)
+	repo.log("FindByUsername", map[string]interface{}{"username": username}, err)
+	return user, err
+}
+
+func (repo LoggingUserRepository) FindByEmail(email string) (models.UserFields, error) {
+	user, err := repo.repo.FindByEmail(email)
+	repo.log("FindByEmail", map[string]interface{}{"email": email}, err)
+	return user, err
+}
+
+func (repo LoggingUserRepository) FindByUsernameContext(ctx context.Context, username string) (models.UserFields, error) {
+	user, err := repo.repo.FindByUsernameContext(ctx, username)
+	repo.log("FindByUsernameContext", map[string]interface{}{"username": username}, err)
+	return user, err
+}
+
+func (repo LoggingUserRepository) FindAllByUsername(username string) ([]models.UserFields, error) {
+	users, err := repo.repo.FindAllByUsername(username)
+	repo.log("FindAllByUsername", map[string]interface{}{"username": username}, err)
+	return users, err
+}
+
+func (repo LoggingUserRepository) FindAllByUsernameContext(ctx context.Context, username string) ([]models.UserFields, error) {
+	users, err := repo.repo.FindAllByUsernameContext(ctx, username)
+	repo.log("FindAllByUsernameContext", map[string]interface{}{"username": username}, err)
+	return users, err
+}
+
+func (repo LoggingUserRepository) FindAllByUsernamePrefix(prefix string) ([]models.UserFields, error) {
+	users, err := repo.repo.FindAllByUsernamePrefix(prefix)
+	repo.log("FindAllByUsernamePrefix", map[string]interface{}{"prefix": prefix}, err)
+	return users, err
+}
+
+func (repo LoggingUserRepository) FindByUsernames(usernames []string) (map[string]models.UserFields, error) {
+	result, err := repo.repo.FindByUsernames(usernames)
+	repo.log("FindByUsernames", map[string]interface{}{"usernames": usernames}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) IsUsernameAvailable(username string) (bool, error) {
+	available, err := repo.repo.IsUsernameAvailable(username)
+	repo.log("IsUsernameAvailable", map[string]interface{}{"username": username}, err)
+	return available, err
+}
+
+func (repo LoggingUserRepository) VerifyCredentials(username string, password string) (bool, error) {
+	valid, err := repo.repo.VerifyCredentials(username, password)
+	repo.log("VerifyCredentials", map[string]interface{}{"username": username, "password": redacted}, err)
+	return valid, err
+}
+
+func (repo LoggingUserRepository) ListUsersInOrgForRole(orgGUID string, role models.Role) ([]models.UserFields, error) {
+	users, err := repo.repo.ListUsersInOrgForRole(orgGUID, role)
+	repo.log("ListUsersInOrgForRole", map[string]interface{}{"orgGUID": orgGUID, "role": role.ToString()}, err)
+	return users, err
+}
+
+func (repo LoggingUserRepository) ListUsersInOrgForRoleContext(ctx context.Context, orgGUID string, role models.Role) ([]models.UserFields, error) {
+	users, err := repo.repo.ListUsersInOrgForRoleContext(ctx, orgGUID, role)
+	repo.log("ListUsersInOrgForRoleContext", map[string]interface{}{"orgGUID": orgGUID, "role": role.ToString()}, err)
+	return users, err
+}
+
+func (repo LoggingUserRepository) CountUsersInOrgForRole(orgGUID string, role models.Role) (int, error) {
+	count, err := repo.repo.CountUsersInOrgForRole(orgGUID, role)
+	repo.log("CountUsersInOrgForRole", map[string]interface{}{"orgGUID": orgGUID, "role": role.ToString()}, err)
+	return count, err
+}
+
+func (repo LoggingUserRepository) ListUsersInOrgForRoleFiltered(orgGUID string, role models.Role, usernameContains string) ([]models.UserFields, error) {
+	users, err := repo.repo.ListUsersInOrgForRoleFiltered(orgGUID, role, usernameContains)
+	repo.log("ListUsersInOrgForRoleFiltered", map[string]interface{}{"orgGUID": orgGUID, "role": role.ToString(), "usernameContains": usernameContains}, err)
+	return users, err
+}
+
+func (repo LoggingUserRepository) ListAllUsersInOrg(orgGUID string) (map[models.Role][]models.UserFields, error) {
+	result, err := repo.repo.ListAllUsersInOrg(orgGUID)
+	repo.log("ListAllUsersInOrg", map[string]interface{}{"orgGUID": orgGUID}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) ListUsersInOrgForRoles(orgGUID string, roles []models.Role) (map[models.Role][]models.UserFields, error) {
+	result, err := repo.repo.ListUsersInOrgForRoles(orgGUID, roles)
+	repo.log("ListUsersInOrgForRoles", map[string]interface{}{"orgGUID": orgGUID, "roles": roleNames(roles)}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) ListUsersInSpaceForAllRoles(spaceGUID string) (map[models.Role][]models.UserFields, error) {
+	result, err := repo.repo.ListUsersInSpaceForAllRoles(spaceGUID)
+	repo.log("ListUsersInSpaceForAllRoles", map[string]interface{}{"spaceGUID": spaceGUID}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) ListUsersInOrgForRoleWithNoUAA(orgGUID string, role models.Role) ([]models.UserFields, error) {
+	users, err := repo.repo.ListUsersInOrgForRoleWithNoUAA(orgGUID, role)
+	repo.log("ListUsersInOrgForRoleWithNoUAA", map[string]interface{}{"orgGUID": orgGUID, "role": role.ToString()}, err)
+	return users, err
+}
+
+func (repo LoggingUserRepository) ListInactiveOrgUsers(orgGUID string, inactiveFor time.Duration) ([]models.UserFields, error) {
+	users, err := repo.repo.ListInactiveOrgUsers(orgGUID, inactiveFor)
+	repo.log("ListInactiveOrgUsers", map[string]interface{}{"orgGUID": orgGUID, "inactiveFor": inactiveFor.String()}, err)
+	return users, err
+}
+
+func (repo LoggingUserRepository) ListUsersInSpaceForRoleWithNoUAA(spaceGUID string, role models.Role) ([]models.UserFields, error) {
+	users, err := repo.repo.ListUsersInSpaceForRoleWithNoUAA(spaceGUID, role)
+	repo.log("ListUsersInSpaceForRoleWithNoUAA", map[string]interface{}{"spaceGUID": spaceGUID, "role": role.ToString()}, err)
+	return users, err
+}
+
+func (repo LoggingUserRepository) ListOrgUsersWithSpaceRoles(orgGUID string) ([]models.OrgUserWithSpaceRoles, error) {
+	result, err := repo.repo.ListOrgUsersWithSpaceRoles(orgGUID)
+	repo.log("ListOrgUsersWithSpaceRoles", map[string]interface{}{"orgGUID": orgGUID}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) FindDuplicateCCRegistrations() ([]models.DuplicateUserRegistration, error) {
+	result, err := repo.repo.FindDuplicateCCRegistrations()
+	repo.log("FindDuplicateCCRegistrations", nil, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) FindUnmatchedUsers() (models.UnmatchedUsers, error) {
+	result, err := repo.repo.FindUnmatchedUsers()
+	repo.log("FindUnmatchedUsers", nil, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) FindRolelessUsers() ([]models.UserFields, error) {
+	users, err := repo.repo.FindRolelessUsers()
+	repo.log("FindRolelessUsers", nil, err)
+	return users, err
+}
+
+func (repo LoggingUserRepository) GetUserAccessSummary(userGUID string, resolveNames bool) (models.UserAccessSummary, error) {
+	result, err := repo.repo.GetUserAccessSummary(userGUID, resolveNames)
+	repo.log("GetUserAccessSummary", map[string]interface{}{"userGUID": userGUID, "resolveNames": resolveNames}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) GetUserMemberships(userGUID string, orgGUID string) (models.UserMemberships, error) {
+	result, err := repo.repo.GetUserMemberships(userGUID, orgGUID)
+	repo.log("GetUserMemberships", map[string]interface{}{"userGUID": userGUID, "orgGUID": orgGUID}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) GetUserRolesInOrg(userGUID string, orgGUID string) ([]models.Role, error) {
+	result, err := repo.repo.GetUserRolesInOrg(userGUID, orgGUID)
+	repo.log("GetUserRolesInOrg", map[string]interface{}{"userGUID": userGUID, "orgGUID": orgGUID}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) CopyRoles(fromUserGUID string, toUserGUID string, orgGUID string) ([]models.RoleChangeResult, error) {
+	result, err := repo.repo.CopyRoles(fromUserGUID, toUserGUID, orgGUID)
+	repo.log("CopyRoles", map[string]interface{}{"fromUserGUID": fromUserGUID, "toUserGUID": toUserGUID, "orgGUID": orgGUID}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) CopySpaceRoles(fromUserGUID string, toUserGUID string, spaceGUID string, orgGUID string) ([]models.RoleChangeResult, error) {
+	result, err := repo.repo.CopySpaceRoles(fromUserGUID, toUserGUID, spaceGUID, orgGUID)
+	repo.log("CopySpaceRoles", map[string]interface{}{"fromUserGUID": fromUserGUID, "toUserGUID": toUserGUID, "spaceGUID": spaceGUID, "orgGUID": orgGUID}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) ExportUsersSCIM(w io.Writer) error {
+	err := repo.repo.ExportUsersSCIM(w)
+	repo.log("ExportUsersSCIM", nil, err)
+	return err
+}
+
+func (repo LoggingUserRepository) ExportFoundationRBAC(w io.Writer) error {
+	err := repo.repo.ExportFoundationRBAC(w)
+	repo.log("ExportFoundationRBAC", nil, err)
+	return err
+}
+
+func (repo LoggingUserRepository) EachRolelessUser(cb func(models.UserFields) bool) error {
+	err := repo.repo.EachRolelessUser(cb)
+	repo.log("EachRolelessUser", nil, err)
+	return err
+}
+
+func (repo LoggingUserRepository) ListAllUsers(cb func(models.UserFields) bool) error {
+	err := repo.repo.ListAllUsers(cb)
+	repo.log("ListAllUsers", nil, err)
+	return err
+}
+
+func (repo LoggingUserRepository) ListCFUsersInUAAGroup(groupName string) ([]models.UserFields, error) {
+	users, err := repo.repo.ListCFUsersInUAAGroup(groupName)
+	repo.log("ListCFUsersInUAAGroup", map[string]interface{}{"groupName": groupName}, err)
+	return users, err
+}
+
+func (repo LoggingUserRepository) DiffSpaceUsers(spaceAGUID string, spaceBGUID string) ([]models.SpaceRoleDiff, error) {
+	result, err := repo.repo.DiffSpaceUsers(spaceAGUID, spaceBGUID)
+	repo.log("DiffSpaceUsers", map[string]interface{}{"spaceAGUID": spaceAGUID, "spaceBGUID": spaceBGUID}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) Create(username string, password string) error {
+	err := repo.repo.Create(username, password)
+	repo.log("Create", map[string]interface{}{"username": username, "password": redacted}, err)
+	return err
+}
+
+func (repo LoggingUserRepository) CreateWithEmail(username string, password string, email string) error {
+	err := repo.repo.CreateWithEmail(username, password, email)
+	repo.log("CreateWithEmail", map[string]interface{}{"username": username, "password": redacted, "email": email}, err)
+	return err
+}
+
+func (repo LoggingUserRepository) CreateWithOrigin(username string, password string, origin string) error {
+	err := repo.repo.CreateWithOrigin(username, password, origin)
+	repo.log("CreateWithOrigin", map[string]interface{}{"username": username, "password": redacted, "origin": origin}, err)
+	return err
+}
+
+func (repo LoggingUserRepository) CreateBulk(users []models.UserCreateRequest) ([]models.UserCreateResult, error) {
+	result, err := repo.repo.CreateBulk(users)
+	repo.log("CreateBulk", map[string]interface{}{"users": createRequestUsernames(users)}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) Delete(userGUID string) error {
+	err := repo.repo.Delete(userGUID)
+	repo.log("Delete", map[string]interface{}{"userGUID": userGUID}, err)
+	return err
+}
+
+func (repo LoggingUserRepository) DeleteAsync(userGUID string) error {
+	err := repo.repo.DeleteAsync(userGUID)
+	repo.log("DeleteAsync", map[string]interface{}{"userGUID": userGUID}, err)
+	return err
+}
+
+func (repo LoggingUserRepository) RenameUser(userGUID string, newUsername string) error {
+	err := repo.repo.RenameUser(userGUID, newUsername)
+	repo.log("RenameUser", map[string]interface{}{"userGUID": userGUID, "newUsername": newUsername}, err)
+	return err
+}
+
+func (repo LoggingUserRepository) UpdatePassword(userGUID string, oldPassword string, newPassword string) error {
+	err := repo.repo.UpdatePassword(userGUID, oldPassword, newPassword)
+	repo.log("UpdatePassword", map[string]interface{}{"userGUID": userGUID, "oldPassword": redacted, "newPassword": redacted}, err)
+	return err
+}
+
+func (repo LoggingUserRepository) SetOrgRoleByGUID(userGUID string, orgGUID string, role models.Role) error {
+	err := repo.repo.SetOrgRoleByGUID(userGUID, orgGUID, role)
+	repo.log("SetOrgRoleByGUID", map[string]interface{}{"userGUID": userGUID, "orgGUID": orgGUID, "role": role.ToString()}, err)
+	return err
+}
+
+func (repo LoggingUserRepository) SetOrgRoleByUsername(username string, orgGUID string, role models.Role) error {
+	err := repo.repo.SetOrgRoleByUsername(username, orgGUID, role)
+	repo.log("SetOrgRoleByUsername", map[string]interface{}{"username": username, "orgGUID": orgGUID, "role": role.ToString()}, err)
+	return err
+}
+
+func (repo LoggingUserRepository) UnsetOrgRoleByGUID(userGUID string, orgGUID string, role models.Role) error {
+	err := repo.repo.UnsetOrgRoleByGUID(userGUID, orgGUID, role)
+	repo.log("UnsetOrgRoleByGUID", map[string]interface{}{"userGUID": userGUID, "orgGUID": orgGUID, "role": role.ToString()}, err)
+	return err
+}
+
+func (repo LoggingUserRepository) UnsetOrgRoleByUsername(username string, orgGUID string, role models.Role) error {
+	err := repo.repo.UnsetOrgRoleByUsername(username, orgGUID, role)
+	repo.log("UnsetOrgRoleByUsername", map[string]interface{}{"username": username, "orgGUID": orgGUID, "role": role.ToString()}, err)
+	return err
+}
+
+func (repo LoggingUserRepository) UnsetOrgRoleBulk(userGUIDs []string, orgGUID string, role models.Role) ([]models.RoleChangeResult, error) {
+	result, err := repo.repo.UnsetOrgRoleBulk(userGUIDs, orgGUID, role)
+	repo.log("UnsetOrgRoleBulk", map[string]interface{}{"userGUIDs": userGUIDs, "orgGUID": orgGUID, "role": role.ToString()}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) UnsetOrgRoleBulkContext(ctx context.Context, userGUIDs []string, orgGUID string, role models.Role) ([]models.RoleChangeResult, error) {
+	result, err := repo.repo.UnsetOrgRoleBulkContext(ctx, userGUIDs, orgGUID, role)
+	repo.log("UnsetOrgRoleBulkContext", map[string]interface{}{"userGUIDs": userGUIDs, "orgGUID": orgGUID, "role": role.ToString()}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) PreviewOrgRoleChange(userGUID string, username string, orgGUID string, role models.Role, set bool) ([]models.RequestPreview, error) {
+	result, err := repo.repo.PreviewOrgRoleChange(userGUID, username, orgGUID, role, set)
+	repo.log("PreviewOrgRoleChange", map[string]interface{}{"userGUID": userGUID, "username": username, "orgGUID": orgGUID, "role": role.ToString(), "set": set}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) PreviewSpaceRoleChange(userGUID string, username string, spaceGUID string, orgGUID string, role models.Role, set bool) ([]models.RequestPreview, error) {
+	result, err := repo.repo.PreviewSpaceRoleChange(userGUID, username, spaceGUID, orgGUID, role, set)
+	repo.log("PreviewSpaceRoleChange", map[string]interface{}{"userGUID": userGUID, "username": username, "spaceGUID": spaceGUID, "orgGUID": orgGUID, "role": role.ToString(), "set": set}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) SetSpaceRoleByGUID(userGUID string, spaceGUID string, orgGUID string, role models.Role) error {
+	err := repo.repo.SetSpaceRoleByGUID(userGUID, spaceGUID, orgGUID, role)
+	repo.log("SetSpaceRoleByGUID", map[string]interface{}{"userGUID": userGUID, "spaceGUID": spaceGUID, "orgGUID": orgGUID, "role": role.ToString()}, err)
+	return err
+}
+
+func (repo LoggingUserRepository) SetSpaceRoleByUsername(username string, spaceGUID string, orgGUID string, role models.Role) error {
+	err := repo.repo.SetSpaceRoleByUsername(username, spaceGUID, orgGUID, role)
+	repo.log("SetSpaceRoleByUsername", map[string]interface{}{"username": username, "spaceGUID": spaceGUID, "orgGUID": orgGUID, "role": role.ToString()}, err)
+	return err
+}
+
+func (repo LoggingUserRepository) SetSpaceRolesByGUID(userGUID string, spaceGUID string, orgGUID string, roles []models.Role) ([]models.RoleChangeResult, error) {
+	result, err := repo.repo.SetSpaceRolesByGUID(userGUID, spaceGUID, orgGUID, roles)
+	repo.log("SetSpaceRolesByGUID", map[string]interface{}{"userGUID": userGUID, "spaceGUID": spaceGUID, "orgGUID": orgGUID, "roles": roleNames(roles)}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) SetSpaceRolesByUsername(username string, spaceGUID string, orgGUID string, roles []models.Role) ([]models.RoleChangeResult, error) {
+	result, err := repo.repo.SetSpaceRolesByUsername(username, spaceGUID, orgGUID, roles)
+	repo.log("SetSpaceRolesByUsername", map[string]interface{}{"username": username, "spaceGUID": spaceGUID, "orgGUID": orgGUID, "roles": roleNames(roles)}, err)
+	return result, err
+}
+
+func (repo LoggingUserRepository) UnsetSpaceRoleByGUID(userGUID string, spaceGUID string, role models.Role) error {
+	err := repo.repo.UnsetSpaceRoleByGUID(userGUID, spaceGUID, role)
+	repo.log("UnsetSpaceRoleByGUID", map[string]interface{}{"userGUID": userGUID, "spaceGUID": spaceGUID, "role": role.ToString()}, err)
+	return err
+}
+
+func (repo LoggingUserRepository) UnsetSpaceRoleByUsername(userGUID string, spaceGUID string, role models.Role) error {
+	err := repo.repo.UnsetSpaceRoleByUsername(userGUID, spaceGUID, role)
+	repo.log("UnsetSpaceRoleByUsername", map[string]interface{}{"userGUID": userGUID, "spaceGUID": spaceGUID, "role": role.ToString()}, err)
+	return err
+}
+
+func (repo LoggingUserRepository) SetOrgRoleByGUIDAsync(userGUID string, orgGUID string, role models.Role) (RoleJob, error) {
+	job, err := repo.repo.SetOrgRoleByGUIDAsync(userGUID, orgGUID, role)
+	repo.log("SetOrgRoleByGUIDAsync", map[string]interface{}{"userGUID": userGUID, "orgGUID": orgGUID, "role": role.ToString()}, err)
+	return job, err
+}
+
+func (repo LoggingUserRepository) UnsetOrgRoleByGUIDAsync(userGUID string, orgGUID string, role models.Role) (RoleJob, error) {
+	job, err := repo.repo.UnsetOrgRoleByGUIDAsync(userGUID, orgGUID, role)
+	repo.log("UnsetOrgRoleByGUIDAsync", map[string]interface{}{"userGUID": userGUID, "orgGUID": orgGUID, "role": role.ToString()}, err)
+	return job, err
+}
+
+func (repo LoggingUserRepository) SetSpaceRoleByGUIDAsync(userGUID string, spaceGUID string, orgGUID string, role models.Role) (RoleJob, error) {
+	job, err := repo.repo.SetSpaceRoleByGUIDAsync(userGUID, spaceGUID, orgGUID, role)
+	repo.log("SetSpaceRoleByGUIDAsync", map[string]interface{}{"userGUID": userGUID, "spaceGUID": spaceGUID, "orgGUID": orgGUID, "role": role.ToString()}, err)
+	return job, err
+}
+
+func (repo LoggingUserRepository) SpaceRoleETag(spaceGUID string, role models.Role) (string, error) {
+	etag, err := repo.repo.SpaceRoleETag(spaceGUID, role)
+	repo.log("SpaceRoleETag", map[string]interface{}{"spaceGUID": spaceGUID, "role": role.ToString()}, err)
+	return etag, err
+}
+
+func (repo LoggingUserRepository) SetSpaceRoleByGUIDIfMatch(userGUID string, spaceGUID string, orgGUID string, role models.Role, etag string) error {
+	err := repo.repo.SetSpaceRoleByGUIDIfMatch(userGUID, spaceGUID, orgGUID, role, etag)
+	repo.log("SetSpaceRoleByGUIDIfMatch", map[string]interface{}{"userGUID": userGUID, "spaceGUID": spaceGUID, "orgGUID": orgGUID, "role": role.ToString(), "etag": etag}, err)
+	return err
+}
+
+func (repo LoggingUserRepository) UnsetSpaceRoleByGUIDAsync(userGUID string, spaceGUID string, role models.Role) (RoleJob, error) {
+	job, err := repo.repo.UnsetSpaceRoleByGUIDAsync(userGUID, spaceGUID, role)
+	repo.log("UnsetSpaceRoleByGUIDAsync", map[string]interface{}{"userGUID": userGUID, "spaceGUID": spaceGUID, "role": role.ToString()}, err)
+	return job, err
+}
+
+func (repo LoggingUserRepository) WaitForRoleJob(job RoleJob) error {
+	err := repo.repo.WaitForRoleJob(job)
+	repo.log("WaitForRoleJob", map[string]interface{}{"job": job.URL}, err)
+	return err
+}
@@ -0,0 +1,98 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"code.cloudfoundry.org/cli/cf/api"
+	"code.cloudfoundry.org/cli/cf/api/apifakes"
+	"code.cloudfoundry.org/cli/cf/errors"
+	"code.cloudfoundry.org/cli/cf/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoggingUserRepository", func() {
+	var (
+		inner  *apifakes.FakeUserRepository
+		out    *bytes.Buffer
+		logged api.LoggingUserRepository
+	)
+
+	BeforeEach(func() {
+		inner = new(apifakes.FakeUserRepository)
+		out = new(bytes.Buffer)
+		logged = api.NewLoggingUserRepository(inner, out)
+	})
+
+	loggedCalls := func() []map[string]interface{} {
+		var calls []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			call := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(line), &call)).To(Succeed())
+			calls = append(calls, call)
+		}
+		return calls
+	}
+
+	It("logs the method and its arguments and passes the results through", func() {
+		inner.FindByUsernameReturns(models.UserFields{GUID: "user-guid", Username: "alice"}, nil)
+
+		user, err := logged.FindByUsername("alice")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user).To(Equal(models.UserFields{GUID: "user-guid", Username: "alice"}))
+		Expect(inner.FindByUsernameArgsForCall(0)).To(Equal("alice"))
+
+		calls := loggedCalls()
+		Expect(calls).To(HaveLen(1))
+		Expect(calls[0]["repository"]).To(Equal("UserRepository"))
+		Expect(calls[0]["method"]).To(Equal("FindByUsername"))
+		Expect(calls[0]["args"]).To(Equal(map[string]interface{}{"username": "alice"}))
+		Expect(calls[0]).NotTo(HaveKey("error"))
+		Expect(calls[0]).To(HaveKey("time"))
+	})
+
+	It("logs the error and returns it unchanged", func() {
+		notFound := errors.NewModelNotFoundError("User", "alice")
+		inner.SetOrgRoleByUsernameReturns(notFound)
+
+		err := logged.SetOrgRoleByUsername("alice", "org-guid", models.RoleOrgManager)
+		Expect(err).To(BeIdenticalTo(notFound))
+
+		calls := loggedCalls()
+		Expect(calls[0]["args"]).To(Equal(map[string]interface{}{
+			"username": "alice",
+			"orgGUID":  "org-guid",
+			"role":     "RoleOrgManager",
+		}))
+		Expect(calls[0]["error"]).To(Equal(notFound.Error()))
+	})
+
+	It("logs a warning apart from errors", func() {
+		inner.CreateReturns(&api.UAAUserReusedWarning{Username: "alice"})
+
+		err := logged.Create("alice", "s3cret")
+		Expect(err).To(BeAssignableToTypeOf(&api.UAAUserReusedWarning{}))
+
+		calls := loggedCalls()
+		Expect(calls[0]).NotTo(HaveKey("error"))
+		Expect(calls[0]["warning"]).To(Equal(err.Error()))
+	})
+
+	It("redacts passwords", func() {
+		Expect(logged.Create("alice", "s3cret")).To(Succeed())
+		_, _ = logged.CreateBulk([]models.UserCreateRequest{{Username: "bob", Password: "hunter2"}})
+		Expect(logged.UpdatePassword("user-guid", "old-s3cret", "new-s3cret")).To(Succeed())
+
+		_, password := inner.CreateArgsForCall(0)
+		Expect(password).To(Equal("s3cret"))
+		Expect(out.String()).NotTo(ContainSubstring("s3cret"))
+		Expect(out.String()).NotTo(ContainSubstring("hunter2"))
+
+		calls := loggedCalls()
+		Expect(calls[0]["args"]).To(HaveKeyWithValue("password", "[REDACTED]"))
+		Expect(calls[1]["args"]).To(Equal(map[string]interface{}{"users": []interface{}{"bob"}}))
+		Expect(calls[2]["args"]).To(HaveKeyWithValue("newPassword", "[REDACTED]"))
+	})
+})
@@ -1,12 +1,20 @@
 package api_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	gonet "net"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
 	"time"
 
+	"code.cloudfoundry.org/cli/cf"
 	"code.cloudfoundry.org/cli/cf/api"
+	"code.cloudfoundry.org/cli/cf/api/resources"
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
 	"code.cloudfoundry.org/cli/cf/errors"
 	"code.cloudfoundry.org/cli/cf/models"
@@ -17,6 +25,7 @@ import (
 	testconfig "code.cloudfoundry.org/cli/util/testhelpers/configuration"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/ghttp"
 )
@@ -53,6 +62,68 @@ var _ = Describe("UserRepository", func() {
 		}
 	})
 
+	Describe("FindByUsername", func() {
+		Context("when UAA has no user with the given username", func() {
+			BeforeEach(func() {
+				uaaServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`userName Eq "missing-user"`))),
+						ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+					),
+				)
+			})
+
+			It("returns a not found error for the user resource", func() {
+				_, err := client.FindByUsername("missing-user")
+				Expect(err).To(HaveOccurred())
+
+				notFoundErr, ok := err.(*errors.ModelNotFoundError)
+				Expect(ok).To(BeTrue())
+				Expect(notFoundErr.Kind).To(Equal(errors.UserResource))
+				Expect(notFoundErr.ModelName).To(Equal("missing-user"))
+				Expect(err.Error()).To(Equal("User missing-user not found"))
+			})
+		})
+
+		DescribeTable("escaping the username in the SCIM filter",
+			func(username, filter string) {
+				uaaServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(filter))),
+						ghttp.RespondWith(http.StatusOK, fmt.Sprintf(`{"resources": [{"id": "the-guid", "userName": %q}]}`, username)),
+					),
+				)
+
+				user, err := client.FindByUsername(username)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(user.Username).To(Equal(username))
+			},
+			Entry("an apostrophe", `O'Brien`, `userName Eq "O'Brien"`),
+			Entry("double quotes", `jane "JJ" doe`, `userName Eq "jane \"JJ\" doe"`),
+			Entry("a backslash", `DOMAIN\jane`, `userName Eq "DOMAIN\\jane"`),
+			Entry("spaces", `jane doe`, `userName Eq "jane doe"`),
+			Entry("a plus sign", `jane+test@example.com`, `userName Eq "jane+test@example.com"`),
+		)
+
+		Context("when UAA finds the user", func() {
+			BeforeEach(func() {
+				uaaServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`userName Eq "my-user"`))),
+						ghttp.RespondWith(http.StatusOK, `{"resources": [{ "id": "my-user-guid", "userName": "my-user" }]}`),
+					),
+				)
+			})
+
+			It("returns the user", func() {
+				user, err := client.FindByUsername("my-user")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(user.GUID).To(Equal("my-user-guid"))
+				Expect(user.Username).To(Equal("my-user"))
+			})
+		})
+	})
+
 	Describe("ListUsersInOrgForRole", func() {
 		Context("when there are no users in the given org with the given role", func() {
 			BeforeEach(func() {
@@ -97,13 +168,13 @@ var _ = Describe("UserRepository", func() {
 
 				uaaServer.AppendHandlers(
 					ghttp.CombineHandlers(
-						ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName&filter=%s", url.QueryEscape(`ID eq "user-1-guid"`))),
+						ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`ID eq "user-1-guid"`))),
 						ghttp.VerifyHeader(http.Header{
 							"accept": []string{"application/json"},
 						}),
 						ghttp.RespondWith(http.StatusOK, `{
 							"resources": [
-							{ "id": "user-1-guid", "userName": "Super user 1" }
+							{ "id": "user-1-guid", "userName": "Super user 1", "origin": "ldap" }
 							]}`),
 					),
 				)
@@ -128,6 +199,7 @@ var _ = Describe("UserRepository", func() {
 				Expect(len(users)).To(Equal(1))
 				Expect(users[0].GUID).To(Equal("user-1-guid"))
 				Expect(users[0].Username).To(Equal("Super user 1"))
+				Expect(users[0].Origin).To(Equal("ldap"))
 			})
 		})
 
@@ -160,7 +232,7 @@ var _ = Describe("UserRepository", func() {
 
 				uaaServer.AppendHandlers(
 					ghttp.CombineHandlers(
-						ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName&filter=%s", url.QueryEscape(`ID eq "user-1-guid" or ID eq "user-2-guid" or ID eq "user-3-guid"`))),
+						ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`ID eq "user-1-guid" or ID eq "user-2-guid" or ID eq "user-3-guid"`))),
 						ghttp.VerifyHeader(http.Header{
 							"accept": []string{"application/json"},
 						}),
@@ -251,6 +323,119 @@ var _ = Describe("UserRepository", func() {
 		})
 	})
 
+	Describe("ListUsersInOrgForRoleFiltered", func() {
+		BeforeEach(func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/organizations/org-guid/managers"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{"metadata": {"guid": "user-1-guid"}, "entity": {}},
+						{"metadata": {"guid": "user-2-guid"}, "entity": {}}
+					]}`),
+				),
+			)
+		})
+
+		It("has UAA match the usernames and keeps only the role holders", func() {
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s",
+						url.QueryEscape(`(ID eq "user-1-guid" or ID eq "user-2-guid") and userName co "ali"`))),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{"id": "user-1-guid", "userName": "alice"},
+						{"id": "user-3-guid", "userName": "alina"}
+					]}`),
+				),
+			)
+
+			users, err := client.ListUsersInOrgForRoleFiltered("org-guid", models.RoleOrgManager, "ali")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(1))
+			Expect(users[0].Username).To(Equal("alice"))
+		})
+
+		It("escapes double quotes in the username to match", func() {
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s",
+						url.QueryEscape(`(ID eq "user-1-guid" or ID eq "user-2-guid") and userName co "\"JJ\""`))),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [{"id": "user-2-guid", "userName": "jane \"JJ\" doe"}]}`),
+				),
+			)
+
+			users, err := client.ListUsersInOrgForRoleFiltered("org-guid", models.RoleOrgManager, `"JJ"`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(1))
+			Expect(users[0].Username).To(Equal(`jane "JJ" doe`))
+		})
+
+		It("returns the error when UAA cannot be asked", func() {
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusInternalServerError, `{}`),
+			)
+
+			_, err := client.ListUsersInOrgForRoleFiltered("org-guid", models.RoleOrgManager, "ali")
+			Expect(err).To(HaveOccurred())
+			_, partial := err.(*api.PartialUAALookupWarning)
+			Expect(partial).To(BeFalse())
+		})
+	})
+
+	Describe("CountUsersInOrgForRole", func() {
+		It("reads the total from a single page without looking users up in UAA", func() {
+			ccServer.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v2/organizations/org-guid/billing_managers", "results-per-page=1"),
+				ghttp.RespondWith(http.StatusOK, `{"total_results": 42, "total_pages": 42, "resources": [{"metadata": {"guid": "user-1-guid"}, "entity": {}}]}`),
+			))
+
+			count, err := client.CountUsersInOrgForRole("org-guid", models.RoleBillingManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(42))
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(1))
+			Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("rejects a role that is not an org role", func() {
+			_, err := client.CountUsersInOrgForRole("org-guid", models.RoleSpaceDeveloper)
+			Expect(err).To(HaveOccurred())
+			Expect(ccServer.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+
+	Describe("ListUsersInOrgForRoleContext", func() {
+		var (
+			ctx    context.Context
+			cancel context.CancelFunc
+		)
+
+		BeforeEach(func() {
+			ctx, cancel = context.WithCancel(context.Background())
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/organizations/org-guid/managers"),
+					ghttp.RespondWith(http.StatusOK, `{
+						"next_url": "/v2/organizations/org-guid/managers?page=2",
+						"resources": [{"metadata": {"guid": "user-1-guid"}, "entity": {}}]
+					}`),
+					func(http.ResponseWriter, *http.Request) { cancel() },
+				),
+			)
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		It("returns the users listed so far when the context is cancelled", func() {
+			users, err := client.ListUsersInOrgForRoleContext(ctx, "org-guid", models.RoleOrgManager)
+			Expect(err).To(Equal(context.Canceled))
+			Expect(users).To(HaveLen(1))
+			Expect(users[0].GUID).To(Equal("user-1-guid"))
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(1))
+			Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+
 	Describe("ListUsersInOrgForRoleWithNoUAA", func() {
 		Context("when there are users in the given org with the given role", func() {
 			BeforeEach(func() {
@@ -402,4 +587,3273 @@ var _ = Describe("UserRepository", func() {
 			})
 		})
 	})
-})
+
+	Describe("SetOrgRoleByGUID", func() {
+		Context("when CC applies the role synchronously", func() {
+			BeforeEach(func() {
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/managers/user-guid"),
+						ghttp.RespondWith(http.StatusCreated, `{"metadata": {"guid": "org-guid"}}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/users/user-guid"),
+						ghttp.RespondWith(http.StatusCreated, `{"metadata": {"guid": "org-guid"}}`),
+					),
+				)
+			})
+
+			It("sets the role and associates the user with the org", func() {
+				err := client.SetOrgRoleByGUID("user-guid", "org-guid", models.RoleOrgManager)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ccServer.ReceivedRequests()).To(HaveLen(2))
+			})
+
+			It("returns a job handle that is not pending from the async variant", func() {
+				job, err := client.SetOrgRoleByGUIDAsync("user-guid", "org-guid", models.RoleOrgManager)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(job.Pending()).To(BeFalse())
+			})
+		})
+
+		Context("when CC accepts the role change as a job", func() {
+			BeforeEach(func() {
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/managers/user-guid"),
+						ghttp.RespondWith(http.StatusAccepted, `{"metadata": {"guid": "job-guid", "url": "/v2/jobs/job-guid"}, "entity": {"status": "queued"}}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/users/user-guid"),
+						ghttp.RespondWith(http.StatusCreated, `{"metadata": {"guid": "org-guid"}}`),
+					),
+				)
+			})
+
+			It("polls the job until it finishes", func() {
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/v2/jobs/job-guid"),
+						ghttp.RespondWith(http.StatusOK, `{"entity": {"status": "finished"}}`),
+					),
+				)
+
+				err := client.SetOrgRoleByGUID("user-guid", "org-guid", models.RoleOrgManager)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ccServer.ReceivedRequests()).To(HaveLen(3))
+			})
+
+			It("returns the failure when the job fails", func() {
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/v2/jobs/job-guid"),
+						ghttp.RespondWith(http.StatusOK, `{"entity": {"status": "failed", "error_details": {"description": "role not granted"}}}`),
+					),
+				)
+
+				err := client.SetOrgRoleByGUID("user-guid", "org-guid", models.RoleOrgManager)
+				Expect(err).To(MatchError("role not granted"))
+			})
+
+			It("returns the job handle without polling from the async variant", func() {
+				job, err := client.SetOrgRoleByGUIDAsync("user-guid", "org-guid", models.RoleOrgManager)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(job.Pending()).To(BeTrue())
+				Expect(job.URL).To(Equal("/v2/jobs/job-guid"))
+				Expect(ccServer.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+	})
+
+	Describe("UnsetSpaceRoleByGUIDAsync", func() {
+		BeforeEach(func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/v2/spaces/space-guid/developers/user-guid"),
+					ghttp.RespondWith(http.StatusAccepted, `{"metadata": {"guid": "job-guid", "url": "/v2/jobs/job-guid"}}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/jobs/job-guid"),
+					ghttp.RespondWith(http.StatusOK, `{"entity": {"status": "finished"}}`),
+				),
+			)
+		})
+
+		It("returns a job handle that can be waited on later", func() {
+			job, err := client.UnsetSpaceRoleByGUIDAsync("user-guid", "space-guid", models.RoleSpaceDeveloper)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(1))
+
+			err = client.WaitForRoleJob(job)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+
+	Describe("space role ETags", func() {
+		It("reads the ETag of the role's holders", func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/spaces/space-guid/developers"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": []}`, http.Header{"ETag": []string{`"v1"`}}),
+				),
+			)
+
+			etag, err := client.SpaceRoleETag("space-guid", models.RoleSpaceDeveloper)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(etag).To(Equal(`"v1"`))
+		})
+
+		It("sends the ETag in If-Match on the space role request only", func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/users/user-guid"),
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.Header.Get("If-Match")).To(BeEmpty())
+					},
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/spaces/space-guid/developers/user-guid"),
+					ghttp.VerifyHeaderKV("If-Match", `"v1"`),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+			)
+
+			err := client.SetSpaceRoleByGUIDIfMatch("user-guid", "space-guid", "org-guid", models.RoleSpaceDeveloper, `"v1"`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("returns a precondition failed error when the holders changed", func() {
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusCreated, `{}`),
+				ghttp.RespondWith(http.StatusPreconditionFailed, `{"code": 10000, "description": "changed"}`),
+			)
+
+			err := client.SetSpaceRoleByGUIDIfMatch("user-guid", "space-guid", "org-guid", models.RoleSpaceDeveloper, `"v1"`)
+			Expect(err).To(BeAssignableToTypeOf(&errors.HTTPPreconditionFailedError{}))
+		})
+
+		It("sends no If-Match without an ETag", func() {
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusCreated, `{}`),
+				ghttp.CombineHandlers(
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.Header).NotTo(HaveKey("If-Match"))
+					},
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+			)
+
+			err := client.SetSpaceRoleByGUIDIfMatch("user-guid", "space-guid", "org-guid", models.RoleSpaceDeveloper, "")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("options", func() {
+		Context("when built with WithDryRun", func() {
+			BeforeEach(func() {
+				client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithDryRun())
+			})
+
+			It("does not send role changes", func() {
+				err := client.SetOrgRoleByGUID("user-guid", "org-guid", models.RoleOrgManager)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = client.UnsetSpaceRoleByUsername("user-name", "space-guid", models.RoleSpaceDeveloper)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(ccServer.ReceivedRequests()).To(BeEmpty())
+			})
+
+			It("does not create users", func() {
+				err := client.Create("new-user", "password")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+				Expect(ccServer.ReceivedRequests()).To(BeEmpty())
+			})
+
+			It("still validates the role", func() {
+				err := client.SetSpaceRoleByGUID("user-guid", "space-guid", "org-guid", models.RoleOrgManager)
+				Expect(err).To(MatchError("Invalid Role 1"))
+			})
+		})
+
+		Context("when built with a job timeout and polling interval", func() {
+			BeforeEach(func() {
+				client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway,
+					api.WithJobTimeout(50*time.Millisecond),
+					api.WithJobPollingInterval(10*time.Millisecond),
+				)
+
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("DELETE", "/v2/organizations/org-guid/managers/user-guid"),
+						ghttp.RespondWith(http.StatusAccepted, `{"metadata": {"guid": "job-guid", "url": "/v2/jobs/job-guid"}}`),
+					),
+				)
+				ccServer.RouteToHandler("GET", "/v2/jobs/job-guid",
+					ghttp.RespondWith(http.StatusOK, `{"entity": {"status": "queued"}}`),
+				)
+			})
+
+			It("gives up waiting for the job after the timeout", func() {
+				err := client.UnsetOrgRoleByGUID("user-guid", "org-guid", models.RoleOrgManager)
+				Expect(err).To(BeAssignableToTypeOf(&errors.AsyncTimeoutError{}))
+				Expect(len(ccServer.ReceivedRequests())).To(BeNumerically(">", 2))
+			})
+		})
+
+		Context("when built with WithDryRun and a job timeout together", func() {
+			BeforeEach(func() {
+				client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway,
+					api.WithDryRun(),
+					api.WithJobTimeout(time.Millisecond),
+				)
+			})
+
+			It("applies both options", func() {
+				job, err := client.UnsetOrgRoleByGUIDAsync("user-guid", "org-guid", models.RoleOrgManager)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(job.Pending()).To(BeFalse())
+				Expect(ccServer.ReceivedRequests()).To(BeEmpty())
+
+				err = client.UnsetOrgRoleByGUID("user-guid", "org-guid", models.RoleOrgManager)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ccServer.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("ListOrgUsersWithSpaceRoles", func() {
+		BeforeEach(func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/organizations/org-guid/users"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{"metadata": {"guid": "user-1-guid"}, "entity": {}},
+						{"metadata": {"guid": "user-2-guid"}, "entity": {}}
+					]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/organizations/org-guid/spaces"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{"metadata": {"guid": "space-1-guid"}, "entity": {"name": "space-1"}}
+					]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/spaces/space-1-guid/managers"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/spaces/space-1-guid/developers"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{"metadata": {"guid": "user-1-guid"}, "entity": {}}
+					]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/spaces/space-1-guid/auditors"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+				),
+			)
+
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{ "id": "user-1-guid", "userName": "user-1" },
+						{ "id": "user-2-guid", "userName": "user-2" }
+					]}`),
+				),
+			)
+		})
+
+		It("returns each org user with the space roles they hold", func() {
+			users, err := client.ListOrgUsersWithSpaceRoles("org-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(2))
+
+			Expect(users[0].Username).To(Equal("user-1"))
+			Expect(users[0].Spaces).To(Equal([]models.UserSpaceRoles{
+				{
+					Space: models.SpaceFields{GUID: "space-1-guid", Name: "space-1"},
+					Roles: []models.Role{models.RoleSpaceDeveloper},
+				},
+			}))
+
+			Expect(users[1].Username).To(Equal("user-2"))
+			Expect(users[1].Spaces).To(BeEmpty())
+		})
+
+		It("queries each space role once rather than once per user", func() {
+			_, err := client.ListOrgUsersWithSpaceRoles("org-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(5))
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Describe("FindByUsernames", func() {
+		It("looks the usernames up in batches and leaves out the ones UAA does not know", func() {
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithUAAFilterBatchSize(2))
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`userName eq "alice" or userName eq "Bob"`))),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{"id": "alice-guid", "userName": "alice"},
+						{"id": "bob-guid", "userName": "bob"}
+					]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`userName eq "missing"`))),
+					ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+				),
+			)
+
+			users, err := client.FindByUsernames([]string{"alice", "Bob", "missing", "alice"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(2))
+			Expect(users).To(Equal(map[string]models.UserFields{
+				"alice": {GUID: "alice-guid", Username: "alice"},
+				"Bob":   {GUID: "bob-guid", Username: "bob"},
+			}))
+		})
+
+		It("makes no request for no usernames", func() {
+			users, err := client.FindByUsernames(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(BeEmpty())
+			Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("returns the error when a batch cannot be looked up", func() {
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithUAAFilterBatchSize(1))
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": [{"id": "alice-guid", "userName": "alice"}]}`),
+				ghttp.RespondWith(http.StatusForbidden, `{}`),
+			)
+
+			_, err := client.FindByUsernames([]string{"alice", "bob"})
+			Expect(err).To(HaveOccurred())
+			_, partial := err.(*api.PartialUAALookupWarning)
+			Expect(partial).To(BeFalse())
+		})
+	})
+
+	Describe("CC page size", func() {
+		BeforeEach(func() {
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": [{"id": "user-1-guid", "userName": "alice"}]}`),
+			)
+		})
+
+		It("lists users at the gateway's page size", func() {
+			ccGateway.ResultsPerPage = net.MaxResultsPerPage
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway)
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/organizations/org-guid/managers", "results-per-page=100"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [{"metadata": {"guid": "user-1-guid"}, "entity": {}}]}`),
+				),
+			)
+
+			_, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("lists users at the page size it is given, without changing the UAA batch size", func() {
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithResultsPerPage(20))
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/organizations/org-guid/managers", "results-per-page=20"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [{"metadata": {"guid": "user-1-guid"}, "entity": {}}]}`),
+				),
+			)
+
+			_, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Describe("FindByEmail", func() {
+		It("filters UAA users by email", func() {
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`emails.value eq "alice@example.com"`))),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [{"id": "alice-guid", "userName": "alice"}]}`),
+				),
+			)
+
+			user, err := client.FindByEmail("alice@example.com")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(user).To(Equal(models.UserFields{GUID: "alice-guid", Username: "alice"}))
+		})
+
+		It("returns a not found error when no user has the email", func() {
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+			)
+
+			_, err := client.FindByEmail("missing@example.com")
+			Expect(err).To(BeAssignableToTypeOf(&errors.ModelNotFoundError{}))
+		})
+	})
+
+	Describe("FindDuplicateCCRegistrations", func() {
+		BeforeEach(func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/users"),
+					ghttp.RespondWith(http.StatusOK, `{
+						"next_url": "/v2/users?page=2",
+						"resources": [
+							{"metadata": {"guid": "alice-guid"}, "entity": {"username": "alice"}},
+							{"metadata": {"guid": "bob-guid"}, "entity": {"username": "bob"}}
+						]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/users", "page=2"),
+					ghttp.RespondWith(http.StatusOK, `{
+						"resources": [
+							{"metadata": {"guid": "stale-alice-guid"}, "entity": {"username": "alice"}}
+						]}`),
+				),
+			)
+
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{ "id": "alice-guid", "userName": "alice" },
+						{ "id": "bob-guid", "userName": "bob" }
+					]}`),
+				),
+			)
+		})
+
+		It("pages through all CC users", func() {
+			_, err := client.FindDuplicateCCRegistrations()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("reports the UAA user registered under both CC GUIDs", func() {
+			duplicates, err := client.FindDuplicateCCRegistrations()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(duplicates).To(Equal([]models.DuplicateUserRegistration{
+				{
+					UAAID:    "alice-guid",
+					Username: "alice",
+					CCGUIDs:  []string{"alice-guid", "stale-alice-guid"},
+				},
+			}))
+		})
+	})
+
+	Describe("FindAllByUsernamePrefix", func() {
+		It("asks UAA for the users whose names start with the prefix", func() {
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`userName sw "ali"`))),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{ "id": "alice-guid", "userName": "alice" },
+						{ "id": "alistair-guid", "userName": "alistair" }
+					]}`),
+				),
+			)
+
+			users, err := client.FindAllByUsernamePrefix("ali")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(2))
+			Expect(users[0].Username).To(Equal("alice"))
+			Expect(users[1].GUID).To(Equal("alistair-guid"))
+		})
+
+		It("returns a ModelNotFoundError when no name starts with the prefix", func() {
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+			)
+
+			_, err := client.FindAllByUsernamePrefix("zed")
+			Expect(err).To(BeAssignableToTypeOf(&errors.ModelNotFoundError{}))
+		})
+	})
+
+	Describe("FindUnmatchedUsers", func() {
+		BeforeEach(func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/users"),
+					ghttp.RespondWith(http.StatusOK, `{
+						"resources": [
+							{"metadata": {"guid": "alice-guid"}, "entity": {"username": "alice"}},
+							{"metadata": {"guid": "cc-only-guid"}, "entity": {"username": "carol"}},
+							{"metadata": {"guid": "ci-client"}, "entity": {}}
+						]}`),
+				),
+			)
+
+			justNow := time.Now().UTC().Format(time.RFC3339)
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", "attributes=id,userName,origin,groups,meta&count=500"),
+					ghttp.RespondWith(http.StatusOK, `{"startIndex": 1, "totalResults": 5, "resources": [
+						{ "id": "alice-guid", "userName": "alice", "origin": "uaa", "meta": {"created": "2017-01-01T00:00:00.000Z"} },
+						{ "id": "admin-guid", "userName": "admin", "origin": "uaa", "groups": [{"display": "uaa.admin"}], "meta": {"created": "2017-01-01T00:00:00.000Z"} },
+						{ "id": "ldap-guid", "userName": "dave", "origin": "ldap", "meta": {"created": "2017-01-01T00:00:00.000Z"} }
+					]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", "attributes=id,userName,origin,groups,meta&count=500&startIndex=4"),
+					ghttp.RespondWith(http.StatusOK, `{"startIndex": 4, "totalResults": 5, "resources": [
+						{ "id": "orphan-guid", "userName": "erin", "origin": "uaa", "meta": {"created": "2017-01-01T00:00:00.000Z"} },
+						{ "id": "new-guid", "userName": "frank", "origin": "uaa", "meta": {"created": "`+justNow+`"} }
+					]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/oauth/clients", "count=500"),
+					ghttp.RespondWith(http.StatusOK, `{"startIndex": 1, "totalResults": 2, "resources": [
+						{ "client_id": "cf" },
+						{ "client_id": "ci-client" }
+					]}`),
+				),
+			)
+		})
+
+		It("pages through all UAA users and clients", func() {
+			_, err := client.FindUnmatchedUsers()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(3))
+		})
+
+		It("returns the users only one side knows", func() {
+			unmatched, err := client.FindUnmatchedUsers()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unmatched.CCOnly).To(HaveLen(1))
+			Expect(unmatched.CCOnly[0].GUID).To(Equal("cc-only-guid"))
+			Expect(unmatched.UAAOnly).To(Equal([]models.UserFields{
+				{GUID: "orphan-guid", Username: "erin", Origin: "uaa"},
+			}))
+		})
+
+		It("leaves out admins, client principals, users from other origins and new users", func() {
+			unmatched, err := client.FindUnmatchedUsers()
+			Expect(err).NotTo(HaveOccurred())
+
+			var guids []string
+			for _, user := range append(unmatched.CCOnly, unmatched.UAAOnly...) {
+				guids = append(guids, user.GUID)
+			}
+			Expect(guids).NotTo(ContainElement("admin-guid"))
+			Expect(guids).NotTo(ContainElement("ci-client"))
+			Expect(guids).NotTo(ContainElement("ldap-guid"))
+			Expect(guids).NotTo(ContainElement("new-guid"))
+		})
+	})
+
+	Describe("admin resolution", func() {
+		BeforeEach(func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/organizations/org-guid/managers"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{"metadata": {"guid": "user-1-guid"}, "entity": {"admin": true}},
+						{"metadata": {"guid": "user-2-guid"}, "entity": {"admin": false}}
+					]}`),
+				),
+			)
+		})
+
+		Context("by default", func() {
+			BeforeEach(func() {
+				uaaServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`ID eq "user-1-guid" or ID eq "user-2-guid"`))),
+						ghttp.RespondWith(http.StatusOK, `{"resources": [
+							{ "id": "user-1-guid", "userName": "user-1" },
+							{ "id": "user-2-guid", "userName": "user-2" }
+						]}`),
+					),
+				)
+			})
+
+			It("uses the CC admin flag", func() {
+				users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(users[0].IsAdmin).To(BeTrue())
+				Expect(users[1].IsAdmin).To(BeFalse())
+			})
+		})
+
+		Context("with a custom AdminResolver", func() {
+			BeforeEach(func() {
+				client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway,
+					api.WithAdminResolver(uaaGroupAdminResolver{group: "cloud_controller.admin"}),
+				)
+
+				uaaServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin,groups&filter=%s", url.QueryEscape(`ID eq "user-1-guid" or ID eq "user-2-guid"`))),
+						ghttp.RespondWith(http.StatusOK, `{"resources": [
+							{ "id": "user-1-guid", "userName": "user-1", "groups": [{"display": "openid"}] },
+							{ "id": "user-2-guid", "userName": "user-2", "groups": [{"display": "openid"}, {"display": "cloud_controller.admin"}] }
+						]}`),
+					),
+				)
+			})
+
+			It("marks admins using the resolver", func() {
+				users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(users[0].IsAdmin).To(BeFalse())
+				Expect(users[1].IsAdmin).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("username lookup caching", func() {
+		BeforeEach(func() {
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithUserLookupCache())
+
+			uaaServer.RouteToHandler("GET", "/Users",
+				ghttp.RespondWith(http.StatusOK, `{"resources": [{ "id": "my-user-guid", "userName": "my-user" }]}`),
+			)
+		})
+
+		It("serves repeated lookups from the cache", func() {
+			_, err := client.FindByUsername("my-user")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.FindByUsername("my-user")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("makes a fresh UAA lookup when the context disables caching", func() {
+			_, err := client.FindByUsername("my-user")
+			Expect(err).NotTo(HaveOccurred())
+
+			user, err := client.FindByUsernameContext(api.WithNoCache(context.Background()), "my-user")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(user.GUID).To(Equal("my-user-guid"))
+
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+
+	Describe("metrics", func() {
+		var collector *countingCollector
+
+		BeforeEach(func() {
+			collector = &countingCollector{counts: map[string]int{}}
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithMetricsCollector(collector))
+		})
+
+		Context("when the operation succeeds", func() {
+			BeforeEach(func() {
+				uaaServer.AppendHandlers(
+					ghttp.RespondWith(http.StatusOK, `{"resources": [{ "id": "my-user-guid", "userName": "my-user" }]}`),
+				)
+			})
+
+			It("counts the operation as a success and the UAA call", func() {
+				_, err := client.FindByUsername("my-user")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(collector.counts).To(Equal(map[string]int{
+					"operations_total{method=FindByUsername,outcome=success}": 1,
+					"uaa_calls_total": 1,
+				}))
+			})
+		})
+
+		Context("when the operation fails", func() {
+			BeforeEach(func() {
+				uaaServer.AppendHandlers(
+					ghttp.RespondWith(http.StatusForbidden, `{}`),
+				)
+			})
+
+			It("counts the operation as a failure and the UAA call", func() {
+				_, err := client.FindByUsername("my-user")
+				Expect(err).To(HaveOccurred())
+
+				Expect(collector.counts).To(Equal(map[string]int{
+					"operations_total{method=FindByUsername,outcome=failure}": 1,
+					"uaa_calls_total": 1,
+				}))
+			})
+		})
+
+		Context("when the operation succeeds with a warning", func() {
+			BeforeEach(func() {
+				uaaServer.AppendHandlers(
+					ghttp.RespondWith(http.StatusConflict, `{"error": "scim_resource_already_exists"}`),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [{"id": "existing-guid", "userName": "my-user"}]}`),
+				)
+				ccServer.AppendHandlers(
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				)
+			})
+
+			It("counts the operation as a success", func() {
+				err := client.Create("my-user", "password")
+				Expect(err).To(BeAssignableToTypeOf(&api.UAAUserReusedWarning{}))
+
+				Expect(collector.counts).To(HaveKeyWithValue("operations_total{method=Create,outcome=success}", 1))
+			})
+		})
+	})
+	Describe("ValidateRoleMaps", func() {
+		It("accepts the built in role maps", func() {
+			Expect(api.ValidateRoleMaps()).To(Succeed())
+		})
+
+		It("rejects a role with an empty path", func() {
+			err := api.ValidateRolePaths("org", map[models.Role]string{
+				models.RoleOrgManager: "managers",
+				models.RoleOrgAuditor: "",
+			})
+			Expect(err).To(MatchError("org role RoleOrgAuditor has no path"))
+		})
+
+		It("rejects roles that share a path", func() {
+			err := api.ValidateRolePaths("space", map[models.Role]string{
+				models.RoleSpaceManager:   "managers",
+				models.RoleSpaceDeveloper: "managers",
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("share the path managers"))
+		})
+	})
+	Describe("ListCFUsersInUAAGroup", func() {
+		Context("when some group members are registered with CF", func() {
+			BeforeEach(func() {
+				uaaServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/Groups", fmt.Sprintf("filter=%s", url.QueryEscape(`displayName eq "ldap-devs"`))),
+						ghttp.RespondWith(http.StatusOK, `{"resources": [{
+							"id": "group-guid",
+							"displayName": "ldap-devs",
+							"members": [
+								{ "value": "user-1-guid", "type": "USER" },
+								{ "value": "uaa-only-guid", "type": "USER" },
+								{ "value": "user-2-guid", "type": "GROUP" }
+							]
+						}]}`),
+					),
+				)
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/v2/users"),
+						ghttp.RespondWith(http.StatusOK, `{"resources": [
+							{ "metadata": { "guid": "user-1-guid" }, "entity": { "username": "user-1" } },
+							{ "metadata": { "guid": "user-2-guid" }, "entity": { "username": "user-2" } }
+						]}`),
+					),
+				)
+			})
+
+			It("returns only the members that CF knows about", func() {
+				users, err := client.ListCFUsersInUAAGroup("ldap-devs")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(users).To(HaveLen(1))
+				Expect(users[0].GUID).To(Equal("user-1-guid"))
+				Expect(users[0].Username).To(Equal("user-1"))
+			})
+		})
+
+		Context("when the group has no user members", func() {
+			BeforeEach(func() {
+				uaaServer.AppendHandlers(
+					ghttp.RespondWith(http.StatusOK, `{"resources": [{ "id": "group-guid", "displayName": "ldap-devs", "members": [] }]}`),
+				)
+			})
+
+			It("returns an empty list without asking the Cloud Controller", func() {
+				users, err := client.ListCFUsersInUAAGroup("ldap-devs")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(users).To(BeEmpty())
+				Expect(ccServer.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+
+		Context("when the group does not exist", func() {
+			BeforeEach(func() {
+				uaaServer.AppendHandlers(
+					ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+				)
+			})
+
+			It("returns a group not found error", func() {
+				_, err := client.ListCFUsersInUAAGroup("ldap-devs")
+				Expect(err).To(MatchError("Group ldap-devs not found"))
+			})
+		})
+	})
+	Describe("verbose errors", func() {
+		BeforeEach(func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/managers/user-guid"),
+					ghttp.RespondWith(http.StatusInternalServerError, `{"code": 10001, "description": "boom", "password": "hunter2"}`),
+				),
+			)
+		})
+
+		Context("when verbose errors are enabled", func() {
+			BeforeEach(func() {
+				client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithVerboseErrors())
+			})
+
+			It("attaches the redacted raw response to the error", func() {
+				err := client.SetOrgRoleByGUID("user-guid", "org-guid", models.RoleOrgManager)
+				Expect(err).To(HaveOccurred())
+
+				rawErr, ok := err.(*errors.RawResponseError)
+				Expect(ok).To(BeTrue())
+				Expect(rawErr.StatusCode()).To(Equal(http.StatusInternalServerError))
+				Expect(rawErr.RawStatus).To(Equal("500 Internal Server Error"))
+				Expect(rawErr.RawBody).To(ContainSubstring(`"description": "boom"`))
+				Expect(err.Error()).To(ContainSubstring(`"password":"[PRIVATE DATA HIDDEN]"`))
+				Expect(err.Error()).NotTo(ContainSubstring("hunter2"))
+			})
+		})
+
+		Context("when verbose errors are not enabled", func() {
+			It("returns the error without the raw response", func() {
+				err := client.SetOrgRoleByGUID("user-guid", "org-guid", models.RoleOrgManager)
+				Expect(err).To(HaveOccurred())
+
+				_, ok := err.(*errors.RawResponseError)
+				Expect(ok).To(BeFalse())
+				Expect(err.Error()).NotTo(ContainSubstring("Raw response"))
+			})
+		})
+	})
+	Describe("UAA filter URL length", func() {
+		BeforeEach(func() {
+			filterPrefix := uaaServer.URL() + "/Users?attributes=id,userName,origin&filter="
+			twoGUIDFilter := url.QueryEscape(`ID eq "user-1-guid" or ID eq "user-2-guid"`)
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithMaxUAAFilterURLLength(len(filterPrefix+twoGUIDFilter)))
+
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"metadata": {"guid": "user-1-guid"}, "entity": {}},
+					{"metadata": {"guid": "user-2-guid"}, "entity": {}},
+					{"metadata": {"guid": "user-3-guid"}, "entity": {}}
+				]}`),
+			)
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`ID eq "user-1-guid" or ID eq "user-2-guid"`))),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{ "id": "user-1-guid", "userName": "user-1" },
+						{ "id": "user-2-guid", "userName": "user-2" }
+					]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`ID eq "user-3-guid"`))),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [{ "id": "user-3-guid", "userName": "user-3" }]}`),
+				),
+			)
+		})
+
+		It("splits the lookup into requests under the limit and merges the results", func() {
+			users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(2))
+
+			Expect(users).To(HaveLen(3))
+			Expect(users[0].Username).To(Equal("user-1"))
+			Expect(users[1].Username).To(Equal("user-2"))
+			Expect(users[2].Username).To(Equal("user-3"))
+		})
+	})
+	Describe("UAA filter batch size", func() {
+		BeforeEach(func() {
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithUAAFilterBatchSize(2))
+
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"metadata": {"guid": "user-1-guid"}, "entity": {"admin": true}},
+					{"metadata": {"guid": "user-2-guid"}, "entity": {}},
+					{"metadata": {"guid": "user-3-guid"}, "entity": {}}
+				]}`),
+			)
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`ID eq "user-1-guid" or ID eq "user-2-guid"`))),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{ "id": "user-1-guid", "userName": "user-1" },
+						{ "id": "user-2-guid", "userName": "user-2" }
+					]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`ID eq "user-3-guid"`))),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{ "id": "user-3-guid", "userName": "user-3" },
+						{ "id": "user-1-guid", "userName": "user-1" }
+					]}`),
+				),
+			)
+		})
+
+		It("looks users up in batches, keeping the admin flag and each user once", func() {
+			users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(2))
+
+			Expect(users).To(Equal([]models.UserFields{
+				{GUID: "user-1-guid", Username: "user-1", IsAdmin: true},
+				{GUID: "user-2-guid", Username: "user-2"},
+				{GUID: "user-3-guid", Username: "user-3"},
+			}))
+		})
+	})
+	Describe("partial UAA lookup failures", func() {
+		BeforeEach(func() {
+			filterPrefix := uaaServer.URL() + "/Users?attributes=id,userName,origin&filter="
+			twoGUIDFilter := url.QueryEscape(`ID eq "user-1-guid" or ID eq "user-2-guid"`)
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithMaxUAAFilterURLLength(len(filterPrefix+twoGUIDFilter)))
+
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"metadata": {"guid": "user-1-guid"}, "entity": {}},
+					{"metadata": {"guid": "user-2-guid"}, "entity": {}},
+					{"metadata": {"guid": "user-3-guid"}, "entity": {}}
+				]}`),
+			)
+		})
+
+		It("does not fail listings that only need the users", func() {
+			uaaServer.RouteToHandler("GET", "/Users", func(w http.ResponseWriter, r *http.Request) {
+				filter := r.URL.Query().Get("filter")
+				if strings.Contains(filter, "user-3-guid") {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					fmt.Fprint(w, `{"error": "unavailable"}`)
+					return
+				}
+				var resources []string
+				for _, guid := range []string{"user-1-guid", "user-2-guid"} {
+					if strings.Contains(filter, guid) {
+						resources = append(resources, fmt.Sprintf(`{"id": "%s", "userName": "%s"}`, guid, strings.TrimSuffix(guid, "-guid")))
+					}
+				}
+				fmt.Fprintf(w, `{"resources": [%s]}`, strings.Join(resources, ","))
+			})
+
+			users, err := client.ListInactiveOrgUsers("org-guid", time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(3))
+		})
+
+		It("returns the users from the batches that succeeded with a warning", func() {
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{ "id": "user-1-guid", "userName": "user-1" },
+					{ "id": "user-2-guid", "userName": "user-2" }
+				]}`),
+				ghttp.RespondWith(http.StatusServiceUnavailable, `{"error": "unavailable"}`),
+			)
+
+			users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+			warning, ok := err.(*api.PartialUAALookupWarning)
+			Expect(ok).To(BeTrue())
+			Expect(warning.Count).To(Equal(1))
+
+			Expect(users).To(Equal([]models.UserFields{
+				{GUID: "user-3-guid"},
+				{GUID: "user-1-guid", Username: "user-1"},
+				{GUID: "user-2-guid", Username: "user-2"},
+			}))
+		})
+
+		It("fails when every batch fails", func() {
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusServiceUnavailable, `{"error": "unavailable"}`),
+				ghttp.RespondWith(http.StatusServiceUnavailable, `{"error": "unavailable"}`),
+			)
+
+			_, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+			Expect(err).To(HaveOccurred())
+			Expect(err).NotTo(BeAssignableToTypeOf(&api.PartialUAALookupWarning{}))
+		})
+	})
+	Describe("v3 role listing", func() {
+		Context("when the Cloud Controller supports v3 roles", func() {
+			BeforeEach(func() {
+				config.SetAPIVersion("2.150.0")
+
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/v3/roles", "organization_guids=org-guid&types=organization_manager&include=user"),
+						ghttp.RespondWith(http.StatusOK, `{
+							"pagination": { "next": null },
+							"resources": [
+								{ "type": "organization_manager", "relationships": { "user": { "data": { "guid": "user-1-guid" } } } },
+								{ "type": "organization_manager", "relationships": { "user": { "data": { "guid": "user-2-guid" } } } }
+							],
+							"included": { "users": [
+								{ "guid": "user-1-guid", "username": "user-1", "origin": "uaa" },
+								{ "guid": "user-2-guid", "username": "user-2", "origin": "ldap" }
+							] }
+						}`),
+					),
+				)
+			})
+
+			It("lists the users, their usernames and origins in one request", func() {
+				users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(ccServer.ReceivedRequests()).To(HaveLen(1))
+				Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+				Expect(users).To(Equal([]models.UserFields{
+					{GUID: "user-1-guid", Username: "user-1", Origin: "uaa"},
+					{GUID: "user-2-guid", Username: "user-2", Origin: "ldap"},
+				}))
+			})
+
+			It("rejects a role /v3/roles has no type for", func() {
+				_, err := client.ListUsersInOrgForRoleWithNoUAA("org-guid", models.RoleSpaceManager)
+				Expect(err).To(MatchError("Invalid Role RoleSpaceManager"))
+				Expect(ccServer.ReceivedRequests()).To(BeEmpty())
+			})
+
+			Context("when the admin resolver reads the Cloud Controller admin flag", func() {
+				BeforeEach(func() {
+					client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithAdminResolver(ccFlagReadingResolver{}))
+
+					ccServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", "/v2/users/user-1-guid"),
+							ghttp.RespondWith(http.StatusOK, `{"metadata": {"guid": "user-1-guid"}, "entity": {"admin": false}}`),
+						),
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", "/v2/users/user-2-guid"),
+							ghttp.RespondWith(http.StatusOK, `{"metadata": {"guid": "user-2-guid"}, "entity": {"admin": true}}`),
+						),
+					)
+				})
+
+				It("looks up the flag of each returned user", func() {
+					users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(ccServer.ReceivedRequests()).To(HaveLen(3))
+					Expect(users[0].IsAdmin).To(BeFalse())
+					Expect(users[1].IsAdmin).To(BeTrue())
+				})
+			})
+		})
+
+		Context("when spaces are laid out by a custom path template", func() {
+			BeforeEach(func() {
+				config.SetAPIVersion("2.150.0")
+				client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithSpaceRolePathTemplate(func(spaceGUID, rolePath string) string {
+					return fmt.Sprintf("/v2/spaces/parent-guid/subspaces/%s/%s", spaceGUID, rolePath)
+				}))
+
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/v2/spaces/parent-guid/subspaces/space-guid/developers"),
+						ghttp.RespondWith(http.StatusOK, `{"resources": [{"metadata": {"guid": "user-1-guid"}, "entity": {"username": "user-1", "admin": true}}]}`),
+					),
+				)
+			})
+
+			It("lists space roles through the templated v2 path", func() {
+				users, err := client.ListUsersInSpaceForRoleWithNoUAA("space-guid", models.RoleSpaceDeveloper)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(ccServer.ReceivedRequests()).To(HaveLen(1))
+				Expect(users).To(Equal([]models.UserFields{{GUID: "user-1-guid", Username: "user-1", IsAdmin: true}}))
+			})
+		})
+
+		Context("when the Cloud Controller predates v3 roles", func() {
+			BeforeEach(func() {
+				config.SetAPIVersion("2.100.0")
+
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/v2/organizations/org-guid/managers"),
+						ghttp.RespondWith(http.StatusOK, `{"resources": [{"metadata": {"guid": "user-1-guid"}, "entity": {}}]}`),
+					),
+				)
+				uaaServer.AppendHandlers(
+					ghttp.RespondWith(http.StatusOK, `{"resources": [{ "id": "user-1-guid", "userName": "user-1" }]}`),
+				)
+			})
+
+			It("falls back to the v2 endpoints and UAA", func() {
+				users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+				Expect(users).To(HaveLen(1))
+				Expect(users[0].Username).To(Equal("user-1"))
+			})
+		})
+	})
+	Describe("email validation", func() {
+		Context("when the email is valid", func() {
+			BeforeEach(func() {
+				uaaServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/Users"),
+						ghttp.VerifyJSON(`{
+							"userName": "my-user",
+							"emails": [{ "value": "my-user@example.com" }],
+							"password": "password",
+							"name": { "givenName": "my-user@example.com", "familyName": "my-user@example.com" }
+						}`),
+						ghttp.RespondWith(http.StatusCreated, `{ "id": "my-user-guid" }`),
+					),
+				)
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/v2/users"),
+						ghttp.RespondWith(http.StatusCreated, `{}`),
+					),
+				)
+			})
+
+			It("creates the user with the normalized email", func() {
+				err := client.CreateWithEmail("my-user", "password", " my-user@example.com ")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
+
+		It("rejects a malformed email before calling UAA", func() {
+			err := client.CreateWithEmail("my-user", "password", "my-user@@example")
+			Expect(err).To(MatchError("Invalid email address my-user@@example"))
+			Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("rejects a malformed email used as the username", func() {
+			err := client.Create("my-user@", "password")
+			Expect(err).To(MatchError("Invalid email address my-user@"))
+			Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		Context("when the username is not an email", func() {
+			BeforeEach(func() {
+				uaaServer.AppendHandlers(
+					ghttp.RespondWith(http.StatusCreated, `{ "id": "my-user-guid" }`),
+				)
+				ccServer.AppendHandlers(
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				)
+			})
+
+			It("allows it as a username", func() {
+				Expect(client.Create("my-user", "password")).To(Succeed())
+			})
+
+			It("rejects it as an email", func() {
+				err := client.CreateWithEmail("my-user", "password", "my-user")
+				Expect(err).To(MatchError("Invalid email address my-user"))
+			})
+		})
+	})
+	Describe("username validation", func() {
+		It("rejects an empty username before calling UAA", func() {
+			err := client.Create("", "password")
+			Expect(err).To(MatchError("Username must not be empty"))
+			Expect(err).To(BeAssignableToTypeOf(&errors.InvalidInputError{}))
+			Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("rejects a whitespace-only username", func() {
+			err := client.CreateWithEmail("  \t", "password", "my-user@example.com")
+			Expect(err).To(MatchError("Username must not be empty"))
+			Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("rejects an over-long username", func() {
+			err := client.Create(strings.Repeat("a", 256), "password")
+			Expect(err).To(MatchError("Username must be at most 255 characters long"))
+			Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("rejects characters UAA does not allow", func() {
+			err := client.Create("my user", "password")
+			Expect(err).To(MatchError(ContainSubstring("Username my user may only contain")))
+			Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("leaves the characters of an external user to its identity provider", func() {
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusCreated, `{ "id": "my-user-guid" }`),
+			)
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusCreated, `{}`),
+			)
+
+			Expect(client.CreateWithOrigin("My User", "", "ldap")).To(Succeed())
+		})
+
+		It("reports an invalid username in its CreateBulk result", func() {
+			results, err := client.CreateBulk([]models.UserCreateRequest{{Username: " ", Password: "password"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results[0].Err).To(MatchError("Username must not be empty"))
+			Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+	Describe("user ordering", func() {
+		BeforeEach(func() {
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"metadata": {"guid": "user-1-guid"}, "entity": {}},
+					{"metadata": {"guid": "user-2-guid"}, "entity": {}},
+					{"metadata": {"guid": "user-3-guid"}, "entity": {}}
+				]}`),
+			)
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{ "id": "user-1-guid", "userName": "charlie" },
+					{ "id": "user-2-guid", "userName": "Alice" },
+					{ "id": "user-3-guid", "userName": "bob" }
+				]}`),
+			)
+		})
+
+		It("sorts users case-insensitively by username", func() {
+			users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(users).To(HaveLen(3))
+			Expect(users[0].Username).To(Equal("Alice"))
+			Expect(users[1].Username).To(Equal("bob"))
+			Expect(users[2].Username).To(Equal("charlie"))
+		})
+
+		It("keeps the server order when asked to", func() {
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithServerOrder())
+
+			users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(users).To(HaveLen(3))
+			Expect(users[0].Username).To(Equal("charlie"))
+			Expect(users[1].Username).To(Equal("Alice"))
+			Expect(users[2].Username).To(Equal("bob"))
+		})
+	})
+	Describe("on behalf of", func() {
+		Context("when an actor is configured", func() {
+			BeforeEach(func() {
+				client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithOnBehalfOf("jane@example.com"))
+
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/managers/user-guid"),
+						ghttp.VerifyHeader(http.Header{
+							"X-On-Behalf-Of": []string{"jane@example.com"},
+						}),
+						ghttp.RespondWith(http.StatusCreated, nil),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/users/user-guid"),
+						ghttp.VerifyHeader(http.Header{
+							"X-On-Behalf-Of": []string{"jane@example.com"},
+						}),
+						ghttp.RespondWith(http.StatusCreated, nil),
+					),
+				)
+			})
+
+			It("sends the actor with the role change", func() {
+				err := client.SetOrgRoleByGUID("user-guid", "org-guid", models.RoleOrgManager)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ccServer.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+
+		Context("when no actor is configured", func() {
+			BeforeEach(func() {
+				ccServer.AppendHandlers(
+					ghttp.RespondWith(http.StatusCreated, nil),
+					ghttp.RespondWith(http.StatusCreated, nil),
+				)
+			})
+
+			It("does not send the header", func() {
+				err := client.SetOrgRoleByGUID("user-guid", "org-guid", models.RoleOrgManager)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ccServer.ReceivedRequests()[0].Header).NotTo(HaveKey("X-On-Behalf-Of"))
+			})
+		})
+	})
+	Describe("unresolved users", func() {
+		BeforeEach(func() {
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"metadata": {"guid": "user-1-guid"}, "entity": {}},
+					{"metadata": {"guid": "orphan-guid"}, "entity": {}}
+				]}`),
+			)
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": [{ "id": "user-1-guid", "userName": "user-1" }]}`),
+			)
+		})
+
+		It("drops them silently by default", func() {
+			users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(1))
+		})
+
+		It("returns a warning with the count when asked to", func() {
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithUnresolvedUsersWarning())
+
+			users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+			Expect(users).To(HaveLen(1))
+			Expect(users[0].Username).To(Equal("user-1"))
+
+			warning, ok := err.(*api.UnresolvedUsersWarning)
+			Expect(ok).To(BeTrue())
+			Expect(warning.Count).To(Equal(1))
+			Expect(err.Error()).To(Equal("1 user association(s) could not be resolved in UAA"))
+		})
+	})
+	Describe("excluding service accounts", func() {
+		BeforeEach(func() {
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"metadata": {"guid": "person-guid"}, "entity": {}},
+					{"metadata": {"guid": "ldap-bot-guid"}, "entity": {}},
+					{"metadata": {"guid": "ci-bot-guid"}, "entity": {}},
+					{"metadata": {"guid": "no-email-guid"}, "entity": {}}
+				]}`),
+			)
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"id": "person-guid", "userName": "person", "origin": "uaa", "emails": [{"value": "person@example.com"}]},
+					{"id": "ldap-bot-guid", "userName": "ldap-bot", "origin": "automation", "emails": [{"value": "bot@example.com"}]},
+					{"id": "ci-bot-guid", "userName": "svc-ci", "origin": "uaa", "emails": [{"value": "ci@example.com"}]},
+					{"id": "no-email-guid", "userName": "no-email", "origin": "uaa"}
+				]}`),
+			)
+		})
+
+		It("returns every user by default", func() {
+			users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(4))
+		})
+
+		It("drops matching users and users without an email when asked to", func() {
+			matcher := api.NewServiceAccountMatcher([]string{"automation"}, regexp.MustCompile(`^svc-`))
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithoutServiceAccounts(matcher))
+
+			users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(Equal([]models.UserFields{
+				{GUID: "person-guid", Username: "person", Origin: "uaa", Email: "person@example.com"},
+			}))
+			Expect(uaaServer.ReceivedRequests()[0].URL.Query().Get("attributes")).To(Equal("id,userName,origin,emails"))
+		})
+	})
+	Describe("UAA pagination", func() {
+		BeforeEach(func() {
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"metadata": {"guid": "user-1-guid"}, "entity": {}},
+					{"metadata": {"guid": "user-2-guid"}, "entity": {}},
+					{"metadata": {"guid": "user-3-guid"}, "entity": {}}
+				]}`),
+			)
+		})
+
+		It("follows the cursor without returning a user twice", func() {
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.URL.Query().Get("cursor")).To(BeEmpty())
+					},
+					ghttp.RespondWith(http.StatusOK, `{"nextCursor": "page-2", "resources": [
+						{"id": "user-1-guid", "userName": "user-1"},
+						{"id": "user-2-guid", "userName": "user-2"}
+					]}`),
+				),
+				ghttp.CombineHandlers(
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.URL.Query().Get("cursor")).To(Equal("page-2"))
+					},
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{"id": "user-2-guid", "userName": "user-2"},
+						{"id": "user-3-guid", "userName": "user-3"}
+					]}`),
+				),
+			)
+
+			users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(2))
+			Expect(users).To(Equal([]models.UserFields{
+				{GUID: "user-1-guid", Username: "user-1"},
+				{GUID: "user-2-guid", Username: "user-2"},
+				{GUID: "user-3-guid", Username: "user-3"},
+			}))
+		})
+
+		It("falls back to startIndex paging", func() {
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"startIndex": 1, "itemsPerPage": 2, "totalResults": 3, "resources": [
+					{"id": "user-1-guid", "userName": "user-1"},
+					{"id": "user-2-guid", "userName": "user-2"}
+				]}`),
+				ghttp.CombineHandlers(
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.URL.Query().Get("startIndex")).To(Equal("3"))
+					},
+					ghttp.RespondWith(http.StatusOK, `{"startIndex": 3, "itemsPerPage": 2, "totalResults": 3, "resources": [
+						{"id": "user-3-guid", "userName": "user-3"}
+					]}`),
+				),
+			)
+
+			users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(3))
+		})
+	})
+	Describe("roleless users", func() {
+		BeforeEach(func() {
+			ccServer.RouteToHandler("GET", "/v2/users", ghttp.RespondWith(http.StatusOK, `{"resources": [
+				{"metadata": {"guid": "org-user-guid"}, "entity": {"username": "org-user"}},
+				{"metadata": {"guid": "roleless-guid"}, "entity": {"username": "roleless"}},
+				{"metadata": {"guid": "space-user-guid"}, "entity": {"username": "space-user"}}
+			]}`))
+
+			roles := map[string]string{
+				"org-user-guid":   "organizations",
+				"space-user-guid": "managed_spaces",
+			}
+			for _, userGUID := range []string{"org-user-guid", "roleless-guid", "space-user-guid"} {
+				for _, association := range []string{
+					"organizations", "managed_organizations", "billing_managed_organizations", "audited_organizations",
+					"spaces", "managed_spaces", "audited_spaces",
+				} {
+					total := 0
+					if roles[userGUID] == association {
+						total = 1
+					}
+					ccServer.RouteToHandler("GET", fmt.Sprintf("/v2/users/%s/%s", userGUID, association),
+						ghttp.RespondWith(http.StatusOK, fmt.Sprintf(`{"total_results": %d, "resources": []}`, total)))
+				}
+			}
+		})
+
+		It("reports only the users with no org or space roles", func() {
+			users, err := client.FindRolelessUsers()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(Equal([]models.UserFields{{GUID: "roleless-guid", Username: "roleless"}}))
+		})
+
+		It("stops checking users once the callback returns false", func() {
+			var found []models.UserFields
+			err := client.EachRolelessUser(func(user models.UserFields) bool {
+				found = append(found, user)
+				return false
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(HaveLen(1))
+			for _, request := range ccServer.ReceivedRequests() {
+				Expect(request.URL.Path).NotTo(HavePrefix("/v2/users/space-user-guid"))
+			}
+		})
+	})
+	Describe("transforming users", func() {
+		BeforeEach(func() {
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"metadata": {"guid": "user-1-guid"}, "entity": {"username": "alice"}},
+					{"metadata": {"guid": "user-2-guid"}, "entity": {"username": "bob"}}
+				]}`),
+			)
+		})
+
+		It("returns users unchanged by default", func() {
+			users, err := client.ListUsersInSpaceForRoleWithNoUAA("space-guid", models.RoleSpaceManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users[0].Username).To(Equal("alice"))
+			Expect(users[1].Username).To(Equal("bob"))
+		})
+
+		It("applies the transform to every listed user", func() {
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithUserTransform(func(user models.UserFields) models.UserFields {
+				user.Username = strings.ToUpper(user.Username)
+				return user
+			}))
+
+			users, err := client.ListUsersInSpaceForRoleWithNoUAA("space-guid", models.RoleSpaceManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(Equal([]models.UserFields{
+				{GUID: "user-1-guid", Username: "ALICE"},
+				{GUID: "user-2-guid", Username: "BOB"},
+			}))
+		})
+	})
+	Describe("UnsetOrgRoleBulk", func() {
+		It("unsets the role for every user, tolerating users who lack it", func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/v2/organizations/org-guid/managers/user-1-guid"),
+					ghttp.RespondWith(http.StatusNoContent, nil),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/v2/organizations/org-guid/managers/user-2-guid"),
+					ghttp.RespondWith(http.StatusNotFound, `{"code": 10000, "description": "Unknown request", "error_code": "CF-NotFound"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/v2/organizations/org-guid/managers/user-3-guid"),
+					ghttp.RespondWith(http.StatusNoContent, nil),
+				),
+			)
+
+			results, err := client.UnsetOrgRoleBulk([]string{"user-1-guid", "user-2-guid", "user-3-guid"}, "org-guid", models.RoleOrgManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]models.RoleChangeResult{
+				{UserGUID: "user-1-guid", Changed: true},
+				{UserGUID: "user-2-guid", Changed: false},
+				{UserGUID: "user-3-guid", Changed: true},
+			}))
+		})
+
+		It("reports other failures per user", func() {
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusInternalServerError, `{"code": 10001, "description": "boom", "error_code": "CF-ServerError"}`),
+				ghttp.RespondWith(http.StatusNoContent, nil),
+			)
+
+			results, err := client.UnsetOrgRoleBulk([]string{"user-1-guid", "user-2-guid"}, "org-guid", models.RoleOrgManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results[0].Err).To(HaveOccurred())
+			Expect(results[0].Changed).To(BeFalse())
+			Expect(results[1]).To(Equal(models.RoleChangeResult{UserGUID: "user-2-guid", Changed: true}))
+		})
+	})
+	Describe("UnsetOrgRoleBulkContext", func() {
+		It("stops after the change in flight when the context is canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/v2/organizations/org-guid/managers/user-1-guid"),
+					ghttp.RespondWith(http.StatusNoContent, nil),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/v2/organizations/org-guid/managers/user-2-guid"),
+					func(w http.ResponseWriter, r *http.Request) { cancel() },
+					ghttp.RespondWith(http.StatusNoContent, nil),
+				),
+			)
+
+			results, err := client.UnsetOrgRoleBulkContext(ctx, []string{"user-1-guid", "user-2-guid", "user-3-guid"}, "org-guid", models.RoleOrgManager)
+			Expect(err).To(Equal(context.Canceled))
+			Expect(results).To(Equal([]models.RoleChangeResult{
+				{UserGUID: "user-1-guid", Changed: true},
+				{UserGUID: "user-2-guid", Changed: true},
+			}))
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+	Describe("ExportUsersSCIM", func() {
+		BeforeEach(func() {
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"startIndex": 1, "itemsPerPage": 1, "totalResults": 2, "resources": [{
+					"userName": "alice", "name": {"givenName": "Alice", "familyName": "Smith"},
+					"emails": [{"value": "alice@example.com"}], "origin": "uaa", "active": true
+				}]}`),
+				ghttp.CombineHandlers(
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.URL.Query().Get("startIndex")).To(Equal("2"))
+					},
+					ghttp.RespondWith(http.StatusOK, `{"startIndex": 2, "itemsPerPage": 1, "totalResults": 2, "resources": [{
+						"userName": "bob", "emails": [{"value": "bob@example.com"}], "origin": "ldap", "externalId": "cn=bob"
+					}]}`),
+				),
+			)
+		})
+
+		It("writes one SCIM core user per line", func() {
+			output := new(bytes.Buffer)
+			Expect(client.ExportUsersSCIM(output)).To(Succeed())
+
+			lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+
+			var alice map[string]interface{}
+			Expect(json.Unmarshal([]byte(lines[0]), &alice)).To(Succeed())
+			Expect(alice).To(Equal(map[string]interface{}{
+				"schemas":  []interface{}{"urn:scim:schemas:core:1.0"},
+				"userName": "alice",
+				"name":     map[string]interface{}{"givenName": "Alice", "familyName": "Smith"},
+				"emails":   []interface{}{map[string]interface{}{"value": "alice@example.com"}},
+				"origin":   "uaa",
+				"active":   true,
+			}))
+
+			var bob map[string]interface{}
+			Expect(json.Unmarshal([]byte(lines[1]), &bob)).To(Succeed())
+			Expect(bob).To(HaveKeyWithValue("externalId", "cn=bob"))
+			Expect(bob).NotTo(HaveKey("password"))
+		})
+	})
+	Describe("ListAllUsers", func() {
+		BeforeEach(func() {
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", "attributes=id,userName,origin,active&count=500"),
+					ghttp.RespondWith(http.StatusOK, `{"startIndex": 1, "itemsPerPage": 1, "totalResults": 2, "resources": [
+						{"id": "alice-guid", "userName": "alice", "origin": "uaa", "active": true}
+					]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", "attributes=id,userName,origin,active&count=500&startIndex=2"),
+					ghttp.RespondWith(http.StatusOK, `{"startIndex": 2, "itemsPerPage": 1, "totalResults": 2, "resources": [
+						{"id": "bob-guid", "userName": "bob", "origin": "ldap", "active": false}
+					]}`),
+				),
+			)
+		})
+
+		It("calls back with every UAA user, a page at a time", func() {
+			var users []models.UserFields
+			err := client.ListAllUsers(func(user models.UserFields) bool {
+				users = append(users, user)
+				return true
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(users).To(Equal([]models.UserFields{
+				{GUID: "alice-guid", Username: "alice", Origin: "uaa", Active: true},
+				{GUID: "bob-guid", Username: "bob", Origin: "ldap"},
+			}))
+			Expect(ccServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("stops paging when the callback returns false", func() {
+			calls := 0
+			err := client.ListAllUsers(func(models.UserFields) bool {
+				calls++
+				return false
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(calls).To(Equal(1))
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+	Describe("space role path templates", func() {
+		BeforeEach(func() {
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithSpaceRolePathTemplate(func(spaceGUID, rolePath string) string {
+				return fmt.Sprintf("/v2/spaces/parent-guid/subspaces/%s/%s", spaceGUID, rolePath)
+			}))
+		})
+
+		It("sets roles by username on the templated path", func() {
+			config.SetAPIVersion(cf.SetRolesByUsernameMinimumAPIVersion.String())
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/users"),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/spaces/parent-guid/subspaces/space-guid/developers"),
+					ghttp.VerifyJSON(`{"username": "alice"}`),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+			)
+
+			err := client.SetSpaceRoleByUsername("alice", "space-guid", "org-guid", models.RoleSpaceDeveloper)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("lists role holders from the templated path", func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/spaces/parent-guid/subspaces/space-guid/managers"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+				),
+			)
+
+			_, err := client.ListUsersInSpaceForRoleWithNoUAA("space-guid", models.RoleSpaceManager)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+	Describe("UAA timeouts", func() {
+		var silentUAA gonet.Listener
+
+		BeforeEach(func() {
+			var err error
+			silentUAA, err = gonet.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			go func() {
+				for {
+					conn, err := silentUAA.Accept()
+					if err != nil {
+						return
+					}
+					defer conn.Close()
+				}
+			}()
+
+			config.SetUaaEndpoint("https://" + silentUAA.Addr().String())
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithTransportTimeouts(time.Second, 50*time.Millisecond))
+		})
+
+		AfterEach(func() {
+			silentUAA.Close()
+		})
+
+		It("says it was UAA that timed out, not the Cloud Controller", func() {
+			ccServer.AppendHandlers(ghttp.RespondWith(http.StatusOK, `{"resources": [{"metadata": {"guid": "user-1-guid"}, "entity": {}}]}`))
+
+			_, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Timed out waiting for UAA at " + silentUAA.Addr().String()))
+			Expect(err.Error()).To(ContainSubstring("the Cloud Controller responded"))
+		})
+	})
+
+	Describe("token expiry check", func() {
+		setTokenExpiry := func(expiry time.Time) {
+			token, err := testconfig.EncodeAccessToken(coreconfig.TokenInfo{Username: "my-user", Expiry: expiry.Unix()})
+			Expect(err).NotTo(HaveOccurred())
+			config.SetAccessToken(token)
+		}
+
+		BeforeEach(func() {
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithTokenExpiryCheck())
+		})
+
+		It("fails before making a request when the token has expired", func() {
+			expiredAt := time.Now().Add(-time.Minute)
+			setTokenExpiry(expiredAt)
+
+			_, err := client.ListUsersInOrgForRoleWithNoUAA("org-guid", models.RoleOrgManager)
+			Expect(err).To(BeAssignableToTypeOf(&errors.TokenExpiredError{}))
+			Expect(err.(*errors.TokenExpiredError).ExpiredAt.Unix()).To(Equal(expiredAt.Unix()))
+			Expect(ccServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("still verifies credentials when the token has expired", func() {
+			setTokenExpiry(time.Now().Add(-time.Minute))
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/oauth/token"),
+					ghttp.RespondWith(http.StatusOK, `{"access_token": "new-user-token", "token_type": "bearer"}`),
+				),
+			)
+
+			valid, err := client.VerifyCredentials("new-user", "secret")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(valid).To(BeTrue())
+		})
+
+		It("makes the request when the token is still valid", func() {
+			setTokenExpiry(time.Now().Add(time.Hour))
+			ccServer.AppendHandlers(ghttp.RespondWith(http.StatusOK, `{"resources": []}`))
+
+			_, err := client.ListUsersInOrgForRoleWithNoUAA("org-guid", models.RoleOrgManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+	Describe("ListAllUsersInOrg", func() {
+		BeforeEach(func() {
+			ccServer.RouteToHandler("GET", "/v2/organizations/org-guid/users",
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"metadata": {"guid": "user-2-guid"}, "entity": {}},
+					{"metadata": {"guid": "user-1-guid"}, "entity": {}}
+				]}`),
+			)
+			ccServer.RouteToHandler("GET", "/v2/organizations/org-guid/managers",
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"metadata": {"guid": "user-1-guid"}, "entity": {}}
+				]}`),
+			)
+			ccServer.RouteToHandler("GET", "/v2/organizations/org-guid/billing_managers",
+				ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+			)
+			ccServer.RouteToHandler("GET", "/v2/organizations/org-guid/auditors",
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"metadata": {"guid": "user-2-guid"}, "entity": {}}
+				]}`),
+			)
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`ID eq "user-2-guid" or ID eq "user-1-guid"`))),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{ "id": "user-1-guid", "userName": "alice" },
+						{ "id": "user-2-guid", "userName": "bob" }
+					]}`),
+				),
+			)
+		})
+
+		It("lists every role, resolving all usernames in one UAA request", func() {
+			usersByRole, err := client.ListAllUsersInOrg("org-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(4))
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+
+			alice := models.UserFields{GUID: "user-1-guid", Username: "alice"}
+			bob := models.UserFields{GUID: "user-2-guid", Username: "bob"}
+			Expect(usersByRole).To(Equal(map[models.Role][]models.UserFields{
+				models.RoleOrgUser:        {alice, bob},
+				models.RoleOrgManager:     {alice},
+				models.RoleBillingManager: {},
+				models.RoleOrgAuditor:     {bob},
+			}))
+		})
+	})
+	Describe("ListUsersInOrgForRoles", func() {
+		BeforeEach(func() {
+			ccServer.RouteToHandler("GET", "/v2/organizations/org-guid/managers",
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"metadata": {"guid": "user-1-guid"}, "entity": {}}
+				]}`),
+			)
+			ccServer.RouteToHandler("GET", "/v2/organizations/org-guid/auditors",
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"metadata": {"guid": "user-2-guid"}, "entity": {}}
+				]}`),
+			)
+		})
+
+		It("lists only the given roles, resolving all usernames in one UAA request", func() {
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`ID eq "user-1-guid" or ID eq "user-2-guid"`))),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{ "id": "user-1-guid", "userName": "alice" },
+						{ "id": "user-2-guid", "userName": "bob" }
+					]}`),
+				),
+			)
+
+			usersByRole, err := client.ListUsersInOrgForRoles("org-guid", []models.Role{models.RoleOrgManager, models.RoleOrgAuditor})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(2))
+			Expect(usersByRole).To(Equal(map[models.Role][]models.UserFields{
+				models.RoleOrgManager: {{GUID: "user-1-guid", Username: "alice"}},
+				models.RoleOrgAuditor: {{GUID: "user-2-guid", Username: "bob"}},
+			}))
+		})
+
+		It("lists every role and reports each one that failed", func() {
+			ccServer.RouteToHandler("GET", "/v2/organizations/org-guid/users",
+				ghttp.RespondWith(http.StatusBadRequest, `{"code": 10001, "description": "users-error"}`),
+			)
+			ccServer.RouteToHandler("GET", "/v2/organizations/org-guid/billing_managers",
+				ghttp.RespondWith(http.StatusBadRequest, `{"code": 10001, "description": "billing-error"}`),
+			)
+
+			_, err := client.ListUsersInOrgForRoles("org-guid", []models.Role{
+				models.RoleOrgUser, models.RoleOrgManager, models.RoleBillingManager, models.RoleOrgAuditor,
+			})
+			Expect(err).To(BeAssignableToTypeOf(&api.RoleListingError{}))
+			failed := err.(*api.RoleListingError)
+			Expect(failed.Roles).To(Equal([]models.Role{models.RoleOrgUser, models.RoleBillingManager}))
+			Expect(err.Error()).To(ContainSubstring("users-error"))
+			Expect(err.Error()).To(ContainSubstring("billing-error"))
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(4))
+			Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("keeps the warnings of every role listed at once", func() {
+			for _, path := range []string{"users", "managers", "billing_managers", "auditors"} {
+				ccServer.RouteToHandler("GET", "/v2/organizations/org-guid/"+path,
+					ghttp.RespondWith(http.StatusOK, `{"resources": []}`, http.Header{"X-Cf-Warnings": {path + "-warning"}}),
+				)
+			}
+
+			_, err := client.ListUsersInOrgForRoles("org-guid", []models.Role{
+				models.RoleOrgUser, models.RoleOrgManager, models.RoleBillingManager, models.RoleOrgAuditor,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ccGateway.Warnings()).To(ConsistOf(
+				"users-warning", "managers-warning", "billing_managers-warning", "auditors-warning",
+			))
+		})
+	})
+
+	Describe("ListUsersInSpaceForAllRoles", func() {
+		BeforeEach(func() {
+			ccServer.RouteToHandler("GET", "/v2/spaces/space-guid/managers",
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"metadata": {"guid": "user-1-guid"}, "entity": {}}
+				]}`),
+			)
+			ccServer.RouteToHandler("GET", "/v2/spaces/space-guid/developers",
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"metadata": {"guid": "user-2-guid"}, "entity": {}},
+					{"metadata": {"guid": "user-1-guid"}, "entity": {}}
+				]}`),
+			)
+			ccServer.RouteToHandler("GET", "/v2/spaces/space-guid/auditors",
+				ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+			)
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`ID eq "user-1-guid" or ID eq "user-2-guid"`))),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{ "id": "user-1-guid", "userName": "alice" },
+						{ "id": "user-2-guid", "userName": "bob" }
+					]}`),
+				),
+			)
+		})
+
+		It("lists every role in Cloud Controller order, resolving all usernames in one UAA request", func() {
+			usersByRole, err := client.ListUsersInSpaceForAllRoles("space-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(3))
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+
+			alice := models.UserFields{GUID: "user-1-guid", Username: "alice"}
+			bob := models.UserFields{GUID: "user-2-guid", Username: "bob"}
+			Expect(usersByRole).To(Equal(map[models.Role][]models.UserFields{
+				models.RoleSpaceManager:   {alice},
+				models.RoleSpaceDeveloper: {bob, alice},
+				models.RoleSpaceAuditor:   {},
+			}))
+		})
+	})
+	Describe("ListInactiveOrgUsers", func() {
+		It("returns users who have not logged in recently or at all", func() {
+			millisAgo := func(d time.Duration) int64 {
+				return time.Now().Add(-d).UnixNano() / int64(time.Millisecond)
+			}
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/organizations/org-guid/users"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{"metadata": {"guid": "active-guid"}, "entity": {}},
+						{"metadata": {"guid": "stale-guid"}, "entity": {}},
+						{"metadata": {"guid": "never-guid"}, "entity": {}}
+					]}`),
+				),
+			)
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.URL.Query().Get("attributes")).To(Equal("id,userName,origin,lastLogonTime"))
+					},
+					ghttp.RespondWith(http.StatusOK, fmt.Sprintf(`{"resources": [
+						{"id": "active-guid", "userName": "active", "lastLogonTime": %d},
+						{"id": "stale-guid", "userName": "stale", "lastLogonTime": %d},
+						{"id": "never-guid", "userName": "never"}
+					]}`, millisAgo(24*time.Hour), millisAgo(100*24*time.Hour))),
+				),
+			)
+
+			users, err := client.ListInactiveOrgUsers("org-guid", 30*24*time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(2))
+			Expect(users[0].Username).To(Equal("never"))
+			Expect(users[0].LastLogon.IsZero()).To(BeTrue())
+			Expect(users[1].Username).To(Equal("stale"))
+			Expect(users[1].LastLogon).To(BeTemporally("~", time.Now().Add(-100*24*time.Hour), time.Second))
+		})
+	})
+	Describe("WithCreationTimestamps", func() {
+		It("attaches the UAA creation time to users that have one", func() {
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway, api.WithCreationTimestamps())
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/organizations/org-guid/users"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{"metadata": {"guid": "new-guid", "created_at": "2017-03-15T10:00:00Z"}, "entity": {}},
+						{"metadata": {"guid": "old-guid"}, "entity": {}}
+					]}`),
+				),
+			)
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.URL.Query().Get("attributes")).To(Equal("id,userName,origin,meta"))
+					},
+					ghttp.RespondWith(http.StatusOK, `{"resources": [
+						{"id": "new-guid", "userName": "new", "meta": {"created": "2017-03-14T09:26:53.589Z"}},
+						{"id": "old-guid", "userName": "old"}
+					]}`),
+				),
+			)
+
+			users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgUser)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(2))
+			Expect(users[0].Username).To(Equal("new"))
+			Expect(users[0].UAACreatedAt).To(Equal(time.Date(2017, 3, 14, 9, 26, 53, 589000000, time.UTC)))
+			Expect(users[0].CreatedAt).To(Equal(time.Date(2017, 3, 15, 10, 0, 0, 0, time.UTC)))
+			Expect(users[1].Username).To(Equal("old"))
+			Expect(users[1].UAACreatedAt.IsZero()).To(BeTrue())
+		})
+	})
+	Describe("VerifyCredentials", func() {
+		It("returns true when UAA grants a token", func() {
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/oauth/token"),
+					ghttp.VerifyBasicAuth("cf", ""),
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.ParseForm()).To(Succeed())
+						Expect(r.PostForm.Get("grant_type")).To(Equal("password"))
+						Expect(r.PostForm.Get("username")).To(Equal("new-user"))
+						Expect(r.PostForm.Get("password")).To(Equal("secret"))
+					},
+					ghttp.RespondWith(http.StatusOK, `{"access_token": "new-user-token", "token_type": "bearer"}`),
+				),
+			)
+			token := config.AccessToken()
+
+			valid, err := client.VerifyCredentials("new-user", "secret")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(valid).To(BeTrue())
+			Expect(config.AccessToken()).To(Equal(token))
+		})
+
+		It("returns false when UAA rejects the credentials", func() {
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusUnauthorized, `{"error": "unauthorized", "error_description": "Bad credentials"}`),
+			)
+
+			valid, err := client.VerifyCredentials("new-user", "wrong")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(valid).To(BeFalse())
+		})
+
+		It("returns an error when UAA fails", func() {
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusInternalServerError, `{"error": "server_error"}`),
+			)
+
+			valid, err := client.VerifyCredentials("new-user", "secret")
+			Expect(err).To(HaveOccurred())
+			Expect(err.(errors.HTTPError).StatusCode()).To(Equal(http.StatusInternalServerError))
+			Expect(valid).To(BeFalse())
+		})
+	})
+	Describe("IsUsernameAvailable", func() {
+		respondWith := func(body string) {
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`userName Eq "new-user"`))),
+					ghttp.RespondWith(http.StatusOK, body),
+				),
+			)
+		}
+
+		It("returns true when no UAA user has the name", func() {
+			respondWith(`{"resources": []}`)
+
+			available, err := client.IsUsernameAvailable("new-user")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(available).To(BeTrue())
+		})
+
+		It("returns false when the name is taken in the default origin", func() {
+			respondWith(`{"resources": [{"id": "uaa-guid", "userName": "new-user", "origin": "uaa"}]}`)
+
+			available, err := client.IsUsernameAvailable("new-user")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(available).To(BeFalse())
+		})
+
+		It("returns false when the name is taken in an external origin", func() {
+			respondWith(`{"resources": [{"id": "ldap-guid", "userName": "new-user", "origin": "ldap"}]}`)
+
+			available, err := client.IsUsernameAvailable("new-user")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(available).To(BeFalse())
+		})
+
+		It("returns the error when UAA cannot be asked", func() {
+			uaaServer.AppendHandlers(ghttp.RespondWith(http.StatusInternalServerError, `{}`))
+
+			available, err := client.IsUsernameAvailable("new-user")
+			Expect(err).To(HaveOccurred())
+			Expect(available).To(BeFalse())
+		})
+	})
+	Describe("GetUserAccessSummary", func() {
+		BeforeEach(func() {
+			respond := func(path string, body string) {
+				ccServer.RouteToHandler("GET", path, ghttp.RespondWith(http.StatusOK, body))
+			}
+			for _, association := range []string{"organizations", "billing_managed_organizations", "audited_organizations", "managed_spaces"} {
+				respond("/v2/users/user-guid/"+association, `{"resources": []}`)
+			}
+			respond("/v2/users/user-guid/managed_organizations", `{"resources": [
+				{"metadata": {"guid": "org-a-guid"}, "entity": {"name": "org-a"}}
+			]}`)
+			respond("/v2/users/user-guid/spaces", `{"resources": [
+				{"metadata": {"guid": "space-1-guid"}, "entity": {"name": "space-1", "organization_guid": "org-b-guid"}},
+				{"metadata": {"guid": "space-2-guid"}, "entity": {"name": "space-2", "organization_guid": "org-b-guid"}}
+			]}`)
+			respond("/v2/users/user-guid/audited_spaces", `{"resources": [
+				{"metadata": {"guid": "space-3-guid"}, "entity": {"name": "space-3", "organization_guid": "org-a-guid"}},
+				{"metadata": {"guid": "space-4-guid"}, "entity": {"name": "space-4", "organization_guid": "gone-org-guid"}}
+			]}`)
+			respond("/v2/organizations/org-b-guid", `{"metadata": {"guid": "org-b-guid"}, "entity": {"name": "org-b"}}`)
+			ccServer.RouteToHandler("GET", "/v2/organizations/gone-org-guid", ghttp.RespondWith(http.StatusNotFound, `{}`))
+		})
+
+		orgLookups := func() int {
+			count := 0
+			for _, request := range ccServer.ReceivedRequests() {
+				if strings.HasPrefix(request.URL.Path, "/v2/organizations/") {
+					count++
+				}
+			}
+			return count
+		}
+
+		It("resolves org names once per org", func() {
+			summary, err := client.GetUserAccessSummary("user-guid", true)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(summary.UserGUID).To(Equal("user-guid"))
+			Expect(summary.Grants).To(Equal([]models.AccessGrant{
+				{Role: models.RoleOrgManager, OrgGUID: "org-a-guid", OrgName: "org-a"},
+				{Role: models.RoleSpaceDeveloper, OrgGUID: "org-b-guid", OrgName: "org-b", SpaceGUID: "space-1-guid", SpaceName: "space-1"},
+				{Role: models.RoleSpaceDeveloper, OrgGUID: "org-b-guid", OrgName: "org-b", SpaceGUID: "space-2-guid", SpaceName: "space-2"},
+				{Role: models.RoleSpaceAuditor, OrgGUID: "org-a-guid", OrgName: "org-a", SpaceGUID: "space-3-guid", SpaceName: "space-3"},
+				{Role: models.RoleSpaceAuditor, OrgGUID: "gone-org-guid", OrgName: "gone-org-guid", SpaceGUID: "space-4-guid", SpaceName: "space-4"},
+			}))
+			Expect(orgLookups()).To(Equal(2))
+		})
+
+		It("shows GUIDs without looking orgs up when resolution is skipped", func() {
+			summary, err := client.GetUserAccessSummary("user-guid", false)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(summary.Grants[1].OrgName).To(Equal("org-b-guid"))
+			Expect(summary.Grants[3].OrgName).To(Equal("org-a"))
+			Expect(orgLookups()).To(Equal(0))
+		})
+	})
+	Describe("GetUserMemberships", func() {
+		BeforeEach(func() {
+			respond := func(path string, body string) {
+				ccServer.RouteToHandler("GET", path, ghttp.RespondWith(http.StatusOK, body))
+			}
+			for _, association := range []string{"billing_managed_organizations", "audited_organizations", "managed_spaces"} {
+				respond("/v2/users/user-guid/"+association, `{"resources": []}`)
+			}
+			respond("/v2/users/user-guid/organizations", `{"resources": [
+				{"metadata": {"guid": "org-b-guid"}, "entity": {"name": "org-b"}},
+				{"metadata": {"guid": "org-a-guid"}, "entity": {"name": "org-a"}}
+			]}`)
+			respond("/v2/users/user-guid/managed_organizations", `{"resources": [
+				{"metadata": {"guid": "org-a-guid"}, "entity": {"name": "org-a"}}
+			]}`)
+			respond("/v2/users/user-guid/spaces", `{"resources": [
+				{"metadata": {"guid": "space-2-guid"}, "entity": {"name": "space-2", "organization_guid": "org-a-guid"}},
+				{"metadata": {"guid": "space-1-guid"}, "entity": {"name": "space-1", "organization_guid": "org-a-guid"}},
+				{"metadata": {"guid": "space-3-guid"}, "entity": {"name": "space-3", "organization_guid": "org-c-guid"}}
+			]}`)
+			respond("/v2/users/user-guid/audited_spaces", `{"resources": [
+				{"metadata": {"guid": "space-1-guid"}, "entity": {"name": "space-1", "organization_guid": "org-a-guid"}}
+			]}`)
+			respond("/v2/organizations/org-c-guid", `{"metadata": {"guid": "org-c-guid"}, "entity": {"name": "org-c"}}`)
+		})
+
+		It("nests space roles under their orgs, following every page", func() {
+			ccServer.RouteToHandler("GET", "/v2/users/user-guid/managed_organizations", func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("page") == "2" {
+					w.Write([]byte(`{"resources": [{"metadata": {"guid": "org-b-guid"}, "entity": {"name": "org-b"}}]}`))
+					return
+				}
+				w.Write([]byte(`{
+					"next_url": "/v2/users/user-guid/managed_organizations?page=2",
+					"resources": [{"metadata": {"guid": "org-a-guid"}, "entity": {"name": "org-a"}}]
+				}`))
+			})
+
+			memberships, err := client.GetUserMemberships("user-guid", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(memberships).To(Equal(models.UserMemberships{
+				UserGUID: "user-guid",
+				Orgs: []models.OrgMembership{
+					{
+						Org:   models.OrganizationFields{GUID: "org-a-guid", Name: "org-a"},
+						Roles: []models.Role{models.RoleOrgUser, models.RoleOrgManager},
+						Spaces: []models.SpaceMembership{
+							{Space: models.SpaceFields{GUID: "space-1-guid", Name: "space-1"}, Roles: []models.Role{models.RoleSpaceDeveloper, models.RoleSpaceAuditor}},
+							{Space: models.SpaceFields{GUID: "space-2-guid", Name: "space-2"}, Roles: []models.Role{models.RoleSpaceDeveloper}},
+						},
+					},
+					{
+						Org:   models.OrganizationFields{GUID: "org-b-guid", Name: "org-b"},
+						Roles: []models.Role{models.RoleOrgUser, models.RoleOrgManager},
+					},
+					{
+						Org: models.OrganizationFields{GUID: "org-c-guid", Name: "org-c"},
+						Spaces: []models.SpaceMembership{
+							{Space: models.SpaceFields{GUID: "space-3-guid", Name: "space-3"}, Roles: []models.Role{models.RoleSpaceDeveloper}},
+						},
+					},
+				},
+			}))
+		})
+
+		It("limits the listing to one org and has the Cloud Controller filter spaces by it", func() {
+			ccServer.RouteToHandler("GET", "/v2/users/user-guid/spaces", ghttp.RespondWith(http.StatusOK, `{"resources": [
+				{"metadata": {"guid": "space-4-guid"}, "entity": {"name": "space-4", "organization_guid": "org-b-guid"}}
+			]}`))
+			ccServer.RouteToHandler("GET", "/v2/users/user-guid/audited_spaces", ghttp.RespondWith(http.StatusOK, `{"resources": []}`))
+
+			memberships, err := client.GetUserMemberships("user-guid", "org-b-guid")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(memberships.Orgs).To(HaveLen(1))
+			Expect(memberships.Orgs[0].Org.GUID).To(Equal("org-b-guid"))
+			Expect(memberships.Orgs[0].Roles).To(Equal([]models.Role{models.RoleOrgUser}))
+			Expect(memberships.Orgs[0].Spaces).To(HaveLen(1))
+
+			for _, request := range ccServer.ReceivedRequests() {
+				if strings.HasSuffix(request.URL.Path, "spaces") {
+					Expect(request.URL.Query().Get("q")).To(Equal("organization_guid:org-b-guid"))
+				}
+			}
+		})
+
+		It("returns an error when an association cannot be read", func() {
+			ccServer.RouteToHandler("GET", "/v2/users/user-guid/spaces", ghttp.RespondWith(http.StatusForbidden, `{}`))
+
+			_, err := client.GetUserMemberships("user-guid", "")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("GetUserRolesInOrg", func() {
+		BeforeEach(func() {
+			respond := func(association string, body string) {
+				ccServer.RouteToHandler("GET", "/v2/users/user-guid/"+association, ghttp.RespondWith(http.StatusOK, body))
+			}
+			respond("organizations", `{"resources": [
+				{"metadata": {"guid": "other-org-guid"}, "entity": {"name": "other-org"}},
+				{"metadata": {"guid": "org-guid"}, "entity": {"name": "org"}}
+			]}`)
+			respond("managed_organizations", `{"resources": [
+				{"metadata": {"guid": "other-org-guid"}, "entity": {"name": "other-org"}}
+			]}`)
+			respond("billing_managed_organizations", `{"resources": []}`)
+			respond("audited_organizations", `{"resources": [
+				{"metadata": {"guid": "org-guid"}, "entity": {"name": "org"}}
+			]}`)
+		})
+
+		It("returns the roles the user holds in the org", func() {
+			roles, err := client.GetUserRolesInOrg("user-guid", "org-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roles).To(Equal([]models.Role{models.RoleOrgUser, models.RoleOrgAuditor}))
+		})
+
+		It("returns an empty slice when the user has no roles in the org", func() {
+			roles, err := client.GetUserRolesInOrg("user-guid", "unrelated-org-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roles).NotTo(BeNil())
+			Expect(roles).To(BeEmpty())
+		})
+
+		It("returns the error when an association cannot be listed", func() {
+			ccServer.RouteToHandler("GET", "/v2/users/user-guid/managed_organizations",
+				ghttp.RespondWith(http.StatusForbidden, `{"code": 10003, "description": "not authorized"}`))
+
+			_, err := client.GetUserRolesInOrg("user-guid", "org-guid")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Describe("CopyRoles", func() {
+		var puts map[string]int
+
+		BeforeEach(func() {
+			respond := func(userGUID, association string, body string) {
+				ccServer.RouteToHandler("GET", "/v2/users/"+userGUID+"/"+association, ghttp.RespondWith(http.StatusOK, body))
+			}
+			inOrg := `{"resources": [{"metadata": {"guid": "org-guid"}, "entity": {"name": "org"}}]}`
+			none := `{"resources": []}`
+			respond("from-guid", "organizations", inOrg)
+			respond("from-guid", "managed_organizations", inOrg)
+			respond("from-guid", "billing_managed_organizations", none)
+			respond("from-guid", "audited_organizations", inOrg)
+			respond("to-guid", "organizations", inOrg)
+			respond("to-guid", "managed_organizations", none)
+			respond("to-guid", "billing_managed_organizations", none)
+			respond("to-guid", "audited_organizations", none)
+
+			puts = map[string]int{}
+			for _, path := range []string{"users", "managers", "auditors"} {
+				path := path
+				ccServer.RouteToHandler("PUT", "/v2/organizations/org-guid/"+path+"/to-guid", func(w http.ResponseWriter, r *http.Request) {
+					puts[path]++
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(`{}`))
+				})
+			}
+		})
+
+		It("gives the target each of the source's org roles it does not already hold", func() {
+			results, err := client.CopyRoles("from-guid", "to-guid", "org-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]models.RoleChangeResult{
+				{UserGUID: "to-guid", Role: models.RoleOrgUser, Changed: false},
+				{UserGUID: "to-guid", Role: models.RoleOrgManager, Changed: true},
+				{UserGUID: "to-guid", Role: models.RoleOrgAuditor, Changed: true},
+			}))
+			Expect(puts["managers"]).To(Equal(1))
+			Expect(puts["auditors"]).To(Equal(1))
+		})
+
+		It("reports a role that cannot be given and copies the rest", func() {
+			ccServer.RouteToHandler("PUT", "/v2/organizations/org-guid/managers/to-guid",
+				ghttp.RespondWith(http.StatusForbidden, `{"code": 10003, "description": "not authorized"}`))
+
+			results, err := client.CopyRoles("from-guid", "to-guid", "org-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(HaveLen(3))
+			Expect(results[1].Role).To(Equal(models.RoleOrgManager))
+			Expect(results[1].Changed).To(BeFalse())
+			Expect(results[1].Err).To(HaveOccurred())
+			Expect(results[2].Changed).To(BeTrue())
+		})
+
+		It("returns the error when the source's roles cannot be read", func() {
+			ccServer.RouteToHandler("GET", "/v2/users/from-guid/managed_organizations",
+				ghttp.RespondWith(http.StatusForbidden, `{"code": 10003, "description": "not authorized"}`))
+
+			_, err := client.CopyRoles("from-guid", "to-guid", "org-guid")
+			Expect(err).To(HaveOccurred())
+			Expect(puts).To(BeEmpty())
+		})
+	})
+
+	Describe("CopySpaceRoles", func() {
+		It("gives the target each of the source's space roles it does not already hold", func() {
+			inSpace := `{"resources": [{"metadata": {"guid": "space-guid"}, "entity": {"name": "space"}}]}`
+			none := `{"resources": []}`
+			for association, body := range map[string]string{"managed_spaces": none, "spaces": inSpace, "audited_spaces": inSpace} {
+				ccServer.RouteToHandler("GET", "/v2/users/from-guid/"+association, ghttp.RespondWith(http.StatusOK, body))
+			}
+			for association, body := range map[string]string{"managed_spaces": none, "spaces": inSpace, "audited_spaces": none} {
+				ccServer.RouteToHandler("GET", "/v2/users/to-guid/"+association, ghttp.RespondWith(http.StatusOK, body))
+			}
+			ccServer.RouteToHandler("PUT", "/v2/organizations/org-guid/users/to-guid", ghttp.RespondWith(http.StatusCreated, `{}`))
+			ccServer.RouteToHandler("PUT", "/v2/spaces/space-guid/auditors/to-guid", ghttp.RespondWith(http.StatusCreated, `{}`))
+
+			results, err := client.CopySpaceRoles("from-guid", "to-guid", "space-guid", "org-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]models.RoleChangeResult{
+				{UserGUID: "to-guid", Role: models.RoleSpaceDeveloper, Changed: false},
+				{UserGUID: "to-guid", Role: models.RoleSpaceAuditor, Changed: true},
+			}))
+		})
+	})
+
+	Describe("org association", func() {
+		It("succeeds when the user is already in the org", func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/managers/user-guid"),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/users/user-guid"),
+					ghttp.RespondWith(http.StatusUnprocessableEntity, `{"code": 10008, "description": "User 'alice' already has 'organization_user' role in organization 'my-org'.", "error_code": "CF-UnprocessableEntity"}`),
+				),
+			)
+
+			err := client.SetOrgRoleByGUID("user-guid", "org-guid", models.RoleOrgManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("still fails on other unprocessable entity errors", func() {
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusCreated, `{}`),
+				ghttp.RespondWith(http.StatusUnprocessableEntity, `{"code": 10008, "description": "The user is already being removed from the organization", "error_code": "CF-UnprocessableEntity"}`),
+			)
+
+			err := client.SetOrgRoleByGUID("user-guid", "org-guid", models.RoleOrgManager)
+			Expect(err).To(HaveOccurred())
+			Expect(err.(errors.HTTPError).StatusCode()).To(Equal(http.StatusUnprocessableEntity))
+		})
+
+		It("still fails on other conflicts", func() {
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusCreated, `{}`),
+				ghttp.RespondWith(http.StatusConflict, `{"code": 30002, "description": "The organization name is taken", "error_code": "CF-OrganizationNameTaken"}`),
+			)
+
+			err := client.SetOrgRoleByGUID("user-guid", "org-guid", models.RoleOrgManager)
+			Expect(err).To(HaveOccurred())
+			Expect(err.(errors.HTTPError).StatusCode()).To(Equal(http.StatusConflict))
+		})
+
+		It("still fails on other association errors", func() {
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusCreated, `{}`),
+				ghttp.RespondWith(http.StatusForbidden, `{"code": 10003, "description": "You are not authorized to perform the requested action", "error_code": "CF-NotAuthorized"}`),
+			)
+
+			err := client.SetOrgRoleByGUID("user-guid", "org-guid", models.RoleOrgManager)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Describe("inconsistent paging", func() {
+		BeforeEach(func() {
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"total_results": 4, "next_url": "/v2/organizations/org-guid/managers?page=2", "resources": [
+					{"metadata": {"guid": "user-1-guid"}, "entity": {}},
+					{"metadata": {"guid": "user-2-guid"}, "entity": {}}
+				]}`),
+				ghttp.RespondWith(http.StatusOK, `{"total_results": 3, "resources": [
+					{"metadata": {"guid": "user-4-guid"}, "entity": {}}
+				]}`),
+			)
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": [
+					{"id": "user-1-guid", "userName": "user-1"},
+					{"id": "user-2-guid", "userName": "user-2"},
+					{"id": "user-4-guid", "userName": "user-4"}
+				]}`),
+			)
+		})
+
+		It("warns when the dataset changed while it was paged through", func() {
+			users, err := client.ListUsersInOrgForRole("org-guid", models.RoleOrgManager)
+			Expect(users).To(HaveLen(3))
+
+			warning, ok := err.(*api.InconsistentResultsWarning)
+			Expect(ok).To(BeTrue())
+			Expect(warning.Expected).To(Equal(4))
+			Expect(warning.Collected).To(Equal(3))
+		})
+	})
+	Describe("Delete", func() {
+		BeforeEach(func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/v2/users/my-user-guid"),
+					ghttp.RespondWith(http.StatusNoContent, nil),
+				),
+			)
+		})
+
+		It("succeeds when UAA no longer has the user", func() {
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/Users/my-user-guid"),
+					ghttp.RespondWith(http.StatusNotFound, `{"error": "scim_resource_not_found"}`),
+				),
+			)
+
+			err := client.Delete("my-user-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("returns other UAA errors", func() {
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusForbidden, `{"error": "access_denied"}`),
+			)
+
+			err := client.Delete("my-user-guid")
+			Expect(err).To(HaveOccurred())
+			Expect(err.(errors.HTTPError).StatusCode()).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	Describe("DeleteAsync", func() {
+		It("deletes the CC user in the background and leaves UAA alone", func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/v2/users/my-user-guid", "async=true"),
+					ghttp.RespondWith(http.StatusAccepted, `{"metadata": {"guid": "job-guid"}, "entity": {"status": "queued"}}`),
+				),
+			)
+
+			err := client.DeleteAsync("my-user-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(1))
+			Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("succeeds when the CC no longer has the user", func() {
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusNotFound, `{"code": 20003, "description": "The user could not be found"}`),
+			)
+
+			err := client.DeleteAsync("my-user-guid")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("previewing role changes", func() {
+		It("lists the requests for setting an org role by GUID", func() {
+			previews, err := client.PreviewOrgRoleChange("user-guid", "alice", "org-guid", models.RoleOrgManager, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(previews).To(Equal([]models.RequestPreview{
+				{Method: "PUT", URL: ccServer.URL() + "/v2/organizations/org-guid/managers/user-guid"},
+				{Method: "PUT", URL: ccServer.URL() + "/v2/organizations/org-guid/users/user-guid"},
+			}))
+			Expect(ccServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("lists the request for unsetting an org role by username", func() {
+			previews, err := client.PreviewOrgRoleChange("", "alice", "org-guid", models.RoleBillingManager, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(previews).To(Equal([]models.RequestPreview{
+				{Method: "DELETE", URL: ccServer.URL() + "/v2/organizations/org-guid/billing_managers", Body: `{"username": "alice"}`},
+			}))
+		})
+
+		It("lists the requests for setting a space role by username", func() {
+			previews, err := client.PreviewSpaceRoleChange("", "alice", "space-guid", "org-guid", models.RoleSpaceDeveloper, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(previews).To(Equal([]models.RequestPreview{
+				{Method: "PUT", URL: ccServer.URL() + "/v2/organizations/org-guid/users", Body: `{"username": "alice"}`},
+				{Method: "PUT", URL: ccServer.URL() + "/v2/spaces/space-guid/developers", Body: `{"username": "alice"}`},
+			}))
+			Expect(ccServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("lists the request for unsetting a space role by GUID", func() {
+			previews, err := client.PreviewSpaceRoleChange("user-guid", "alice", "space-guid", "org-guid", models.RoleSpaceAuditor, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(previews).To(Equal([]models.RequestPreview{
+				{Method: "DELETE", URL: ccServer.URL() + "/v2/spaces/space-guid/auditors/user-guid"},
+			}))
+		})
+
+		It("rejects a role that does not apply", func() {
+			_, err := client.PreviewOrgRoleChange("user-guid", "alice", "org-guid", models.RoleSpaceDeveloper, true)
+			Expect(err).To(HaveOccurred())
+
+			_, err = client.PreviewSpaceRoleChange("user-guid", "alice", "space-guid", "org-guid", models.RoleOrgManager, true)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("space role changes with an unknown role", func() {
+		var role models.Role
+
+		BeforeEach(func() {
+			var err error
+			role, err = models.RoleFromString("NotARole")
+			Expect(err).To(HaveOccurred())
+			config.SetAPIVersion(cf.SetRolesByUsernameMinimumAPIVersion.String())
+		})
+
+		It("does not send any request when setting the role", func() {
+			err := client.SetSpaceRoleByUsername("alice", "space-guid", "org-guid", role)
+			Expect(err).To(MatchError("Invalid Role -1"))
+			Expect(ccServer.ReceivedRequests()).To(BeEmpty())
+			Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("does not send any request when unsetting the role", func() {
+			err := client.UnsetSpaceRoleByUsername("alice", "space-guid", role)
+			Expect(err).To(MatchError("Invalid Role -1"))
+			Expect(ccServer.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+
+	Describe("setting roles by username", func() {
+		Context("when the Cloud Controller accepts usernames", func() {
+			BeforeEach(func() {
+				config.SetAPIVersion(cf.SetRolesByUsernameMinimumAPIVersion.String())
+			})
+
+			It("sets the org role without asking UAA", func() {
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/managers"),
+						ghttp.VerifyJSON(`{"username": "alice"}`),
+						ghttp.RespondWith(http.StatusCreated, `{}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/users"),
+						ghttp.VerifyJSON(`{"username": "alice"}`),
+						ghttp.RespondWith(http.StatusCreated, `{}`),
+					),
+				)
+
+				err := client.SetOrgRoleByUsername("alice", "org-guid", models.RoleOrgManager)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+
+		Context("when the Cloud Controller is too old to accept usernames", func() {
+			BeforeEach(func() {
+				config.SetAPIVersion("2.36.0")
+				uaaServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`userName Eq "alice"`))),
+						ghttp.RespondWith(http.StatusOK, `{"resources": [{"id": "alice-guid", "userName": "alice"}]}`),
+					),
+				)
+			})
+
+			It("sets the org role by the GUID UAA has for the user", func() {
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/managers/alice-guid"),
+						ghttp.RespondWith(http.StatusCreated, `{}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/users/alice-guid"),
+						ghttp.RespondWith(http.StatusCreated, `{}`),
+					),
+				)
+
+				err := client.SetOrgRoleByUsername("alice", "org-guid", models.RoleOrgManager)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ccServer.ReceivedRequests()).To(HaveLen(2))
+			})
+
+			It("sets the space role by the GUID UAA has for the user", func() {
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/users/alice-guid"),
+						ghttp.RespondWith(http.StatusCreated, `{}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/v2/spaces/space-guid/developers/alice-guid"),
+						ghttp.RespondWith(http.StatusCreated, `{}`),
+					),
+				)
+
+				err := client.SetSpaceRoleByUsername("alice", "space-guid", "org-guid", models.RoleSpaceDeveloper)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ccServer.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+	})
+
+	Describe("setting several space roles at once", func() {
+		roles := []models.Role{models.RoleSpaceDeveloper, models.RoleSpaceManager, models.RoleSpaceAuditor}
+
+		It("adds the user to the org once and attempts every role by GUID", func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/users/user-guid"),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/spaces/space-guid/developers/user-guid"),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/spaces/space-guid/managers/user-guid"),
+					ghttp.RespondWith(http.StatusForbidden, `{"code": 10003, "description": "You are not authorized to perform the requested action", "error_code": "CF-NotAuthorized"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/spaces/space-guid/auditors/user-guid"),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+			)
+
+			results, err := client.SetSpaceRolesByGUID("user-guid", "space-guid", "org-guid", roles)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(4))
+			Expect(results).To(HaveLen(3))
+			Expect(results[0]).To(Equal(models.RoleChangeResult{UserGUID: "user-guid", Role: models.RoleSpaceDeveloper, Changed: true}))
+			Expect(results[1].Role).To(Equal(models.RoleSpaceManager))
+			Expect(results[1].Changed).To(BeFalse())
+			Expect(results[1].Err).To(MatchError(ContainSubstring("You are not authorized")))
+			Expect(results[2]).To(Equal(models.RoleChangeResult{UserGUID: "user-guid", Role: models.RoleSpaceAuditor, Changed: true}))
+		})
+
+		It("adds the user to the org once and attempts every role by username", func() {
+			config.SetAPIVersion(cf.SetRolesByUsernameMinimumAPIVersion.String())
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/users"),
+					ghttp.VerifyJSON(`{"username": "alice"}`),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/spaces/space-guid/developers"),
+					ghttp.VerifyJSON(`{"username": "alice"}`),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/spaces/space-guid/managers"),
+					ghttp.VerifyJSON(`{"username": "alice"}`),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/spaces/space-guid/auditors"),
+					ghttp.VerifyJSON(`{"username": "alice"}`),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+			)
+
+			results, err := client.SetSpaceRolesByUsername("alice", "space-guid", "org-guid", roles)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(4))
+			Expect(results).To(Equal([]models.RoleChangeResult{
+				{Role: models.RoleSpaceDeveloper, Changed: true},
+				{Role: models.RoleSpaceManager, Changed: true},
+				{Role: models.RoleSpaceAuditor, Changed: true},
+			}))
+		})
+
+		It("sends nothing when any role is not a space role", func() {
+			_, err := client.SetSpaceRolesByGUID("user-guid", "space-guid", "org-guid", []models.Role{models.RoleSpaceDeveloper, models.RoleOrgManager})
+			Expect(err).To(HaveOccurred())
+			Expect(ccServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("sets no roles when the user cannot be added to the org", func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/organizations/org-guid/users/user-guid"),
+					ghttp.RespondWith(http.StatusInternalServerError, `{"code": 10001, "description": "Unknown error", "error_code": "UnknownError"}`),
+				),
+			)
+
+			results, err := client.SetSpaceRolesByGUID("user-guid", "space-guid", "org-guid", roles)
+			Expect(err).To(HaveOccurred())
+			Expect(results).To(BeNil())
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Describe("UpdatePassword", func() {
+		setTokenScope := func(scope ...string) {
+			token, err := testconfig.EncodeAccessToken(coreconfig.TokenInfo{Username: "my-user", Scope: scope})
+			Expect(err).NotTo(HaveOccurred())
+			config.SetAccessToken(token)
+		}
+
+		It("sends the old and new passwords", func() {
+			uaaServer.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/Users/user-guid/password"),
+				ghttp.VerifyJSON(`{"password": "new-pass", "oldPassword": "old-pass"}`),
+				ghttp.RespondWith(http.StatusOK, `{"status": "ok"}`),
+			))
+
+			err := client.UpdatePassword("user-guid", "old-pass", "new-pass")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		Context("without the old password", func() {
+			It("resets the password when the token has an admin scope", func() {
+				setTokenScope("openid", "password.write")
+				uaaServer.AppendHandlers(ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/Users/user-guid/password"),
+					ghttp.VerifyJSON(`{"password": "new-pass"}`),
+					ghttp.RespondWith(http.StatusOK, `{"status": "ok"}`),
+				))
+
+				err := client.UpdatePassword("user-guid", "", "new-pass")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+			})
+
+			It("fails without a request when the token lacks the scope", func() {
+				setTokenScope("openid", "cloud_controller.read")
+
+				err := client.UpdatePassword("user-guid", "", "new-pass")
+				Expect(err).To(BeAssignableToTypeOf(&errors.MissingScopeError{}))
+				Expect(err.Error()).To(ContainSubstring("uaa.admin"))
+				Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("RenameUser", func() {
+		usernameLookup := func(body string) http.HandlerFunc {
+			return ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`userName Eq "new-name"`))),
+				ghttp.RespondWith(http.StatusOK, body),
+			)
+		}
+
+		It("patches the username with the user's current version", func() {
+			uaaServer.AppendHandlers(
+				usernameLookup(`{"resources": []}`),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/Users/user-guid"),
+					ghttp.RespondWith(http.StatusOK, `{"id": "user-guid", "userName": "old-name", "meta": {"version": 3}}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PATCH", "/Users/user-guid"),
+					ghttp.VerifyHeaderKV("If-Match", "3"),
+					ghttp.VerifyJSON(`{"userName": "new-name"}`),
+					ghttp.RespondWith(http.StatusOK, `{"id": "user-guid", "userName": "new-name"}`),
+				),
+			)
+
+			Expect(client.RenameUser("user-guid", "new-name")).To(Succeed())
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(3))
+			Expect(ccServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("refuses a username another user already has", func() {
+			uaaServer.AppendHandlers(
+				usernameLookup(`{"resources": [{"id": "other-guid", "userName": "new-name"}]}`),
+			)
+
+			err := client.RenameUser("user-guid", "new-name")
+			Expect(err).To(BeAssignableToTypeOf(&errors.ModelAlreadyExistsError{}))
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("reports a conflict from UAA as the name being taken", func() {
+			uaaServer.AppendHandlers(
+				usernameLookup(`{"resources": []}`),
+				ghttp.RespondWith(http.StatusOK, `{"meta": {"version": 3}}`),
+				ghttp.RespondWith(http.StatusConflict, `{"error": "scim_resource_already_exists", "error_description": "Username already in use: new-name"}`),
+			)
+
+			err := client.RenameUser("user-guid", "new-name")
+			Expect(err).To(BeAssignableToTypeOf(&errors.ModelAlreadyExistsError{}))
+		})
+	})
+	Describe("Create when the user may already be in UAA", func() {
+		It("creates the user in UAA and then the Cloud Controller", func() {
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/Users"),
+					ghttp.RespondWith(http.StatusCreated, `{"id": "new-user-guid"}`),
+				),
+			)
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/v2/users"),
+					ghttp.VerifyJSON(`{"guid": "new-user-guid"}`),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+			)
+
+			Expect(client.Create("my-user", "password")).To(Succeed())
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		Context("when UAA already has the user", func() {
+			BeforeEach(func() {
+				uaaServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/Users"),
+						ghttp.RespondWith(http.StatusConflict, `{"error": "scim_resource_already_exists"}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/Users", fmt.Sprintf("attributes=id,userName,origin&filter=%s", url.QueryEscape(`userName Eq "my-user"`))),
+						ghttp.RespondWith(http.StatusOK, `{"resources": [{"id": "existing-guid", "userName": "my-user"}]}`),
+					),
+				)
+			})
+
+			It("creates only the Cloud Controller record and warns that the UAA user was reused", func() {
+				ccServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/v2/users"),
+						ghttp.VerifyJSON(`{"guid": "existing-guid"}`),
+						ghttp.RespondWith(http.StatusCreated, `{}`),
+					),
+				)
+
+				err := client.Create("my-user", "password")
+				Expect(err).To(BeAssignableToTypeOf(&api.UAAUserReusedWarning{}))
+				Expect(err).To(MatchError(ContainSubstring("my-user already existed in UAA")))
+				Expect(ccServer.ReceivedRequests()).To(HaveLen(1))
+			})
+
+			It("reports that the user exists when the Cloud Controller has it too", func() {
+				ccServer.AppendHandlers(
+					ghttp.RespondWith(http.StatusBadRequest, `{"code": 20002, "description": "The UAA ID is taken: existing-guid", "error_code": "CF-UaaIdTaken"}`),
+				)
+
+				err := client.Create("my-user", "password")
+				Expect(err).To(BeAssignableToTypeOf(&errors.ModelAlreadyExistsError{}))
+			})
+		})
+	})
+
+	Describe("CreateWithOrigin", func() {
+		BeforeEach(func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/v2/users"),
+					ghttp.VerifyJSON(`{"guid": "my-user-guid"}`),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+			)
+		})
+
+		It("creates an external user without a password", func() {
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/Users"),
+					ghttp.VerifyJSON(`{
+						"userName": "my-user@example.com",
+						"emails": [{ "value": "my-user@example.com" }],
+						"name": { "givenName": "my-user@example.com", "familyName": "my-user@example.com" },
+						"origin": "ldap"
+					}`),
+					ghttp.RespondWith(http.StatusCreated, `{ "id": "my-user-guid" }`),
+				),
+			)
+
+			err := client.CreateWithOrigin("my-user@example.com", "ignored", "ldap")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("creates an internal user with its password for the uaa origin", func() {
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/Users"),
+					ghttp.VerifyJSON(`{
+						"userName": "my-user",
+						"emails": [{ "value": "my-user" }],
+						"password": "password",
+						"name": { "givenName": "my-user", "familyName": "my-user" }
+					}`),
+					ghttp.RespondWith(http.StatusCreated, `{ "id": "my-user-guid" }`),
+				),
+			)
+
+			err := client.CreateWithOrigin("my-user", "password", "uaa")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("CreateBulk", func() {
+		It("creates every user, collecting failures and rolling back orphaned UAA users", func() {
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/Users"),
+					ghttp.VerifyJSONRepresenting(resources.NewUAAUserResource("user-a", "pass-a")),
+					ghttp.RespondWith(http.StatusCreated, `{"id": "user-a-guid"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/Users"),
+					ghttp.RespondWith(http.StatusConflict, `{"error": "scim_resource_already_exists"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/Users"),
+					ghttp.RespondWith(http.StatusCreated, `{"id": "user-c-guid"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/Users/user-c-guid"),
+					ghttp.RespondWith(http.StatusOK, `{}`),
+				),
+			)
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/v2/users"),
+					ghttp.VerifyJSON(`{"guid": "user-a-guid"}`),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/v2/users"),
+					ghttp.VerifyJSON(`{"guid": "user-c-guid"}`),
+					ghttp.RespondWith(http.StatusInternalServerError, `{"code": 10001, "description": "boom", "error_code": "CF-ServerError"}`),
+				),
+			)
+
+			results, err := client.CreateBulk([]models.UserCreateRequest{
+				{Username: "user-a", Password: "pass-a"},
+				{Username: "user-b", Password: "pass-b"},
+				{Username: "user-c", Password: "pass-c"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(HaveLen(3))
+			Expect(results[0]).To(Equal(models.UserCreateResult{
+				User: models.UserFields{GUID: "user-a-guid", Username: "user-a", Email: "user-a"},
+			}))
+			Expect(results[1].User).To(Equal(models.UserFields{}))
+			Expect(results[1].Err).To(BeAssignableToTypeOf(&errors.ModelAlreadyExistsError{}))
+			Expect(results[2].User).To(Equal(models.UserFields{}))
+			Expect(results[2].Err).To(HaveOccurred())
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(4))
+		})
+
+		It("reports a UAA user left behind when the rollback fails", func() {
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusCreated, `{"id": "user-a-guid"}`),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/Users/user-a-guid"),
+					ghttp.RespondWith(http.StatusForbidden, `{"error": "access_denied", "error_description": "no delete"}`),
+				),
+			)
+			ccServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusBadRequest, `{"code": 10001, "description": "boom", "error_code": "CF-ServerError"}`),
+			)
+
+			results, err := client.CreateBulk([]models.UserCreateRequest{{Username: "user-a", Password: "pass-a"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results[0].Err).To(BeAssignableToTypeOf(&api.UAARollbackError{}))
+			rollbackErr := results[0].Err.(*api.UAARollbackError)
+			Expect(rollbackErr.Username).To(Equal("user-a"))
+			Expect(rollbackErr.GUID).To(Equal("user-a-guid"))
+			Expect(rollbackErr.Error()).To(ContainSubstring("boom"))
+			Expect(rollbackErr.Error()).To(ContainSubstring("user-a-guid"))
+		})
+
+		It("reports an invalid email username without calling UAA", func() {
+			results, err := client.CreateBulk([]models.UserCreateRequest{{Username: "user@", Password: "pass"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results[0].Err).To(MatchError("Invalid email address user@"))
+			Expect(uaaServer.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+
+	Describe("post create hook", func() {
+		var created []models.UserFields
+
+		BeforeEach(func() {
+			created = nil
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/Users"),
+					ghttp.RespondWith(http.StatusCreated, `{ "id": "my-user-guid" }`),
+				),
+			)
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/v2/users"),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+			)
+		})
+
+		It("calls the hook with the created user", func() {
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway,
+				api.WithPostCreateHook(func(user models.UserFields) error {
+					created = append(created, user)
+					return nil
+				}))
+
+			err := client.CreateWithEmail("my-user", "password", "my-user@example.com")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(Equal([]models.UserFields{{
+				GUID:     "my-user-guid",
+				Username: "my-user",
+				Email:    "my-user@example.com",
+			}}))
+		})
+
+		It("returns the hook error without undoing the creation", func() {
+			client = api.NewCloudControllerUserRepository(config, uaaGateway, ccGateway,
+				api.WithPostCreateHook(func(models.UserFields) error {
+					return errors.New("hook failed")
+				}))
+
+			err := client.Create("my-user", "password")
+			Expect(err).To(BeAssignableToTypeOf(&api.PostCreateHookError{}))
+			Expect(err.(*api.PostCreateHookError).Err).To(MatchError("hook failed"))
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Describe("ExportFoundationRBAC", func() {
+		var uaaUsernames map[string]string
+
+		BeforeEach(func() {
+			uaaUsernames = map[string]string{"user-1-guid": "alice", "user-2-guid": "bob"}
+			usersJSON := func(guids ...string) string {
+				var resources []string
+				for _, guid := range guids {
+					resources = append(resources, fmt.Sprintf(`{"metadata": {"guid": "%s"}, "entity": {"username": "cc-%s"}}`, guid, guid))
+				}
+				return fmt.Sprintf(`{"total_results": %d, "resources": [%s]}`, len(guids), strings.Join(resources, ","))
+			}
+			holders := map[string][]string{
+				"/v2/organizations/org-1-guid/managers": {"user-1-guid"},
+				"/v2/organizations/org-1-guid/users":    {"user-1-guid", "user-2-guid"},
+				"/v2/organizations/org-2-guid/users":    {"user-2-guid"},
+				"/v2/spaces/space-1-guid/developers":    {"user-1-guid", "user-2-guid"},
+				"/v2/spaces/space-3-guid/auditors":      {"user-2-guid"},
+			}
+			for _, guid := range []string{"org-1-guid", "org-2-guid"} {
+				for _, path := range []string{"users", "managers", "billing_managers", "auditors"} {
+					route := fmt.Sprintf("/v2/organizations/%s/%s", guid, path)
+					ccServer.RouteToHandler("GET", route, ghttp.RespondWith(http.StatusOK, usersJSON(holders[route]...)))
+				}
+			}
+			for _, guid := range []string{"space-1-guid", "space-2-guid", "space-3-guid"} {
+				for _, path := range []string{"managers", "developers", "auditors"} {
+					route := fmt.Sprintf("/v2/spaces/%s/%s", guid, path)
+					ccServer.RouteToHandler("GET", route, ghttp.RespondWith(http.StatusOK, usersJSON(holders[route]...)))
+				}
+			}
+			ccServer.RouteToHandler("GET", "/v2/organizations", ghttp.RespondWith(http.StatusOK, `{"resources": [
+				{"metadata": {"guid": "org-1-guid"}, "entity": {"name": "org-1"}},
+				{"metadata": {"guid": "org-2-guid"}, "entity": {"name": "org-2"}}
+			]}`))
+			ccServer.RouteToHandler("GET", "/v2/organizations/org-1-guid/spaces", ghttp.RespondWith(http.StatusOK, `{"resources": [
+				{"metadata": {"guid": "space-1-guid"}, "entity": {"name": "space-1"}},
+				{"metadata": {"guid": "space-2-guid"}, "entity": {"name": "space-2"}}
+			]}`))
+			ccServer.RouteToHandler("GET", "/v2/organizations/org-2-guid/spaces", ghttp.RespondWith(http.StatusOK, `{"resources": [
+				{"metadata": {"guid": "space-3-guid"}, "entity": {"name": "space-3"}}
+			]}`))
+			uaaServer.RouteToHandler("GET", "/Users", func(w http.ResponseWriter, r *http.Request) {
+				var resources []string
+				for _, guid := range []string{"user-1-guid", "user-2-guid"} {
+					if strings.Contains(r.URL.Query().Get("filter"), guid) {
+						resources = append(resources, fmt.Sprintf(`{"id": "%s", "userName": "%s"}`, guid, uaaUsernames[guid]))
+					}
+				}
+				fmt.Fprintf(w, `{"resources": [%s]}`, strings.Join(resources, ","))
+			})
+		})
+
+		decodeRecords := func(output *bytes.Buffer) []map[string]interface{} {
+			var records []map[string]interface{}
+			decoder := json.NewDecoder(output)
+			for decoder.More() {
+				var record map[string]interface{}
+				Expect(decoder.Decode(&record)).To(Succeed())
+				records = append(records, record)
+			}
+			return records
+		}
+
+		It("streams each org followed by its spaces with the holders of every role", func() {
+			output := new(bytes.Buffer)
+			err := client.ExportFoundationRBAC(output)
+			Expect(err).NotTo(HaveOccurred())
+
+			records := decodeRecords(output)
+			Expect(records).To(HaveLen(5))
+			Expect(records[0]).To(Equal(map[string]interface{}{
+				"type": "organization",
+				"guid": "org-1-guid",
+				"name": "org-1",
+				"roles": map[string]interface{}{
+					"users": []interface{}{
+						map[string]interface{}{"guid": "user-1-guid", "username": "alice"},
+						map[string]interface{}{"guid": "user-2-guid", "username": "bob"},
+					},
+					"managers":         []interface{}{map[string]interface{}{"guid": "user-1-guid", "username": "alice"}},
+					"billing_managers": []interface{}{},
+					"auditors":         []interface{}{},
+				},
+			}))
+			Expect(records[1]).To(Equal(map[string]interface{}{
+				"type":              "space",
+				"guid":              "space-1-guid",
+				"name":              "space-1",
+				"organization_guid": "org-1-guid",
+				"roles": map[string]interface{}{
+					"managers": []interface{}{},
+					"developers": []interface{}{
+						map[string]interface{}{"guid": "user-1-guid", "username": "alice"},
+						map[string]interface{}{"guid": "user-2-guid", "username": "bob"},
+					},
+					"auditors": []interface{}{},
+				},
+			}))
+			Expect(records[2]).To(HaveKeyWithValue("guid", "space-2-guid"))
+			Expect(records[3]).To(HaveKeyWithValue("guid", "org-2-guid"))
+			Expect(records[3]).To(HaveKeyWithValue("type", "organization"))
+			Expect(records[4]).To(HaveKeyWithValue("guid", "space-3-guid"))
+			Expect(records[4]).To(HaveKeyWithValue("organization_guid", "org-2-guid"))
+		})
+
+		It("falls back to Cloud Controller usernames and warns when UAA is unavailable", func() {
+			uaaServer.RouteToHandler("GET", "/Users", ghttp.RespondWith(http.StatusServiceUnavailable, `{"error": "unavailable"}`))
+
+			output := new(bytes.Buffer)
+			err := client.ExportFoundationRBAC(output)
+			Expect(err).To(BeAssignableToTypeOf(&api.PartialUAALookupWarning{}))
+			Expect(err.(*api.PartialUAALookupWarning).Count).To(Equal(7))
+
+			records := decodeRecords(output)
+			Expect(records).To(HaveLen(5))
+			Expect(records[0]["roles"]).To(HaveKeyWithValue("managers",
+				[]interface{}{map[string]interface{}{"guid": "user-1-guid", "username": "cc-user-1-guid"}}))
+		})
+	})
+	Describe("DiffSpaceUsers", func() {
+		BeforeEach(func() {
+			respond := func(path string, body string) {
+				ccServer.RouteToHandler("GET", path, ghttp.RespondWith(http.StatusOK, body))
+			}
+			respond("/v2/spaces/space-a-guid/managers", `{"resources": [
+				{"metadata": {"guid": "shared-guid"}, "entity": {"username": "shared"}},
+				{"metadata": {"guid": "only-a-guid"}, "entity": {"username": "only-a"}}
+			]}`)
+			respond("/v2/spaces/space-b-guid/managers", `{"resources": [
+				{"metadata": {"guid": "shared-guid"}, "entity": {"username": "shared"}}
+			]}`)
+			respond("/v2/spaces/space-a-guid/developers", `{"resources": []}`)
+			respond("/v2/spaces/space-b-guid/developers", `{"resources": [
+				{"metadata": {"guid": "only-b-guid"}, "entity": {"username": "only-b"}}
+			]}`)
+			respond("/v2/spaces/space-a-guid/auditors", `{"resources": [
+				{"metadata": {"guid": "shared-guid"}, "entity": {"username": "shared"}}
+			]}`)
+			respond("/v2/spaces/space-b-guid/auditors", `{"resources": [
+				{"metadata": {"guid": "shared-guid"}, "entity": {"username": "shared"}}
+			]}`)
+		})
+
+		It("returns the users in only one space for each differing role", func() {
+			diffs, err := client.DiffSpaceUsers("space-a-guid", "space-b-guid")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(diffs).To(HaveLen(2))
+			Expect(diffs[0].Role).To(Equal(models.RoleSpaceManager))
+			Expect(diffs[0].OnlyInA).To(Equal([]models.UserFields{{GUID: "only-a-guid", Username: "only-a"}}))
+			Expect(diffs[0].OnlyInB).To(BeEmpty())
+			Expect(diffs[1].Role).To(Equal(models.RoleSpaceDeveloper))
+			Expect(diffs[1].OnlyInA).To(BeEmpty())
+			Expect(diffs[1].OnlyInB).To(Equal([]models.UserFields{{GUID: "only-b-guid", Username: "only-b"}}))
+		})
+	})
+})
+
+type uaaGroupAdminResolver struct {
+	group string
+}
+
+func (resolver uaaGroupAdminResolver) IsAdmin(user models.UserFields, uaaGroups []string) bool {
+	for _, group := range uaaGroups {
+		if group == resolver.group {
+			return true
+		}
+	}
+	return false
+}
+
+type ccFlagReadingResolver struct {
+	api.CCFlagAdminResolver
+}
+
+func (ccFlagReadingResolver) ReadsCCAdminFlag() bool {
+	return true
+}
+
+type countingCollector struct {
+	counts map[string]int
+}
+
+func (collector *countingCollector) IncrementCounter(name string, labels map[string]string) {
+	if len(labels) > 0 {
+		name = fmt.Sprintf("%s{method=%s,outcome=%s}", name, labels["method"], labels["outcome"])
+	}
+	collector.counts[name]++
+}
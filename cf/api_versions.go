@@ -12,6 +12,7 @@ var (
 	OrgAppInstanceLimitMinimumAPIVersion, _             = semver.Make("2.33.0")
 	ListUsersInOrgOrSpaceWithoutUAAMinimumAPIVersion, _ = semver.Make("2.21.0")
 	UpdateServicePlanMinimumAPIVersion, _               = semver.Make("2.16.0")
+	V3RolesMinimumAPIVersion, _                         = semver.Make("2.145.0")
 
 	ServiceAuthTokenMaximumAPIVersion, _ = semver.Make("2.46.0")
 	SpaceScopedMaximumAPIVersion, _      = semver.Make("2.47.0")
@@ -14,6 +14,7 @@ import (
 	"code.cloudfoundry.org/cli/cf/configuration/confighelpers"
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
 	"code.cloudfoundry.org/cli/cf/configuration/pluginconfig"
+	"code.cloudfoundry.org/cli/cf/errors"
 	"code.cloudfoundry.org/cli/cf/flags"
 	. "code.cloudfoundry.org/cli/cf/i18n"
 	"code.cloudfoundry.org/cli/cf/net"
@@ -114,14 +115,14 @@ func Main(traceEnv string, args []string) {
 			err = req.Execute()
 			if err != nil {
 				deps.UI.Failed(err.Error())
-				os.Exit(1)
+				os.Exit(errors.ExitCode(err))
 			}
 		}
 
 		err = cmd.Execute(flagContext)
 		if err != nil {
 			deps.UI.Failed(err.Error())
-			os.Exit(1)
+			os.Exit(errors.ExitCode(err))
 		}
 
 		err = warningsCollector.PrintWarnings()
@@ -7,6 +7,7 @@ import (
 	"code.cloudfoundry.org/cli/cf/commandregistry"
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
 	"code.cloudfoundry.org/cli/cf/flags"
+	"code.cloudfoundry.org/cli/cf/net"
 	"code.cloudfoundry.org/cli/cf/requirements"
 	"code.cloudfoundry.org/cli/cf/terminal"
 
@@ -25,6 +26,8 @@ func init() {
 func (cmd *ConfigCommands) MetaData() commandregistry.CommandMetadata {
 	fs := make(map[string]flags.FlagSet)
 	fs["async-timeout"] = &flags.IntFlag{Name: "async-timeout", Usage: T("Timeout for async HTTP requests")}
+	fs["uaa-dial-timeout"] = &flags.IntFlag{Name: "uaa-dial-timeout", Usage: T("Timeout for connecting to UAA when looking up users, 0 to use the default")}
+	fs["results-per-page"] = &flags.IntFlag{Name: "results-per-page", Usage: T("Number of results to ask for per page when listing, up to 100, 0 to use the default")}
 	fs["trace"] = &flags.StringFlag{Name: "trace", Usage: T("Trace HTTP requests")}
 	fs["color"] = &flags.StringFlag{Name: "color", Usage: T("Enable or disable color")}
 	fs["locale"] = &flags.StringFlag{Name: "locale", Usage: T("Set default locale. If LOCALE is 'CLEAR', previous locale is deleted.")}
@@ -33,7 +36,7 @@ func (cmd *ConfigCommands) MetaData() commandregistry.CommandMetadata {
 		Name:        "config",
 		Description: T("Write default values to the config"),
 		Usage: []string{
-			T("CF_NAME config [--async-timeout TIMEOUT_IN_MINUTES] [--trace (true | false | path/to/file)] [--color (true | false)] [--locale (LOCALE | CLEAR)]"),
+			T("CF_NAME config [--async-timeout TIMEOUT_IN_MINUTES] [--uaa-dial-timeout TIMEOUT_IN_SECONDS] [--results-per-page COUNT] [--trace (true | false | path/to/file)] [--color (true | false)] [--locale (LOCALE | CLEAR)]"),
 		},
 		Flags: fs,
 	}
@@ -51,7 +54,7 @@ func (cmd *ConfigCommands) SetDependency(deps commandregistry.Dependency, plugin
 }
 
 func (cmd *ConfigCommands) Execute(context flags.FlagContext) error {
-	if !context.IsSet("trace") && !context.IsSet("async-timeout") && !context.IsSet("color") && !context.IsSet("locale") {
+	if !context.IsSet("trace") && !context.IsSet("async-timeout") && !context.IsSet("uaa-dial-timeout") && !context.IsSet("results-per-page") && !context.IsSet("color") && !context.IsSet("locale") {
 		return errors.New(T("Incorrect Usage") + "\n\n" + commandregistry.Commands.CommandUsage("config"))
 	}
 
@@ -64,6 +67,24 @@ func (cmd *ConfigCommands) Execute(context flags.FlagContext) error {
 		cmd.config.SetAsyncTimeout(uint(asyncTimeout))
 	}
 
+	if context.IsSet("uaa-dial-timeout") {
+		uaaDialTimeout := context.Int("uaa-dial-timeout")
+		if uaaDialTimeout < 0 {
+			return errors.New(T("Incorrect Usage") + "\n\n" + commandregistry.Commands.CommandUsage("config"))
+		}
+
+		cmd.config.SetUAADialTimeout(uint(uaaDialTimeout))
+	}
+
+	if context.IsSet("results-per-page") {
+		resultsPerPage := context.Int("results-per-page")
+		if resultsPerPage < 0 || resultsPerPage > net.MaxResultsPerPage {
+			return errors.New(T("Incorrect Usage") + "\n\n" + commandregistry.Commands.CommandUsage("config"))
+		}
+
+		cmd.config.SetResultsPerPage(uint(resultsPerPage))
+	}
+
 	if context.IsSet("trace") {
 		cmd.config.SetTrace(context.String("trace"))
 	}
@@ -66,6 +66,36 @@ var _ = Describe("config command", func() {
 		})
 	})
 
+	Context("--uaa-dial-timeout flag", func() {
+		It("stores the timeout in seconds", func() {
+			runCommand("--uaa-dial-timeout", "30")
+			Expect(configRepo.UAADialTimeout()).To(Equal(uint(30)))
+		})
+
+		It("fails with usage when a negative timeout is passed", func() {
+			runCommand("--uaa-dial-timeout", "-1")
+			Expect(ui.Outputs()).To(ContainSubstrings(
+				[]string{"Incorrect Usage"},
+			))
+			Expect(configRepo.UAADialTimeout()).To(Equal(uint(0)))
+		})
+	})
+
+	Context("--results-per-page flag", func() {
+		It("stores the page size", func() {
+			runCommand("--results-per-page", "100")
+			Expect(configRepo.ResultsPerPage()).To(Equal(uint(100)))
+		})
+
+		It("fails with usage when the page size is above the Cloud Controller maximum", func() {
+			runCommand("--results-per-page", "101")
+			Expect(ui.Outputs()).To(ContainSubstrings(
+				[]string{"Incorrect Usage"},
+			))
+			Expect(configRepo.ResultsPerPage()).To(Equal(uint(0)))
+		})
+	})
+
 	Context("--trace flag", func() {
 		It("stores the trace value when --trace flag is provided", func() {
 			runCommand("--trace", "true")
@@ -2,22 +2,34 @@ package space
 
 import (
 	"errors"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 
+	"code.cloudfoundry.org/cli/cf/api/organizations"
+	"code.cloudfoundry.org/cli/cf/api/spacequotas"
 	"code.cloudfoundry.org/cli/cf/api/spaces"
 	"code.cloudfoundry.org/cli/cf/commandregistry"
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
 	"code.cloudfoundry.org/cli/cf/flags"
+	"code.cloudfoundry.org/cli/cf/formatters"
 	. "code.cloudfoundry.org/cli/cf/i18n"
 	"code.cloudfoundry.org/cli/cf/models"
+	"code.cloudfoundry.org/cli/cf/net"
 	"code.cloudfoundry.org/cli/cf/requirements"
 	"code.cloudfoundry.org/cli/cf/terminal"
 	"code.cloudfoundry.org/cli/plugin/models"
+	xterminal "golang.org/x/crypto/ssh/terminal"
 )
 
 type ListSpaces struct {
 	ui        terminal.UI
 	config    coreconfig.Reader
 	spaceRepo spaces.SpaceRepository
+	orgRepo   organizations.OrganizationRepository
+	quotaRepo spacequotas.SpaceQuotaRepository
 
 	pluginModel *[]plugin_models.GetSpaces_Model
 	pluginCall  bool
@@ -27,13 +39,25 @@ func init() {
 	commandregistry.Register(&ListSpaces{})
 }
 
+var spaceSortKeys = []string{"name", "created", "apps"}
+
 func (cmd *ListSpaces) MetaData() commandregistry.CommandMetadata {
+	fs := make(map[string]flags.FlagSet)
+	fs["sort-by"] = &flags.StringFlag{Name: "sort-by", Usage: T("Sort spaces by name, created or apps (default: name)")}
+	fs["all"] = &flags.BoolFlag{Name: "all", Usage: T("List spaces in every org, not just the targeted one")}
+	fs["show-ssh"] = &flags.BoolFlag{Name: "show-ssh", Usage: T("Show whether SSH is allowed in each space")}
+	fs["page"] = &flags.BoolFlag{Name: "page", Usage: T("Pause after each screenful when writing to a terminal")}
+	fs["by-segment"] = &flags.BoolFlag{Name: "by-segment", Usage: T("Group spaces by their isolation segment")}
+	fs["no-header"] = &flags.BoolFlag{Name: "no-header", Usage: T("Do not print the column header row")}
+	fs["detailed"] = &flags.BoolFlag{Name: "detailed", Usage: T("Show the space quota, app and service counts and memory usage of each space (slower; looks up every space)")}
+
 	return commandregistry.CommandMetadata{
 		Name:        "spaces",
 		Description: T("List all spaces in an org"),
 		Usage: []string{
-			T("CF_NAME spaces"),
+			T("CF_NAME spaces [--all] [--sort-by name|created|apps] [--show-ssh] [--page] [--by-segment] [--no-header] [--detailed]"),
 		},
+		Flags: fs,
 	}
 
 }
@@ -46,10 +70,20 @@ func (cmd *ListSpaces) Requirements(requirementsFactory requirements.Factory, fc
 		},
 	)
 
+	sortByReq := requirements.NewUsageRequirement(commandregistry.CLICommandUsagePresenter(cmd),
+		T("--sort-by must be one of name, created or apps"),
+		func() bool {
+			return fc.IsSet("sort-by") && !isSpaceSortKey(fc.String("sort-by"))
+		},
+	)
+
 	reqs := []requirements.Requirement{
 		usageReq,
+		sortByReq,
 		requirementsFactory.NewLoginRequirement(),
-		requirementsFactory.NewTargetedOrgRequirement(),
+	}
+	if !fc.Bool("all") {
+		reqs = append(reqs, requirementsFactory.NewTargetedOrgRequirement())
 	}
 
 	return reqs, nil
@@ -59,23 +93,81 @@ func (cmd *ListSpaces) SetDependency(deps commandregistry.Dependency, pluginCall
 	cmd.ui = deps.UI
 	cmd.config = deps.Config
 	cmd.spaceRepo = deps.RepoLocator.GetSpaceRepository()
+	cmd.orgRepo = deps.RepoLocator.GetOrganizationRepository()
+	cmd.quotaRepo = deps.RepoLocator.GetSpaceQuotaRepository()
 	cmd.pluginCall = pluginCall
 	cmd.pluginModel = deps.PluginModels.Spaces
 	return cmd
 }
 
 func (cmd *ListSpaces) Execute(c flags.FlagContext) error {
-	cmd.ui.Say(T("Getting spaces in org {{.TargetOrgName}} as {{.CurrentUser}}...\n",
-		map[string]interface{}{
-			"TargetOrgName": terminal.EntityNameColor(cmd.config.OrganizationFields().Name),
-			"CurrentUser":   terminal.EntityNameColor(cmd.config.Username()),
-		}))
-
-	foundSpaces := false
-	table := cmd.ui.Table([]string{T("name")})
-	err := cmd.spaceRepo.ListSpaces(func(space models.Space) bool {
-		table.Add(space.Name)
-		foundSpaces = true
+	allOrgs := c.Bool("all")
+	listSpaces := cmd.spaceRepo.ListSpaces
+	if allOrgs {
+		cmd.ui.Say(T("Getting spaces in all orgs as {{.CurrentUser}}...\n",
+			map[string]interface{}{
+				"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
+			}))
+		listSpaces = cmd.spaceRepo.ListAllSpaces
+	} else {
+		cmd.ui.Say(T("Getting spaces in org {{.TargetOrgName}} as {{.CurrentUser}}...\n",
+			map[string]interface{}{
+				"TargetOrgName": terminal.EntityNameColor(cmd.config.OrganizationFields().Name),
+				"CurrentUser":   terminal.EntityNameColor(cmd.config.Username()),
+			}))
+	}
+
+	orgNames := map[string]string{}
+	showSSH := c.Bool("show-ssh")
+	headers := []string{T("name")}
+	if allOrgs {
+		headers = append(headers, T("org"))
+	}
+	if showSSH {
+		headers = append(headers, T("ssh"))
+	}
+	detailed := c.Bool("detailed")
+	if detailed {
+		headers = append(headers, T("quota"), T("apps"), T("services"), T("memory"))
+	}
+
+	quotaNames := map[string]map[string]string{}
+	row := func(space models.Space) ([]string, error) {
+		row := []string{space.Name}
+		if allOrgs {
+			row = append(row, cmd.resolveOrgName(orgNames, space))
+		}
+		if showSSH {
+			row = append(row, sshStatus(space.AllowSSH))
+		}
+		if detailed {
+			details, err := cmd.spaceDetails(quotaNames, space)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, details...)
+		}
+		return row, nil
+	}
+
+	pageSize := 0
+	if c.Bool("page") {
+		if rows, ok := terminalRows(cmd.ui.Writer()); ok {
+			pageSize = rows
+		}
+	}
+	noHeader := c.Bool("no-header")
+
+	// The Cloud Controller already lists spaces by name, so unless they are
+	// to be sorted or grouped here, each is printed as it arrives.
+	sortBy := c.String("sort-by")
+	if !cmd.pluginCall && !c.Bool("by-segment") && sortBy == "" {
+		return cmd.streamSpaces(listSpaces, headers, row, pageSize, noHeader)
+	}
+
+	var spaceList []models.Space
+	err := listSpaces(func(space models.Space) bool {
+		spaceList = append(spaceList, space)
 
 		if cmd.pluginCall {
 			s := plugin_models.GetSpaces_Model{}
@@ -86,11 +178,102 @@ func (cmd *ListSpaces) Execute(c flags.FlagContext) error {
 
 		return true
 	})
-	err = table.Print()
 	if err != nil {
+		return errors.New(T("Failed fetching spaces.\n{{.ErrorDescription}}",
+			map[string]interface{}{
+				"ErrorDescription": err.Error(),
+			}))
+	}
+
+	sortSpaces(spaceList, sortBy)
+
+	if pageSize == 0 {
+		pageSize = len(spaceList)
+	}
+
+	if len(spaceList) == 0 {
+		return cmd.sayNoSpaces(headers, noHeader)
+	}
+
+	if !c.Bool("by-segment") {
+		_, err = cmd.printSpaces(spaceList, headers, row, pageSize, noHeader)
 		return err
 	}
 
+	groups, err := cmd.groupBySegment(spaceList)
+	if err != nil {
+		return err
+	}
+	for i, group := range groups {
+		if i > 0 {
+			cmd.ui.Say("")
+		}
+		cmd.ui.Say(T("isolation segment: {{.Segment}}",
+			map[string]interface{}{"Segment": terminal.EntityNameColor(group.segment)}))
+		stopped, err := cmd.printSpaces(group.spaces, headers, row, pageSize, noHeader)
+		if err != nil || stopped {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamSpaces prints the spaces as listSpaces delivers them, so the first
+// rows appear before the last page has been fetched. Rows are printed a
+// Cloud Controller page at a time, so that the columns of each page line up.
+// With a pageSize it pauses after every pageSize rows, and stops listing if
+// the user asks to.
+func (cmd *ListSpaces) streamSpaces(listSpaces func(func(models.Space) bool) error, headers []string, row func(models.Space) ([]string, error), pageSize int, noHeader bool) error {
+	table := cmd.ui.Table(headers)
+	if noHeader {
+		table.Table.NoHeaders()
+	}
+
+	batchSize := int(cmd.config.ResultsPerPage())
+	if batchSize == 0 {
+		batchSize = net.MaxResultsPerPage
+	}
+
+	var printed, pending int
+	var printErr error
+	flush := func() error {
+		if pending == 0 {
+			return nil
+		}
+		printed += pending
+		pending = 0
+		return table.Print()
+	}
+	err := listSpaces(func(space models.Space) bool {
+		if pageSize > 0 && printed+pending > 0 && (printed+pending)%pageSize == 0 {
+			printErr = flush()
+			if printErr != nil {
+				return false
+			}
+			answer := cmd.ui.Ask(T("Press Enter for more, or q to stop"))
+			if strings.EqualFold(answer, "q") {
+				return false
+			}
+		}
+
+		var cells []string
+		cells, printErr = row(space)
+		if printErr != nil {
+			return false
+		}
+		table.Add(cells...)
+		pending++
+		if pending == batchSize {
+			printErr = flush()
+		}
+		return printErr == nil
+	})
+	if flushErr := flush(); printErr == nil {
+		printErr = flushErr
+	}
+	if printErr != nil {
+		return printErr
+	}
 	if err != nil {
 		return errors.New(T("Failed fetching spaces.\n{{.ErrorDescription}}",
 			map[string]interface{}{
@@ -98,8 +281,211 @@ func (cmd *ListSpaces) Execute(c flags.FlagContext) error {
 			}))
 	}
 
-	if !foundSpaces {
-		cmd.ui.Say(T("No spaces found"))
+	if printed == 0 {
+		return cmd.sayNoSpaces(headers, noHeader)
 	}
 	return nil
 }
+
+func (cmd *ListSpaces) sayNoSpaces(headers []string, noHeader bool) error {
+	if !noHeader {
+		err := cmd.ui.Table(headers).Print()
+		if err != nil {
+			return err
+		}
+	}
+	cmd.ui.Say(T("No spaces found"))
+	return nil
+}
+
+// printSpaces prints a table of spaceList, pausing after every pageSize
+// rows. It returns true when the user chose to stop.
+func (cmd *ListSpaces) printSpaces(spaceList []models.Space, headers []string, row func(models.Space) ([]string, error), pageSize int, noHeader bool) (bool, error) {
+	newTable := func() *terminal.UITable {
+		table := cmd.ui.Table(headers)
+		if noHeader {
+			table.Table.NoHeaders()
+		}
+		return table
+	}
+
+	table := newTable()
+	for i, space := range spaceList {
+		cells, err := row(space)
+		if err != nil {
+			return false, err
+		}
+		table.Add(cells...)
+
+		if (i+1)%pageSize == 0 && i+1 < len(spaceList) {
+			err := table.Print()
+			if err != nil {
+				return false, err
+			}
+			answer := cmd.ui.Ask(T("Press Enter for more, or q to stop"))
+			if strings.EqualFold(answer, "q") {
+				return true, nil
+			}
+			table = newTable()
+		}
+	}
+	return false, table.Print()
+}
+
+type segmentGroup struct {
+	segment string
+	spaces  []models.Space
+}
+
+// groupBySegment splits spaceList by isolation segment, keeping the order of
+// spaces within each group. Segments are sorted by name, followed by the
+// spaces with no segment under "shared".
+func (cmd *ListSpaces) groupBySegment(spaceList []models.Space) ([]segmentGroup, error) {
+	spaceGUIDs := make([]string, 0, len(spaceList))
+	for _, space := range spaceList {
+		spaceGUIDs = append(spaceGUIDs, space.GUID)
+	}
+	segmentNames, err := cmd.spaceRepo.ListIsolationSegmentNames(spaceGUIDs)
+	if err != nil {
+		return nil, errors.New(T("Failed fetching isolation segments.\n{{.ErrorDescription}}",
+			map[string]interface{}{
+				"ErrorDescription": err.Error(),
+			}))
+	}
+
+	bySegment := map[string][]models.Space{}
+	var shared []models.Space
+	for _, space := range spaceList {
+		segment, assigned := segmentNames[space.GUID]
+		if !assigned {
+			shared = append(shared, space)
+			continue
+		}
+		bySegment[segment] = append(bySegment[segment], space)
+	}
+
+	groups := make([]segmentGroup, 0, len(bySegment)+1)
+	for segment, spaces := range bySegment {
+		groups = append(groups, segmentGroup{segment: segment, spaces: spaces})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].segment < groups[j].segment
+	})
+	if len(shared) > 0 {
+		groups = append(groups, segmentGroup{segment: T("shared"), spaces: shared})
+	}
+	return groups, nil
+}
+
+// resolveOrgName returns the name of the org space belongs to, looking each
+// org up once and remembering it in orgNames. Orgs that cannot be looked up
+// are shown by GUID.
+func (cmd *ListSpaces) resolveOrgName(orgNames map[string]string, space models.Space) string {
+	orgGUID := space.Organization.GUID
+	if name, resolved := orgNames[orgGUID]; resolved {
+		return name
+	}
+
+	orgNames[orgGUID] = orgGUID
+	if space.Organization.Name != "" {
+		orgNames[orgGUID] = space.Organization.Name
+	} else if orgs, err := cmd.orgRepo.GetManyOrgsByGUID([]string{orgGUID}); err == nil && len(orgs) == 1 {
+		orgNames[orgGUID] = orgs[0].Name
+	}
+	return orgNames[orgGUID]
+}
+
+// spaceDetails returns the quota name, app and service counts and memory
+// usage columns for space. Each org's space quotas are looked up once and
+// remembered in quotaNames.
+func (cmd *ListSpaces) spaceDetails(quotaNames map[string]map[string]string, space models.Space) ([]string, error) {
+	summary, err := cmd.spaceRepo.GetSpaceSummary(space.GUID)
+	if err != nil {
+		return nil, errors.New(T("Failed fetching details of space {{.SpaceName}}.\n{{.ErrorDescription}}",
+			map[string]interface{}{
+				"SpaceName":        space.Name,
+				"ErrorDescription": err.Error(),
+			}))
+	}
+
+	quotaName := T("none")
+	if space.SpaceQuotaGUID != "" {
+		orgGUID := space.Organization.GUID
+		if _, found := quotaNames[orgGUID]; !found {
+			quotas, err := cmd.quotaRepo.FindByOrg(orgGUID)
+			if err != nil {
+				return nil, errors.New(T("Failed fetching space quotas.\n{{.ErrorDescription}}",
+					map[string]interface{}{
+						"ErrorDescription": err.Error(),
+					}))
+			}
+			quotaNames[orgGUID] = map[string]string{}
+			for _, quota := range quotas {
+				quotaNames[orgGUID][quota.GUID] = quota.Name
+			}
+		}
+
+		quotaName = space.SpaceQuotaGUID
+		if name, found := quotaNames[orgGUID][space.SpaceQuotaGUID]; found {
+			quotaName = name
+		}
+	}
+
+	return []string{
+		quotaName,
+		strconv.Itoa(summary.AppsCount),
+		strconv.Itoa(summary.ServicesCount),
+		formatters.ByteSize(summary.MemoryUsage * formatters.MEGABYTE),
+	}, nil
+}
+
+// terminalRows returns how many table rows fit on one screen of w, and
+// false when w is not an interactive terminal.
+func terminalRows(w io.Writer) (int, bool) {
+	file, ok := w.(*os.File)
+	if !ok || !xterminal.IsTerminal(int(file.Fd())) {
+		return 0, false
+	}
+
+	_, height, err := xterminal.GetSize(int(file.Fd()))
+	if err != nil || height < 4 {
+		return 20, true
+	}
+	// Leave room for the header row and the prompt.
+	return height - 3, true
+}
+
+func sshStatus(allowed bool) string {
+	if allowed {
+		return T("enabled")
+	}
+	return T("disabled")
+}
+
+func isSpaceSortKey(key string) bool {
+	for _, k := range spaceSortKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// sortSpaces orders spaces by the given key. Spaces with equal keys keep
+// their name ordering. App counts sort descending so the busiest spaces come
+// first; names and creation dates sort ascending.
+func sortSpaces(spaceList []models.Space, sortBy string) {
+	sort.SliceStable(spaceList, func(i, j int) bool {
+		switch sortBy {
+		case "created":
+			if spaceList[i].CreatedAt != spaceList[j].CreatedAt {
+				return spaceList[i].CreatedAt < spaceList[j].CreatedAt
+			}
+		case "apps":
+			if len(spaceList[i].Applications) != len(spaceList[j].Applications) {
+				return len(spaceList[i].Applications) > len(spaceList[j].Applications)
+			}
+		}
+		return spaceList[i].Name < spaceList[j].Name
+	})
+}
@@ -1,9 +1,15 @@
 package space_test
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"strings"
 
+	"code.cloudfoundry.org/cli/cf/api/organizations/organizationsfakes"
+	"code.cloudfoundry.org/cli/cf/api/spacequotas/spacequotasfakes"
 	"code.cloudfoundry.org/cli/cf/api/spaces/spacesfakes"
 	"code.cloudfoundry.org/cli/cf/commandregistry"
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
@@ -11,6 +17,7 @@ import (
 	"code.cloudfoundry.org/cli/cf/models"
 	"code.cloudfoundry.org/cli/cf/requirements"
 	"code.cloudfoundry.org/cli/cf/requirements/requirementsfakes"
+	"code.cloudfoundry.org/cli/cf/terminal"
 	"code.cloudfoundry.org/cli/cf/trace/tracefakes"
 	"code.cloudfoundry.org/cli/plugin/models"
 	testcmd "code.cloudfoundry.org/cli/util/testhelpers/commands"
@@ -26,24 +33,32 @@ import (
 var _ = Describe("spaces command", func() {
 	var (
 		ui                  *testterm.FakeUI
+		commandUI           terminal.UI
 		requirementsFactory *requirementsfakes.FakeFactory
 		configRepo          coreconfig.Repository
 		spaceRepo           *spacesfakes.FakeSpaceRepository
+		orgRepo             *organizationsfakes.FakeOrganizationRepository
+		quotaRepo           *spacequotasfakes.FakeSpaceQuotaRepository
 
 		deps commandregistry.Dependency
 	)
 
 	updateCommandDependency := func(pluginCall bool) {
-		deps.UI = ui
+		deps.UI = commandUI
 		deps.Config = configRepo
 		deps.RepoLocator = deps.RepoLocator.SetSpaceRepository(spaceRepo)
+		deps.RepoLocator = deps.RepoLocator.SetOrganizationRepository(orgRepo)
+		deps.RepoLocator = deps.RepoLocator.SetSpaceQuotaRepository(quotaRepo)
 		commandregistry.Commands.SetCommand(commandregistry.Commands.FindCommand("spaces").SetDependency(deps, pluginCall))
 	}
 
 	BeforeEach(func() {
 		deps = commandregistry.NewDependency(os.Stdout, new(tracefakes.FakePrinter), "")
 		ui = &testterm.FakeUI{}
+		commandUI = ui
 		spaceRepo = new(spacesfakes.FakeSpaceRepository)
+		orgRepo = new(organizationsfakes.FakeOrganizationRepository)
+		quotaRepo = new(spacequotasfakes.FakeSpaceQuotaRepository)
 		requirementsFactory = new(requirementsfakes.FakeFactory)
 		configRepo = testconfig.NewRepositoryWithDefaults()
 	})
@@ -68,6 +83,13 @@ var _ = Describe("spaces command", func() {
 			Expect(runCommand()).To(BeFalse())
 		})
 
+		It("does not require a targeted org with --all", func() {
+			requirementsFactory.NewLoginRequirementReturns(requirements.Passing{})
+
+			Expect(runCommand("--all")).To(BeTrue())
+			Expect(requirementsFactory.NewTargetedOrgRequirementCallCount()).To(Equal(0))
+		})
+
 		Context("when arguments are provided", func() {
 			var cmd commandregistry.Command
 			var flagContext flags.FlagContext
@@ -89,6 +111,18 @@ var _ = Describe("spaces command", func() {
 				Expect(err.Error()).To(ContainSubstring("Incorrect Usage"))
 				Expect(err.Error()).To(ContainSubstring("No argument required"))
 			})
+
+			It("fails with usage when --sort-by is not a known key", func() {
+				flagContext.Parse("--sort-by", "color")
+
+				reqs, err := cmd.Requirements(requirementsFactory, flagContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = testcmd.RunRequirements(reqs)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Incorrect Usage"))
+				Expect(err.Error()).To(ContainSubstring("--sort-by must be one of name, created or apps"))
+			})
 		})
 	})
 
@@ -160,6 +194,326 @@ var _ = Describe("spaces command", func() {
 			))
 		})
 
+		It("prints each page of spaces as it is listed", func() {
+			configRepo.SetResultsPerPage(1)
+			var outputBeforeLast []string
+			spaceRepo.ListSpacesStub = func(cb func(models.Space) bool) error {
+				first := models.Space{}
+				first.Name = "first"
+				cb(first)
+				outputBeforeLast = ui.Outputs()
+				last := models.Space{}
+				last.Name = "last"
+				cb(last)
+				return nil
+			}
+
+			runCommand()
+
+			Expect(outputBeforeLast).To(ContainSubstrings([]string{"first"}))
+			Expect(ui.Outputs()).To(BeInDisplayOrder(
+				[]string{"name"},
+				[]string{"first"},
+				[]string{"last"},
+			))
+		})
+
+		Context("when --sort-by is provided", func() {
+			BeforeEach(func() {
+				busy := models.Space{}
+				busy.Name = "busy"
+				busy.CreatedAt = "2017-03-01T00:00:00Z"
+				busy.Applications = []models.ApplicationFields{{Name: "app1"}, {Name: "app2"}, {Name: "app3"}}
+				empty := models.Space{}
+				empty.Name = "alpha"
+				empty.CreatedAt = "2017-01-01T00:00:00Z"
+				some := models.Space{}
+				some.Name = "middle"
+				some.CreatedAt = "2017-02-01T00:00:00Z"
+				some.Applications = []models.ApplicationFields{{Name: "app4"}}
+				spaceRepo.ListSpacesStub = listSpacesStub([]models.Space{some, busy, empty})
+			})
+
+			It("sorts by name ascending", func() {
+				runCommand("--sort-by", "name")
+
+				Expect(ui.Outputs()).To(BeInDisplayOrder(
+					[]string{"alpha"},
+					[]string{"busy"},
+					[]string{"middle"},
+				))
+			})
+
+			It("sorts by app count descending", func() {
+				runCommand("--sort-by", "apps")
+
+				Expect(ui.Outputs()).To(BeInDisplayOrder(
+					[]string{"busy"},
+					[]string{"middle"},
+					[]string{"alpha"},
+				))
+			})
+
+			It("sorts by creation date ascending", func() {
+				runCommand("--sort-by", "created")
+
+				Expect(ui.Outputs()).To(BeInDisplayOrder(
+					[]string{"alpha"},
+					[]string{"middle"},
+					[]string{"busy"},
+				))
+			})
+		})
+
+		Context("when --show-ssh is provided", func() {
+			BeforeEach(func() {
+				open := models.Space{}
+				open.Name = "open"
+				open.AllowSSH = true
+				closed := models.Space{}
+				closed.Name = "closed"
+				spaceRepo.ListSpacesStub = listSpacesStub([]models.Space{closed, open})
+			})
+
+			It("shows whether each space allows SSH", func() {
+				runCommand("--show-ssh")
+
+				Expect(ui.Outputs()).To(BeInDisplayOrder(
+					[]string{"name", "ssh"},
+					[]string{"closed", "disabled"},
+					[]string{"open", "enabled"},
+				))
+			})
+
+			It("lines up the columns of every streamed row with the header", func() {
+				short := models.Space{}
+				short.Name = "a"
+				long := models.Space{}
+				long.Name = "a-much-longer-space-name"
+				long.AllowSSH = true
+				spaceRepo.ListSpacesStub = listSpacesStub([]models.Space{short, long})
+
+				runCommand("--show-ssh")
+
+				var columns []int
+				for _, line := range ui.Outputs() {
+					line = terminal.Decolorize(line)
+					for _, cell := range []string{"ssh", "disabled", "enabled"} {
+						if index := strings.Index(line, cell); index > 0 {
+							columns = append(columns, index)
+							break
+						}
+					}
+				}
+				Expect(columns).To(HaveLen(3))
+				Expect(columns[1]).To(Equal(columns[0]))
+				Expect(columns[2]).To(Equal(columns[0]))
+			})
+
+			It("omits the column by default", func() {
+				runCommand()
+
+				Expect(ui.Outputs()).NotTo(ContainSubstrings([]string{"ssh"}))
+				Expect(ui.Outputs()).NotTo(ContainSubstrings([]string{"enabled"}))
+			})
+		})
+
+		Context("when --no-header is provided", func() {
+			It("lists only the space names", func() {
+				runCommand("--no-header")
+
+				Expect(ui.Outputs()).To(ContainSubstrings(
+					[]string{"space1"},
+					[]string{"space2"},
+					[]string{"space3"},
+				))
+				Expect(ui.Outputs()).NotTo(ContainElement(HavePrefix("name")))
+			})
+
+			It("still says when there are no spaces", func() {
+				spaceRepo.ListSpacesStub = listSpacesStub([]models.Space{})
+
+				runCommand("--no-header")
+
+				Expect(ui.Outputs()).To(ContainSubstrings([]string{"No spaces found"}))
+				Expect(ui.Outputs()).NotTo(ContainElement(HavePrefix("name")))
+			})
+		})
+
+		Context("when --detailed is provided", func() {
+			BeforeEach(func() {
+				limited := models.Space{}
+				limited.Name = "limited"
+				limited.GUID = "limited-guid"
+				limited.Organization.GUID = "my-org-guid"
+				limited.SpaceQuotaGUID = "small-quota-guid"
+				unlimited := models.Space{}
+				unlimited.Name = "unlimited"
+				unlimited.GUID = "unlimited-guid"
+				unlimited.Organization.GUID = "my-org-guid"
+				spaceRepo.ListSpacesStub = listSpacesStub([]models.Space{limited, unlimited})
+
+				spaceRepo.GetSpaceSummaryStub = func(spaceGUID string) (models.SpaceSummary, error) {
+					if spaceGUID == "limited-guid" {
+						return models.SpaceSummary{AppsCount: 2, ServicesCount: 1, MemoryUsage: 512}, nil
+					}
+					return models.SpaceSummary{AppsCount: 5, ServicesCount: 3, MemoryUsage: 2048}, nil
+				}
+				quotaRepo.FindByOrgReturns([]models.SpaceQuota{{GUID: "small-quota-guid", Name: "small"}}, nil)
+			})
+
+			It("shows the quota, apps, services and memory of each space", func() {
+				Expect(runCommand("--detailed")).To(BeTrue())
+
+				Expect(ui.Outputs()).To(BeInDisplayOrder(
+					[]string{"name", "quota", "apps", "services", "memory"},
+					[]string{"limited", "small", "2", "1", "512M"},
+					[]string{"unlimited", "none", "5", "3", "2G"},
+				))
+				Expect(quotaRepo.FindByOrgCallCount()).To(Equal(1))
+				Expect(quotaRepo.FindByOrgArgsForCall(0)).To(Equal("my-org-guid"))
+			})
+
+			It("does not look up details by default", func() {
+				Expect(runCommand()).To(BeTrue())
+
+				Expect(spaceRepo.GetSpaceSummaryCallCount()).To(Equal(0))
+				Expect(quotaRepo.FindByOrgCallCount()).To(Equal(0))
+				Expect(ui.Outputs()).NotTo(ContainSubstrings([]string{"quota"}))
+			})
+
+			It("fails when a space summary cannot be fetched", func() {
+				spaceRepo.GetSpaceSummaryStub = nil
+				spaceRepo.GetSpaceSummaryReturns(models.SpaceSummary{}, errors.New("summary unavailable"))
+
+				Expect(runCommand("--detailed")).To(BeFalse())
+				Expect(ui.Outputs()).To(ContainSubstrings(
+					[]string{"FAILED"},
+					[]string{"Failed fetching details of space limited"},
+					[]string{"summary unavailable"},
+				))
+			})
+		})
+
+		Context("when --page is provided", func() {
+			BeforeEach(func() {
+				var spaceList []models.Space
+				for i := 0; i < 50; i++ {
+					space := models.Space{}
+					space.Name = fmt.Sprintf("space-%02d", i)
+					spaceList = append(spaceList, space)
+				}
+				spaceRepo.ListSpacesStub = listSpacesStub(spaceList)
+			})
+
+			It("prints everything without prompting when output is not a terminal", func() {
+				commandUI = &nonTerminalUI{FakeUI: ui}
+
+				Expect(runCommand("--page")).To(BeTrue())
+				Expect(ui.Prompts).To(BeEmpty())
+				Expect(ui.Outputs()).To(ContainSubstrings([]string{"space-00"}, []string{"space-49"}))
+			})
+		})
+
+		Context("when --by-segment is provided", func() {
+			BeforeEach(func() {
+				var spaceList []models.Space
+				for _, name := range []string{"dev", "prod", "sandbox", "staging"} {
+					space := models.Space{}
+					space.Name = name
+					space.GUID = name + "-guid"
+					spaceList = append(spaceList, space)
+				}
+				spaceRepo.ListSpacesStub = listSpacesStub(spaceList)
+				spaceRepo.ListIsolationSegmentNamesReturns(map[string]string{
+					"prod-guid":    "segment-b",
+					"staging-guid": "segment-a",
+					"dev-guid":     "segment-a",
+				}, nil)
+			})
+
+			It("groups the spaces by isolation segment", func() {
+				Expect(runCommand("--by-segment")).To(BeTrue())
+
+				Expect(spaceRepo.ListIsolationSegmentNamesCallCount()).To(Equal(1))
+				Expect(spaceRepo.ListIsolationSegmentNamesArgsForCall(0)).To(ConsistOf("dev-guid", "prod-guid", "sandbox-guid", "staging-guid"))
+				Expect(ui.Outputs()).To(BeInDisplayOrder(
+					[]string{"isolation segment", "segment-a"},
+					[]string{"dev"},
+					[]string{"staging"},
+					[]string{"isolation segment", "segment-b"},
+					[]string{"prod"},
+					[]string{"isolation segment", "shared"},
+					[]string{"sandbox"},
+				))
+			})
+
+			It("does not look up segments by default", func() {
+				runCommand()
+
+				Expect(spaceRepo.ListIsolationSegmentNamesCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when --all is provided", func() {
+			BeforeEach(func() {
+				first := models.Space{}
+				first.Name = "first"
+				first.Organization.GUID = "org1-guid"
+				second := models.Space{}
+				second.Name = "second"
+				second.Organization.GUID = "org1-guid"
+				orphan := models.Space{}
+				orphan.Name = "orphan"
+				orphan.Organization.GUID = "gone-org-guid"
+				spaceRepo.ListAllSpacesStub = listSpacesStub([]models.Space{first, orphan, second})
+
+				orgRepo.GetManyOrgsByGUIDStub = func(orgGUIDs []string) ([]models.Organization, error) {
+					if orgGUIDs[0] == "org1-guid" {
+						org := models.Organization{}
+						org.GUID = "org1-guid"
+						org.Name = "org-one"
+						return []models.Organization{org}, nil
+					}
+					return nil, errors.New("not found")
+				}
+			})
+
+			It("lists spaces from every org with their org names", func() {
+				runCommand("--all")
+
+				Expect(spaceRepo.ListSpacesCallCount()).To(Equal(0))
+				Expect(ui.Outputs()).To(BeInDisplayOrder(
+					[]string{"Getting spaces in all orgs as", "my-user"},
+					[]string{"name", "org"},
+					[]string{"first", "org-one"},
+					[]string{"orphan", "gone-org-guid"},
+					[]string{"second", "org-one"},
+				))
+			})
+
+			It("looks up each org only once", func() {
+				runCommand("--all")
+
+				Expect(orgRepo.GetManyOrgsByGUIDCallCount()).To(Equal(2))
+			})
+		})
+
+		Context("when listing spaces fails", func() {
+			BeforeEach(func() {
+				spaceRepo.ListSpacesReturns(errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				Expect(runCommand()).To(BeFalse())
+				Expect(ui.Outputs()).To(ContainSubstrings(
+					[]string{"FAILED"},
+					[]string{"Failed fetching spaces"},
+					[]string{"boom"},
+				))
+			})
+		})
+
 		Context("when there are no spaces", func() {
 			BeforeEach(func() {
 				spaceRepo.ListSpacesStub = listSpacesStub([]models.Space{})
@@ -175,3 +529,12 @@ var _ = Describe("spaces command", func() {
 		})
 	})
 })
+
+// nonTerminalUI writes somewhere that is never an interactive terminal.
+type nonTerminalUI struct {
+	*testterm.FakeUI
+}
+
+func (ui *nonTerminalUI) Writer() io.Writer {
+	return new(bytes.Buffer)
+}
@@ -15,9 +15,10 @@ import (
 )
 
 type CreateUser struct {
-	ui       terminal.UI
-	config   coreconfig.Reader
-	userRepo api.UserRepository
+	ui           terminal.UI
+	config       coreconfig.Reader
+	userRepo     api.UserRepository
+	endpointRepo coreconfig.EndpointRepository
 }
 
 func init() {
@@ -25,20 +26,35 @@ func init() {
 }
 
 func (cmd *CreateUser) MetaData() commandregistry.CommandMetadata {
+	fs := make(map[string]flags.FlagSet)
+	fs["origin"] = &flags.StringFlag{Name: "origin", Usage: T("Origin for mapping a user account to a user in an external identity provider")}
+	fs["email"] = &flags.StringFlag{Name: "email", Usage: T("Email address of the user, when it is not the username")}
+
 	return commandregistry.CommandMetadata{
 		Name:        "create-user",
 		Description: T("Create a new user"),
 		Usage: []string{
-			T("CF_NAME create-user USERNAME PASSWORD"),
+			T("CF_NAME create-user USERNAME PASSWORD [--email EMAIL]\n"),
+			T("   CF_NAME create-user USERNAME --origin ORIGIN"),
 		},
+		Flags: fs,
 	}
 }
 
 func (cmd *CreateUser) Requirements(requirementsFactory requirements.Factory, fc flags.FlagContext) ([]requirements.Requirement, error) {
-	if len(fc.Args()) != 2 {
+	required := 2
+	if isExternalOrigin(fc.String("origin")) {
+		required = 1
+	}
+	if len(fc.Args()) != required {
 		usage := commandregistry.Commands.CommandUsage("create-user")
 		cmd.ui.Failed(T("Incorrect Usage. Requires arguments\n\n") + usage)
-		return nil, fmt.Errorf("Incorrect usage: %d arguments of %d required", len(fc.Args()), 2)
+		return nil, fmt.Errorf("Incorrect usage: %d arguments of %d required", len(fc.Args()), required)
+	}
+	if required == 1 && fc.IsSet("email") {
+		usage := commandregistry.Commands.CommandUsage("create-user")
+		cmd.ui.Failed(T("Incorrect Usage. --email cannot be used with an external --origin\n\n") + usage)
+		return nil, fmt.Errorf("Incorrect usage: --email with origin %s", fc.String("origin"))
 	}
 
 	reqs := []requirements.Requirement{
@@ -52,12 +68,13 @@ func (cmd *CreateUser) SetDependency(deps commandregistry.Dependency, pluginCall
 	cmd.ui = deps.UI
 	cmd.config = deps.Config
 	cmd.userRepo = deps.RepoLocator.GetUserRepository()
+	cmd.endpointRepo = deps.RepoLocator.GetEndpointRepository()
 	return cmd
 }
 
 func (cmd *CreateUser) Execute(c flags.FlagContext) error {
 	username := c.Args()[0]
-	password := c.Args()[1]
+	origin := c.String("origin")
 
 	cmd.ui.Say(T("Creating user {{.TargetUser}}...",
 		map[string]interface{}{
@@ -65,10 +82,21 @@ func (cmd *CreateUser) Execute(c flags.FlagContext) error {
 			"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
 		}))
 
-	err := cmd.userRepo.Create(username, password)
+	err := pingUAA(cmd.config, cmd.endpointRepo)
+	if err != nil {
+		return err
+	}
+
+	if isExternalOrigin(origin) {
+		err = cmd.userRepo.CreateWithOrigin(username, "", origin)
+	} else if c.IsSet("email") {
+		err = cmd.userRepo.CreateWithEmail(username, c.Args()[1], c.String("email"))
+	} else {
+		err = cmd.userRepo.Create(username, c.Args()[1])
+	}
 	switch err.(type) {
 	case nil:
-	case *errors.ModelAlreadyExistsError:
+	case *errors.ModelAlreadyExistsError, *api.UAAUserReusedWarning:
 		cmd.ui.Warn("%s", err.Error())
 	default:
 		return errors.New(T("Error creating user {{.TargetUser}}.\n{{.Error}}",
@@ -82,3 +110,19 @@ func (cmd *CreateUser) Execute(c flags.FlagContext) error {
 	cmd.ui.Say(T("\nTIP: Assign roles with '{{.CurrentUser}} set-org-role' and '{{.CurrentUser}} set-space-role'", map[string]interface{}{"CurrentUser": cf.Name}))
 	return nil
 }
+
+// pingUAA fails with a clear message when the targeted UAA cannot be
+// reached, before a command does any work. With no UAA endpoint configured
+// it leaves the failure to the user repository.
+func pingUAA(config coreconfig.Reader, endpointRepo coreconfig.EndpointRepository) error {
+	if config.UaaEndpoint() == "" {
+		return nil
+	}
+	return endpointRepo.PingUAA(config.UaaEndpoint())
+}
+
+// isExternalOrigin reports whether origin names an identity provider other
+// than UAA itself, whose users have no UAA password.
+func isExternalOrigin(origin string) bool {
+	return origin != "" && origin != api.UAAOrigin
+}
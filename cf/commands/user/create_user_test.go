@@ -1,8 +1,10 @@
 package user_test
 
 import (
+	"code.cloudfoundry.org/cli/cf/api"
 	"code.cloudfoundry.org/cli/cf/api/apifakes"
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig/coreconfigfakes"
 	"code.cloudfoundry.org/cli/cf/errors"
 	"code.cloudfoundry.org/cli/cf/requirements"
 	"code.cloudfoundry.org/cli/cf/requirements/requirementsfakes"
@@ -21,6 +23,7 @@ var _ = Describe("Create user command", func() {
 		requirementsFactory *requirementsfakes.FakeFactory
 		ui                  *testterm.FakeUI
 		userRepo            *apifakes.FakeUserRepository
+		endpointRepo        *coreconfigfakes.FakeEndpointRepository
 		config              coreconfig.Repository
 		deps                commandregistry.Dependency
 	)
@@ -30,6 +33,7 @@ var _ = Describe("Create user command", func() {
 		requirementsFactory.NewLoginRequirementReturns(requirements.Passing{})
 		ui = new(testterm.FakeUI)
 		userRepo = new(apifakes.FakeUserRepository)
+		endpointRepo = new(coreconfigfakes.FakeEndpointRepository)
 		config = testconfig.NewRepositoryWithDefaults()
 		accessToken, _ := testconfig.EncodeAccessToken(coreconfig.TokenInfo{
 			Username: "current-user",
@@ -41,6 +45,7 @@ var _ = Describe("Create user command", func() {
 		deps.UI = ui
 		deps.Config = config
 		deps.RepoLocator = deps.RepoLocator.SetUserRepository(userRepo)
+		deps.RepoLocator = deps.RepoLocator.SetEndpointRepository(endpointRepo)
 		commandregistry.Commands.SetCommand(commandregistry.Commands.FindCommand("create-user").SetDependency(deps, pluginCall))
 	}
 
@@ -48,6 +53,30 @@ var _ = Describe("Create user command", func() {
 		return testcmd.RunCLICommand("create-user", args, requirementsFactory, updateCommandDependency, false, ui)
 	}
 
+	Context("when a UAA endpoint is configured", func() {
+		BeforeEach(func() {
+			config.SetUaaEndpoint("https://uaa.example.com")
+		})
+
+		It("checks UAA is reachable before creating the user", func() {
+			Expect(runCommand("my-user", "my-password")).To(BeTrue())
+			Expect(endpointRepo.PingUAAArgsForCall(0)).To(Equal("https://uaa.example.com"))
+			Expect(userRepo.CreateCallCount()).To(Equal(1))
+		})
+
+		It("fails without creating the user when UAA is unreachable", func() {
+			endpointRepo.PingUAAReturns(errors.NewUAAUnreachableError("https://uaa.example.com", errors.New("connection refused")))
+
+			Expect(runCommand("my-user", "my-password")).To(BeFalse())
+			Expect(userRepo.CreateCallCount()).To(BeZero())
+			Expect(ui.Outputs()).To(ContainSubstrings(
+				[]string{"FAILED"},
+				[]string{"UAA endpoint https://uaa.example.com is unreachable"},
+				[]string{"connection refused"},
+			))
+		})
+	})
+
 	It("creates a user", func() {
 		runCommand("my-user", "my-password")
 
@@ -75,6 +104,17 @@ var _ = Describe("Create user command", func() {
 			Expect(ui.Outputs()).ToNot(ContainSubstrings([]string{"FAILED"}))
 		})
 
+		It("prints a warning and succeeds when an existing UAA user was reused", func() {
+			userRepo.CreateReturns(&api.UAAUserReusedWarning{Username: "my-user"})
+
+			runCommand("my-user", "my-password")
+
+			Expect(ui.WarnOutputs).To(ContainSubstrings(
+				[]string{"my-user already existed in UAA"},
+			))
+			Expect(ui.Outputs()).To(ContainSubstrings([]string{"OK"}))
+		})
+
 		It("fails when any error other than alreadyExists is returned", func() {
 			userRepo.CreateReturns(errors.NewHTTPError(403, "403", "Forbidden"))
 
@@ -89,6 +129,50 @@ var _ = Describe("Create user command", func() {
 		})
 	})
 
+	Context("when --origin is provided", func() {
+		It("creates an external user without a password", func() {
+			Expect(runCommand("my-user", "--origin", "ldap")).To(BeTrue())
+
+			Expect(userRepo.CreateCallCount()).To(Equal(0))
+			userName, password, origin := userRepo.CreateWithOriginArgsForCall(0)
+			Expect(userName).To(Equal("my-user"))
+			Expect(password).To(BeEmpty())
+			Expect(origin).To(Equal("ldap"))
+			Expect(ui.Outputs()).To(ContainSubstrings([]string{"OK"}))
+		})
+
+		It("still requires a password for the uaa origin", func() {
+			Expect(runCommand("my-user", "--origin", "uaa")).To(BeFalse())
+			Expect(userRepo.CreateCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when --email is provided", func() {
+		It("creates the user with that email", func() {
+			Expect(runCommand("my-user", "my-password", "--email", "my-user@example.com")).To(BeTrue())
+
+			Expect(userRepo.CreateCallCount()).To(Equal(0))
+			userName, password, email := userRepo.CreateWithEmailArgsForCall(0)
+			Expect(userName).To(Equal("my-user"))
+			Expect(password).To(Equal("my-password"))
+			Expect(email).To(Equal("my-user@example.com"))
+		})
+
+		It("fails when the email is invalid", func() {
+			userRepo.CreateWithEmailReturns(errors.New("Invalid email address my-user@"))
+
+			Expect(runCommand("my-user", "my-password", "--email", "my-user@")).To(BeFalse())
+			Expect(ui.Outputs()).To(ContainSubstrings([]string{"FAILED"}, []string{"Invalid email address my-user@"}))
+		})
+
+		It("cannot be used with an external origin", func() {
+			Expect(runCommand("my-user", "--origin", "ldap", "--email", "my-user@example.com")).To(BeFalse())
+
+			Expect(userRepo.CreateWithOriginCallCount()).To(Equal(0))
+			Expect(ui.Outputs()).To(ContainSubstrings([]string{"Incorrect Usage", "--email"}))
+		})
+	})
+
 	It("fails when no arguments are passed", func() {
 		Expect(runCommand()).To(BeFalse())
 	})
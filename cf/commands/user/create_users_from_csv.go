@@ -0,0 +1,142 @@
+package user
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"code.cloudfoundry.org/cli/cf"
+	"code.cloudfoundry.org/cli/cf/api"
+	"code.cloudfoundry.org/cli/cf/commandregistry"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/errors"
+	"code.cloudfoundry.org/cli/cf/flags"
+	. "code.cloudfoundry.org/cli/cf/i18n"
+	"code.cloudfoundry.org/cli/cf/models"
+	"code.cloudfoundry.org/cli/cf/requirements"
+	"code.cloudfoundry.org/cli/cf/terminal"
+)
+
+type CreateUsersFromCSV struct {
+	ui       terminal.UI
+	config   coreconfig.Reader
+	userRepo api.UserRepository
+}
+
+func init() {
+	commandregistry.Register(&CreateUsersFromCSV{})
+}
+
+func (cmd *CreateUsersFromCSV) MetaData() commandregistry.CommandMetadata {
+	return commandregistry.CommandMetadata{
+		Name:        "create-users-from-csv",
+		Description: T("Create the users listed in a CSV file"),
+		Usage: []string{
+			T("CF_NAME create-users-from-csv PATH_TO_CSV\n\n"),
+			T("   The file has one user per line, as username,password. A first line of username,password is skipped."),
+		},
+	}
+}
+
+func (cmd *CreateUsersFromCSV) Requirements(requirementsFactory requirements.Factory, fc flags.FlagContext) ([]requirements.Requirement, error) {
+	if len(fc.Args()) != 1 {
+		cmd.ui.Failed(T("Incorrect Usage. Requires an argument\n\n") + commandregistry.Commands.CommandUsage("create-users-from-csv"))
+		return nil, fmt.Errorf("Incorrect usage: %d arguments of %d required", len(fc.Args()), 1)
+	}
+
+	reqs := []requirements.Requirement{
+		requirementsFactory.NewLoginRequirement(),
+	}
+
+	return reqs, nil
+}
+
+func (cmd *CreateUsersFromCSV) SetDependency(deps commandregistry.Dependency, pluginCall bool) commandregistry.Command {
+	cmd.ui = deps.UI
+	cmd.config = deps.Config
+	cmd.userRepo = deps.RepoLocator.GetUserRepository()
+	return cmd
+}
+
+func (cmd *CreateUsersFromCSV) Execute(c flags.FlagContext) error {
+	path := c.Args()[0]
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.New(T("Error reading {{.Path}}.\n{{.Error}}",
+			map[string]interface{}{"Path": path, "Error": err.Error()}))
+	}
+	defer file.Close()
+
+	requests, err := readUserCreateRequests(file)
+	if err != nil {
+		return errors.New(T("Error reading {{.Path}}.\n{{.Error}}",
+			map[string]interface{}{"Path": path, "Error": err.Error()}))
+	}
+
+	cmd.ui.Say(T("Creating {{.Count}} users from {{.Path}} as {{.CurrentUser}}...",
+		map[string]interface{}{
+			"Count":       len(requests),
+			"Path":        terminal.EntityNameColor(path),
+			"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
+		}))
+
+	results, err := cmd.userRepo.CreateBulk(requests)
+	if err != nil {
+		return errors.New(T("Error creating users.\n{{.Error}}",
+			map[string]interface{}{"Error": err.Error()}))
+	}
+
+	failed := 0
+	table := cmd.ui.Table([]string{T("username"), T("result")})
+	for i, result := range results {
+		status := T("created")
+		if result.Err != nil {
+			failed++
+			status = T("failed: {{.Error}}", map[string]interface{}{"Error": result.Err.Error()})
+		}
+		table.Add(requests[i].Username, status)
+	}
+	cmd.ui.Say("")
+	err = table.Print()
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return errors.New(T("{{.Failed}} of {{.Total}} users could not be created.",
+			map[string]interface{}{"Failed": failed, "Total": len(results)}))
+	}
+
+	cmd.ui.Ok()
+	cmd.ui.Say(T("\nTIP: Assign roles with '{{.CurrentUser}} set-org-role' and '{{.CurrentUser}} set-space-role'", map[string]interface{}{"CurrentUser": cf.Name}))
+	return nil
+}
+
+// readUserCreateRequests parses username,password lines, skipping a header
+// line and blank lines.
+func readUserCreateRequests(r io.Reader) ([]models.UserCreateRequest, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	var requests []models.UserCreateRequest
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 && strings.EqualFold(record[0], "username") && strings.EqualFold(record[1], "password") {
+			continue
+		}
+		if record[0] == "" {
+			return nil, errors.New(T("Missing username on line {{.Line}}", map[string]interface{}{"Line": line}))
+		}
+		requests = append(requests, models.UserCreateRequest{Username: record[0], Password: record[1]})
+	}
+	return requests, nil
+}
@@ -0,0 +1,142 @@
+package user_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	"code.cloudfoundry.org/cli/cf/api"
+	"code.cloudfoundry.org/cli/cf/api/apifakes"
+	"code.cloudfoundry.org/cli/cf/commandregistry"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/errors"
+	"code.cloudfoundry.org/cli/cf/models"
+	"code.cloudfoundry.org/cli/cf/requirements"
+	"code.cloudfoundry.org/cli/cf/requirements/requirementsfakes"
+	"code.cloudfoundry.org/cli/cf/trace/tracefakes"
+	testcmd "code.cloudfoundry.org/cli/util/testhelpers/commands"
+	testconfig "code.cloudfoundry.org/cli/util/testhelpers/configuration"
+	testterm "code.cloudfoundry.org/cli/util/testhelpers/terminal"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "code.cloudfoundry.org/cli/util/testhelpers/matchers"
+)
+
+var _ = Describe("create-users-from-csv command", func() {
+	var (
+		ui                  *testterm.FakeUI
+		requirementsFactory *requirementsfakes.FakeFactory
+		userRepo            *apifakes.FakeUserRepository
+		configRepo          coreconfig.Repository
+		deps                commandregistry.Dependency
+		csvPath             string
+	)
+
+	updateCommandDependency := func(pluginCall bool) {
+		deps.UI = ui
+		deps.Config = configRepo
+		deps.RepoLocator = deps.RepoLocator.SetUserRepository(userRepo)
+
+		commandregistry.Commands.SetCommand(commandregistry.Commands.FindCommand("create-users-from-csv").SetDependency(deps, pluginCall))
+	}
+
+	writeCSV := func(contents string) {
+		file, err := ioutil.TempFile("", "users-csv")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = file.WriteString(contents)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(file.Close()).To(Succeed())
+		csvPath = file.Name()
+	}
+
+	BeforeEach(func() {
+		configRepo = testconfig.NewRepositoryWithDefaults()
+		ui = &testterm.FakeUI{}
+		requirementsFactory = new(requirementsfakes.FakeFactory)
+		userRepo = new(apifakes.FakeUserRepository)
+		deps = commandregistry.NewDependency(os.Stdout, new(tracefakes.FakePrinter), "")
+		csvPath = ""
+	})
+
+	AfterEach(func() {
+		if csvPath != "" {
+			os.Remove(csvPath)
+		}
+	})
+
+	runCommand := func(args ...string) bool {
+		return testcmd.RunCLICommand("create-users-from-csv", args, requirementsFactory, updateCommandDependency, false, ui)
+	}
+
+	It("fails with usage when not given a file", func() {
+		runCommand()
+		Expect(ui.Outputs()).To(ContainSubstrings(
+			[]string{"Incorrect Usage", "Requires an argument"},
+		))
+	})
+
+	It("fails when not logged in", func() {
+		requirementsFactory.NewLoginRequirementReturns(requirements.Failing{Message: "not logged in"})
+		Expect(runCommand("users.csv")).To(BeFalse())
+	})
+
+	Context("when logged in", func() {
+		BeforeEach(func() {
+			requirementsFactory.NewLoginRequirementReturns(requirements.Passing{})
+		})
+
+		It("creates every user in the file and summarizes the results", func() {
+			writeCSV("username,password\nuser-a,pass-a\nuser-b, pass-b\n")
+			userRepo.CreateBulkReturns([]models.UserCreateResult{
+				{User: models.UserFields{GUID: "user-a-guid", Username: "user-a"}},
+				{Err: errors.NewModelAlreadyExistsError("user", "user-b")},
+			}, nil)
+
+			Expect(runCommand(csvPath)).To(BeFalse())
+
+			Expect(userRepo.CreateBulkCallCount()).To(Equal(1))
+			Expect(userRepo.CreateBulkArgsForCall(0)).To(Equal([]models.UserCreateRequest{
+				{Username: "user-a", Password: "pass-a"},
+				{Username: "user-b", Password: "pass-b"},
+			}))
+			Expect(ui.Outputs()).To(BeInDisplayOrder(
+				[]string{"Creating 2 users from", csvPath, "my-user"},
+				[]string{"username", "result"},
+				[]string{"user-a", "created"},
+				[]string{"user-b", "failed", "already exists"},
+				[]string{"FAILED"},
+				[]string{"1 of 2 users could not be created"},
+			))
+		})
+
+		It("reports a UAA user left behind by a failed rollback", func() {
+			writeCSV("user-a,pass-a\n")
+			userRepo.CreateBulkReturns([]models.UserCreateResult{
+				{Err: &api.UAARollbackError{Username: "user-a", GUID: "user-a-guid", Err: errors.New("cc-error"), RollbackErr: errors.New("uaa-error")}},
+			}, nil)
+
+			Expect(runCommand(csvPath)).To(BeFalse())
+			Expect(ui.Outputs()).To(ContainSubstrings(
+				[]string{"user-a", "failed", "cc-error", "user-a-guid", "uaa-error"},
+			))
+		})
+
+		It("succeeds when every user is created", func() {
+			writeCSV("user-a,pass-a\n")
+			userRepo.CreateBulkReturns([]models.UserCreateResult{
+				{User: models.UserFields{GUID: "user-a-guid", Username: "user-a"}},
+			}, nil)
+
+			Expect(runCommand(csvPath)).To(BeTrue())
+			Expect(ui.Outputs()).To(ContainSubstrings([]string{"OK"}))
+		})
+
+		It("creates nobody when a line does not have two columns", func() {
+			writeCSV("user-a,pass-a\nuser-b\n")
+
+			Expect(runCommand(csvPath)).To(BeFalse())
+			Expect(userRepo.CreateBulkCallCount()).To(Equal(0))
+			Expect(ui.Outputs()).To(ContainSubstrings([]string{"Error reading", csvPath}))
+		})
+	})
+})
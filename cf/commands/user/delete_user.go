@@ -3,6 +3,7 @@ package user
 import (
 	"fmt"
 
+	"code.cloudfoundry.org/cli/cf"
 	"code.cloudfoundry.org/cli/cf/api"
 	"code.cloudfoundry.org/cli/cf/commandregistry"
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
@@ -14,9 +15,11 @@ import (
 )
 
 type DeleteUser struct {
-	ui       terminal.UI
-	config   coreconfig.Reader
-	userRepo api.UserRepository
+	ui           terminal.UI
+	config       coreconfig.Reader
+	userRepo     api.UserRepository
+	endpointRepo coreconfig.EndpointRepository
+	userReq      requirements.UserRequirement
 }
 
 func init() {
@@ -26,12 +29,15 @@ func init() {
 func (cmd *DeleteUser) MetaData() commandregistry.CommandMetadata {
 	fs := make(map[string]flags.FlagSet)
 	fs["f"] = &flags.BoolFlag{ShortName: "f", Usage: T("Force deletion without confirmation")}
+	fs["guid"] = &flags.BoolFlag{Name: "guid", Usage: T("Treat USERNAME as a user GUID and skip looking the user up")}
+	fs["interactive"] = &flags.BoolFlag{Name: "interactive", Usage: T("When no user has the given username, choose from the users whose names start with it")}
+	fs["async"] = &flags.BoolFlag{Name: "async", Usage: T("Delete the user from the Cloud Controller without waiting, and leave removing the UAA user to reconcile-users")}
 
 	return commandregistry.CommandMetadata{
 		Name:        "delete-user",
 		Description: T("Delete a user"),
 		Usage: []string{
-			T("CF_NAME delete-user USERNAME [-f]"),
+			T("CF_NAME delete-user USERNAME [-f] [--guid] [--async] [--interactive]"),
 		},
 		Flags: fs,
 	}
@@ -46,6 +52,13 @@ func (cmd *DeleteUser) Requirements(requirementsFactory requirements.Factory, fc
 	reqs := []requirements.Requirement{
 		requirementsFactory.NewLoginRequirement(),
 	}
+	if fc.Bool("guid") {
+		cmd.userReq = requirementsFactory.NewUserGUIDRequirement(fc.Args()[0])
+		reqs = append(reqs, cmd.userReq)
+	} else if fc.Bool("interactive") {
+		cmd.userReq = requirementsFactory.NewUserRequirementWithPrefixMatch(fc.Args()[0], chooseUser(cmd.ui))
+		reqs = append(reqs, cmd.userReq)
+	}
 
 	return reqs, nil
 }
@@ -54,11 +67,15 @@ func (cmd *DeleteUser) SetDependency(deps commandregistry.Dependency, pluginCall
 	cmd.ui = deps.UI
 	cmd.config = deps.Config
 	cmd.userRepo = deps.RepoLocator.GetUserRepository()
+	cmd.endpointRepo = deps.RepoLocator.GetEndpointRepository()
 	return cmd
 }
 
 func (cmd *DeleteUser) Execute(c flags.FlagContext) error {
 	username := c.Args()[0]
+	if c.Bool("interactive") {
+		username = cmd.userReq.GetUser().Username
+	}
 	force := c.Bool("f")
 
 	if !force && !cmd.ui.ConfirmDelete(T("user"), username) {
@@ -71,6 +88,15 @@ func (cmd *DeleteUser) Execute(c flags.FlagContext) error {
 			"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
 		}))
 
+	err := pingUAA(cmd.config, cmd.endpointRepo)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("guid") || c.Bool("interactive") {
+		return cmd.deleteUser(cmd.userReq.GetUser().GUID, c.Bool("async"))
+	}
+
 	users, err := cmd.userRepo.FindAllByUsername(username)
 
 	switch err.(type) {
@@ -90,7 +116,23 @@ func (cmd *DeleteUser) Execute(c flags.FlagContext) error {
 		return err
 	}
 
-	err = cmd.userRepo.Delete(users[0].GUID)
+	return cmd.deleteUser(users[0].GUID, c.Bool("async"))
+}
+
+func (cmd *DeleteUser) deleteUser(userGUID string, async bool) error {
+	if async {
+		err := cmd.userRepo.DeleteAsync(userGUID)
+		if err != nil {
+			return err
+		}
+
+		cmd.ui.Ok()
+		cmd.ui.Say(T("\nUAA cleanup was deferred. The user can still log in until '{{.Command}}' removes the UAA user.",
+			map[string]interface{}{"Command": terminal.CommandColor(cf.Name + " reconcile-users --delete-uaa-only")}))
+		return nil
+	}
+
+	err := cmd.userRepo.Delete(userGUID)
 	if err != nil {
 		return err
 	}
@@ -4,6 +4,7 @@ import (
 	"code.cloudfoundry.org/cli/cf/api/apifakes"
 	"code.cloudfoundry.org/cli/cf/commandregistry"
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig/coreconfigfakes"
 	"code.cloudfoundry.org/cli/cf/errors"
 	"code.cloudfoundry.org/cli/cf/models"
 	"code.cloudfoundry.org/cli/cf/requirements"
@@ -22,6 +23,7 @@ var _ = Describe("delete-user command", func() {
 		ui                  *testterm.FakeUI
 		configRepo          coreconfig.Repository
 		userRepo            *apifakes.FakeUserRepository
+		endpointRepo        *coreconfigfakes.FakeEndpointRepository
 		requirementsFactory *requirementsfakes.FakeFactory
 		deps                commandregistry.Dependency
 	)
@@ -30,12 +32,14 @@ var _ = Describe("delete-user command", func() {
 		deps.UI = ui
 		deps.Config = configRepo
 		deps.RepoLocator = deps.RepoLocator.SetUserRepository(userRepo)
+		deps.RepoLocator = deps.RepoLocator.SetEndpointRepository(endpointRepo)
 		commandregistry.Commands.SetCommand(commandregistry.Commands.FindCommand("delete-user").SetDependency(deps, pluginCall))
 	}
 
 	BeforeEach(func() {
 		ui = &testterm.FakeUI{Inputs: []string{"y"}}
 		userRepo = new(apifakes.FakeUserRepository)
+		endpointRepo = new(coreconfigfakes.FakeEndpointRepository)
 		requirementsFactory = new(requirementsfakes.FakeFactory)
 		requirementsFactory.NewLoginRequirementReturns(requirements.Passing{})
 		configRepo = testconfig.NewRepositoryWithDefaults()
@@ -110,6 +114,81 @@ var _ = Describe("delete-user command", func() {
 			Expect(userRepo.FindAllByUsernameArgsForCall(0)).To(Equal("user-name"))
 			Expect(userRepo.DeleteArgsForCall(0)).To(Equal("user-guid"))
 		})
+
+		It("leaves UAA cleanup for later when --async is given", func() {
+			Expect(runCommand("-f", "--async", "user-name")).To(BeTrue())
+
+			Expect(userRepo.DeleteAsyncArgsForCall(0)).To(Equal("user-guid"))
+			Expect(userRepo.DeleteCallCount()).To(BeZero())
+			Expect(ui.Outputs()).To(ContainSubstrings(
+				[]string{"OK"},
+				[]string{"UAA cleanup was deferred", "reconcile-users"},
+			))
+		})
+	})
+
+	Context("when --guid is given", func() {
+		var userRequirement *requirementsfakes.FakeUserRequirement
+
+		BeforeEach(func() {
+			userRequirement = new(requirementsfakes.FakeUserRequirement)
+			userRequirement.GetUserReturns(models.UserFields{GUID: "user-guid", Username: "user-guid"})
+			requirementsFactory.NewUserGUIDRequirementReturns(userRequirement)
+		})
+
+		It("deletes the user with the GUID without looking it up", func() {
+			Expect(runCommand("-f", "--guid", "user-guid")).To(BeTrue())
+
+			Expect(requirementsFactory.NewUserGUIDRequirementArgsForCall(0)).To(Equal("user-guid"))
+			Expect(userRepo.FindAllByUsernameCallCount()).To(BeZero())
+			Expect(userRepo.DeleteArgsForCall(0)).To(Equal("user-guid"))
+			Expect(ui.Outputs()).To(ContainSubstrings(
+				[]string{"Deleting user", "user-guid"},
+				[]string{"OK"},
+			))
+		})
+
+		It("deletes nothing when the argument is not a GUID", func() {
+			requirementsFactory.NewUserGUIDRequirementReturns(requirements.NewUserGUIDRequirement("user-name"))
+
+			Expect(runCommand("-f", "--guid", "user-name")).To(BeFalse())
+			Expect(userRepo.DeleteCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when --interactive is given", func() {
+		BeforeEach(func() {
+			userRequirement := new(requirementsfakes.FakeUserRequirement)
+			userRequirement.GetUserReturns(models.UserFields{GUID: "user-guid", Username: "user-name"})
+			requirementsFactory.NewUserRequirementWithPrefixMatchReturns(userRequirement)
+		})
+
+		It("deletes the user that was chosen", func() {
+			Expect(runCommand("--interactive", "user-na")).To(BeTrue())
+
+			username, _ := requirementsFactory.NewUserRequirementWithPrefixMatchArgsForCall(0)
+			Expect(username).To(Equal("user-na"))
+			Expect(ui.Prompts).To(ContainSubstrings([]string{"Really delete the user user-name"}))
+			Expect(userRepo.FindAllByUsernameCallCount()).To(BeZero())
+			Expect(userRepo.DeleteArgsForCall(0)).To(Equal("user-guid"))
+		})
+	})
+
+	Context("when UAA is unreachable", func() {
+		BeforeEach(func() {
+			configRepo.SetUaaEndpoint("https://uaa.example.com")
+			endpointRepo.PingUAAReturns(errors.NewUAAUnreachableError("https://uaa.example.com", errors.New("connection refused")))
+		})
+
+		It("fails before looking up the user", func() {
+			Expect(runCommand("-f", "user-name")).To(BeFalse())
+			Expect(endpointRepo.PingUAAArgsForCall(0)).To(Equal("https://uaa.example.com"))
+			Expect(userRepo.FindAllByUsernameCallCount()).To(BeZero())
+			Expect(ui.Outputs()).To(ContainSubstrings(
+				[]string{"FAILED"},
+				[]string{"UAA endpoint https://uaa.example.com is unreachable"},
+			))
+		})
 	})
 
 	Context("when the given user does not exist", func() {
@@ -0,0 +1,114 @@
+package user
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/cli/cf/api"
+	"code.cloudfoundry.org/cli/cf/api/spaces"
+	"code.cloudfoundry.org/cli/cf/commandregistry"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/flags"
+	. "code.cloudfoundry.org/cli/cf/i18n"
+	"code.cloudfoundry.org/cli/cf/models"
+	"code.cloudfoundry.org/cli/cf/requirements"
+	"code.cloudfoundry.org/cli/cf/terminal"
+)
+
+type DiffSpaceUsers struct {
+	ui        terminal.UI
+	config    coreconfig.Reader
+	spaceRepo spaces.SpaceRepository
+	userRepo  api.UserRepository
+}
+
+func init() {
+	commandregistry.Register(&DiffSpaceUsers{})
+}
+
+func (cmd *DiffSpaceUsers) MetaData() commandregistry.CommandMetadata {
+	return commandregistry.CommandMetadata{
+		Name:        "diff-space-users",
+		Description: T("Show users whose space roles differ between two spaces"),
+		Usage: []string{
+			T("CF_NAME diff-space-users SPACE_A SPACE_B"),
+		},
+	}
+}
+
+func (cmd *DiffSpaceUsers) Requirements(requirementsFactory requirements.Factory, fc flags.FlagContext) ([]requirements.Requirement, error) {
+	if len(fc.Args()) != 2 {
+		cmd.ui.Failed(T("Incorrect Usage. Requires arguments\n\n") + commandregistry.Commands.CommandUsage("diff-space-users"))
+		return nil, fmt.Errorf("Incorrect usage: %d arguments of %d required", len(fc.Args()), 2)
+	}
+
+	reqs := []requirements.Requirement{
+		requirementsFactory.NewLoginRequirement(),
+		requirementsFactory.NewTargetedOrgRequirement(),
+	}
+
+	return reqs, nil
+}
+
+func (cmd *DiffSpaceUsers) SetDependency(deps commandregistry.Dependency, pluginCall bool) commandregistry.Command {
+	cmd.ui = deps.UI
+	cmd.config = deps.Config
+	cmd.userRepo = deps.RepoLocator.GetUserRepository()
+	cmd.spaceRepo = deps.RepoLocator.GetSpaceRepository()
+	return cmd
+}
+
+func (cmd *DiffSpaceUsers) Execute(c flags.FlagContext) error {
+	spaceA, err := cmd.spaceRepo.FindByName(c.Args()[0])
+	if err != nil {
+		return err
+	}
+	spaceB, err := cmd.spaceRepo.FindByName(c.Args()[1])
+	if err != nil {
+		return err
+	}
+
+	cmd.ui.Say(T("Comparing users in space {{.SpaceA}} and space {{.SpaceB}} in org {{.TargetOrg}} as {{.CurrentUser}}...",
+		map[string]interface{}{
+			"SpaceA":      terminal.EntityNameColor(spaceA.Name),
+			"SpaceB":      terminal.EntityNameColor(spaceB.Name),
+			"TargetOrg":   terminal.EntityNameColor(cmd.config.OrganizationFields().Name),
+			"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
+		}))
+
+	diffs, err := cmd.userRepo.DiffSpaceUsers(spaceA.GUID, spaceB.GUID)
+	if err != nil {
+		return err
+	}
+
+	cmd.ui.Ok()
+	cmd.ui.Say("")
+
+	if len(diffs) == 0 {
+		cmd.ui.Say(T("No differences found"))
+		return nil
+	}
+
+	roleDisplayNames := map[models.Role]string{
+		models.RoleSpaceManager:   T("SPACE MANAGER"),
+		models.RoleSpaceDeveloper: T("SPACE DEVELOPER"),
+		models.RoleSpaceAuditor:   T("SPACE AUDITOR"),
+	}
+
+	table := cmd.ui.Table([]string{T("role"), T("user"), T("only in")})
+	for _, diff := range diffs {
+		for _, user := range diff.OnlyInA {
+			table.Add(roleDisplayNames[diff.Role], displayName(user), spaceA.Name)
+		}
+		for _, user := range diff.OnlyInB {
+			table.Add(roleDisplayNames[diff.Role], displayName(user), spaceB.Name)
+		}
+	}
+	return table.Print()
+}
+
+func displayName(user models.UserFields) string {
+	if user.Username == "" {
+		return user.GUID
+	}
+	return user.Username
+}
@@ -0,0 +1,120 @@
+package user_test
+
+import (
+	"os"
+
+	"code.cloudfoundry.org/cli/cf/api/apifakes"
+	"code.cloudfoundry.org/cli/cf/api/spaces/spacesfakes"
+	"code.cloudfoundry.org/cli/cf/commandregistry"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/models"
+	"code.cloudfoundry.org/cli/cf/requirements"
+	"code.cloudfoundry.org/cli/cf/requirements/requirementsfakes"
+	"code.cloudfoundry.org/cli/cf/trace/tracefakes"
+	testcmd "code.cloudfoundry.org/cli/util/testhelpers/commands"
+	testconfig "code.cloudfoundry.org/cli/util/testhelpers/configuration"
+	testterm "code.cloudfoundry.org/cli/util/testhelpers/terminal"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "code.cloudfoundry.org/cli/util/testhelpers/matchers"
+)
+
+var _ = Describe("diff-space-users command", func() {
+	var (
+		ui                  *testterm.FakeUI
+		requirementsFactory *requirementsfakes.FakeFactory
+		spaceRepo           *spacesfakes.FakeSpaceRepository
+		userRepo            *apifakes.FakeUserRepository
+		configRepo          coreconfig.Repository
+		deps                commandregistry.Dependency
+	)
+
+	updateCommandDependency := func(pluginCall bool) {
+		deps.UI = ui
+		deps.Config = configRepo
+		deps.RepoLocator = deps.RepoLocator.SetUserRepository(userRepo)
+		deps.RepoLocator = deps.RepoLocator.SetSpaceRepository(spaceRepo)
+
+		commandregistry.Commands.SetCommand(commandregistry.Commands.FindCommand("diff-space-users").SetDependency(deps, pluginCall))
+	}
+
+	BeforeEach(func() {
+		configRepo = testconfig.NewRepositoryWithDefaults()
+		ui = &testterm.FakeUI{}
+		requirementsFactory = new(requirementsfakes.FakeFactory)
+		spaceRepo = new(spacesfakes.FakeSpaceRepository)
+		userRepo = new(apifakes.FakeUserRepository)
+		deps = commandregistry.NewDependency(os.Stdout, new(tracefakes.FakePrinter), "")
+	})
+
+	runCommand := func(args ...string) bool {
+		return testcmd.RunCLICommand("diff-space-users", args, requirementsFactory, updateCommandDependency, false, ui)
+	}
+
+	It("fails with usage when not invoked with exactly two args", func() {
+		runCommand("space-a")
+		Expect(ui.Outputs()).To(ContainSubstrings(
+			[]string{"Incorrect Usage", "Requires arguments"},
+		))
+	})
+
+	It("fails when not logged in", func() {
+		requirementsFactory.NewLoginRequirementReturns(requirements.Failing{Message: "not logged in"})
+		Expect(runCommand("space-a", "space-b")).To(BeFalse())
+	})
+
+	Context("when logged in with an org targeted", func() {
+		BeforeEach(func() {
+			requirementsFactory.NewLoginRequirementReturns(requirements.Passing{})
+			requirementsFactory.NewTargetedOrgRequirementReturns(new(requirementsfakes.FakeTargetedOrgRequirement))
+
+			spaceRepo.FindByNameStub = func(name string) (models.Space, error) {
+				space := models.Space{}
+				space.Name = name
+				space.GUID = name + "-guid"
+				return space, nil
+			}
+		})
+
+		Context("when the spaces have different members", func() {
+			BeforeEach(func() {
+				userRepo.DiffSpaceUsersReturns([]models.SpaceRoleDiff{
+					{
+						Role:    models.RoleSpaceManager,
+						OnlyInA: []models.UserFields{{GUID: "alice-guid", Username: "alice"}},
+					},
+					{
+						Role:    models.RoleSpaceDeveloper,
+						OnlyInA: []models.UserFields{{GUID: "bob-guid", Username: "bob"}},
+						OnlyInB: []models.UserFields{{GUID: "carol-guid", Username: "carol"}},
+					},
+				}, nil)
+			})
+
+			It("reports the users that hold a role in only one space", func() {
+				Expect(runCommand("space-a", "space-b")).To(BeTrue())
+
+				spaceAGUID, spaceBGUID := userRepo.DiffSpaceUsersArgsForCall(0)
+				Expect(spaceAGUID).To(Equal("space-a-guid"))
+				Expect(spaceBGUID).To(Equal("space-b-guid"))
+
+				Expect(ui.Outputs()).To(BeInDisplayOrder(
+					[]string{"Comparing users in space", "space-a", "space-b", "my-org", "my-user"},
+					[]string{"OK"},
+					[]string{"role", "user", "only in"},
+					[]string{"SPACE MANAGER", "alice", "space-a"},
+					[]string{"SPACE DEVELOPER", "bob", "space-a"},
+					[]string{"SPACE DEVELOPER", "carol", "space-b"},
+				))
+			})
+		})
+
+		Context("when the spaces have the same members", func() {
+			It("says there are no differences", func() {
+				Expect(runCommand("space-a", "space-b")).To(BeTrue())
+				Expect(ui.Outputs()).To(ContainSubstrings([]string{"No differences found"}))
+			})
+		})
+	})
+})
@@ -0,0 +1,90 @@
+package user
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/cli/cf/api"
+	"code.cloudfoundry.org/cli/cf/commandregistry"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/flags"
+	. "code.cloudfoundry.org/cli/cf/i18n"
+	"code.cloudfoundry.org/cli/cf/models"
+	"code.cloudfoundry.org/cli/cf/requirements"
+	"code.cloudfoundry.org/cli/cf/terminal"
+)
+
+// listAllUsersRow lays out a user as list-all-users prints it. GUIDs are all
+// the same width, so rows line up without collecting them into a table.
+const listAllUsersRow = "%-36s   %-10s   %-6s   %s"
+
+type ListAllUsers struct {
+	ui       terminal.UI
+	config   coreconfig.Reader
+	userRepo api.UserRepository
+}
+
+func init() {
+	commandregistry.Register(&ListAllUsers{})
+}
+
+func (cmd *ListAllUsers) MetaData() commandregistry.CommandMetadata {
+	return commandregistry.CommandMetadata{
+		Name:        "list-all-users",
+		Description: T("List every user in UAA, whether or not they belong to an org"),
+		Usage: []string{
+			T("CF_NAME list-all-users\n\n"),
+			T("   Users are printed as they are read from UAA, so a large foundation starts listing straight away."),
+		},
+	}
+}
+
+func (cmd *ListAllUsers) Requirements(requirementsFactory requirements.Factory, fc flags.FlagContext) ([]requirements.Requirement, error) {
+	if len(fc.Args()) != 0 {
+		cmd.ui.Failed(T("Incorrect Usage. No argument required\n\n") + commandregistry.Commands.CommandUsage("list-all-users"))
+		return nil, fmt.Errorf("Incorrect usage: %d arguments of %d required", len(fc.Args()), 0)
+	}
+
+	reqs := []requirements.Requirement{
+		requirementsFactory.NewLoginRequirement(),
+	}
+
+	return reqs, nil
+}
+
+func (cmd *ListAllUsers) SetDependency(deps commandregistry.Dependency, pluginCall bool) commandregistry.Command {
+	cmd.ui = deps.UI
+	cmd.config = deps.Config
+	cmd.userRepo = deps.RepoLocator.GetUserRepository()
+	return cmd
+}
+
+func (cmd *ListAllUsers) Execute(c flags.FlagContext) error {
+	cmd.ui.Say(T("Getting all users as {{.CurrentUser}}...",
+		map[string]interface{}{
+			"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
+		}))
+	cmd.ui.Say("")
+
+	count := 0
+	err := cmd.userRepo.ListAllUsers(func(user models.UserFields) bool {
+		if count == 0 {
+			cmd.ui.Say(terminal.HeaderColor(fmt.Sprintf(listAllUsersRow, T("guid"), T("origin"), T("active"), T("username"))))
+		}
+		count++
+		cmd.ui.Say(fmt.Sprintf(listAllUsersRow, user.GUID, user.Origin, fmt.Sprint(user.Active), user.Username))
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		cmd.ui.Say(T("No users found"))
+		return nil
+	}
+
+	cmd.ui.Say("")
+	cmd.ui.Say(T("{{.Count}} users", map[string]interface{}{"Count": count}))
+	cmd.ui.Ok()
+	return nil
+}
@@ -0,0 +1,94 @@
+package user_test
+
+import (
+	"code.cloudfoundry.org/cli/cf/api/apifakes"
+	"code.cloudfoundry.org/cli/cf/commandregistry"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/errors"
+	"code.cloudfoundry.org/cli/cf/models"
+	"code.cloudfoundry.org/cli/cf/requirements"
+	"code.cloudfoundry.org/cli/cf/requirements/requirementsfakes"
+	testcmd "code.cloudfoundry.org/cli/util/testhelpers/commands"
+	testconfig "code.cloudfoundry.org/cli/util/testhelpers/configuration"
+	testterm "code.cloudfoundry.org/cli/util/testhelpers/terminal"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "code.cloudfoundry.org/cli/util/testhelpers/matchers"
+)
+
+var _ = Describe("list-all-users command", func() {
+	var (
+		ui                  *testterm.FakeUI
+		configRepo          coreconfig.Repository
+		userRepo            *apifakes.FakeUserRepository
+		requirementsFactory *requirementsfakes.FakeFactory
+		deps                commandregistry.Dependency
+	)
+
+	updateCommandDependency := func(pluginCall bool) {
+		deps.UI = ui
+		deps.Config = configRepo
+		deps.RepoLocator = deps.RepoLocator.SetUserRepository(userRepo)
+		commandregistry.Commands.SetCommand(commandregistry.Commands.FindCommand("list-all-users").SetDependency(deps, pluginCall))
+	}
+
+	BeforeEach(func() {
+		ui = &testterm.FakeUI{}
+		userRepo = new(apifakes.FakeUserRepository)
+		configRepo = testconfig.NewRepositoryWithDefaults()
+
+		requirementsFactory = new(requirementsfakes.FakeFactory)
+		requirementsFactory.NewLoginRequirementReturns(requirements.Passing{})
+	})
+
+	runCommand := func(args ...string) bool {
+		return testcmd.RunCLICommand("list-all-users", args, requirementsFactory, updateCommandDependency, false, ui)
+	}
+
+	Describe("requirements", func() {
+		It("fails with usage when given an argument", func() {
+			runCommand("alice")
+			Expect(ui.Outputs()).To(ContainSubstrings([]string{"Incorrect Usage", "No argument required"}))
+		})
+
+		It("fails when not logged in", func() {
+			requirementsFactory.NewLoginRequirementReturns(requirements.Failing{Message: "not logged in"})
+
+			Expect(runCommand()).To(BeFalse())
+		})
+	})
+
+	It("prints each user as the repository calls back with it", func() {
+		userRepo.ListAllUsersStub = func(cb func(models.UserFields) bool) error {
+			Expect(cb(models.UserFields{GUID: "alice-guid", Username: "alice", Origin: "uaa", Active: true})).To(BeTrue())
+			Expect(ui.Outputs()).To(ContainSubstrings([]string{"alice-guid", "uaa", "true", "alice"}))
+
+			cb(models.UserFields{GUID: "bob-guid", Username: "bob", Origin: "ldap"})
+			return nil
+		}
+
+		Expect(runCommand()).To(BeTrue())
+
+		Expect(ui.Outputs()).To(ContainSubstrings(
+			[]string{"Getting all users as", "my-user"},
+			[]string{"guid", "origin", "active", "username"},
+			[]string{"alice-guid", "uaa", "true", "alice"},
+			[]string{"bob-guid", "ldap", "false", "bob"},
+			[]string{"2 users"},
+			[]string{"OK"},
+		))
+	})
+
+	It("says so when there are no users", func() {
+		Expect(runCommand()).To(BeTrue())
+		Expect(ui.Outputs()).To(ContainSubstrings([]string{"No users found"}))
+	})
+
+	It("fails when the users cannot be read", func() {
+		userRepo.ListAllUsersReturns(errors.New("uaa down"))
+
+		Expect(runCommand()).To(BeFalse())
+		Expect(ui.Outputs()).To(ContainSubstrings([]string{"uaa down"}))
+	})
+})
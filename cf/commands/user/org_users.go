@@ -32,6 +32,10 @@ func init() {
 func (cmd *OrgUsers) MetaData() commandregistry.CommandMetadata {
 	fs := make(map[string]flags.FlagSet)
 	fs["a"] = &flags.BoolFlag{ShortName: "a", Usage: T("List all users in the org")}
+	fs["json"] = &flags.BoolFlag{Name: "json", Usage: T("Print the users and their roles as JSON")}
+	fs["field-style"] = &flags.StringFlag{Name: "field-style", Usage: T("Key casing of the JSON output: camel (default) or snake")}
+	fs["count-only"] = &flags.BoolFlag{Name: "count-only", Usage: T("Print only the number of users with each role")}
+	fs["detailed"] = &flags.BoolFlag{Name: "detailed", Usage: T("Show each user's identity provider origin, such as uaa or ldap")}
 
 	return commandregistry.CommandMetadata{
 		Name:        "org-users",
@@ -49,6 +53,11 @@ func (cmd *OrgUsers) Requirements(requirementsFactory requirements.Factory, fc f
 		return nil, fmt.Errorf("Incorrect usage: %d arguments of %d required", len(fc.Args()), 1)
 	}
 
+	if style := fc.String("field-style"); style != "" && style != string(userprint.CamelCase) && style != string(userprint.SnakeCase) {
+		cmd.ui.Failed(T(`Incorrect Usage. --field-style must be "camel" or "snake"\n\n`) + commandregistry.Commands.CommandUsage("org-users"))
+		return nil, fmt.Errorf("Incorrect usage: invalid field style %s", style)
+	}
+
 	cmd.orgReq = requirementsFactory.NewOrganizationRequirement(fc.Args()[0])
 
 	reqs := []requirements.Requirement{
@@ -71,18 +80,14 @@ func (cmd *OrgUsers) SetDependency(deps commandregistry.Dependency, pluginCall b
 func (cmd *OrgUsers) Execute(c flags.FlagContext) error {
 	org := cmd.orgReq.GetOrganization()
 
-	cmd.ui.Say(T("Getting users in org {{.TargetOrg}} as {{.CurrentUser}}...",
-		map[string]interface{}{
-			"TargetOrg":   terminal.EntityNameColor(org.Name),
-			"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
-		}))
-
-	printer := cmd.printer(c)
-	printer.PrintUsers(org.GUID, cmd.config.Username())
-	return nil
-}
+	if !c.Bool("json") {
+		cmd.ui.Say(T("Getting users in org {{.TargetOrg}} as {{.CurrentUser}}...",
+			map[string]interface{}{
+				"TargetOrg":   terminal.EntityNameColor(org.Name),
+				"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
+			}))
+	}
 
-func (cmd *OrgUsers) printer(c flags.FlagContext) userprint.UserPrinter {
 	var roles []models.Role
 	if c.Bool("a") {
 		roles = []models.Role{models.RoleOrgUser}
@@ -90,29 +95,76 @@ func (cmd *OrgUsers) printer(c flags.FlagContext) userprint.UserPrinter {
 		roles = []models.Role{models.RoleOrgManager, models.RoleBillingManager, models.RoleOrgAuditor}
 	}
 
+	var userLister func(orgGUID string, role models.Role) ([]models.UserFields, error)
+	if !c.Bool("count-only") {
+		var err error
+		userLister, err = cmd.userLister(org.GUID, roles, c.Bool("detailed"))
+		if err != nil {
+			return err
+		}
+	}
+
+	printer := cmd.printer(c, roles, userLister)
+	printer.PrintUsers(org.GUID, cmd.config.Username())
+	return nil
+}
+
+func (cmd *OrgUsers) printer(c flags.FlagContext, roles []models.Role, userLister func(orgGUID string, role models.Role) ([]models.UserFields, error)) userprint.UserPrinter {
 	if cmd.pluginCall {
 		return userprint.NewOrgUsersPluginPrinter(
 			cmd.pluginModel,
-			cmd.userLister(),
+			userLister,
 			roles,
 		)
 	}
+	if c.Bool("json") {
+		return &userprint.JSONPrinter{
+			UI:         cmd.ui,
+			UserLister: userLister,
+			Roles:      roles,
+			FieldStyle: userprint.FieldStyle(c.String("field-style")),
+		}
+	}
+	roleDisplayNames := map[models.Role]string{
+		models.RoleOrgUser:        T("USERS"),
+		models.RoleOrgManager:     T("ORG MANAGER"),
+		models.RoleBillingManager: T("BILLING MANAGER"),
+		models.RoleOrgAuditor:     T("ORG AUDITOR"),
+	}
+	if c.Bool("count-only") {
+		return &userprint.CountPrinter{
+			UI:               cmd.ui,
+			UserCounter:      cmd.userRepo.CountUsersInOrgForRole,
+			Roles:            roles,
+			RoleDisplayNames: roleDisplayNames,
+		}
+	}
 	return &userprint.OrgUsersUIPrinter{
-		UI:         cmd.ui,
-		UserLister: cmd.userLister(),
-		Roles:      roles,
-		RoleDisplayNames: map[models.Role]string{
-			models.RoleOrgUser:        T("USERS"),
-			models.RoleOrgManager:     T("ORG MANAGER"),
-			models.RoleBillingManager: T("BILLING MANAGER"),
-			models.RoleOrgAuditor:     T("ORG AUDITOR"),
-		},
+		UI:               cmd.ui,
+		UserLister:       userLister,
+		Roles:            roles,
+		RoleDisplayNames: roleDisplayNames,
+		Detailed:         c.Bool("detailed"),
 	}
 }
 
-func (cmd *OrgUsers) userLister() func(orgGUID string, role models.Role) ([]models.UserFields, error) {
-	if cmd.config.IsMinAPIVersion(cf.ListUsersInOrgOrSpaceWithoutUAAMinimumAPIVersion) {
-		return cmd.userRepo.ListUsersInOrgForRoleWithNoUAA
+// userLister returns how to list each role's users. Without the no-UAA
+// endpoints, every role is listed up front so the Cloud Controller calls run
+// concurrently and the usernames are looked up in UAA together; a failure
+// listing any role fails the command. Only UAA knows a user's origin, so
+// needUAA always takes that path.
+func (cmd *OrgUsers) userLister(orgGUID string, roles []models.Role, needUAA bool) (func(orgGUID string, role models.Role) ([]models.UserFields, error), error) {
+	if !needUAA && cmd.config.IsMinAPIVersion(cf.ListUsersInOrgOrSpaceWithoutUAAMinimumAPIVersion) {
+		return cmd.userRepo.ListUsersInOrgForRoleWithNoUAA, nil
+	}
+
+	usersByRole, err := cmd.userRepo.ListUsersInOrgForRoles(orgGUID, roles)
+	if _, partial := err.(*api.PartialUAALookupWarning); partial {
+		cmd.ui.Warn("%s", err.Error())
+	} else if err != nil {
+		return nil, err
 	}
-	return cmd.userRepo.ListUsersInOrgForRole
+	return func(_ string, role models.Role) ([]models.UserFields, error) {
+		return usersByRole[role], nil
+	}, nil
 }
@@ -1,9 +1,11 @@
 package user_test
 
 import (
+	"code.cloudfoundry.org/cli/cf/api"
 	"code.cloudfoundry.org/cli/cf/api/apifakes"
 	"code.cloudfoundry.org/cli/cf/commandregistry"
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/errors"
 	"code.cloudfoundry.org/cli/cf/models"
 	"code.cloudfoundry.org/cli/cf/requirements"
 	"code.cloudfoundry.org/cli/cf/requirements/requirementsfakes"
@@ -87,23 +89,18 @@ var _ = Describe("org-users command", func() {
 
 		Context("shows friendly messaage when no users in ORG_MANAGER role", func() {
 			It("shows the special users in the given org", func() {
-				userRepo.ListUsersInOrgForRoleStub = func(_ string, roleName models.Role) ([]models.UserFields, error) {
-					userFields := map[models.Role][]models.UserFields{
-						models.RoleOrgManager:     {},
-						models.RoleBillingManager: {user1},
-						models.RoleOrgAuditor:     {user2},
-					}[roleName]
-					return userFields, nil
-				}
+				userRepo.ListUsersInOrgForRolesReturns(map[models.Role][]models.UserFields{
+					models.RoleOrgManager:     {},
+					models.RoleBillingManager: {user1},
+					models.RoleOrgAuditor:     {user2},
+				}, nil)
 
 				runCommand("the-org")
 
-				Expect(userRepo.ListUsersInOrgForRoleCallCount()).To(Equal(3))
-				for i, expectedRole := range []models.Role{models.RoleOrgManager, models.RoleBillingManager, models.RoleOrgAuditor} {
-					orgGUID, actualRole := userRepo.ListUsersInOrgForRoleArgsForCall(i)
-					Expect(orgGUID).To(Equal("the-org-guid"))
-					Expect(actualRole).To(Equal(expectedRole))
-				}
+				Expect(userRepo.ListUsersInOrgForRolesCallCount()).To(Equal(1))
+				orgGUID, actualRoles := userRepo.ListUsersInOrgForRolesArgsForCall(0)
+				Expect(orgGUID).To(Equal("the-org-guid"))
+				Expect(actualRoles).To(Equal([]models.Role{models.RoleOrgManager, models.RoleBillingManager, models.RoleOrgAuditor}))
 
 				Expect(ui.Outputs()).To(BeInDisplayOrder(
 					[]string{"Getting users in org", "the-org", "my-user"},
@@ -119,23 +116,18 @@ var _ = Describe("org-users command", func() {
 
 		Context("shows friendly messaage when no users in BILLING_MANAGER role", func() {
 			It("shows the special users in the given org", func() {
-				userRepo.ListUsersInOrgForRoleStub = func(_ string, roleName models.Role) ([]models.UserFields, error) {
-					userFields := map[models.Role][]models.UserFields{
-						models.RoleOrgManager:     {user1},
-						models.RoleBillingManager: {},
-						models.RoleOrgAuditor:     {user2},
-					}[roleName]
-					return userFields, nil
-				}
+				userRepo.ListUsersInOrgForRolesReturns(map[models.Role][]models.UserFields{
+					models.RoleOrgManager:     {user1},
+					models.RoleBillingManager: {},
+					models.RoleOrgAuditor:     {user2},
+				}, nil)
 
 				runCommand("the-org")
 
-				Expect(userRepo.ListUsersInOrgForRoleCallCount()).To(Equal(3))
-				for i, expectedRole := range []models.Role{models.RoleOrgManager, models.RoleBillingManager, models.RoleOrgAuditor} {
-					orgGUID, actualRole := userRepo.ListUsersInOrgForRoleArgsForCall(i)
-					Expect(orgGUID).To(Equal("the-org-guid"))
-					Expect(actualRole).To(Equal(expectedRole))
-				}
+				Expect(userRepo.ListUsersInOrgForRolesCallCount()).To(Equal(1))
+				orgGUID, actualRoles := userRepo.ListUsersInOrgForRolesArgsForCall(0)
+				Expect(orgGUID).To(Equal("the-org-guid"))
+				Expect(actualRoles).To(Equal([]models.Role{models.RoleOrgManager, models.RoleBillingManager, models.RoleOrgAuditor}))
 
 				Expect(ui.Outputs()).To(BeInDisplayOrder(
 					[]string{"Getting users in org", "the-org", "my-user"},
@@ -151,23 +143,18 @@ var _ = Describe("org-users command", func() {
 
 		Context("shows friendly messaage when no users in ORG_AUDITOR role", func() {
 			It("shows the special users in the given org", func() {
-				userRepo.ListUsersInOrgForRoleStub = func(_ string, roleName models.Role) ([]models.UserFields, error) {
-					userFields := map[models.Role][]models.UserFields{
-						models.RoleOrgManager:     {user1},
-						models.RoleBillingManager: {user2},
-						models.RoleOrgAuditor:     {},
-					}[roleName]
-					return userFields, nil
-				}
+				userRepo.ListUsersInOrgForRolesReturns(map[models.Role][]models.UserFields{
+					models.RoleOrgManager:     {user1},
+					models.RoleBillingManager: {user2},
+					models.RoleOrgAuditor:     {},
+				}, nil)
 
 				runCommand("the-org")
 
-				Expect(userRepo.ListUsersInOrgForRoleCallCount()).To(Equal(3))
-				for i, expectedRole := range []models.Role{models.RoleOrgManager, models.RoleBillingManager, models.RoleOrgAuditor} {
-					orgGUID, actualRole := userRepo.ListUsersInOrgForRoleArgsForCall(i)
-					Expect(orgGUID).To(Equal("the-org-guid"))
-					Expect(actualRole).To(Equal(expectedRole))
-				}
+				Expect(userRepo.ListUsersInOrgForRolesCallCount()).To(Equal(1))
+				orgGUID, actualRoles := userRepo.ListUsersInOrgForRolesArgsForCall(0)
+				Expect(orgGUID).To(Equal("the-org-guid"))
+				Expect(actualRoles).To(Equal([]models.Role{models.RoleOrgManager, models.RoleBillingManager, models.RoleOrgAuditor}))
 				Expect(ui.Outputs()).To(BeInDisplayOrder(
 					[]string{"Getting users in org", "the-org", "my-user"},
 					[]string{"ORG MANAGER"},
@@ -192,14 +179,11 @@ var _ = Describe("org-users command", func() {
 			user2 := models.UserFields{Username: "user2"}
 			user3 := models.UserFields{Username: "user3"}
 			user4 := models.UserFields{Username: "user4"}
-			userRepo.ListUsersInOrgForRoleStub = func(_ string, roleName models.Role) ([]models.UserFields, error) {
-				userFields := map[models.Role][]models.UserFields{
-					models.RoleOrgManager:     {user, user2},
-					models.RoleBillingManager: {user4},
-					models.RoleOrgAuditor:     {user3},
-				}[roleName]
-				return userFields, nil
-			}
+			userRepo.ListUsersInOrgForRolesReturns(map[models.Role][]models.UserFields{
+				models.RoleOrgManager:     {user, user2},
+				models.RoleBillingManager: {user4},
+				models.RoleOrgAuditor:     {user3},
+			}, nil)
 
 			requirementsFactory.NewLoginRequirementReturns(requirements.Passing{})
 			organizationReq := new(requirementsfakes.FakeOrganizationRequirement)
@@ -210,7 +194,7 @@ var _ = Describe("org-users command", func() {
 		It("shows the special users in the given org", func() {
 			runCommand("the-org")
 
-			orgGUID, _ := userRepo.ListUsersInOrgForRoleArgsForCall(0)
+			orgGUID, _ := userRepo.ListUsersInOrgForRolesArgsForCall(0)
 			Expect(orgGUID).To(Equal("the-org-guid"))
 			Expect(ui.Outputs()).To(ContainSubstrings(
 				[]string{"Getting users in org", "the-org", "my-user"},
@@ -224,22 +208,42 @@ var _ = Describe("org-users command", func() {
 			))
 		})
 
+		It("fails when any role cannot be listed", func() {
+			userRepo.ListUsersInOrgForRolesReturns(nil, &api.RoleListingError{
+				Roles: []models.Role{models.RoleBillingManager},
+				Errs:  map[models.Role]error{models.RoleBillingManager: errors.New("list-error")},
+			})
+
+			Expect(runCommand("the-org")).To(BeFalse())
+			Expect(ui.Outputs()).NotTo(ContainSubstrings([]string{"ORG MANAGER"}))
+		})
+
+		It("warns and lists the users it could when some usernames cannot be looked up", func() {
+			userRepo.ListUsersInOrgForRolesReturns(map[models.Role][]models.UserFields{
+				models.RoleOrgManager: {{Username: "user1"}},
+			}, &api.PartialUAALookupWarning{Count: 2, Err: errors.New("uaa-error")})
+
+			Expect(runCommand("the-org")).To(BeTrue())
+			Expect(ui.Outputs()).To(ContainSubstrings(
+				[]string{"2 user(s) could not be looked up in UAA", "uaa-error"},
+				[]string{"ORG MANAGER"},
+				[]string{"user1"},
+			))
+		})
+
 		Context("when the -a flag is provided", func() {
 			BeforeEach(func() {
 				user := models.UserFields{Username: "user1"}
 				user2 := models.UserFields{Username: "user2"}
-				userRepo.ListUsersInOrgForRoleStub = func(_ string, roleName models.Role) ([]models.UserFields, error) {
-					userFields := map[models.Role][]models.UserFields{
-						models.RoleOrgUser: {user, user2},
-					}[roleName]
-					return userFields, nil
-				}
+				userRepo.ListUsersInOrgForRolesReturns(map[models.Role][]models.UserFields{
+					models.RoleOrgUser: {user, user2},
+				}, nil)
 			})
 
 			It("lists all org users, regardless of role", func() {
 				runCommand("-a", "the-org")
 
-				orgGUID, _ := userRepo.ListUsersInOrgForRoleArgsForCall(0)
+				orgGUID, _ := userRepo.ListUsersInOrgForRolesArgsForCall(0)
 				Expect(orgGUID).To(Equal("the-org-guid"))
 				Expect(ui.Outputs()).To(ContainSubstrings(
 					[]string{"Getting users in org", "the-org", "my-user"},
@@ -250,23 +254,146 @@ var _ = Describe("org-users command", func() {
 			})
 		})
 
+		Context("when the --json flag is provided", func() {
+			BeforeEach(func() {
+				userRepo.ListUsersInOrgForRolesReturns(map[models.Role][]models.UserFields{
+					models.RoleOrgManager:     {{Username: "user2", GUID: "user2-guid"}, {Username: "user1", GUID: "user1-guid"}},
+					models.RoleBillingManager: {},
+					models.RoleOrgAuditor:     {{Username: "user1", GUID: "user1-guid"}},
+				}, nil)
+			})
+
+			It("prints only a JSON array of each user with all of their roles", func() {
+				runCommand("--json", "the-org")
+
+				Expect(ui.Outputs()).To(HaveLen(1))
+				Expect(ui.Outputs()[0]).To(MatchJSON(`[
+					{"username": "user1", "guid": "user1-guid", "roles": ["RoleOrgManager", "RoleOrgAuditor"], "isAdmin": false},
+					{"username": "user2", "guid": "user2-guid", "roles": ["RoleOrgManager"], "isAdmin": false}
+				]`))
+			})
+
+			It("writes snake_case keys when --field-style is snake", func() {
+				userRepo.ListUsersInOrgForRolesReturns(map[models.Role][]models.UserFields{
+					models.RoleOrgManager: {{Username: "user1", GUID: "user1-guid", IsAdmin: true}},
+				}, nil)
+
+				runCommand("--json", "--field-style", "snake", "the-org")
+
+				Expect(ui.Outputs()[0]).To(MatchJSON(`[
+					{"username": "user1", "guid": "user1-guid", "roles": ["RoleOrgManager"], "is_admin": true}
+				]`))
+			})
+
+			It("writes camelCase keys when --field-style is camel", func() {
+				userRepo.ListUsersInOrgForRolesReturns(map[models.Role][]models.UserFields{
+					models.RoleOrgManager: {{Username: "user1", GUID: "user1-guid", IsAdmin: true}},
+				}, nil)
+
+				runCommand("--json", "--field-style", "camel", "the-org")
+
+				Expect(ui.Outputs()[0]).To(MatchJSON(`[
+					{"username": "user1", "guid": "user1-guid", "roles": ["RoleOrgManager"], "isAdmin": true}
+				]`))
+			})
+
+			It("fails with usage when --field-style is neither camel nor snake", func() {
+				Expect(runCommand("--json", "--field-style", "kebab", "the-org")).To(BeFalse())
+
+				Expect(ui.Outputs()).To(ContainSubstrings(
+					[]string{"Incorrect Usage", "--field-style"},
+				))
+				Expect(userRepo.ListUsersInOrgForRolesCallCount()).To(BeZero())
+			})
+
+			It("prints an empty array when there are no users", func() {
+				userRepo.ListUsersInOrgForRolesReturns(map[models.Role][]models.UserFields{}, nil)
+
+				runCommand("--json", "the-org")
+
+				Expect(ui.Outputs()).To(Equal([]string{"[]"}))
+			})
+		})
+
+		Context("when the --count-only flag is provided", func() {
+			BeforeEach(func() {
+				userRepo.CountUsersInOrgForRoleStub = func(_ string, roleName models.Role) (int, error) {
+					return map[models.Role]int{
+						models.RoleOrgManager:     2,
+						models.RoleBillingManager: 0,
+						models.RoleOrgAuditor:     5,
+					}[roleName], nil
+				}
+			})
+
+			It("prints the number of users with each role without listing them", func() {
+				runCommand("--count-only", "the-org")
+
+				Expect(ui.Outputs()).To(ContainSubstrings(
+					[]string{"ORG MANAGER: 2"},
+					[]string{"BILLING MANAGER: 0"},
+					[]string{"ORG AUDITOR: 5"},
+				))
+				orgGUID, _ := userRepo.CountUsersInOrgForRoleArgsForCall(0)
+				Expect(orgGUID).To(Equal("the-org-guid"))
+				Expect(userRepo.ListUsersInOrgForRolesCallCount()).To(BeZero())
+				Expect(userRepo.ListUsersInOrgForRoleWithNoUAACallCount()).To(BeZero())
+			})
+
+			It("fails when a count cannot be fetched", func() {
+				userRepo.CountUsersInOrgForRoleStub = nil
+				userRepo.CountUsersInOrgForRoleReturns(0, errors.New("count-error"))
+
+				runCommand("--count-only", "the-org")
+
+				Expect(ui.Outputs()).To(ContainSubstrings([]string{"FAILED"}, []string{"count-error"}))
+			})
+		})
+
+		Context("when the --detailed flag is provided", func() {
+			BeforeEach(func() {
+				userRepo.ListUsersInOrgForRolesReturns(map[models.Role][]models.UserFields{
+					models.RoleOrgManager: {{Username: "user1", Origin: "uaa"}, {Username: "user2", Origin: "ldap"}},
+				}, nil)
+			})
+
+			It("shows each user's origin", func() {
+				runCommand("--detailed", "the-org")
+
+				Expect(ui.Outputs()).To(ContainSubstrings(
+					[]string{"ORG MANAGER"},
+					[]string{"user", "origin"},
+					[]string{"user1", "uaa"},
+					[]string{"user2", "ldap"},
+				))
+			})
+
+			It("looks the users up in UAA even when the no-UAA endpoints are available", func() {
+				configRepo.SetAPIVersion("2.22.0")
+				runCommand("--detailed", "the-org")
+
+				Expect(userRepo.ListUsersInOrgForRoleWithNoUAACallCount()).To(BeZero())
+				Expect(userRepo.ListUsersInOrgForRolesCallCount()).To(Equal(1))
+			})
+		})
+
 		Context("when cc api verson is >= 2.21.0", func() {
 			It("calls ListUsersInOrgForRoleWithNoUAA()", func() {
 				configRepo.SetAPIVersion("2.22.0")
 				runCommand("the-org")
 
 				Expect(userRepo.ListUsersInOrgForRoleWithNoUAACallCount()).To(BeNumerically(">=", 1))
-				Expect(userRepo.ListUsersInOrgForRoleCallCount()).To(Equal(0))
+				Expect(userRepo.ListUsersInOrgForRolesCallCount()).To(Equal(0))
 			})
 		})
 
 		Context("when cc api verson is < 2.21.0", func() {
-			It("calls ListUsersInOrgForRole()", func() {
+			It("calls ListUsersInOrgForRoles()", func() {
 				configRepo.SetAPIVersion("2.20.0")
 				runCommand("the-org")
 
 				Expect(userRepo.ListUsersInOrgForRoleWithNoUAACallCount()).To(Equal(0))
-				Expect(userRepo.ListUsersInOrgForRoleCallCount()).To(BeNumerically(">=", 1))
+				Expect(userRepo.ListUsersInOrgForRolesCallCount()).To(Equal(1))
 			})
 		})
 	})
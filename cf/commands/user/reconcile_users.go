@@ -0,0 +1,149 @@
+package user
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/cli/cf/api"
+	"code.cloudfoundry.org/cli/cf/commandregistry"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/flags"
+	. "code.cloudfoundry.org/cli/cf/i18n"
+	"code.cloudfoundry.org/cli/cf/models"
+	"code.cloudfoundry.org/cli/cf/requirements"
+	"code.cloudfoundry.org/cli/cf/terminal"
+)
+
+type ReconcileUsers struct {
+	ui           terminal.UI
+	config       coreconfig.Reader
+	userRepo     api.UserRepository
+	endpointRepo coreconfig.EndpointRepository
+}
+
+func init() {
+	commandregistry.Register(&ReconcileUsers{})
+}
+
+func (cmd *ReconcileUsers) MetaData() commandregistry.CommandMetadata {
+	fs := make(map[string]flags.FlagSet)
+	fs["f"] = &flags.BoolFlag{ShortName: "f", Usage: T("Force deletion without confirmation")}
+	fs["delete-cc-only"] = &flags.BoolFlag{Name: "delete-cc-only", Usage: T("Delete the listed users that only the Cloud Controller has a record of")}
+	fs["delete-uaa-only"] = &flags.BoolFlag{Name: "delete-uaa-only", Usage: T("Delete the listed users that only UAA has a record of")}
+
+	return commandregistry.CommandMetadata{
+		Name:        "reconcile-users",
+		Description: T("List users that only one of the Cloud Controller and UAA has a record of, and optionally delete them"),
+		Usage: []string{
+			T("CF_NAME reconcile-users [--delete-cc-only] [--delete-uaa-only] [-f]"),
+		},
+		Flags: fs,
+	}
+}
+
+func (cmd *ReconcileUsers) Requirements(requirementsFactory requirements.Factory, fc flags.FlagContext) ([]requirements.Requirement, error) {
+	usageReq := requirements.NewUsageRequirement(commandregistry.CLICommandUsagePresenter(cmd),
+		T("No argument required"),
+		func() bool {
+			return len(fc.Args()) != 0
+		},
+	)
+
+	reqs := []requirements.Requirement{
+		usageReq,
+		requirementsFactory.NewLoginRequirement(),
+	}
+
+	return reqs, nil
+}
+
+func (cmd *ReconcileUsers) SetDependency(deps commandregistry.Dependency, pluginCall bool) commandregistry.Command {
+	cmd.ui = deps.UI
+	cmd.config = deps.Config
+	cmd.userRepo = deps.RepoLocator.GetUserRepository()
+	cmd.endpointRepo = deps.RepoLocator.GetEndpointRepository()
+	return cmd
+}
+
+func (cmd *ReconcileUsers) Execute(c flags.FlagContext) error {
+	cmd.ui.Say(T("Finding users without both a Cloud Controller and a UAA record as {{.CurrentUser}}...",
+		map[string]interface{}{
+			"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
+		}))
+
+	err := pingUAA(cmd.config, cmd.endpointRepo)
+	if err != nil {
+		return err
+	}
+
+	unmatched, err := cmd.userRepo.FindUnmatchedUsers()
+	if err != nil {
+		return err
+	}
+
+	cmd.ui.Ok()
+	cmd.ui.Say("")
+
+	total := len(unmatched.CCOnly) + len(unmatched.UAAOnly)
+	if total == 0 {
+		cmd.ui.Say(T("No unmatched users found"))
+		return nil
+	}
+
+	table := cmd.ui.Table([]string{T("user"), T("guid"), T("only in")})
+	for _, user := range unmatched.CCOnly {
+		table.Add(displayName(user), user.GUID, T("Cloud Controller"))
+	}
+	for _, user := range unmatched.UAAOnly {
+		table.Add(displayName(user), user.GUID, T("UAA"))
+	}
+	err = table.Print()
+	if err != nil {
+		return err
+	}
+	cmd.ui.Say("")
+
+	var toDelete []models.UserFields
+	if c.Bool("delete-cc-only") {
+		toDelete = append(toDelete, unmatched.CCOnly...)
+	}
+	if c.Bool("delete-uaa-only") {
+		toDelete = append(toDelete, unmatched.UAAOnly...)
+	}
+	if len(toDelete) == 0 {
+		if !c.Bool("delete-cc-only") && !c.Bool("delete-uaa-only") {
+			cmd.ui.Say(T("Use --delete-cc-only or --delete-uaa-only to delete these users"))
+		}
+		return nil
+	}
+
+	if !c.Bool("f") && !cmd.ui.Confirm(T("Really delete these {{.Count}} users?{{.Prompt}}",
+		map[string]interface{}{"Count": len(toDelete), "Prompt": terminal.PromptColor(">")})) {
+		return nil
+	}
+
+	var failed int
+	for _, user := range toDelete {
+		failed += cmd.deleteUser(user)
+	}
+	if failed > 0 {
+		return errors.New(T("{{.Failed}} of {{.Total}} users could not be deleted",
+			map[string]interface{}{"Failed": failed, "Total": len(toDelete)}))
+	}
+
+	cmd.ui.Ok()
+	return nil
+}
+
+// deleteUser deletes user and returns 1 if that failed, after warning about
+// it.
+func (cmd *ReconcileUsers) deleteUser(user models.UserFields) int {
+	cmd.ui.Say(T("Deleting user {{.TargetUser}}...",
+		map[string]interface{}{"TargetUser": terminal.EntityNameColor(displayName(user))}))
+
+	err := cmd.userRepo.Delete(user.GUID)
+	if err != nil {
+		cmd.ui.Warn(err.Error())
+		return 1
+	}
+	return 0
+}
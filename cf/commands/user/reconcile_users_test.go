@@ -0,0 +1,154 @@
+package user_test
+
+import (
+	"code.cloudfoundry.org/cli/cf/api/apifakes"
+	"code.cloudfoundry.org/cli/cf/commandregistry"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig/coreconfigfakes"
+	"code.cloudfoundry.org/cli/cf/errors"
+	"code.cloudfoundry.org/cli/cf/models"
+	"code.cloudfoundry.org/cli/cf/requirements"
+	"code.cloudfoundry.org/cli/cf/requirements/requirementsfakes"
+	testcmd "code.cloudfoundry.org/cli/util/testhelpers/commands"
+	testconfig "code.cloudfoundry.org/cli/util/testhelpers/configuration"
+	testterm "code.cloudfoundry.org/cli/util/testhelpers/terminal"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "code.cloudfoundry.org/cli/util/testhelpers/matchers"
+)
+
+var _ = Describe("reconcile-users command", func() {
+	var (
+		ui                  *testterm.FakeUI
+		configRepo          coreconfig.Repository
+		userRepo            *apifakes.FakeUserRepository
+		endpointRepo        *coreconfigfakes.FakeEndpointRepository
+		requirementsFactory *requirementsfakes.FakeFactory
+		deps                commandregistry.Dependency
+	)
+
+	updateCommandDependency := func(pluginCall bool) {
+		deps.UI = ui
+		deps.Config = configRepo
+		deps.RepoLocator = deps.RepoLocator.SetUserRepository(userRepo)
+		deps.RepoLocator = deps.RepoLocator.SetEndpointRepository(endpointRepo)
+		commandregistry.Commands.SetCommand(commandregistry.Commands.FindCommand("reconcile-users").SetDependency(deps, pluginCall))
+	}
+
+	BeforeEach(func() {
+		ui = &testterm.FakeUI{Inputs: []string{"y"}}
+		userRepo = new(apifakes.FakeUserRepository)
+		endpointRepo = new(coreconfigfakes.FakeEndpointRepository)
+		requirementsFactory = new(requirementsfakes.FakeFactory)
+		requirementsFactory.NewLoginRequirementReturns(requirements.Passing{})
+		configRepo = testconfig.NewRepositoryWithDefaults()
+	})
+
+	runCommand := func(args ...string) bool {
+		return testcmd.RunCLICommand("reconcile-users", args, requirementsFactory, updateCommandDependency, false, ui)
+	}
+
+	Describe("requirements", func() {
+		It("fails when not logged in", func() {
+			requirementsFactory.NewLoginRequirementReturns(requirements.Failing{Message: "not logged in"})
+
+			Expect(runCommand()).To(BeFalse())
+		})
+
+		It("fails with usage when given an argument", func() {
+			Expect(runCommand("blahblah")).To(BeFalse())
+			Expect(userRepo.FindUnmatchedUsersCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when there are unmatched users", func() {
+		BeforeEach(func() {
+			userRepo.FindUnmatchedUsersReturns(models.UnmatchedUsers{
+				CCOnly:  []models.UserFields{{GUID: "cc-only-guid"}},
+				UAAOnly: []models.UserFields{{GUID: "uaa-only-guid", Username: "dave"}},
+			}, nil)
+		})
+
+		It("only lists them by default", func() {
+			Expect(runCommand()).To(BeTrue())
+
+			Expect(ui.Outputs()).To(ContainSubstrings(
+				[]string{"user", "guid", "only in"},
+				[]string{"cc-only-guid", "cc-only-guid", "Cloud Controller"},
+				[]string{"dave", "uaa-only-guid", "UAA"},
+				[]string{"Use --delete-cc-only or --delete-uaa-only to delete these users"},
+			))
+			Expect(ui.Prompts).To(BeEmpty())
+			Expect(userRepo.DeleteCallCount()).To(BeZero())
+		})
+
+		It("deletes nothing with -f alone", func() {
+			Expect(runCommand("-f")).To(BeTrue())
+
+			Expect(userRepo.DeleteCallCount()).To(BeZero())
+		})
+
+		It("deletes only the UAA-only users with --delete-uaa-only once confirmed", func() {
+			Expect(runCommand("--delete-uaa-only")).To(BeTrue())
+
+			Expect(ui.Prompts).To(ContainSubstrings([]string{"Really delete these 1 users?"}))
+			Expect(userRepo.DeleteCallCount()).To(Equal(1))
+			Expect(userRepo.DeleteArgsForCall(0)).To(Equal("uaa-only-guid"))
+			Expect(ui.Outputs()).To(ContainSubstrings([]string{"OK"}))
+		})
+
+		It("deletes only the Cloud Controller-only users with --delete-cc-only", func() {
+			Expect(runCommand("--delete-cc-only", "-f")).To(BeTrue())
+
+			Expect(userRepo.DeleteCallCount()).To(Equal(1))
+			Expect(userRepo.DeleteArgsForCall(0)).To(Equal("cc-only-guid"))
+		})
+
+		It("deletes both sides when both flags are given", func() {
+			Expect(runCommand("--delete-cc-only", "--delete-uaa-only")).To(BeTrue())
+
+			Expect(ui.Prompts).To(ContainSubstrings([]string{"Really delete these 2 users?"}))
+			Expect(userRepo.DeleteCallCount()).To(Equal(2))
+			Expect(userRepo.DeleteArgsForCall(0)).To(Equal("cc-only-guid"))
+			Expect(userRepo.DeleteArgsForCall(1)).To(Equal("uaa-only-guid"))
+		})
+
+		It("deletes nothing when no confirmation is given", func() {
+			ui.Inputs = []string{"nope"}
+			Expect(runCommand("--delete-cc-only", "--delete-uaa-only")).To(BeTrue())
+
+			Expect(userRepo.DeleteCallCount()).To(BeZero())
+		})
+
+		It("deletes without confirmation when the -f flag is given", func() {
+			ui.Inputs = []string{}
+			Expect(runCommand("--delete-cc-only", "--delete-uaa-only", "-f")).To(BeTrue())
+
+			Expect(ui.Prompts).To(BeEmpty())
+			Expect(userRepo.DeleteCallCount()).To(Equal(2))
+		})
+
+		It("keeps going when a deletion fails and reports the failures", func() {
+			userRepo.DeleteStub = func(userGUID string) error {
+				if userGUID == "cc-only-guid" {
+					return errors.New("delete failed")
+				}
+				return nil
+			}
+
+			Expect(runCommand("--delete-cc-only", "--delete-uaa-only", "-f")).To(BeFalse())
+
+			Expect(userRepo.DeleteCallCount()).To(Equal(2))
+			Expect(ui.WarnOutputs).To(ContainSubstrings([]string{"delete failed"}))
+			Expect(ui.Outputs()).To(ContainSubstrings([]string{"1 of 2 users could not be deleted"}))
+		})
+	})
+
+	It("says so when every user is matched", func() {
+		Expect(runCommand()).To(BeTrue())
+
+		Expect(ui.Outputs()).To(ContainSubstrings([]string{"No unmatched users found"}))
+		Expect(userRepo.DeleteCallCount()).To(BeZero())
+	})
+})
@@ -2,12 +2,15 @@ package user
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"code.cloudfoundry.org/cli/cf"
 	"code.cloudfoundry.org/cli/cf/api"
 	"code.cloudfoundry.org/cli/cf/api/featureflags"
 	"code.cloudfoundry.org/cli/cf/commandregistry"
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/errors"
 	"code.cloudfoundry.org/cli/cf/flags"
 	. "code.cloudfoundry.org/cli/cf/i18n"
 	"code.cloudfoundry.org/cli/cf/models"
@@ -36,6 +39,12 @@ func init() {
 }
 
 func (cmd *SetOrgRole) MetaData() commandregistry.CommandMetadata {
+	fs := make(map[string]flags.FlagSet)
+	fs["dry-run"] = &flags.BoolFlag{Name: "dry-run", Usage: T("Print the requests that would be sent without changing any roles")}
+	fs["guid"] = &flags.BoolFlag{Name: "guid", Usage: T("Treat USERNAME as a user GUID and skip looking the user up")}
+	fs["by-email"] = &flags.BoolFlag{Name: "by-email", Usage: T("Find the user by email when no user has the given username")}
+	fs["interactive"] = &flags.BoolFlag{Name: "interactive", Usage: T("When no user has the given username, choose from the users whose names start with it")}
+
 	return commandregistry.CommandMetadata{
 		Name:        "set-org-role",
 		Description: T("Assign an org role to a user"),
@@ -46,6 +55,7 @@ func (cmd *SetOrgRole) MetaData() commandregistry.CommandMetadata {
 			fmt.Sprintf("   'BillingManager' - %s", T("Create and manage the billing account and payment info\n")),
 			fmt.Sprintf("   'OrgAuditor' - %s", T("Read-only access to org info and reports\n")),
 		},
+		Flags: fs,
 	}
 }
 
@@ -55,15 +65,23 @@ func (cmd *SetOrgRole) Requirements(requirementsFactory requirements.Factory, fc
 		return nil, fmt.Errorf("Incorrect usage: %d arguments of %d required", len(fc.Args()), 3)
 	}
 
-	var wantGUID bool
-	if cmd.config.IsMinAPIVersion(cf.SetRolesByUsernameMinimumAPIVersion) {
-		setRolesByUsernameFlag, err := cmd.flagRepo.FindByName("set_roles_by_username")
-		wantGUID = (err != nil || !setRolesByUsernameFlag.Enabled)
+	if fc.Bool("guid") {
+		cmd.userReq = requirementsFactory.NewUserGUIDRequirement(fc.Args()[0])
+	} else if fc.Bool("by-email") {
+		cmd.userReq = requirementsFactory.NewUserRequirementWithEmailFallback(fc.Args()[0])
+	} else if fc.Bool("interactive") {
+		cmd.userReq = requirementsFactory.NewUserRequirementWithPrefixMatch(fc.Args()[0], chooseUser(cmd.ui))
 	} else {
-		wantGUID = true
+		var wantGUID bool
+		if cmd.config.IsMinAPIVersion(cf.SetRolesByUsernameMinimumAPIVersion) {
+			setRolesByUsernameFlag, err := cmd.flagRepo.FindByName("set_roles_by_username")
+			wantGUID = (err != nil || !setRolesByUsernameFlag.Enabled)
+		} else {
+			wantGUID = true
+		}
+
+		cmd.userReq = requirementsFactory.NewUserRequirement(fc.Args()[0], wantGUID)
 	}
-
-	cmd.userReq = requirementsFactory.NewUserRequirement(fc.Args()[0], wantGUID)
 	cmd.orgReq = requirementsFactory.NewOrganizationRequirement(fc.Args()[1])
 
 	reqs := []requirements.Requirement{
@@ -100,6 +118,26 @@ func (cmd *SetOrgRole) Execute(c flags.FlagContext) error {
 			"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
 		}))
 
+	if cmd.hasOrgRole(user.GUID, org.GUID, role) {
+		cmd.ui.Say(T("User {{.TargetUser}} already has role {{.Role}} in org {{.TargetOrg}}; already assigned.",
+			map[string]interface{}{
+				"Role":       terminal.EntityNameColor(roleStr),
+				"TargetUser": terminal.EntityNameColor(user.Username),
+				"TargetOrg":  terminal.EntityNameColor(org.Name),
+			}))
+		cmd.ui.Ok()
+		return nil
+	}
+
+	if c.Bool("dry-run") {
+		previews, err := cmd.userRepo.PreviewOrgRoleChange(user.GUID, user.Username, org.GUID, role, true)
+		if err != nil {
+			return err
+		}
+		sayRequestPreviews(cmd.ui, previews)
+		return nil
+	}
+
 	err = cmd.SetOrgRole(org.GUID, role, user.GUID, user.Username)
 	if err != nil {
 		return err
@@ -109,6 +147,65 @@ func (cmd *SetOrgRole) Execute(c flags.FlagContext) error {
 	return nil
 }
 
+// sayRequestPreviews prints the requests a --dry-run would have sent.
+func sayRequestPreviews(ui terminal.UI, previews []models.RequestPreview) {
+	ui.Say(T("Dry run, no changes were made. The following requests would be sent:"))
+	for _, preview := range previews {
+		ui.Say("  %s %s", preview.Method, preview.URL)
+		if preview.Body != "" {
+			ui.Say("    %s", preview.Body)
+		}
+	}
+}
+
+// maxUserChoices is the most users --interactive will list to choose from.
+const maxUserChoices = 50
+
+// chooseUser returns a UserChooser that lists the matching users by number
+// and asks which one was meant. Any answer but one of the numbers chooses
+// none.
+func chooseUser(ui terminal.UI) requirements.UserChooser {
+	return func(username string, matches []models.UserFields) (models.UserFields, error) {
+		if len(matches) > maxUserChoices {
+			return models.UserFields{}, errors.NewInvalidInputError(T("User {{.Username}} not found, and {{.Count}} users have names starting with it. Give more of the name.",
+				map[string]interface{}{"Username": username, "Count": len(matches)}))
+		}
+
+		ui.Say(T("User {{.Username}} not found. Select one of these users (or press enter to cancel):",
+			map[string]interface{}{"Username": terminal.EntityNameColor(username)}))
+		for i, user := range matches {
+			ui.Say("%d. %s", i+1, user.Username)
+		}
+
+		answer := strings.TrimSpace(ui.Ask(T("User")))
+		index, err := strconv.Atoi(answer)
+		if err != nil || index < 1 || index > len(matches) {
+			return models.UserFields{}, errors.NewInvalidInputError(T("No user selected"))
+		}
+		return matches[index-1], nil
+	}
+}
+
+// hasOrgRole reports whether the user already holds role in the org, so
+// that re-running the command sends no writes. Users known only by name, and
+// lookups that fail, count as not holding it.
+func (cmd *SetOrgRole) hasOrgRole(userGUID, orgGUID string, role models.Role) bool {
+	if userGUID == "" {
+		return false
+	}
+
+	roles, err := cmd.userRepo.GetUserRolesInOrg(userGUID, orgGUID)
+	if err != nil {
+		return false
+	}
+	for _, held := range roles {
+		if held == role {
+			return true
+		}
+	}
+	return false
+}
+
 func (cmd *SetOrgRole) SetOrgRole(orgGUID string, role models.Role, userGUID, userName string) error {
 	if len(userGUID) > 0 {
 		return cmd.userRepo.SetOrgRoleByGUID(userGUID, orgGUID, role)
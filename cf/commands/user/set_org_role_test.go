@@ -6,6 +6,7 @@ import (
 	"code.cloudfoundry.org/cli/cf/commandregistry"
 	"code.cloudfoundry.org/cli/cf/commands/user"
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	cferrors "code.cloudfoundry.org/cli/cf/errors"
 	"code.cloudfoundry.org/cli/cf/flags"
 	"code.cloudfoundry.org/cli/cf/models"
 	"code.cloudfoundry.org/cli/cf/requirements"
@@ -190,6 +191,95 @@ var _ = Describe("SetOrgRole", func() {
 					Expect(actualRequirements).To(ContainElement(userRequirement))
 				})
 			})
+
+			Context("when --by-email is given", func() {
+				BeforeEach(func() {
+					configRepo.SetAPIVersion("2.37.0")
+					flagContext = flags.NewFlagContext(cmd.MetaData().Flags)
+					flagContext.Parse("the-user-name", "the-org-name", "OrgManager", "--by-email")
+					factory.NewUserRequirementWithEmailFallbackReturns(userRequirement)
+				})
+
+				It("returns a UserRequirement that falls back to the user's email", func() {
+					actualRequirements, err := cmd.Requirements(factory, flagContext)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(factory.NewUserRequirementCallCount()).To(BeZero())
+					Expect(factory.NewUserRequirementWithEmailFallbackCallCount()).To(Equal(1))
+					Expect(factory.NewUserRequirementWithEmailFallbackArgsForCall(0)).To(Equal("the-user-name"))
+					Expect(flagRepo.FindByNameCallCount()).To(BeZero())
+
+					Expect(actualRequirements).To(ContainElement(userRequirement))
+				})
+			})
+
+			Context("when --guid is given", func() {
+				BeforeEach(func() {
+					configRepo.SetAPIVersion("2.37.0")
+					flagContext = flags.NewFlagContext(cmd.MetaData().Flags)
+					flagContext.Parse("the-user-name", "the-org-name", "OrgManager", "--guid")
+					factory.NewUserGUIDRequirementReturns(userRequirement)
+				})
+
+				It("returns a UserRequirement for the GUID without looking the user up", func() {
+					actualRequirements, err := cmd.Requirements(factory, flagContext)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(factory.NewUserGUIDRequirementCallCount()).To(Equal(1))
+					Expect(factory.NewUserGUIDRequirementArgsForCall(0)).To(Equal("the-user-name"))
+					Expect(factory.NewUserRequirementCallCount()).To(BeZero())
+					Expect(flagRepo.FindByNameCallCount()).To(BeZero())
+
+					Expect(actualRequirements).To(ContainElement(userRequirement))
+				})
+			})
+
+			Context("when --interactive is given", func() {
+				var matches []models.UserFields
+
+				BeforeEach(func() {
+					configRepo.SetAPIVersion("2.37.0")
+					flagContext = flags.NewFlagContext(cmd.MetaData().Flags)
+					flagContext.Parse("the-user", "the-org-name", "OrgManager", "--interactive")
+					factory.NewUserRequirementWithPrefixMatchReturns(userRequirement)
+					matches = []models.UserFields{{Username: "the-user-1", GUID: "guid-1"}, {Username: "the-user-2", GUID: "guid-2"}}
+				})
+
+				It("returns a UserRequirement that can choose from similar names", func() {
+					actualRequirements, err := cmd.Requirements(factory, flagContext)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(factory.NewUserRequirementCallCount()).To(BeZero())
+					Expect(factory.NewUserRequirementWithPrefixMatchCallCount()).To(Equal(1))
+					username, _ := factory.NewUserRequirementWithPrefixMatchArgsForCall(0)
+					Expect(username).To(Equal("the-user"))
+
+					Expect(actualRequirements).To(ContainElement(userRequirement))
+				})
+
+				It("asks which of the matching users was meant", func() {
+					_, err := cmd.Requirements(factory, flagContext)
+					Expect(err).NotTo(HaveOccurred())
+					_, choose := factory.NewUserRequirementWithPrefixMatchArgsForCall(0)
+
+					ui.Inputs = []string{"2"}
+					chosen, err := choose("the-user", matches)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(chosen).To(Equal(matches[1]))
+					Expect(ui.Outputs()).To(ContainSubstrings(
+						[]string{"User", "the-user", "not found"},
+						[]string{"1. the-user-1"},
+						[]string{"2. the-user-2"},
+					))
+				})
+
+				It("chooses nobody when the answer is not one of the numbers", func() {
+					_, err := cmd.Requirements(factory, flagContext)
+					Expect(err).NotTo(HaveOccurred())
+					_, choose := factory.NewUserRequirementWithPrefixMatchArgsForCall(0)
+
+					ui.Inputs = []string{"3"}
+					_, err = choose("the-user", matches)
+					Expect(err).To(MatchError("No user selected"))
+				})
+			})
 		})
 	})
 
@@ -233,6 +323,46 @@ var _ = Describe("SetOrgRole", func() {
 				Expect(actualRole).To(Equal(models.RoleOrgManager))
 			})
 
+			Context("when the user already has the role", func() {
+				BeforeEach(func() {
+					userRepo.GetUserRolesInOrgReturns([]models.Role{models.RoleOrgAuditor, models.RoleOrgManager}, nil)
+				})
+
+				It("says so without setting the role", func() {
+					Expect(err).NotTo(HaveOccurred())
+					userGUID, orgGUID := userRepo.GetUserRolesInOrgArgsForCall(0)
+					Expect(userGUID).To(Equal("the-user-guid"))
+					Expect(orgGUID).To(Equal("the-org-guid"))
+					Expect(userRepo.SetOrgRoleByGUIDCallCount()).To(BeZero())
+					Expect(ui.Outputs()).To(ContainSubstrings(
+						[]string{"the-user-name", "already has role", "OrgManager", "already assigned"},
+						[]string{"OK"},
+					))
+				})
+			})
+
+			Context("when the user has other roles in the org", func() {
+				BeforeEach(func() {
+					userRepo.GetUserRolesInOrgReturns([]models.Role{models.RoleOrgAuditor}, nil)
+				})
+
+				It("sets the role", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(userRepo.SetOrgRoleByGUIDCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when the user's roles cannot be looked up", func() {
+				BeforeEach(func() {
+					userRepo.GetUserRolesInOrgReturns(nil, errors.New("forbidden"))
+				})
+
+				It("sets the role anyway", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(userRepo.SetOrgRoleByGUIDCallCount()).To(Equal(1))
+				})
+			})
+
 			Context("when the call to CC fails", func() {
 				BeforeEach(func() {
 					userRepo.SetOrgRoleByGUIDReturns(errors.New("user-repo-error"))
@@ -245,11 +375,68 @@ var _ = Describe("SetOrgRole", func() {
 			})
 		})
 
+		Context("when the role is not valid", func() {
+			BeforeEach(func() {
+				flagContext = flags.NewFlagContext(cmd.MetaData().Flags)
+				flagContext.Parse("the-user-name", "the-org-name", "OrgJanitor")
+			})
+
+			It("returns an error that exits with the user input code", func() {
+				Expect(err).To(MatchError("Unknown Role"))
+				Expect(cferrors.ExitCode(err)).To(Equal(cferrors.ExitCodeUserInput))
+				Expect(userRepo.SetOrgRoleByGUIDCallCount()).To(Equal(0))
+				Expect(userRepo.SetOrgRoleByUsernameCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the Cloud Controller cannot be reached", func() {
+			BeforeEach(func() {
+				userRequirement.GetUserReturns(models.UserFields{GUID: "the-user-guid", Username: "the-user-name"})
+				userRepo.SetOrgRoleByGUIDReturns(cferrors.NewNetworkError("Error performing request: connection refused"))
+			})
+
+			It("returns an error that exits with the backend code", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(cferrors.ExitCode(err)).To(Equal(cferrors.ExitCodeBackend))
+			})
+		})
+
+		Context("when --dry-run is given", func() {
+			BeforeEach(func() {
+				flagContext = flags.NewFlagContext(cmd.MetaData().Flags)
+				flagContext.Parse("the-user-name", "the-org-name", "OrgManager", "--dry-run")
+				userRequirement.GetUserReturns(models.UserFields{GUID: "the-user-guid", Username: "the-user-name"})
+				userRepo.PreviewOrgRoleChangeReturns([]models.RequestPreview{
+					{Method: "PUT", URL: "https://api.example.com/v2/organizations/the-org-guid/managers/the-user-guid"},
+				}, nil)
+			})
+
+			It("prints the requests without setting the role", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(userRepo.SetOrgRoleByGUIDCallCount()).To(BeZero())
+				userGUID, username, orgGUID, role, set := userRepo.PreviewOrgRoleChangeArgsForCall(0)
+				Expect(userGUID).To(Equal("the-user-guid"))
+				Expect(username).To(Equal("the-user-name"))
+				Expect(orgGUID).To(Equal("the-org-guid"))
+				Expect(role).To(Equal(models.RoleOrgManager))
+				Expect(set).To(BeTrue())
+				Expect(ui.Outputs()).To(ContainSubstrings(
+					[]string{"Dry run"},
+					[]string{"PUT https://api.example.com/v2/organizations/the-org-guid/managers/the-user-guid"},
+				))
+				Expect(ui.Outputs()).NotTo(ContainSubstrings([]string{"OK"}))
+			})
+		})
+
 		Context("when the UserRequirement returns a user without a GUID", func() {
 			BeforeEach(func() {
 				userRequirement.GetUserReturns(models.UserFields{Username: "the-user-name"})
 			})
 
+			It("does not look up the user's existing roles", func() {
+				Expect(userRepo.GetUserRolesInOrgCallCount()).To(BeZero())
+			})
+
 			It("sets the role using the given username", func() {
 				Expect(err).NotTo(HaveOccurred())
 				username, orgGUID, role := userRepo.SetOrgRoleByUsernameArgsForCall(0)
@@ -0,0 +1,197 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+
+	"code.cloudfoundry.org/cli/cf"
+	"code.cloudfoundry.org/cli/cf/api"
+	"code.cloudfoundry.org/cli/cf/api/featureflags"
+	"code.cloudfoundry.org/cli/cf/api/spaces"
+	"code.cloudfoundry.org/cli/cf/commandregistry"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/flags"
+	. "code.cloudfoundry.org/cli/cf/i18n"
+	"code.cloudfoundry.org/cli/cf/models"
+	"code.cloudfoundry.org/cli/cf/requirements"
+	"code.cloudfoundry.org/cli/cf/terminal"
+)
+
+type SetRoleForUsers struct {
+	ui        terminal.UI
+	config    coreconfig.Reader
+	spaceRepo spaces.SpaceRepository
+	flagRepo  featureflags.FeatureFlagRepository
+	userRepo  api.UserRepository
+	orgReq    requirements.OrganizationRequirement
+}
+
+func init() {
+	commandregistry.Register(&SetRoleForUsers{})
+}
+
+func (cmd *SetRoleForUsers) MetaData() commandregistry.CommandMetadata {
+	fs := make(map[string]flags.FlagSet)
+	fs["s"] = &flags.StringFlag{ShortName: "s", Usage: T("Assign a space role in the specified space of the org")}
+	fs["continue-on-error"] = &flags.BoolFlag{Name: "continue-on-error", Usage: T("Keep assigning the role to the remaining users after one fails")}
+
+	return commandregistry.CommandMetadata{
+		Name:        "set-role-for-users",
+		Description: T("Assign an org or space role to several users"),
+		Usage: []string{
+			T("CF_NAME set-role-for-users ORG ROLE USERNAME... [-s SPACE] [--continue-on-error]\n\n"),
+			T("   ROLE is an org role as taken by set-org-role or, with -s, a space role as taken by set-space-role.\n"),
+			T("   Users are assigned the role in turn, stopping at the first that fails unless --continue-on-error is given."),
+		},
+		Flags: fs,
+	}
+}
+
+func (cmd *SetRoleForUsers) Requirements(requirementsFactory requirements.Factory, fc flags.FlagContext) ([]requirements.Requirement, error) {
+	if len(fc.Args()) < 3 {
+		cmd.ui.Failed(T("Incorrect Usage. Requires ORG, ROLE and at least one USERNAME as arguments\n\n") + commandregistry.Commands.CommandUsage("set-role-for-users"))
+		return nil, fmt.Errorf("Incorrect usage: %d arguments of at least %d required", len(fc.Args()), 3)
+	}
+
+	cmd.orgReq = requirementsFactory.NewOrganizationRequirement(fc.Args()[0])
+
+	reqs := []requirements.Requirement{
+		requirementsFactory.NewLoginRequirement(),
+		cmd.orgReq,
+	}
+
+	return reqs, nil
+}
+
+func (cmd *SetRoleForUsers) SetDependency(deps commandregistry.Dependency, pluginCall bool) commandregistry.Command {
+	cmd.ui = deps.UI
+	cmd.config = deps.Config
+	cmd.spaceRepo = deps.RepoLocator.GetSpaceRepository()
+	cmd.userRepo = deps.RepoLocator.GetUserRepository()
+	cmd.flagRepo = deps.RepoLocator.GetFeatureFlagRepository()
+	return cmd
+}
+
+func (cmd *SetRoleForUsers) Execute(c flags.FlagContext) error {
+	org := cmd.orgReq.GetOrganization()
+	roleName := c.Args()[1]
+	usernames := c.Args()[2:]
+	spaceName := c.String("s")
+
+	role, err := models.RoleFromString(roleName)
+	if err != nil {
+		return err
+	}
+	if isSpaceRole(role) != (spaceName != "") {
+		if spaceName == "" {
+			return errors.New(T("{{.Role}} is a space role; give the space with -s", map[string]interface{}{"Role": roleName}))
+		}
+		return errors.New(T("{{.Role}} is an org role and cannot be assigned in a space", map[string]interface{}{"Role": roleName}))
+	}
+
+	var space models.Space
+	if spaceName != "" {
+		space, err = cmd.spaceRepo.FindByNameInOrg(spaceName, org.GUID)
+		if err != nil {
+			return err
+		}
+		cmd.ui.Say(T("Assigning role {{.Role}} to {{.Count}} users in org {{.TargetOrg}} / space {{.TargetSpace}} as {{.CurrentUser}}...",
+			map[string]interface{}{
+				"Role":        terminal.EntityNameColor(roleName),
+				"Count":       len(usernames),
+				"TargetOrg":   terminal.EntityNameColor(org.Name),
+				"TargetSpace": terminal.EntityNameColor(space.Name),
+				"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
+			}))
+	} else {
+		cmd.ui.Say(T("Assigning role {{.Role}} to {{.Count}} users in org {{.TargetOrg}} as {{.CurrentUser}}...",
+			map[string]interface{}{
+				"Role":        terminal.EntityNameColor(roleName),
+				"Count":       len(usernames),
+				"TargetOrg":   terminal.EntityNameColor(org.Name),
+				"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
+			}))
+	}
+
+	byUsername := cmd.canSetRolesByUsername()
+	results := make([]models.RoleChangeResult, 0, len(usernames))
+	failed := 0
+	for _, username := range usernames {
+		result := cmd.setRole(username, byUsername, org.GUID, space.GUID, role)
+		results = append(results, result)
+		if result.Err != nil {
+			failed++
+			if !c.Bool("continue-on-error") {
+				break
+			}
+		}
+	}
+
+	table := cmd.ui.Table([]string{T("username"), T("result")})
+	for i, username := range usernames {
+		status := T("not attempted")
+		if i < len(results) {
+			status = T("assigned")
+			if results[i].Err != nil {
+				status = T("failed: {{.Error}}", map[string]interface{}{"Error": results[i].Err.Error()})
+			}
+		}
+		table.Add(username, status)
+	}
+	cmd.ui.Say("")
+	err = table.Print()
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return errors.New(T("{{.Failed}} of {{.Total}} users could not be assigned the role.",
+			map[string]interface{}{"Failed": failed, "Total": len(usernames)}))
+	}
+
+	cmd.ui.Ok()
+	return nil
+}
+
+// canSetRolesByUsername reports whether the Cloud Controller takes usernames
+// in role requests, so that users need not be looked up in UAA first.
+func (cmd *SetRoleForUsers) canSetRolesByUsername() bool {
+	if !cmd.config.IsMinAPIVersion(cf.SetRolesByUsernameMinimumAPIVersion) {
+		return false
+	}
+	setRolesByUsernameFlag, err := cmd.flagRepo.FindByName("set_roles_by_username")
+	return err == nil && setRolesByUsernameFlag.Enabled
+}
+
+// setRole gives one user role in the org or, when spaceGUID is set, in that
+// space, recording any failure in the result.
+func (cmd *SetRoleForUsers) setRole(username string, byUsername bool, orgGUID, spaceGUID string, role models.Role) models.RoleChangeResult {
+	result := models.RoleChangeResult{Role: role}
+
+	switch {
+	case byUsername && spaceGUID != "":
+		result.Err = cmd.userRepo.SetSpaceRoleByUsername(username, spaceGUID, orgGUID, role)
+	case byUsername:
+		result.Err = cmd.userRepo.SetOrgRoleByUsername(username, orgGUID, role)
+	default:
+		user, err := cmd.userRepo.FindByUsername(username)
+		if err != nil {
+			result.Err = err
+			break
+		}
+		result.UserGUID = user.GUID
+		if spaceGUID != "" {
+			result.Err = cmd.userRepo.SetSpaceRoleByGUID(user.GUID, spaceGUID, orgGUID, role)
+		} else {
+			result.Err = cmd.userRepo.SetOrgRoleByGUID(user.GUID, orgGUID, role)
+		}
+	}
+
+	result.Changed = result.Err == nil
+	return result
+}
+
+// isSpaceRole reports whether role is held in a space rather than an org.
+func isSpaceRole(role models.Role) bool {
+	return role == models.RoleSpaceManager || role == models.RoleSpaceDeveloper || role == models.RoleSpaceAuditor
+}
@@ -0,0 +1,175 @@
+package user_test
+
+import (
+	"code.cloudfoundry.org/cli/cf/api/apifakes"
+	"code.cloudfoundry.org/cli/cf/api/featureflags/featureflagsfakes"
+	"code.cloudfoundry.org/cli/cf/api/spaces/spacesfakes"
+	"code.cloudfoundry.org/cli/cf/commandregistry"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/errors"
+	"code.cloudfoundry.org/cli/cf/models"
+	"code.cloudfoundry.org/cli/cf/requirements"
+	"code.cloudfoundry.org/cli/cf/requirements/requirementsfakes"
+	testcmd "code.cloudfoundry.org/cli/util/testhelpers/commands"
+	testconfig "code.cloudfoundry.org/cli/util/testhelpers/configuration"
+	testterm "code.cloudfoundry.org/cli/util/testhelpers/terminal"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "code.cloudfoundry.org/cli/util/testhelpers/matchers"
+)
+
+var _ = Describe("set-role-for-users command", func() {
+	var (
+		ui                  *testterm.FakeUI
+		configRepo          coreconfig.Repository
+		userRepo            *apifakes.FakeUserRepository
+		spaceRepo           *spacesfakes.FakeSpaceRepository
+		flagRepo            *featureflagsfakes.FakeFeatureFlagRepository
+		requirementsFactory *requirementsfakes.FakeFactory
+		deps                commandregistry.Dependency
+	)
+
+	updateCommandDependency := func(pluginCall bool) {
+		deps.UI = ui
+		deps.Config = configRepo
+		deps.RepoLocator = deps.RepoLocator.SetUserRepository(userRepo)
+		deps.RepoLocator = deps.RepoLocator.SetSpaceRepository(spaceRepo)
+		deps.RepoLocator = deps.RepoLocator.SetFeatureFlagRepository(flagRepo)
+		commandregistry.Commands.SetCommand(commandregistry.Commands.FindCommand("set-role-for-users").SetDependency(deps, pluginCall))
+	}
+
+	BeforeEach(func() {
+		ui = &testterm.FakeUI{}
+		configRepo = testconfig.NewRepositoryWithDefaults()
+		configRepo.SetAPIVersion("2.36.0")
+		userRepo = new(apifakes.FakeUserRepository)
+		userRepo.FindByUsernameStub = func(username string) (models.UserFields, error) {
+			return models.UserFields{GUID: username + "-guid", Username: username}, nil
+		}
+		spaceRepo = new(spacesfakes.FakeSpaceRepository)
+		flagRepo = new(featureflagsfakes.FakeFeatureFlagRepository)
+
+		requirementsFactory = new(requirementsfakes.FakeFactory)
+		requirementsFactory.NewLoginRequirementReturns(requirements.Passing{})
+		orgRequirement := new(requirementsfakes.FakeOrganizationRequirement)
+		orgRequirement.GetOrganizationReturns(models.Organization{
+			OrganizationFields: models.OrganizationFields{GUID: "org-guid", Name: "my-org"},
+		})
+		requirementsFactory.NewOrganizationRequirementReturns(orgRequirement)
+	})
+
+	runCommand := func(args ...string) bool {
+		return testcmd.RunCLICommand("set-role-for-users", args, requirementsFactory, updateCommandDependency, false, ui)
+	}
+
+	Describe("requirements", func() {
+		It("fails with usage when not given a username", func() {
+			runCommand("my-org", "OrgManager")
+			Expect(ui.Outputs()).To(ContainSubstrings([]string{"Incorrect Usage", "at least one USERNAME"}))
+		})
+
+		It("fails when not logged in", func() {
+			requirementsFactory.NewLoginRequirementReturns(requirements.Failing{Message: "not logged in"})
+
+			Expect(runCommand("my-org", "OrgManager", "alice")).To(BeFalse())
+		})
+	})
+
+	It("assigns an org role to every user and shows each result", func() {
+		Expect(runCommand("my-org", "OrgManager", "alice", "bob")).To(BeTrue())
+
+		Expect(userRepo.SetOrgRoleByGUIDCallCount()).To(Equal(2))
+		userGUID, orgGUID, role := userRepo.SetOrgRoleByGUIDArgsForCall(1)
+		Expect(userGUID).To(Equal("bob-guid"))
+		Expect(orgGUID).To(Equal("org-guid"))
+		Expect(role).To(Equal(models.RoleOrgManager))
+		Expect(ui.Outputs()).To(ContainSubstrings(
+			[]string{"Assigning role", "OrgManager", "2 users", "my-org"},
+			[]string{"alice", "assigned"},
+			[]string{"bob", "assigned"},
+			[]string{"OK"},
+		))
+	})
+
+	It("assigns by username when the Cloud Controller allows it", func() {
+		configRepo.SetAPIVersion("2.37.0")
+		flagRepo.FindByNameReturns(models.FeatureFlag{Enabled: true}, nil)
+
+		Expect(runCommand("my-org", "OrgAuditor", "alice")).To(BeTrue())
+
+		Expect(userRepo.FindByUsernameCallCount()).To(BeZero())
+		username, _, _ := userRepo.SetOrgRoleByUsernameArgsForCall(0)
+		Expect(username).To(Equal("alice"))
+	})
+
+	It("assigns a space role in the space given with -s", func() {
+		spaceRepo.FindByNameInOrgReturns(models.Space{SpaceFields: models.SpaceFields{GUID: "space-guid", Name: "my-space"}}, nil)
+
+		Expect(runCommand("my-org", "SpaceDeveloper", "alice", "-s", "my-space")).To(BeTrue())
+
+		spaceName, orgGUID := spaceRepo.FindByNameInOrgArgsForCall(0)
+		Expect(spaceName).To(Equal("my-space"))
+		Expect(orgGUID).To(Equal("org-guid"))
+		userGUID, spaceGUID, _, role := userRepo.SetSpaceRoleByGUIDArgsForCall(0)
+		Expect(userGUID).To(Equal("alice-guid"))
+		Expect(spaceGUID).To(Equal("space-guid"))
+		Expect(role).To(Equal(models.RoleSpaceDeveloper))
+	})
+
+	It("refuses a space role without a space and an org role with one", func() {
+		Expect(runCommand("my-org", "SpaceDeveloper", "alice")).To(BeFalse())
+		Expect(ui.Outputs()).To(ContainSubstrings([]string{"is a space role"}))
+
+		Expect(runCommand("my-org", "OrgManager", "alice", "-s", "my-space")).To(BeFalse())
+		Expect(ui.Outputs()).To(ContainSubstrings([]string{"is an org role"}))
+
+		Expect(userRepo.SetOrgRoleByGUIDCallCount()).To(BeZero())
+		Expect(userRepo.SetSpaceRoleByGUIDCallCount()).To(BeZero())
+	})
+
+	Context("when assigning the role to a user fails", func() {
+		BeforeEach(func() {
+			userRepo.SetOrgRoleByGUIDStub = func(userGUID, orgGUID string, role models.Role) error {
+				if userGUID == "bob-guid" {
+					return errors.New("bob is broken")
+				}
+				return nil
+			}
+		})
+
+		It("stops at the failure by default", func() {
+			Expect(runCommand("my-org", "OrgManager", "alice", "bob", "carol")).To(BeFalse())
+
+			Expect(userRepo.SetOrgRoleByGUIDCallCount()).To(Equal(2))
+			Expect(ui.Outputs()).To(ContainSubstrings(
+				[]string{"alice", "assigned"},
+				[]string{"bob", "failed: bob is broken"},
+				[]string{"carol", "not attempted"},
+				[]string{"1 of 3 users could not be assigned the role."},
+			))
+		})
+
+		It("assigns the role to the remaining users with --continue-on-error", func() {
+			Expect(runCommand("my-org", "OrgManager", "alice", "bob", "carol", "--continue-on-error")).To(BeFalse())
+
+			Expect(userRepo.SetOrgRoleByGUIDCallCount()).To(Equal(3))
+			Expect(ui.Outputs()).To(ContainSubstrings(
+				[]string{"bob", "failed: bob is broken"},
+				[]string{"carol", "assigned"},
+				[]string{"1 of 3 users could not be assigned the role."},
+			))
+		})
+
+		It("counts a user that cannot be found as a failure", func() {
+			userRepo.FindByUsernameStub = func(username string) (models.UserFields, error) {
+				return models.UserFields{}, errors.NewModelNotFoundError("User", username)
+			}
+
+			Expect(runCommand("my-org", "OrgManager", "nobody", "--continue-on-error")).To(BeFalse())
+
+			Expect(userRepo.SetOrgRoleByGUIDCallCount()).To(BeZero())
+			Expect(ui.Outputs()).To(ContainSubstrings([]string{"nobody", "failed:", "not found"}))
+		})
+	})
+})
@@ -1,7 +1,9 @@
 package user
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"code.cloudfoundry.org/cli/cf/flags"
 	. "code.cloudfoundry.org/cli/cf/i18n"
@@ -39,16 +41,22 @@ func init() {
 }
 
 func (cmd *SetSpaceRole) MetaData() commandregistry.CommandMetadata {
+	fs := make(map[string]flags.FlagSet)
+	fs["dry-run"] = &flags.BoolFlag{Name: "dry-run", Usage: T("Print the requests that would be sent without changing any roles")}
+	fs["by-email"] = &flags.BoolFlag{Name: "by-email", Usage: T("Find the user by email when no user has the given username")}
+	fs["interactive"] = &flags.BoolFlag{Name: "interactive", Usage: T("When no user has the given username, choose from the users whose names start with it")}
+
 	return commandregistry.CommandMetadata{
 		Name:        "set-space-role",
 		Description: T("Assign a space role to a user"),
 		Usage: []string{
-			T("CF_NAME set-space-role USERNAME ORG SPACE ROLE\n\n"),
+			T("CF_NAME set-space-role USERNAME ORG SPACE ROLE[,ROLE...]\n\n"),
 			T("ROLES:\n"),
 			fmt.Sprintf("   'SpaceManager' - %s", T("Invite and manage users, and enable features for a given space\n")),
 			fmt.Sprintf("   'SpaceDeveloper' - %s", T("Create and manage apps and services, and see logs and reports\n")),
 			fmt.Sprintf("   'SpaceAuditor' - %s", T("View logs, reports, and settings on this space\n")),
 		},
+		Flags: fs,
 	}
 }
 
@@ -58,15 +66,21 @@ func (cmd *SetSpaceRole) Requirements(requirementsFactory requirements.Factory,
 		return nil, fmt.Errorf("Incorrect usage: %d arguments of %d required", len(fc.Args()), 4)
 	}
 
-	var wantGUID bool
-	if cmd.config.IsMinAPIVersion(cf.SetRolesByUsernameMinimumAPIVersion) {
-		setRolesByUsernameFlag, err := cmd.flagRepo.FindByName("set_roles_by_username")
-		wantGUID = (err != nil || !setRolesByUsernameFlag.Enabled)
+	if fc.Bool("by-email") {
+		cmd.userReq = requirementsFactory.NewUserRequirementWithEmailFallback(fc.Args()[0])
+	} else if fc.Bool("interactive") {
+		cmd.userReq = requirementsFactory.NewUserRequirementWithPrefixMatch(fc.Args()[0], chooseUser(cmd.ui))
 	} else {
-		wantGUID = true
+		var wantGUID bool
+		if cmd.config.IsMinAPIVersion(cf.SetRolesByUsernameMinimumAPIVersion) {
+			setRolesByUsernameFlag, err := cmd.flagRepo.FindByName("set_roles_by_username")
+			wantGUID = (err != nil || !setRolesByUsernameFlag.Enabled)
+		} else {
+			wantGUID = true
+		}
+
+		cmd.userReq = requirementsFactory.NewUserRequirement(fc.Args()[0], wantGUID)
 	}
-
-	cmd.userReq = requirementsFactory.NewUserRequirement(fc.Args()[0], wantGUID)
 	cmd.orgReq = requirementsFactory.NewOrganizationRequirement(fc.Args()[1])
 
 	reqs := []requirements.Requirement{
@@ -89,8 +103,7 @@ func (cmd *SetSpaceRole) SetDependency(deps commandregistry.Dependency, pluginCa
 
 func (cmd *SetSpaceRole) Execute(c flags.FlagContext) error {
 	spaceName := c.Args()[2]
-	roleStr := c.Args()[3]
-	role, err := models.RoleFromString(roleStr)
+	roleNames, roles, err := parseRoles(c.Args()[3])
 	if err != nil {
 		return err
 	}
@@ -103,24 +116,73 @@ func (cmd *SetSpaceRole) Execute(c flags.FlagContext) error {
 		return err
 	}
 
-	err = cmd.SetSpaceRole(space, org.GUID, org.Name, role, userFields.GUID, userFields.Username)
+	if c.Bool("dry-run") {
+		cmd.sayAssigning(space, org.Name, displayRoles(roleNames, roles), userFields.Username)
+		var previews []models.RequestPreview
+		for _, role := range roles {
+			rolePreviews, err := cmd.userRepo.PreviewSpaceRoleChange(userFields.GUID, userFields.Username, space.GUID, org.GUID, role, true)
+			if err != nil {
+				return err
+			}
+			// Only the first role needs the request adding the user to the org.
+			if len(previews) > 0 {
+				rolePreviews = rolePreviews[len(rolePreviews)-1:]
+			}
+			previews = append(previews, rolePreviews...)
+		}
+		sayRequestPreviews(cmd.ui, previews)
+		return nil
+	}
+
+	if len(roles) == 1 {
+		return cmd.SetSpaceRole(space, org.GUID, org.Name, roles[0], userFields.GUID, userFields.Username)
+	}
+	return cmd.setSpaceRoles(space, org.GUID, org.Name, roleNames, roles, userFields.GUID, userFields.Username)
+}
+
+// setSpaceRoles gives the user every one of roles, carrying on past any that
+// fail and then reporting which roles were and were not assigned.
+func (cmd *SetSpaceRole) setSpaceRoles(space models.Space, orgGUID, orgName string, roleNames []string, roles []models.Role, userGUID, username string) error {
+	cmd.sayAssigning(space, orgName, displayRoles(roleNames, roles), username)
+
+	var results []models.RoleChangeResult
+	var err error
+	if len(userGUID) > 0 {
+		results, err = cmd.userRepo.SetSpaceRolesByGUID(userGUID, space.GUID, orgGUID, roles)
+	} else {
+		results, err = cmd.userRepo.SetSpaceRolesByUsername(username, space.GUID, orgGUID, roles)
+	}
 	if err != nil {
 		return err
 	}
+
+	var assigned, failures []string
+	for i, result := range results {
+		if result.Err != nil {
+			failures = append(failures, fmt.Sprintf("  %s: %s", roleNames[i], result.Err.Error()))
+			continue
+		}
+		assigned = append(assigned, roleNames[i])
+	}
+	if len(failures) > 0 {
+		if len(assigned) == 0 {
+			assigned = []string{T("none")}
+		}
+		return errors.New(T("Assigned roles: {{.Assigned}}\nFailed to assign roles:\n{{.Failures}}",
+			map[string]interface{}{
+				"Assigned": strings.Join(assigned, ", "),
+				"Failures": strings.Join(failures, "\n"),
+			}))
+	}
+
+	cmd.ui.Ok()
 	return nil
 }
 
 func (cmd *SetSpaceRole) SetSpaceRole(space models.Space, orgGUID, orgName string, role models.Role, userGUID, username string) error {
 	var err error
 
-	cmd.ui.Say(T("Assigning role {{.Role}} to user {{.TargetUser}} in org {{.TargetOrg}} / space {{.TargetSpace}} as {{.CurrentUser}}...",
-		map[string]interface{}{
-			"Role":        terminal.EntityNameColor(role.ToString()),
-			"TargetUser":  terminal.EntityNameColor(username),
-			"TargetOrg":   terminal.EntityNameColor(orgName),
-			"TargetSpace": terminal.EntityNameColor(space.Name),
-			"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
-		}))
+	cmd.sayAssigning(space, orgName, role.ToString(), username)
 
 	if len(userGUID) > 0 {
 		err = cmd.userRepo.SetSpaceRoleByGUID(userGUID, space.GUID, orgGUID, role)
@@ -134,3 +196,45 @@ func (cmd *SetSpaceRole) SetSpaceRole(space models.Space, orgGUID, orgName strin
 	cmd.ui.Ok()
 	return nil
 }
+
+func (cmd *SetSpaceRole) sayAssigning(space models.Space, orgName string, role string, username string) {
+	cmd.ui.Say(T("Assigning role {{.Role}} to user {{.TargetUser}} in org {{.TargetOrg}} / space {{.TargetSpace}} as {{.CurrentUser}}...",
+		map[string]interface{}{
+			"Role":        terminal.EntityNameColor(role),
+			"TargetUser":  terminal.EntityNameColor(username),
+			"TargetOrg":   terminal.EntityNameColor(orgName),
+			"TargetSpace": terminal.EntityNameColor(space.Name),
+			"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
+		}))
+}
+
+// parseRoles splits a comma-separated list of role names, dropping repeats.
+// It returns the names as given alongside the roles they name.
+func parseRoles(roleStr string) ([]string, []models.Role, error) {
+	var names []string
+	var roles []models.Role
+	seen := map[models.Role]bool{}
+	for _, name := range strings.Split(roleStr, ",") {
+		name = strings.TrimSpace(name)
+		role, err := models.RoleFromString(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if seen[role] {
+			continue
+		}
+		seen[role] = true
+		names = append(names, name)
+		roles = append(roles, role)
+	}
+	return names, roles, nil
+}
+
+// displayRoles describes roles for the "Assigning role" message, keeping the
+// single role wording unchanged.
+func displayRoles(roleNames []string, roles []models.Role) string {
+	if len(roles) == 1 {
+		return roles[0].ToString()
+	}
+	return strings.Join(roleNames, ", ")
+}
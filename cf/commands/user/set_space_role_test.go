@@ -193,6 +193,26 @@ var _ = Describe("SetSpaceRole", func() {
 					Expect(actualRequirements).To(ContainElement(userRequirement))
 				})
 			})
+
+			Context("when --by-email is given", func() {
+				BeforeEach(func() {
+					configRepo.SetAPIVersion("2.37.0")
+					flagContext = flags.NewFlagContext(cmd.MetaData().Flags)
+					flagContext.Parse("the-user-name", "the-org-name", "the-space-name", "SpaceManager", "--by-email")
+					factory.NewUserRequirementWithEmailFallbackReturns(userRequirement)
+				})
+
+				It("returns a UserRequirement that falls back to the user's email", func() {
+					actualRequirements, err := cmd.Requirements(factory, flagContext)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(factory.NewUserRequirementCallCount()).To(BeZero())
+					Expect(factory.NewUserRequirementWithEmailFallbackCallCount()).To(Equal(1))
+					Expect(factory.NewUserRequirementWithEmailFallbackArgsForCall(0)).To(Equal("the-user-name"))
+					Expect(flagRepo.FindByNameCallCount()).To(BeZero())
+
+					Expect(actualRequirements).To(ContainElement(userRequirement))
+				})
+			})
 		})
 	})
 
@@ -240,6 +260,32 @@ var _ = Describe("SetSpaceRole", func() {
 				spaceRepo.FindByNameInOrgReturns(space, nil)
 			})
 
+			Context("when --dry-run is given", func() {
+				BeforeEach(func() {
+					flagContext = flags.NewFlagContext(cmd.MetaData().Flags)
+					flagContext.Parse("the-user-name", "the-org-name", "the-space-name", "SpaceManager", "--dry-run")
+					userRequirement.GetUserReturns(models.UserFields{GUID: "the-user-guid", Username: "the-user-name"})
+					userRepo.PreviewSpaceRoleChangeReturns([]models.RequestPreview{
+						{Method: "PUT", URL: "https://api.example.com/v2/spaces/the-space-guid/managers/the-user-guid"},
+					}, nil)
+				})
+
+				It("prints the requests without changing the role", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(userRepo.SetSpaceRoleByGUIDCallCount()).To(BeZero())
+					userGUID, _, spaceGUID, orgGUID, role, set := userRepo.PreviewSpaceRoleChangeArgsForCall(0)
+					Expect(userGUID).To(Equal("the-user-guid"))
+					Expect(spaceGUID).To(Equal("the-space-guid"))
+					Expect(orgGUID).To(Equal("the-org-guid"))
+					Expect(role).To(Equal(models.RoleSpaceManager))
+					Expect(set).To(BeTrue())
+					Expect(ui.Outputs()).To(ContainSubstrings(
+						[]string{"Dry run"},
+						[]string{"PUT https://api.example.com/v2/spaces/the-space-guid/managers/the-user-guid"},
+					))
+				})
+			})
+
 			Context("when the UserRequirement returns a user with a GUID", func() {
 				BeforeEach(func() {
 					userFields := models.UserFields{GUID: "the-user-guid", Username: "the-user-name"}
@@ -309,6 +355,108 @@ var _ = Describe("SetSpaceRole", func() {
 					})
 				})
 			})
+
+			Context("when several roles are given", func() {
+				BeforeEach(func() {
+					flagContext = flags.NewFlagContext(cmd.MetaData().Flags)
+					flagContext.Parse("the-user-name", "the-org-name", "the-space-name", "SpaceDeveloper,SpaceManager,SpaceAuditor,SpaceManager")
+					userRequirement.GetUserReturns(models.UserFields{GUID: "the-user-guid", Username: "the-user-name"})
+					userRepo.SetSpaceRolesByGUIDReturns([]models.RoleChangeResult{
+						{UserGUID: "the-user-guid", Role: models.RoleSpaceDeveloper, Changed: true},
+						{UserGUID: "the-user-guid", Role: models.RoleSpaceManager, Changed: true},
+						{UserGUID: "the-user-guid", Role: models.RoleSpaceAuditor, Changed: true},
+					}, nil)
+				})
+
+				It("sets each role once in a single call", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(userRepo.SetSpaceRoleByGUIDCallCount()).To(BeZero())
+					Expect(userRepo.SetSpaceRolesByGUIDCallCount()).To(Equal(1))
+					userGUID, spaceGUID, orgGUID, roles := userRepo.SetSpaceRolesByGUIDArgsForCall(0)
+					Expect(userGUID).To(Equal("the-user-guid"))
+					Expect(spaceGUID).To(Equal("the-space-guid"))
+					Expect(orgGUID).To(Equal("the-org-guid"))
+					Expect(roles).To(Equal([]models.Role{models.RoleSpaceDeveloper, models.RoleSpaceManager, models.RoleSpaceAuditor}))
+					Expect(ui.Outputs()).To(ContainSubstrings(
+						[]string{"Assigning role", "SpaceDeveloper, SpaceManager, SpaceAuditor", "the-user-name"},
+						[]string{"OK"},
+					))
+				})
+
+				It("sets the roles by username when the user has no GUID", func() {
+					userRequirement.GetUserReturns(models.UserFields{Username: "the-user-name"})
+					userRepo.SetSpaceRolesByUsernameReturns([]models.RoleChangeResult{{Changed: true}, {Changed: true}, {Changed: true}}, nil)
+
+					Expect(cmd.Execute(flagContext)).To(Succeed())
+					Expect(userRepo.SetSpaceRolesByUsernameCallCount()).To(Equal(1))
+					username, _, _, roles := userRepo.SetSpaceRolesByUsernameArgsForCall(0)
+					Expect(username).To(Equal("the-user-name"))
+					Expect(roles).To(HaveLen(3))
+				})
+
+				Context("when some of the roles fail", func() {
+					BeforeEach(func() {
+						userRepo.SetSpaceRolesByGUIDReturns([]models.RoleChangeResult{
+							{UserGUID: "the-user-guid", Role: models.RoleSpaceDeveloper, Changed: true},
+							{UserGUID: "the-user-guid", Role: models.RoleSpaceManager, Err: errors.New("not authorized")},
+							{UserGUID: "the-user-guid", Role: models.RoleSpaceAuditor, Changed: true},
+						}, nil)
+					})
+
+					It("reports which roles were and were not assigned", func() {
+						Expect(err).To(MatchError("Assigned roles: SpaceDeveloper, SpaceAuditor\nFailed to assign roles:\n  SpaceManager: not authorized"))
+						Expect(ui.Outputs()).NotTo(ContainSubstrings([]string{"OK"}))
+					})
+				})
+
+				Context("when the roles cannot be set at all", func() {
+					BeforeEach(func() {
+						userRepo.SetSpaceRolesByGUIDReturns(nil, errors.New("user-repo-error"))
+					})
+
+					It("returns the error", func() {
+						Expect(err).To(MatchError("user-repo-error"))
+					})
+				})
+
+				Context("when one of the roles is unknown", func() {
+					BeforeEach(func() {
+						flagContext = flags.NewFlagContext(cmd.MetaData().Flags)
+						flagContext.Parse("the-user-name", "the-org-name", "the-space-name", "SpaceDeveloper,SpaceJanitor")
+					})
+
+					It("sets no roles", func() {
+						Expect(err).To(MatchError("Unknown Role"))
+						Expect(userRepo.SetSpaceRolesByGUIDCallCount()).To(BeZero())
+					})
+				})
+
+				Context("when --dry-run is given", func() {
+					BeforeEach(func() {
+						flagContext = flags.NewFlagContext(cmd.MetaData().Flags)
+						flagContext.Parse("the-user-name", "the-org-name", "the-space-name", "SpaceDeveloper,SpaceManager", "--dry-run")
+						userRepo.PreviewSpaceRoleChangeStub = func(userGUID, username, spaceGUID, orgGUID string, role models.Role, set bool) ([]models.RequestPreview, error) {
+							path := map[models.Role]string{models.RoleSpaceDeveloper: "developers", models.RoleSpaceManager: "managers"}[role]
+							return []models.RequestPreview{
+								{Method: "PUT", URL: "https://api.example.com/v2/organizations/the-org-guid/users/the-user-guid"},
+								{Method: "PUT", URL: "https://api.example.com/v2/spaces/the-space-guid/" + path + "/the-user-guid"},
+							}, nil
+						}
+					})
+
+					It("lists the org request once, followed by each role's request", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Expect(userRepo.SetSpaceRolesByGUIDCallCount()).To(BeZero())
+						Expect(ui.Outputs()).To(BeInDisplayOrder(
+							[]string{"Dry run"},
+							[]string{"PUT https://api.example.com/v2/organizations/the-org-guid/users/the-user-guid"},
+							[]string{"PUT https://api.example.com/v2/spaces/the-space-guid/developers/the-user-guid"},
+							[]string{"PUT https://api.example.com/v2/spaces/the-space-guid/managers/the-user-guid"},
+						))
+						Expect(ui.Outputs()).To(HaveLen(5))
+					})
+				})
+			})
 		})
 	})
 })
@@ -31,12 +31,17 @@ func init() {
 }
 
 func (cmd *SpaceUsers) MetaData() commandregistry.CommandMetadata {
+	fs := make(map[string]flags.FlagSet)
+	fs["json"] = &flags.BoolFlag{Name: "json", Usage: T("Print the users and their roles as JSON")}
+	fs["field-style"] = &flags.StringFlag{Name: "field-style", Usage: T("Key casing of the JSON output: camel (default) or snake")}
+
 	return commandregistry.CommandMetadata{
 		Name:        "space-users",
 		Description: T("Show space users by role"),
 		Usage: []string{
 			T("CF_NAME space-users ORG SPACE"),
 		},
+		Flags: fs,
 	}
 }
 
@@ -46,6 +51,11 @@ func (cmd *SpaceUsers) Requirements(requirementsFactory requirements.Factory, fc
 		return nil, fmt.Errorf("Incorrect usage: %d arguments of %d required", len(fc.Args()), 2)
 	}
 
+	if style := fc.String("field-style"); style != "" && style != string(userprint.CamelCase) && style != string(userprint.SnakeCase) {
+		cmd.ui.Failed(T(`Incorrect Usage. --field-style must be "camel" or "snake"\n\n`) + commandregistry.Commands.CommandUsage("space-users"))
+		return nil, fmt.Errorf("Incorrect usage: invalid field style %s", style)
+	}
+
 	cmd.orgReq = requirementsFactory.NewOrganizationRequirement(fc.Args()[0])
 
 	reqs := []requirements.Requirement{
@@ -76,12 +86,12 @@ func (cmd *SpaceUsers) Execute(c flags.FlagContext) error {
 		return err
 	}
 
-	printer := cmd.printer(org, space, cmd.config.Username())
+	printer := cmd.printer(c, org, space, cmd.config.Username())
 	printer.PrintUsers(space.GUID, cmd.config.Username())
 	return nil
 }
 
-func (cmd *SpaceUsers) printer(org models.Organization, space models.Space, username string) userprint.UserPrinter {
+func (cmd *SpaceUsers) printer(c flags.FlagContext, org models.Organization, space models.Space, username string) userprint.UserPrinter {
 	var roles = []models.Role{models.RoleSpaceManager, models.RoleSpaceDeveloper, models.RoleSpaceAuditor}
 
 	if cmd.pluginCall {
@@ -92,6 +102,15 @@ func (cmd *SpaceUsers) printer(org models.Organization, space models.Space, user
 		)
 	}
 
+	if c.Bool("json") {
+		return &userprint.JSONPrinter{
+			UI:         cmd.ui,
+			UserLister: cmd.userRepo.ListUsersInSpaceForRoleWithNoUAA,
+			Roles:      roles,
+			FieldStyle: userprint.FieldStyle(c.String("field-style")),
+		}
+	}
+
 	cmd.ui.Say(T("Getting users in org {{.TargetOrg}} / space {{.TargetSpace}} as {{.CurrentUser}}",
 		map[string]interface{}{
 			"TargetOrg":   terminal.EntityNameColor(org.Name),
@@ -165,6 +165,32 @@ var _ = Describe("space-users command", func() {
 				[]string{"internet badness occurred"},
 			))
 		})
+
+		Context("when the --json flag is provided", func() {
+			It("prints only a JSON array of the users and their roles", func() {
+				runCommand("--json", "my-org", "my-space")
+
+				Expect(ui.Outputs()).To(HaveLen(1))
+				Expect(ui.Outputs()[0]).To(MatchJSON(`[
+					{"username": "user1", "guid": "", "roles": ["RoleSpaceManager"], "isAdmin": false},
+					{"username": "user2", "guid": "", "roles": ["RoleSpaceManager"], "isAdmin": false},
+					{"username": "user3", "guid": "", "roles": ["RoleSpaceAuditor"], "isAdmin": false},
+					{"username": "user4", "guid": "", "roles": ["RoleSpaceDeveloper"], "isAdmin": false}
+				]`))
+			})
+
+			It("writes snake_case keys when --field-style is snake", func() {
+				runCommand("--json", "--field-style", "snake", "my-org", "my-space")
+
+				Expect(ui.Outputs()).To(HaveLen(1))
+				Expect(ui.Outputs()[0]).To(MatchJSON(`[
+					{"username": "user1", "guid": "", "roles": ["RoleSpaceManager"], "is_admin": false},
+					{"username": "user2", "guid": "", "roles": ["RoleSpaceManager"], "is_admin": false},
+					{"username": "user3", "guid": "", "roles": ["RoleSpaceAuditor"], "is_admin": false},
+					{"username": "user4", "guid": "", "roles": ["RoleSpaceDeveloper"], "is_admin": false}
+				]`))
+			})
+		})
 	})
 
 	Context("when logged in and there are no non-managers in the space", func() {
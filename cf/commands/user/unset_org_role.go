@@ -29,6 +29,11 @@ func init() {
 }
 
 func (cmd *UnsetOrgRole) MetaData() commandregistry.CommandMetadata {
+	fs := make(map[string]flags.FlagSet)
+	fs["dry-run"] = &flags.BoolFlag{Name: "dry-run", Usage: T("Print the requests that would be sent without changing any roles")}
+	fs["guid"] = &flags.BoolFlag{Name: "guid", Usage: T("Treat USERNAME as a user GUID and skip looking the user up")}
+	fs["interactive"] = &flags.BoolFlag{Name: "interactive", Usage: T("When no user has the given username, choose from the users whose names start with it")}
+
 	return commandregistry.CommandMetadata{
 		Name:        "unset-org-role",
 		Description: T("Remove an org role from a user"),
@@ -39,6 +44,7 @@ func (cmd *UnsetOrgRole) MetaData() commandregistry.CommandMetadata {
 			fmt.Sprintf("   'BillingManager' - %s", T("Create and manage the billing account and payment info\n")),
 			fmt.Sprintf("   'OrgAuditor' - %s", T("Read-only access to org info and reports\n")),
 		},
+		Flags: fs,
 	}
 }
 
@@ -48,15 +54,21 @@ func (cmd *UnsetOrgRole) Requirements(requirementsFactory requirements.Factory,
 		return nil, fmt.Errorf("Incorrect usage: %d arguments of %d required", len(fc.Args()), 3)
 	}
 
-	var wantGUID bool
-	if cmd.config.IsMinAPIVersion(cf.SetRolesByUsernameMinimumAPIVersion) {
-		setRolesByUsernameFlag, err := cmd.flagRepo.FindByName("unset_roles_by_username")
-		wantGUID = (err != nil || !setRolesByUsernameFlag.Enabled)
+	if fc.Bool("guid") {
+		cmd.userReq = requirementsFactory.NewUserGUIDRequirement(fc.Args()[0])
+	} else if fc.Bool("interactive") {
+		cmd.userReq = requirementsFactory.NewUserRequirementWithPrefixMatch(fc.Args()[0], chooseUser(cmd.ui))
 	} else {
-		wantGUID = true
+		var wantGUID bool
+		if cmd.config.IsMinAPIVersion(cf.SetRolesByUsernameMinimumAPIVersion) {
+			setRolesByUsernameFlag, err := cmd.flagRepo.FindByName("unset_roles_by_username")
+			wantGUID = (err != nil || !setRolesByUsernameFlag.Enabled)
+		} else {
+			wantGUID = true
+		}
+
+		cmd.userReq = requirementsFactory.NewUserRequirement(fc.Args()[0], wantGUID)
 	}
-
-	cmd.userReq = requirementsFactory.NewUserRequirement(fc.Args()[0], wantGUID)
 	cmd.orgReq = requirementsFactory.NewOrganizationRequirement(fc.Args()[1])
 
 	reqs := []requirements.Requirement{
@@ -93,6 +105,15 @@ func (cmd *UnsetOrgRole) Execute(c flags.FlagContext) error {
 			"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
 		}))
 
+	if c.Bool("dry-run") {
+		previews, err := cmd.userRepo.PreviewOrgRoleChange(user.GUID, user.Username, org.GUID, role, false)
+		if err != nil {
+			return err
+		}
+		sayRequestPreviews(cmd.ui, previews)
+		return nil
+	}
+
 	if len(user.GUID) > 0 {
 		err = cmd.userRepo.UnsetOrgRoleByGUID(user.GUID, org.GUID, role)
 	} else {
@@ -189,6 +189,26 @@ var _ = Describe("UnsetOrgRole", func() {
 					Expect(actualRequirements).To(ContainElement(userRequirement))
 				})
 			})
+
+			Context("when --guid is given", func() {
+				BeforeEach(func() {
+					configRepo.SetAPIVersion("2.37.0")
+					flagContext = flags.NewFlagContext(cmd.MetaData().Flags)
+					flagContext.Parse("the-user-name", "the-org-name", "OrgManager", "--guid")
+					factory.NewUserGUIDRequirementReturns(userRequirement)
+				})
+
+				It("returns a UserRequirement for the GUID without looking the user up", func() {
+					actualRequirements, err := cmd.Requirements(factory, flagContext)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(factory.NewUserGUIDRequirementCallCount()).To(Equal(1))
+					Expect(factory.NewUserGUIDRequirementArgsForCall(0)).To(Equal("the-user-name"))
+					Expect(factory.NewUserRequirementCallCount()).To(BeZero())
+					Expect(flagRepo.FindByNameCallCount()).To(BeZero())
+
+					Expect(actualRequirements).To(ContainElement(userRequirement))
+				})
+			})
 		})
 	})
 
@@ -209,6 +229,28 @@ var _ = Describe("UnsetOrgRole", func() {
 			err = cmd.Execute(flagContext)
 		})
 
+		Context("when --dry-run is given", func() {
+			BeforeEach(func() {
+				flagContext = flags.NewFlagContext(cmd.MetaData().Flags)
+				flagContext.Parse("the-user-name", "the-org-name", "OrgManager", "--dry-run")
+				userRequirement.GetUserReturns(models.UserFields{Username: "the-user-name"})
+				userRepo.PreviewOrgRoleChangeReturns([]models.RequestPreview{
+					{Method: "DELETE", URL: "https://api.example.com/v2/organizations/the-org-guid/managers", Body: `{"username": "the-user-name"}`},
+				}, nil)
+			})
+
+			It("prints the request without removing the role", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(userRepo.UnsetOrgRoleByUsernameCallCount()).To(BeZero())
+				_, _, _, _, set := userRepo.PreviewOrgRoleChangeArgsForCall(0)
+				Expect(set).To(BeFalse())
+				Expect(ui.Outputs()).To(ContainSubstrings(
+					[]string{"DELETE https://api.example.com/v2/organizations/the-org-guid/managers"},
+					[]string{`{"username": "the-user-name"}`},
+				))
+			})
+		})
+
 		Context("when the UserRequirement returns a user with a GUID", func() {
 			BeforeEach(func() {
 				userFields := models.UserFields{GUID: "the-user-guid", Username: "the-user-name"}
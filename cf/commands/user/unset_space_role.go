@@ -31,6 +31,10 @@ func init() {
 }
 
 func (cmd *UnsetSpaceRole) MetaData() commandregistry.CommandMetadata {
+	fs := make(map[string]flags.FlagSet)
+	fs["dry-run"] = &flags.BoolFlag{Name: "dry-run", Usage: T("Print the requests that would be sent without changing any roles")}
+	fs["interactive"] = &flags.BoolFlag{Name: "interactive", Usage: T("When no user has the given username, choose from the users whose names start with it")}
+
 	return commandregistry.CommandMetadata{
 		Name:        "unset-space-role",
 		Description: T("Remove a space role from a user"),
@@ -41,6 +45,7 @@ func (cmd *UnsetSpaceRole) MetaData() commandregistry.CommandMetadata {
 			fmt.Sprintf("   'SpaceDeveloper' - %s", T("Create and manage apps and services, and see logs and reports\n")),
 			fmt.Sprintf("   'SpaceAuditor' - %s", T("View logs, reports, and settings on this space\n")),
 		},
+		Flags: fs,
 	}
 }
 
@@ -50,15 +55,19 @@ func (cmd *UnsetSpaceRole) Requirements(requirementsFactory requirements.Factory
 		return nil, fmt.Errorf("Incorrect usage: %d arguments of %d required", len(fc.Args()), 4)
 	}
 
-	var wantGUID bool
-	if cmd.config.IsMinAPIVersion(cf.SetRolesByUsernameMinimumAPIVersion) {
-		unsetRolesByUsernameFlag, err := cmd.flagRepo.FindByName("unset_roles_by_username")
-		wantGUID = (err != nil || !unsetRolesByUsernameFlag.Enabled)
+	if fc.Bool("interactive") {
+		cmd.userReq = requirementsFactory.NewUserRequirementWithPrefixMatch(fc.Args()[0], chooseUser(cmd.ui))
 	} else {
-		wantGUID = true
-	}
+		var wantGUID bool
+		if cmd.config.IsMinAPIVersion(cf.SetRolesByUsernameMinimumAPIVersion) {
+			unsetRolesByUsernameFlag, err := cmd.flagRepo.FindByName("unset_roles_by_username")
+			wantGUID = (err != nil || !unsetRolesByUsernameFlag.Enabled)
+		} else {
+			wantGUID = true
+		}
 
-	cmd.userReq = requirementsFactory.NewUserRequirement(fc.Args()[0], wantGUID)
+		cmd.userReq = requirementsFactory.NewUserRequirement(fc.Args()[0], wantGUID)
+	}
 	cmd.orgReq = requirementsFactory.NewOrganizationRequirement(fc.Args()[1])
 
 	reqs := []requirements.Requirement{
@@ -102,6 +111,15 @@ func (cmd *UnsetSpaceRole) Execute(c flags.FlagContext) error {
 			"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
 		}))
 
+	if c.Bool("dry-run") {
+		previews, err := cmd.userRepo.PreviewSpaceRoleChange(user.GUID, user.Username, space.GUID, org.GUID, role, false)
+		if err != nil {
+			return err
+		}
+		sayRequestPreviews(cmd.ui, previews)
+		return nil
+	}
+
 	if len(user.GUID) > 0 {
 		err = cmd.userRepo.UnsetSpaceRoleByGUID(user.GUID, space.GUID, role)
 	} else {
@@ -194,6 +194,26 @@ var _ = Describe("UnsetSpaceRole", func() {
 					Expect(actualRequirements).To(ContainElement(userRequirement))
 				})
 			})
+
+			Context("when --interactive is given", func() {
+				BeforeEach(func() {
+					configRepo.SetAPIVersion("2.37.0")
+					flagContext = flags.NewFlagContext(cmd.MetaData().Flags)
+					flagContext.Parse("the-user", "the-org-name", "the-space-name", "SpaceManager", "--interactive")
+					factory.NewUserRequirementWithPrefixMatchReturns(userRequirement)
+				})
+
+				It("returns a UserRequirement that can choose from similar names", func() {
+					actualRequirements, err := cmd.Requirements(factory, flagContext)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(factory.NewUserRequirementCallCount()).To(BeZero())
+					Expect(flagRepo.FindByNameCallCount()).To(BeZero())
+					username, _ := factory.NewUserRequirementWithPrefixMatchArgsForCall(0)
+					Expect(username).To(Equal("the-user"))
+
+					Expect(actualRequirements).To(ContainElement(userRequirement))
+				})
+			})
 		})
 	})
 
@@ -242,6 +262,32 @@ var _ = Describe("UnsetSpaceRole", func() {
 				spaceRepo.FindByNameInOrgReturns(space, nil)
 			})
 
+			Context("when --dry-run is given", func() {
+				BeforeEach(func() {
+					flagContext = flags.NewFlagContext(cmd.MetaData().Flags)
+					flagContext.Parse("the-user-name", "the-org-name", "the-space-name", "SpaceManager", "--dry-run")
+					userRequirement.GetUserReturns(models.UserFields{GUID: "the-user-guid", Username: "the-user-name"})
+					userRepo.PreviewSpaceRoleChangeReturns([]models.RequestPreview{
+						{Method: "DELETE", URL: "https://api.example.com/v2/spaces/the-space-guid/managers/the-user-guid"},
+					}, nil)
+				})
+
+				It("prints the requests without changing the role", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(userRepo.UnsetSpaceRoleByGUIDCallCount()).To(BeZero())
+					userGUID, _, spaceGUID, orgGUID, role, set := userRepo.PreviewSpaceRoleChangeArgsForCall(0)
+					Expect(userGUID).To(Equal("the-user-guid"))
+					Expect(spaceGUID).To(Equal("the-space-guid"))
+					Expect(orgGUID).To(Equal("the-org-guid"))
+					Expect(role).To(Equal(models.RoleSpaceManager))
+					Expect(set).To(BeFalse())
+					Expect(ui.Outputs()).To(ContainSubstrings(
+						[]string{"Dry run"},
+						[]string{"DELETE https://api.example.com/v2/spaces/the-space-guid/managers/the-user-guid"},
+					))
+				})
+			})
+
 			Context("when the UserRequirement returns a user with a GUID", func() {
 				BeforeEach(func() {
 					userFields := models.UserFields{GUID: "the-user-guid", Username: "the-user-name"}
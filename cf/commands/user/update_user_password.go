@@ -0,0 +1,94 @@
+package user
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/cli/cf/api"
+	"code.cloudfoundry.org/cli/cf/commandregistry"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/errors"
+	"code.cloudfoundry.org/cli/cf/flags"
+	. "code.cloudfoundry.org/cli/cf/i18n"
+	"code.cloudfoundry.org/cli/cf/requirements"
+	"code.cloudfoundry.org/cli/cf/terminal"
+)
+
+type UpdateUserPassword struct {
+	ui       terminal.UI
+	config   coreconfig.Reader
+	userRepo api.UserRepository
+}
+
+func init() {
+	commandregistry.Register(&UpdateUserPassword{})
+}
+
+func (cmd *UpdateUserPassword) MetaData() commandregistry.CommandMetadata {
+	return commandregistry.CommandMetadata{
+		Name:        "update-user-password",
+		Description: T("Set a user's password"),
+		Usage: []string{
+			T("CF_NAME update-user-password USERNAME\n\n"),
+			T("   Resetting another user's password requires the uaa.admin or password.write scope."),
+		},
+	}
+}
+
+func (cmd *UpdateUserPassword) Requirements(requirementsFactory requirements.Factory, fc flags.FlagContext) ([]requirements.Requirement, error) {
+	if len(fc.Args()) != 1 {
+		cmd.ui.Failed(T("Incorrect Usage. Requires an argument\n\n") + commandregistry.Commands.CommandUsage("update-user-password"))
+		return nil, fmt.Errorf("Incorrect usage: %d arguments of %d required", len(fc.Args()), 1)
+	}
+
+	reqs := []requirements.Requirement{
+		requirementsFactory.NewLoginRequirement(),
+	}
+
+	return reqs, nil
+}
+
+func (cmd *UpdateUserPassword) SetDependency(deps commandregistry.Dependency, pluginCall bool) commandregistry.Command {
+	cmd.ui = deps.UI
+	cmd.config = deps.Config
+	cmd.userRepo = deps.RepoLocator.GetUserRepository()
+	return cmd
+}
+
+func (cmd *UpdateUserPassword) Execute(c flags.FlagContext) error {
+	username := c.Args()[0]
+
+	user, err := cmd.userRepo.FindByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	// Users changing their own password must know the current one; anyone
+	// else's is reset, which the repository checks the token's scope for.
+	var oldPassword string
+	if user.GUID == cmd.config.UserGUID() {
+		oldPassword = cmd.ui.AskForPassword(T("Current Password"))
+	}
+	newPassword := cmd.ui.AskForPassword(T("New Password"))
+	verifiedPassword := cmd.ui.AskForPassword(T("Verify Password"))
+
+	if verifiedPassword != newPassword {
+		return errors.New(T("Password verification does not match"))
+	}
+
+	cmd.ui.Say(T("Updating password for user {{.TargetUser}} as {{.CurrentUser}}...",
+		map[string]interface{}{
+			"TargetUser":  terminal.EntityNameColor(username),
+			"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
+		}))
+
+	err = cmd.userRepo.UpdatePassword(user.GUID, oldPassword, newPassword)
+	if httpErr, ok := err.(errors.HTTPError); ok && httpErr.StatusCode() == 401 && oldPassword != "" {
+		return errors.New(T("Current password did not match"))
+	}
+	if err != nil {
+		return err
+	}
+
+	cmd.ui.Ok()
+	return nil
+}
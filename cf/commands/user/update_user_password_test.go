@@ -0,0 +1,122 @@
+package user_test
+
+import (
+	"code.cloudfoundry.org/cli/cf/api/apifakes"
+	"code.cloudfoundry.org/cli/cf/commandregistry"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/errors"
+	"code.cloudfoundry.org/cli/cf/models"
+	"code.cloudfoundry.org/cli/cf/requirements"
+	"code.cloudfoundry.org/cli/cf/requirements/requirementsfakes"
+	testcmd "code.cloudfoundry.org/cli/util/testhelpers/commands"
+	testconfig "code.cloudfoundry.org/cli/util/testhelpers/configuration"
+	testterm "code.cloudfoundry.org/cli/util/testhelpers/terminal"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "code.cloudfoundry.org/cli/util/testhelpers/matchers"
+)
+
+var _ = Describe("update-user-password command", func() {
+	var (
+		ui                  *testterm.FakeUI
+		configRepo          coreconfig.Repository
+		userRepo            *apifakes.FakeUserRepository
+		requirementsFactory *requirementsfakes.FakeFactory
+		deps                commandregistry.Dependency
+	)
+
+	updateCommandDependency := func(pluginCall bool) {
+		deps.UI = ui
+		deps.Config = configRepo
+		deps.RepoLocator = deps.RepoLocator.SetUserRepository(userRepo)
+		commandregistry.Commands.SetCommand(commandregistry.Commands.FindCommand("update-user-password").SetDependency(deps, pluginCall))
+	}
+
+	BeforeEach(func() {
+		ui = &testterm.FakeUI{Inputs: []string{"new-pass", "new-pass"}}
+		userRepo = new(apifakes.FakeUserRepository)
+		userRepo.FindByUsernameReturns(models.UserFields{Username: "user-name", GUID: "user-guid"}, nil)
+		requirementsFactory = new(requirementsfakes.FakeFactory)
+		requirementsFactory.NewLoginRequirementReturns(requirements.Passing{})
+		configRepo = testconfig.NewRepositoryWithDefaults()
+
+		token, err := testconfig.EncodeAccessToken(coreconfig.TokenInfo{
+			UserGUID: "admin-user-guid",
+			Username: "admin-user",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		configRepo.SetAccessToken(token)
+	})
+
+	runCommand := func(args ...string) bool {
+		return testcmd.RunCLICommand("update-user-password", args, requirementsFactory, updateCommandDependency, false, ui)
+	}
+
+	Describe("requirements", func() {
+		It("fails when not logged in", func() {
+			requirementsFactory.NewLoginRequirementReturns(requirements.Failing{Message: "not logged in"})
+
+			Expect(runCommand("user-name")).To(BeFalse())
+		})
+
+		It("fails with usage when no arguments are given", func() {
+			runCommand()
+			Expect(ui.Outputs()).To(ContainSubstrings(
+				[]string{"Incorrect Usage", "Requires an argument"},
+			))
+		})
+	})
+
+	It("resets another user's password without the old one", func() {
+		Expect(runCommand("user-name")).To(BeTrue())
+
+		Expect(ui.PasswordPrompts).To(ContainSubstrings([]string{"New Password"}, []string{"Verify Password"}))
+		Expect(ui.Outputs()).To(ContainSubstrings(
+			[]string{"Updating password for user", "user-name", "admin-user"},
+			[]string{"OK"},
+		))
+
+		userGUID, oldPassword, newPassword := userRepo.UpdatePasswordArgsForCall(0)
+		Expect(userGUID).To(Equal("user-guid"))
+		Expect(oldPassword).To(BeEmpty())
+		Expect(newPassword).To(Equal("new-pass"))
+	})
+
+	It("asks for the current password when changing the user's own password", func() {
+		userRepo.FindByUsernameReturns(models.UserFields{Username: "admin-user", GUID: "admin-user-guid"}, nil)
+		ui.Inputs = []string{"old-pass", "new-pass", "new-pass"}
+
+		Expect(runCommand("admin-user")).To(BeTrue())
+
+		Expect(ui.PasswordPrompts).To(ContainSubstrings([]string{"Current Password"}))
+		_, oldPassword, newPassword := userRepo.UpdatePasswordArgsForCall(0)
+		Expect(oldPassword).To(Equal("old-pass"))
+		Expect(newPassword).To(Equal("new-pass"))
+	})
+
+	It("does not update the password when the verification does not match", func() {
+		ui.Inputs = []string{"new-pass", "other-pass"}
+
+		Expect(runCommand("user-name")).To(BeFalse())
+
+		Expect(ui.Outputs()).To(ContainSubstrings([]string{"Password verification does not match"}))
+		Expect(userRepo.UpdatePasswordCallCount()).To(BeZero())
+	})
+
+	It("fails when the token lacks the scope to reset passwords", func() {
+		userRepo.UpdatePasswordReturns(errors.NewMissingScopeError("uaa.admin", "password.write"))
+
+		Expect(runCommand("user-name")).To(BeFalse())
+
+		Expect(ui.Outputs()).To(ContainSubstrings([]string{"FAILED"}, []string{"uaa.admin"}))
+	})
+
+	It("fails when the user does not exist", func() {
+		userRepo.FindByUsernameReturns(models.UserFields{}, errors.NewModelNotFoundError("User", "user-name"))
+
+		Expect(runCommand("user-name")).To(BeFalse())
+
+		Expect(userRepo.UpdatePasswordCallCount()).To(BeZero())
+	})
+})
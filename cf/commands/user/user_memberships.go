@@ -0,0 +1,124 @@
+package user
+
+import (
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/cli/cf/api"
+	"code.cloudfoundry.org/cli/cf/commandregistry"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/flags"
+	. "code.cloudfoundry.org/cli/cf/i18n"
+	"code.cloudfoundry.org/cli/cf/models"
+	"code.cloudfoundry.org/cli/cf/requirements"
+	"code.cloudfoundry.org/cli/cf/terminal"
+)
+
+type UserMemberships struct {
+	ui       terminal.UI
+	config   coreconfig.Reader
+	userRepo api.UserRepository
+	userReq  requirements.UserRequirement
+	orgReq   requirements.OrganizationRequirement
+}
+
+func init() {
+	commandregistry.Register(&UserMemberships{})
+}
+
+func (cmd *UserMemberships) MetaData() commandregistry.CommandMetadata {
+	fs := make(map[string]flags.FlagSet)
+	fs["o"] = &flags.StringFlag{ShortName: "o", Usage: T("Only show memberships in the specified org")}
+
+	return commandregistry.CommandMetadata{
+		Name:        "user-memberships",
+		Description: T("Show the orgs and spaces a user belongs to and their roles in each"),
+		Usage: []string{
+			T("CF_NAME user-memberships USERNAME [-o ORG]"),
+		},
+		Flags: fs,
+	}
+}
+
+func (cmd *UserMemberships) Requirements(requirementsFactory requirements.Factory, fc flags.FlagContext) ([]requirements.Requirement, error) {
+	if len(fc.Args()) != 1 {
+		cmd.ui.Failed(T("Incorrect Usage. Requires an argument\n\n") + commandregistry.Commands.CommandUsage("user-memberships"))
+		return nil, fmt.Errorf("Incorrect usage: %d arguments of %d required", len(fc.Args()), 1)
+	}
+
+	cmd.userReq = requirementsFactory.NewUserRequirement(fc.Args()[0], true)
+
+	reqs := []requirements.Requirement{
+		requirementsFactory.NewLoginRequirement(),
+		cmd.userReq,
+	}
+
+	cmd.orgReq = nil
+	if fc.String("o") != "" {
+		cmd.orgReq = requirementsFactory.NewOrganizationRequirement(fc.String("o"))
+		reqs = append(reqs, cmd.orgReq)
+	}
+
+	return reqs, nil
+}
+
+func (cmd *UserMemberships) SetDependency(deps commandregistry.Dependency, pluginCall bool) commandregistry.Command {
+	cmd.ui = deps.UI
+	cmd.config = deps.Config
+	cmd.userRepo = deps.RepoLocator.GetUserRepository()
+	return cmd
+}
+
+func (cmd *UserMemberships) Execute(c flags.FlagContext) error {
+	user := cmd.userReq.GetUser()
+
+	var orgGUID string
+	if cmd.orgReq != nil {
+		org := cmd.orgReq.GetOrganization()
+		orgGUID = org.GUID
+		cmd.ui.Say(T("Getting memberships of user {{.TargetUser}} in org {{.TargetOrg}} as {{.CurrentUser}}...",
+			map[string]interface{}{
+				"TargetUser":  terminal.EntityNameColor(user.Username),
+				"TargetOrg":   terminal.EntityNameColor(org.Name),
+				"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
+			}))
+	} else {
+		cmd.ui.Say(T("Getting memberships of user {{.TargetUser}} as {{.CurrentUser}}...",
+			map[string]interface{}{
+				"TargetUser":  terminal.EntityNameColor(user.Username),
+				"CurrentUser": terminal.EntityNameColor(cmd.config.Username()),
+			}))
+	}
+
+	memberships, err := cmd.userRepo.GetUserMemberships(user.GUID, orgGUID)
+	if err != nil {
+		return err
+	}
+
+	cmd.ui.Ok()
+	cmd.ui.Say("")
+
+	if len(memberships.Orgs) == 0 {
+		cmd.ui.Say(T("No memberships found"))
+		return nil
+	}
+
+	table := cmd.ui.Table([]string{T("org"), T("space"), T("roles")})
+	for _, org := range memberships.Orgs {
+		table.Add(org.Org.Name, "", membershipRoles(org.Roles))
+		for _, space := range org.Spaces {
+			table.Add(org.Org.Name, space.Space.Name, membershipRoles(space.Roles))
+		}
+	}
+	return table.Print()
+}
+
+// membershipRoles lists roles by the names set-org-role and set-space-role
+// take.
+func membershipRoles(roles []models.Role) string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, strings.TrimPrefix(role.ToString(), "Role"))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,130 @@
+package user_test
+
+import (
+	"code.cloudfoundry.org/cli/cf/api/apifakes"
+	"code.cloudfoundry.org/cli/cf/commandregistry"
+	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
+	"code.cloudfoundry.org/cli/cf/errors"
+	"code.cloudfoundry.org/cli/cf/models"
+	"code.cloudfoundry.org/cli/cf/requirements"
+	"code.cloudfoundry.org/cli/cf/requirements/requirementsfakes"
+	testcmd "code.cloudfoundry.org/cli/util/testhelpers/commands"
+	testconfig "code.cloudfoundry.org/cli/util/testhelpers/configuration"
+	testterm "code.cloudfoundry.org/cli/util/testhelpers/terminal"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "code.cloudfoundry.org/cli/util/testhelpers/matchers"
+)
+
+var _ = Describe("user-memberships command", func() {
+	var (
+		ui                  *testterm.FakeUI
+		configRepo          coreconfig.Repository
+		userRepo            *apifakes.FakeUserRepository
+		requirementsFactory *requirementsfakes.FakeFactory
+		userRequirement     *requirementsfakes.FakeUserRequirement
+		orgRequirement      *requirementsfakes.FakeOrganizationRequirement
+		deps                commandregistry.Dependency
+	)
+
+	updateCommandDependency := func(pluginCall bool) {
+		deps.UI = ui
+		deps.Config = configRepo
+		deps.RepoLocator = deps.RepoLocator.SetUserRepository(userRepo)
+		commandregistry.Commands.SetCommand(commandregistry.Commands.FindCommand("user-memberships").SetDependency(deps, pluginCall))
+	}
+
+	BeforeEach(func() {
+		ui = &testterm.FakeUI{}
+		userRepo = new(apifakes.FakeUserRepository)
+		configRepo = testconfig.NewRepositoryWithDefaults()
+
+		requirementsFactory = new(requirementsfakes.FakeFactory)
+		requirementsFactory.NewLoginRequirementReturns(requirements.Passing{})
+
+		userRequirement = new(requirementsfakes.FakeUserRequirement)
+		userRequirement.GetUserReturns(models.UserFields{GUID: "user-guid", Username: "alice"})
+		requirementsFactory.NewUserRequirementReturns(userRequirement)
+
+		orgRequirement = new(requirementsfakes.FakeOrganizationRequirement)
+		orgRequirement.GetOrganizationReturns(models.Organization{
+			OrganizationFields: models.OrganizationFields{GUID: "org-b-guid", Name: "org-b"},
+		})
+		requirementsFactory.NewOrganizationRequirementReturns(orgRequirement)
+	})
+
+	runCommand := func(args ...string) bool {
+		return testcmd.RunCLICommand("user-memberships", args, requirementsFactory, updateCommandDependency, false, ui)
+	}
+
+	Describe("requirements", func() {
+		It("fails with usage when not given a username", func() {
+			runCommand()
+			Expect(ui.Outputs()).To(ContainSubstrings([]string{"Incorrect Usage", "Requires an argument"}))
+		})
+
+		It("fails when not logged in", func() {
+			requirementsFactory.NewLoginRequirementReturns(requirements.Failing{Message: "not logged in"})
+
+			Expect(runCommand("alice")).To(BeFalse())
+		})
+
+		It("looks the user up by GUID and only looks up an org when one is given", func() {
+			runCommand("alice")
+
+			Expect(requirementsFactory.NewUserRequirementCallCount()).To(Equal(1))
+			username, wantGUID := requirementsFactory.NewUserRequirementArgsForCall(0)
+			Expect(username).To(Equal("alice"))
+			Expect(wantGUID).To(BeTrue())
+			Expect(requirementsFactory.NewOrganizationRequirementCallCount()).To(BeZero())
+		})
+	})
+
+	It("shows each org and space membership with its roles", func() {
+		userRepo.GetUserMembershipsReturns(models.UserMemberships{
+			UserGUID: "user-guid",
+			Orgs: []models.OrgMembership{
+				{
+					Org:   models.OrganizationFields{GUID: "org-a-guid", Name: "org-a"},
+					Roles: []models.Role{models.RoleOrgUser, models.RoleOrgManager},
+					Spaces: []models.SpaceMembership{
+						{Space: models.SpaceFields{Name: "space-1"}, Roles: []models.Role{models.RoleSpaceDeveloper, models.RoleSpaceAuditor}},
+					},
+				},
+			},
+		}, nil)
+
+		Expect(runCommand("alice")).To(BeTrue())
+
+		userGUID, orgGUID := userRepo.GetUserMembershipsArgsForCall(0)
+		Expect(userGUID).To(Equal("user-guid"))
+		Expect(orgGUID).To(BeEmpty())
+		Expect(ui.Outputs()).To(ContainSubstrings(
+			[]string{"Getting memberships of user", "alice", "my-user"},
+			[]string{"OK"},
+			[]string{"org", "space", "roles"},
+			[]string{"org-a", "OrgUser, OrgManager"},
+			[]string{"org-a", "space-1", "SpaceDeveloper, SpaceAuditor"},
+		))
+	})
+
+	It("scopes the listing to the org given with -o", func() {
+		Expect(runCommand("alice", "-o", "org-b")).To(BeTrue())
+
+		Expect(requirementsFactory.NewOrganizationRequirementArgsForCall(0)).To(Equal("org-b"))
+		_, orgGUID := userRepo.GetUserMembershipsArgsForCall(0)
+		Expect(orgGUID).To(Equal("org-b-guid"))
+		Expect(ui.Outputs()).To(ContainSubstrings(
+			[]string{"Getting memberships of user", "alice", "in org", "org-b"},
+			[]string{"No memberships found"},
+		))
+	})
+
+	It("fails when the memberships cannot be read", func() {
+		userRepo.GetUserMembershipsReturns(models.UserMemberships{}, errors.New("cc down"))
+
+		Expect(runCommand("alice")).To(BeFalse())
+		Expect(ui.Outputs()).To(ContainSubstrings([]string{"cc down"}))
+	})
+})
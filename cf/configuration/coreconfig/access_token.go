@@ -7,9 +7,11 @@ import (
 )
 
 type TokenInfo struct {
-	Username string `json:"user_name"`
-	Email    string `json:"email"`
-	UserGUID string `json:"user_id"`
+	Username string   `json:"user_name"`
+	Email    string   `json:"email"`
+	UserGUID string   `json:"user_id"`
+	Expiry   int64    `json:"exp,omitempty"`
+	Scope    []string `json:"scope,omitempty"`
 }
 
 func NewTokenInfo(accessToken string) (info TokenInfo) {
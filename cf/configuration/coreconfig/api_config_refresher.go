@@ -10,6 +10,7 @@ import (
 
 type EndpointRepository interface {
 	GetCCInfo(string) (*CCInfo, string, error)
+	PingUAA(uaaEndpoint string) error
 }
 
 type APIConfigRefresher struct {
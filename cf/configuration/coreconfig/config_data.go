@@ -35,6 +35,8 @@ type Data struct {
 	SpaceFields              models.SpaceFields
 	SSLDisabled              bool
 	AsyncTimeout             uint
+	UAADialTimeout           uint `json:",omitempty"`
+	ResultsPerPage           uint `json:",omitempty"`
 	Trace                    string
 	ColorEnabled             string
 	Locale                   string
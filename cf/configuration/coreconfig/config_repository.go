@@ -88,6 +88,8 @@ type Reader interface {
 	CLIVersion() string
 
 	AsyncTimeout() uint
+	UAADialTimeout() uint
+	ResultsPerPage() uint
 	Trace() string
 
 	ColorEnabled() string
@@ -119,6 +121,8 @@ type ReadWriter interface {
 	SetSpaceFields(models.SpaceFields)
 	SetSSLDisabled(bool)
 	SetAsyncTimeout(uint)
+	SetUAADialTimeout(uint)
+	SetResultsPerPage(uint)
 	SetTrace(string)
 	SetColorEnabled(string)
 	SetLocale(string)
@@ -397,6 +401,25 @@ func (c *ConfigRepository) AsyncTimeout() (timeout uint) {
 	return
 }
 
+// UAADialTimeout is the dial timeout, in seconds, for UAA requests made while
+// listing and managing users. Zero means the usual dial timeout applies.
+func (c *ConfigRepository) UAADialTimeout() (timeout uint) {
+	c.read(func() {
+		timeout = c.data.UAADialTimeout
+	})
+	return
+}
+
+// ResultsPerPage is the page size asked of the Cloud Controller when listing
+// resources. Zero, the default, asks for the largest page the Cloud
+// Controller serves.
+func (c *ConfigRepository) ResultsPerPage() (perPage uint) {
+	c.read(func() {
+		perPage = c.data.ResultsPerPage
+	})
+	return
+}
+
 func (c *ConfigRepository) Trace() (trace string) {
 	c.read(func() {
 		trace = c.data.Trace
@@ -538,6 +561,18 @@ func (c *ConfigRepository) SetAsyncTimeout(timeout uint) {
 	})
 }
 
+func (c *ConfigRepository) SetUAADialTimeout(timeout uint) {
+	c.write(func() {
+		c.data.UAADialTimeout = timeout
+	})
+}
+
+func (c *ConfigRepository) SetResultsPerPage(perPage uint) {
+	c.write(func() {
+		c.data.ResultsPerPage = perPage
+	})
+}
+
 func (c *ConfigRepository) SetTrace(value string) {
 	c.write(func() {
 		c.data.Trace = value
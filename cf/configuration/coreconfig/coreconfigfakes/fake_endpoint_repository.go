@@ -18,6 +18,14 @@ type FakeEndpointRepository struct {
 		result2 string
 		result3 error
 	}
+	PingUAAStub        func(uaaEndpoint string) error
+	pingUAAMutex       sync.RWMutex
+	pingUAAArgsForCall []struct {
+		uaaEndpoint string
+	}
+	pingUAAReturns struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -57,11 +65,46 @@ func (fake *FakeEndpointRepository) GetCCInfoReturns(result1 *coreconfig.CCInfo,
 	}{result1, result2, result3}
 }
 
+func (fake *FakeEndpointRepository) PingUAA(uaaEndpoint string) error {
+	fake.pingUAAMutex.Lock()
+	fake.pingUAAArgsForCall = append(fake.pingUAAArgsForCall, struct {
+		uaaEndpoint string
+	}{uaaEndpoint})
+	fake.recordInvocation("PingUAA", []interface{}{uaaEndpoint})
+	fake.pingUAAMutex.Unlock()
+	if fake.PingUAAStub != nil {
+		return fake.PingUAAStub(uaaEndpoint)
+	} else {
+		return fake.pingUAAReturns.result1
+	}
+}
+
+func (fake *FakeEndpointRepository) PingUAACallCount() int {
+	fake.pingUAAMutex.RLock()
+	defer fake.pingUAAMutex.RUnlock()
+	return len(fake.pingUAAArgsForCall)
+}
+
+func (fake *FakeEndpointRepository) PingUAAArgsForCall(i int) string {
+	fake.pingUAAMutex.RLock()
+	defer fake.pingUAAMutex.RUnlock()
+	return fake.pingUAAArgsForCall[i].uaaEndpoint
+}
+
+func (fake *FakeEndpointRepository) PingUAAReturns(result1 error) {
+	fake.PingUAAStub = nil
+	fake.pingUAAReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeEndpointRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.getCCInfoMutex.RLock()
 	defer fake.getCCInfoMutex.RUnlock()
+	fake.pingUAAMutex.RLock()
+	defer fake.pingUAAMutex.RUnlock()
 	return fake.invocations
 }
 
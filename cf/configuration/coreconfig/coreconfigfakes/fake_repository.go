@@ -176,6 +176,18 @@ type FakeRepository struct {
 	asyncTimeoutReturns     struct {
 		result1 uint
 	}
+	UAADialTimeoutStub        func() uint
+	uAADialTimeoutMutex       sync.RWMutex
+	uAADialTimeoutArgsForCall []struct{}
+	uAADialTimeoutReturns     struct {
+		result1 uint
+	}
+	ResultsPerPageStub        func() uint
+	resultsPerPageMutex       sync.RWMutex
+	resultsPerPageArgsForCall []struct{}
+	resultsPerPageReturns     struct {
+		result1 uint
+	}
 	TraceStub        func() string
 	traceMutex       sync.RWMutex
 	traceArgsForCall []struct{}
@@ -288,6 +300,16 @@ type FakeRepository struct {
 	setAsyncTimeoutArgsForCall []struct {
 		arg1 uint
 	}
+	SetUAADialTimeoutStub        func(uint)
+	setUAADialTimeoutMutex       sync.RWMutex
+	setUAADialTimeoutArgsForCall []struct {
+		arg1 uint
+	}
+	SetResultsPerPageStub        func(uint)
+	setResultsPerPageMutex       sync.RWMutex
+	setResultsPerPageArgsForCall []struct {
+		arg1 uint
+	}
 	SetTraceStub        func(string)
 	setTraceMutex       sync.RWMutex
 	setTraceArgsForCall []struct {
@@ -1003,12 +1025,48 @@ func (fake *FakeRepository) AsyncTimeout() uint {
 	}
 }
 
+func (fake *FakeRepository) UAADialTimeout() uint {
+	fake.uAADialTimeoutMutex.Lock()
+	fake.uAADialTimeoutArgsForCall = append(fake.uAADialTimeoutArgsForCall, struct{}{})
+	fake.recordInvocation("UAADialTimeout", []interface{}{})
+	fake.uAADialTimeoutMutex.Unlock()
+	if fake.UAADialTimeoutStub != nil {
+		return fake.UAADialTimeoutStub()
+	} else {
+		return fake.uAADialTimeoutReturns.result1
+	}
+}
+
+func (fake *FakeRepository) ResultsPerPage() uint {
+	fake.resultsPerPageMutex.Lock()
+	fake.resultsPerPageArgsForCall = append(fake.resultsPerPageArgsForCall, struct{}{})
+	fake.recordInvocation("ResultsPerPage", []interface{}{})
+	fake.resultsPerPageMutex.Unlock()
+	if fake.ResultsPerPageStub != nil {
+		return fake.ResultsPerPageStub()
+	} else {
+		return fake.resultsPerPageReturns.result1
+	}
+}
+
 func (fake *FakeRepository) AsyncTimeoutCallCount() int {
 	fake.asyncTimeoutMutex.RLock()
 	defer fake.asyncTimeoutMutex.RUnlock()
 	return len(fake.asyncTimeoutArgsForCall)
 }
 
+func (fake *FakeRepository) UAADialTimeoutCallCount() int {
+	fake.uAADialTimeoutMutex.RLock()
+	defer fake.uAADialTimeoutMutex.RUnlock()
+	return len(fake.uAADialTimeoutArgsForCall)
+}
+
+func (fake *FakeRepository) ResultsPerPageCallCount() int {
+	fake.resultsPerPageMutex.RLock()
+	defer fake.resultsPerPageMutex.RUnlock()
+	return len(fake.resultsPerPageArgsForCall)
+}
+
 func (fake *FakeRepository) AsyncTimeoutReturns(result1 uint) {
 	fake.AsyncTimeoutStub = nil
 	fake.asyncTimeoutReturns = struct {
@@ -1016,6 +1074,20 @@ func (fake *FakeRepository) AsyncTimeoutReturns(result1 uint) {
 	}{result1}
 }
 
+func (fake *FakeRepository) UAADialTimeoutReturns(result1 uint) {
+	fake.UAADialTimeoutStub = nil
+	fake.uAADialTimeoutReturns = struct {
+		result1 uint
+	}{result1}
+}
+
+func (fake *FakeRepository) ResultsPerPageReturns(result1 uint) {
+	fake.ResultsPerPageStub = nil
+	fake.resultsPerPageReturns = struct {
+		result1 uint
+	}{result1}
+}
+
 func (fake *FakeRepository) Trace() string {
 	fake.traceMutex.Lock()
 	fake.traceArgsForCall = append(fake.traceArgsForCall, struct{}{})
@@ -1528,18 +1600,66 @@ func (fake *FakeRepository) SetAsyncTimeout(arg1 uint) {
 	}
 }
 
+func (fake *FakeRepository) SetUAADialTimeout(arg1 uint) {
+	fake.setUAADialTimeoutMutex.Lock()
+	fake.setUAADialTimeoutArgsForCall = append(fake.setUAADialTimeoutArgsForCall, struct {
+		arg1 uint
+	}{arg1})
+	fake.recordInvocation("SetUAADialTimeout", []interface{}{arg1})
+	fake.setUAADialTimeoutMutex.Unlock()
+	if fake.SetUAADialTimeoutStub != nil {
+		fake.SetUAADialTimeoutStub(arg1)
+	}
+}
+
+func (fake *FakeRepository) SetResultsPerPage(arg1 uint) {
+	fake.setResultsPerPageMutex.Lock()
+	fake.setResultsPerPageArgsForCall = append(fake.setResultsPerPageArgsForCall, struct {
+		arg1 uint
+	}{arg1})
+	fake.recordInvocation("SetResultsPerPage", []interface{}{arg1})
+	fake.setResultsPerPageMutex.Unlock()
+	if fake.SetResultsPerPageStub != nil {
+		fake.SetResultsPerPageStub(arg1)
+	}
+}
+
 func (fake *FakeRepository) SetAsyncTimeoutCallCount() int {
 	fake.setAsyncTimeoutMutex.RLock()
 	defer fake.setAsyncTimeoutMutex.RUnlock()
 	return len(fake.setAsyncTimeoutArgsForCall)
 }
 
+func (fake *FakeRepository) SetUAADialTimeoutCallCount() int {
+	fake.setUAADialTimeoutMutex.RLock()
+	defer fake.setUAADialTimeoutMutex.RUnlock()
+	return len(fake.setUAADialTimeoutArgsForCall)
+}
+
+func (fake *FakeRepository) SetResultsPerPageCallCount() int {
+	fake.setResultsPerPageMutex.RLock()
+	defer fake.setResultsPerPageMutex.RUnlock()
+	return len(fake.setResultsPerPageArgsForCall)
+}
+
 func (fake *FakeRepository) SetAsyncTimeoutArgsForCall(i int) uint {
 	fake.setAsyncTimeoutMutex.RLock()
 	defer fake.setAsyncTimeoutMutex.RUnlock()
 	return fake.setAsyncTimeoutArgsForCall[i].arg1
 }
 
+func (fake *FakeRepository) SetUAADialTimeoutArgsForCall(i int) uint {
+	fake.setUAADialTimeoutMutex.RLock()
+	defer fake.setUAADialTimeoutMutex.RUnlock()
+	return fake.setUAADialTimeoutArgsForCall[i].arg1
+}
+
+func (fake *FakeRepository) SetResultsPerPageArgsForCall(i int) uint {
+	fake.setResultsPerPageMutex.RLock()
+	defer fake.setResultsPerPageMutex.RUnlock()
+	return fake.setResultsPerPageArgsForCall[i].arg1
+}
+
 func (fake *FakeRepository) SetTrace(arg1 string) {
 	fake.setTraceMutex.Lock()
 	fake.setTraceArgsForCall = append(fake.setTraceArgsForCall, struct {
@@ -1757,6 +1877,10 @@ func (fake *FakeRepository) Invocations() map[string][][]interface{} {
 	defer fake.cLIVersionMutex.RUnlock()
 	fake.asyncTimeoutMutex.RLock()
 	defer fake.asyncTimeoutMutex.RUnlock()
+	fake.uAADialTimeoutMutex.RLock()
+	defer fake.uAADialTimeoutMutex.RUnlock()
+	fake.resultsPerPageMutex.RLock()
+	defer fake.resultsPerPageMutex.RUnlock()
 	fake.traceMutex.RLock()
 	defer fake.traceMutex.RUnlock()
 	fake.colorEnabledMutex.RLock()
@@ -1801,6 +1925,10 @@ func (fake *FakeRepository) Invocations() map[string][][]interface{} {
 	defer fake.setSSLDisabledMutex.RUnlock()
 	fake.setAsyncTimeoutMutex.RLock()
 	defer fake.setAsyncTimeoutMutex.RUnlock()
+	fake.setUAADialTimeoutMutex.RLock()
+	defer fake.setUAADialTimeoutMutex.RUnlock()
+	fake.setResultsPerPageMutex.RLock()
+	defer fake.setResultsPerPageMutex.RUnlock()
 	fake.setTraceMutex.RLock()
 	defer fake.setTraceMutex.RUnlock()
 	fake.setColorEnabledMutex.RLock()
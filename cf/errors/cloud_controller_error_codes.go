@@ -5,6 +5,9 @@ const (
 	InvalidRelation                        = "1002"
 	NotAuthorized                          = "10003"
 	BadQueryParameter                      = "10005"
+	UnprocessableEntity                    = "10008"
+	RateLimitExceeded                      = "10013"
+	ServiceUnavailable                     = "10015"
 	UserNotFound                           = "20003"
 	OrganizationNameTaken                  = "30002"
 	SpaceNameTaken                         = "40002"
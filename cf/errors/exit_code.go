@@ -0,0 +1,36 @@
+package errors
+
+// Exit codes for failed commands, so that scripts can tell a failure worth
+// retrying from one that needs different input:
+//
+//	ExitCodeFailure   (1) any failure not covered below
+//	ExitCodeUserInput (2) the input needs fixing, e.g. an invalid role or a
+//	                      user, org or space that does not exist
+//	ExitCodeBackend   (3) the request did not complete, e.g. a network
+//	                      failure, a timeout or a server error; retrying may
+//	                      succeed
+const (
+	ExitCodeFailure   = 1
+	ExitCodeUserInput = 2
+	ExitCodeBackend   = 3
+)
+
+// ExitCoder is implemented by errors that choose their own exit code.
+type ExitCoder interface {
+	ExitCode() int
+}
+
+// ExitCode returns the exit code a command failing with err should exit with.
+func ExitCode(err error) int {
+	switch typedErr := err.(type) {
+	case ExitCoder:
+		return typedErr.ExitCode()
+	case *ModelNotFoundError:
+		return ExitCodeUserInput
+	case HTTPError:
+		if typedErr.StatusCode() >= 500 {
+			return ExitCodeBackend
+		}
+	}
+	return ExitCodeFailure
+}
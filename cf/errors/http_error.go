@@ -10,20 +10,28 @@ import (
 
 type HTTPError interface {
 	Error() string
-	StatusCode() int   // actual HTTP status code
-	ErrorCode() string // error code returned in response body from CC or UAA
+	StatusCode() int     // actual HTTP status code
+	ErrorCode() string   // error code returned in response body from CC or UAA
+	Description() string // description returned in response body from CC or UAA
 }
 
 type baseHTTPError struct {
 	statusCode   int
 	apiErrorCode string
 	description  string
+	requestID    string
 }
 
 type HTTPNotFoundError struct {
 	baseHTTPError
 }
 
+// HTTPPreconditionFailedError is a 412 response: the resource changed after
+// the ETag sent in If-Match was read.
+type HTTPPreconditionFailedError struct {
+	baseHTTPError
+}
+
 func NewHTTPError(statusCode int, code string, description string) error {
 	err := baseHTTPError{
 		statusCode:   statusCode,
@@ -33,6 +41,8 @@ func NewHTTPError(statusCode int, code string, description string) error {
 	switch statusCode {
 	case 404:
 		return &HTTPNotFoundError{err}
+	case 412:
+		return &HTTPPreconditionFailedError{err}
 	default:
 		return &err
 	}
@@ -43,13 +53,42 @@ func (err *baseHTTPError) StatusCode() int {
 }
 
 func (err *baseHTTPError) Error() string {
-	return fmt.Sprintf(T("Server error, status code: {{.ErrStatusCode}}, error code: {{.ErrAPIErrorCode}}, message: {{.ErrDescription}}",
+	message := fmt.Sprintf(T("Server error, status code: {{.ErrStatusCode}}, error code: {{.ErrAPIErrorCode}}, message: {{.ErrDescription}}",
 		map[string]interface{}{"ErrStatusCode": err.statusCode,
 			"ErrAPIErrorCode": err.apiErrorCode,
 			"ErrDescription":  err.description}),
 	)
+	if err.requestID != "" {
+		message += " " + T("(request-id: {{.RequestID}})", map[string]interface{}{"RequestID": err.requestID})
+	}
+	return message
+}
+
+// RequestID is the X-VCAP-Request-ID of the failed response, or empty when
+// the server sent none.
+func (err *baseHTTPError) RequestID() string {
+	return err.requestID
+}
+
+// WithRequestID records requestID on an error made by NewHTTPError, so that
+// its message can be matched with the server's logs. Other errors are
+// returned unchanged.
+func WithRequestID(err error, requestID string) error {
+	switch httpErr := err.(type) {
+	case *baseHTTPError:
+		httpErr.requestID = requestID
+	case *HTTPNotFoundError:
+		httpErr.requestID = requestID
+	case *HTTPPreconditionFailedError:
+		httpErr.requestID = requestID
+	}
+	return err
 }
 
 func (err *baseHTTPError) ErrorCode() string {
 	return err.apiErrorCode
 }
+
+func (err *baseHTTPError) Description() string {
+	return err.description
+}
@@ -0,0 +1,19 @@
+package errors
+
+// InvalidInputError is returned when a command's arguments are invalid, such
+// as an unknown role name. It exits with ExitCodeUserInput.
+type InvalidInputError struct {
+	Message string
+}
+
+func NewInvalidInputError(message string) error {
+	return &InvalidInputError{Message: message}
+}
+
+func (err *InvalidInputError) Error() string {
+	return err.Message
+}
+
+func (err *InvalidInputError) ExitCode() int {
+	return ExitCodeUserInput
+}
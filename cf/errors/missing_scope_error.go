@@ -0,0 +1,18 @@
+package errors
+
+import . "code.cloudfoundry.org/cli/cf/i18n"
+
+// MissingScopeError is returned instead of making a request the access
+// token lacks the scope for.
+type MissingScopeError struct {
+	Scopes []string
+}
+
+func NewMissingScopeError(scopes ...string) error {
+	return &MissingScopeError{Scopes: scopes}
+}
+
+func (err *MissingScopeError) Error() string {
+	return T("Your access token needs one of the scopes {{.Scopes}} to do this. Log in as an admin and try again.",
+		map[string]interface{}{"Scopes": err.Scopes})
+}
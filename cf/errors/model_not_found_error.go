@@ -4,9 +4,21 @@ import (
 	. "code.cloudfoundry.org/cli/cf/i18n"
 )
 
+// ResourceKind identifies which kind of resource a lookup failed to find.
+type ResourceKind string
+
+const (
+	UnknownResource ResourceKind = ""
+	OrgResource     ResourceKind = "Organization"
+	SpaceResource   ResourceKind = "Space"
+	UserResource    ResourceKind = "User"
+	GroupResource   ResourceKind = "Group"
+)
+
 type ModelNotFoundError struct {
 	ModelType string
 	ModelName string
+	Kind      ResourceKind
 }
 
 func NewModelNotFoundError(modelType, name string) error {
@@ -16,6 +28,17 @@ func NewModelNotFoundError(modelType, name string) error {
 	}
 }
 
+// NewNotFoundError returns a ModelNotFoundError tagged with the kind of
+// resource that was missing, so commands resolving an org, space and user in
+// sequence can tell which lookup failed.
+func NewNotFoundError(kind ResourceKind, name string) error {
+	return &ModelNotFoundError{
+		ModelType: string(kind),
+		ModelName: name,
+		Kind:      kind,
+	}
+}
+
 func (err *ModelNotFoundError) Error() string {
 	return err.ModelType + " " + err.ModelName + T(" not found")
 }
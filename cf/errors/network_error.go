@@ -0,0 +1,23 @@
+package errors
+
+// NetworkError is returned when a request fails before any response arrives,
+// such as when the host cannot be reached. It exits with ExitCodeBackend.
+type NetworkError struct {
+	Message string
+}
+
+func NewNetworkError(message string) error {
+	return &NetworkError{Message: message}
+}
+
+func (err *NetworkError) Error() string {
+	return err.Message
+}
+
+func (err *NetworkError) ExitCode() int {
+	return ExitCodeBackend
+}
+
+func (err *NetworkError) Retryable() bool {
+	return true
+}
@@ -0,0 +1,30 @@
+package errors
+
+import (
+	"fmt"
+
+	. "code.cloudfoundry.org/cli/cf/i18n"
+)
+
+// NetworkTimeoutError is returned when a request to Host times out before a
+// response arrives.
+type NetworkTimeoutError struct {
+	Host string
+	Err  error
+}
+
+func NewNetworkTimeoutError(host string, err error) error {
+	return &NetworkTimeoutError{Host: host, Err: err}
+}
+
+func (err *NetworkTimeoutError) Error() string {
+	return fmt.Sprintf("%s: %s", T("Error performing request"), err.Err.Error())
+}
+
+func (err *NetworkTimeoutError) ExitCode() int {
+	return ExitCodeBackend
+}
+
+func (err *NetworkTimeoutError) Retryable() bool {
+	return true
+}
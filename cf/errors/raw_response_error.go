@@ -0,0 +1,26 @@
+package errors
+
+import (
+	. "code.cloudfoundry.org/cli/cf/i18n"
+)
+
+// RawResponseError is an HTTPError that also carries the status line and
+// body of the response it was built from.
+type RawResponseError struct {
+	HTTPError
+	RawStatus string
+	RawBody   string
+}
+
+func NewRawResponseError(err HTTPError, status, body string) error {
+	return &RawResponseError{
+		HTTPError: err,
+		RawStatus: status,
+		RawBody:   body,
+	}
+}
+
+func (err *RawResponseError) Error() string {
+	return err.HTTPError.Error() + "\n" + T("Raw response: {{.Status}}\n{{.Body}}",
+		map[string]interface{}{"Status": err.RawStatus, "Body": err.RawBody})
+}
@@ -0,0 +1,24 @@
+package errors
+
+import (
+	. "code.cloudfoundry.org/cli/cf/i18n"
+)
+
+// RetriedHTTPError is an HTTPError returned after the request was attempted
+// more than once.
+type RetriedHTTPError struct {
+	HTTPError
+	Attempts int
+}
+
+func NewRetriedHTTPError(err HTTPError, attempts int) error {
+	return &RetriedHTTPError{
+		HTTPError: err,
+		Attempts:  attempts,
+	}
+}
+
+func (err *RetriedHTTPError) Error() string {
+	return T("{{.Err}} (gave up after {{.Attempts}} attempts)",
+		map[string]interface{}{"Err": err.HTTPError.Error(), "Attempts": err.Attempts})
+}
@@ -0,0 +1,56 @@
+package errors
+
+// RetryableError is implemented by errors that know whether the failed
+// operation is safe to try again.
+type RetryableError interface {
+	Retryable() bool
+}
+
+// retryableErrorCodes says, for the Cloud Controller error codes the CLI
+// knows about, whether the failure can clear up on its own. Codes not listed
+// here are judged by their HTTP status.
+var retryableErrorCodes = map[string]bool{
+	MessageParseError:                      false,
+	InvalidRelation:                        false,
+	NotAuthorized:                          false,
+	BadQueryParameter:                      false,
+	RateLimitExceeded:                      true,
+	ServiceUnavailable:                     true,
+	UserNotFound:                           false,
+	OrganizationNameTaken:                  false,
+	SpaceNameTaken:                         false,
+	ServiceInstanceNameTaken:               false,
+	ServiceBindingAppServiceTaken:          false,
+	UnbindableService:                      false,
+	ServiceInstanceAlreadyBoundToSameRoute: false,
+	NotStaged:                              true,
+	InstancesError:                         false,
+	QuotaDefinitionNameTaken:               false,
+	BuildpackNameTaken:                     false,
+	SecurityGroupNameTaken:                 false,
+	ServiceKeyNameTaken:                    false,
+}
+
+// retryableStatusCodes are the HTTP statuses of a rate-limited request or of
+// a server or proxy that is briefly unavailable.
+var retryableStatusCodes = map[int]bool{
+	429: true,
+	502: true,
+	503: true,
+	504: true,
+}
+
+// IsRetryable reports whether the operation that failed with err may succeed
+// if tried again after backing off.
+func IsRetryable(err error) bool {
+	switch typedErr := err.(type) {
+	case RetryableError:
+		return typedErr.Retryable()
+	case HTTPError:
+		if retryable, ok := retryableErrorCodes[typedErr.ErrorCode()]; ok {
+			return retryable
+		}
+		return retryableStatusCodes[typedErr.StatusCode()]
+	}
+	return false
+}
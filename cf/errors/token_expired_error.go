@@ -0,0 +1,22 @@
+package errors
+
+import (
+	"time"
+
+	. "code.cloudfoundry.org/cli/cf/i18n"
+)
+
+// TokenExpiredError is returned instead of making a request with an access
+// token that has already expired.
+type TokenExpiredError struct {
+	ExpiredAt time.Time
+}
+
+func NewTokenExpiredError(expiredAt time.Time) error {
+	return &TokenExpiredError{ExpiredAt: expiredAt}
+}
+
+func (err *TokenExpiredError) Error() string {
+	return T("Access token expired at {{.ExpiredAt}}. Log in again.",
+		map[string]interface{}{"ExpiredAt": err.ExpiredAt.Format(time.RFC3339)})
+}
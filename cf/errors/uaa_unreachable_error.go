@@ -0,0 +1,32 @@
+package errors
+
+import (
+	. "code.cloudfoundry.org/cli/cf/i18n"
+)
+
+// UAAUnreachableError is returned when no response at all comes back from
+// the UAA at Endpoint. It exits with ExitCodeBackend.
+type UAAUnreachableError struct {
+	Endpoint string
+	Err      error
+}
+
+func NewUAAUnreachableError(endpoint string, err error) error {
+	return &UAAUnreachableError{Endpoint: endpoint, Err: err}
+}
+
+func (err *UAAUnreachableError) Error() string {
+	return T("UAA endpoint {{.Endpoint}} is unreachable.\n{{.Err}}",
+		map[string]interface{}{
+			"Endpoint": err.Endpoint,
+			"Err":      err.Err.Error(),
+		})
+}
+
+func (err *UAAUnreachableError) ExitCode() int {
+	return ExitCodeBackend
+}
+
+func (err *UAAUnreachableError) Retryable() bool {
+	return true
+}
@@ -261,15 +261,23 @@ func newAppPresenter() appPresenter {
 			CommandSubGroups: [][]cmdPresenter{
 				{
 					presentCommand("create-user"),
+					presentCommand("create-users-from-csv"),
 					presentCommand("delete-user"),
+					presentCommand("update-user-password"),
+					presentCommand("reconcile-users"),
+					presentCommand("list-all-users"),
 				}, {
 					presentCommand("org-users"),
 					presentCommand("set-org-role"),
 					presentCommand("unset-org-role"),
+					presentCommand("user-memberships"),
 				}, {
 					presentCommand("space-users"),
 					presentCommand("set-space-role"),
 					presentCommand("unset-space-role"),
+					presentCommand("diff-space-users"),
+				}, {
+					presentCommand("set-role-for-users"),
 				},
 			},
 		}, {
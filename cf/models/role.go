@@ -1,6 +1,6 @@
 package models
 
-import "errors"
+import "code.cloudfoundry.org/cli/cf/errors"
 
 type Role int
 
@@ -15,7 +15,7 @@ const (
 	RoleSpaceAuditor
 )
 
-var ErrUnknownRole = errors.New("Unknown Role")
+var ErrUnknownRole = errors.NewInvalidInputError("Unknown Role")
 
 func RoleFromString(roleString string) (Role, error) {
 	switch roleString {
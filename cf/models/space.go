@@ -14,4 +14,13 @@ type Space struct {
 	Domains          []DomainFields
 	SecurityGroups   []SecurityGroupFields
 	SpaceQuotaGUID   string
+	CreatedAt        string
+}
+
+// SpaceSummary totals the apps and service instances in a space. MemoryUsage
+// is in megabytes, counting every instance of each started app.
+type SpaceSummary struct {
+	AppsCount     int
+	ServicesCount int
+	MemoryUsage   int64
 }
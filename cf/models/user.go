@@ -1,8 +1,127 @@
 package models
 
+import "time"
+
 type UserFields struct {
-	GUID     string
+	GUID      string
+	Username  string
+	Password  string
+	IsAdmin   bool
+	Origin    string
+	Email     string
+	LastLogon time.Time
+
+	// CreatedAt and UpdatedAt are the Cloud Controller's timestamps for
+	// the user. UAACreatedAt is when the UAA account was created, and is
+	// only read by repositories built WithCreationTimestamps.
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	UAACreatedAt time.Time
+
+	// Active is only read for users listed straight from UAA.
+	Active bool
+}
+
+// UserSpaceRoles lists the roles a user holds in a single space.
+type UserSpaceRoles struct {
+	Space SpaceFields
+	Roles []Role
+}
+
+// OrgUserWithSpaceRoles is an org member along with the roles they hold in
+// each of the org's spaces.
+type OrgUserWithSpaceRoles struct {
+	UserFields
+	Spaces []UserSpaceRoles
+}
+
+// DuplicateUserRegistration is a UAA user that more than one Cloud
+// Controller user record resolves to.
+type DuplicateUserRegistration struct {
+	UAAID    string
+	Username string
+	CCGUIDs  []string
+}
+
+// UnmatchedUsers are the users that only one of the Cloud Controller and UAA
+// has a record of, such as those left behind by an async delete.
+type UnmatchedUsers struct {
+	CCOnly  []UserFields
+	UAAOnly []UserFields
+}
+
+// RoleChangeResult is the outcome of changing one user's role as part of a
+// bulk change. Changed is false when the user was already in the desired
+// state. Role is set when one user is given several roles at once.
+type RoleChangeResult struct {
+	UserGUID string
+	Role     Role
+	Changed  bool
+	Err      error
+}
+
+// RequestPreview is a Cloud Controller request a change would send. Body is
+// empty for requests without one.
+type RequestPreview struct {
+	Method string
+	URL    string
+	Body   string
+}
+
+// UserCreateRequest is one user to create as part of a bulk creation.
+type UserCreateRequest struct {
 	Username string
 	Password string
-	IsAdmin  bool
+}
+
+// UserCreateResult is the outcome of creating one user as part of a bulk
+// creation. User is empty when the user was not created.
+type UserCreateResult struct {
+	User UserFields
+	Err  error
+}
+
+// AccessGrant is one role a user holds. SpaceGUID and SpaceName are empty
+// for org roles.
+type AccessGrant struct {
+	Role      Role
+	OrgGUID   string
+	OrgName   string
+	SpaceGUID string
+	SpaceName string
+}
+
+// UserAccessSummary lists every org and space role a user holds.
+type UserAccessSummary struct {
+	UserGUID string
+	Grants   []AccessGrant
+}
+
+// UserMemberships is every org a user belongs to, with the roles they hold
+// in it and in each of its spaces.
+type UserMemberships struct {
+	UserGUID string
+	Orgs     []OrgMembership
+}
+
+// OrgMembership is a user's roles in one org and its spaces. Roles is empty
+// when the user holds only space roles in the org.
+type OrgMembership struct {
+	Org    OrganizationFields
+	Roles  []Role
+	Spaces []SpaceMembership
+}
+
+// SpaceMembership is a user's roles in one space.
+type SpaceMembership struct {
+	Space SpaceFields
+	Roles []Role
+}
+
+// SpaceRoleDiff lists the holders of one role who have it in only one of
+// two compared spaces.
+type SpaceRoleDiff struct {
+	Role    Role
+	OnlyInA []UserFields
+	OnlyInB []UserFields
 }
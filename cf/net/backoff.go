@@ -0,0 +1,41 @@
+package net
+
+import (
+	"math/rand"
+	"time"
+)
+
+// BackoffStrategy decides how long to wait before a retry. attempt is 1 for
+// the first retry.
+type BackoffStrategy interface {
+	NextDelay(attempt int) time.Duration
+}
+
+// ConstantBackoff waits the same time before every retry.
+type ConstantBackoff time.Duration
+
+func (backoff ConstantBackoff) NextDelay(attempt int) time.Duration {
+	return time.Duration(backoff)
+}
+
+// JitteredExponentialBackoff doubles the wait from Base with each attempt,
+// up to Max, and waits a random time between half that and all of it so
+// that clients retrying together spread out.
+type JitteredExponentialBackoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+func (backoff JitteredExponentialBackoff) NextDelay(attempt int) time.Duration {
+	delay := backoff.Base
+	for i := 1; i < attempt && delay < backoff.Max; i++ {
+		delay *= 2
+	}
+	if delay > backoff.Max {
+		delay = backoff.Max
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
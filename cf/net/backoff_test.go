@@ -0,0 +1,30 @@
+package net_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/cli/cf/net"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JitteredExponentialBackoff", func() {
+	var backoff net.JitteredExponentialBackoff
+
+	BeforeEach(func() {
+		backoff = net.JitteredExponentialBackoff{Base: 100 * time.Millisecond, Max: time.Second}
+	})
+
+	It("doubles the wait with each attempt, with up to half of it jittered", func() {
+		for i := 0; i < 20; i++ {
+			Expect(backoff.NextDelay(1)).To(BeNumerically("~", 75*time.Millisecond, 25*time.Millisecond))
+			Expect(backoff.NextDelay(3)).To(BeNumerically("~", 300*time.Millisecond, 100*time.Millisecond))
+		}
+	})
+
+	It("never waits longer than Max", func() {
+		for i := 0; i < 20; i++ {
+			Expect(backoff.NextDelay(30)).To(BeNumerically("<=", time.Second))
+		}
+	})
+})
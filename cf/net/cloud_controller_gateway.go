@@ -3,6 +3,7 @@ package net
 import (
 	"encoding/json"
 	"strconv"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
@@ -31,14 +32,17 @@ func cloudControllerErrorHandler(statusCode int, body []byte) error {
 
 func NewCloudControllerGateway(config coreconfig.Reader, clock func() time.Time, ui terminal.UI, logger trace.Printer, envDialTimeout string) Gateway {
 	return Gateway{
-		errHandler:      cloudControllerErrorHandler,
-		config:          config,
-		PollingThrottle: DefaultPollingThrottle,
-		warnings:        &[]string{},
-		Clock:           clock,
-		ui:              ui,
-		logger:          logger,
-		PollingEnabled:  true,
-		DialTimeout:     dialTimeout(envDialTimeout),
+		errHandler:       cloudControllerErrorHandler,
+		config:           config,
+		PollingThrottle:  DefaultPollingThrottle,
+		ReadRetryBackoff: DefaultBackoff,
+		RetryBackoff:     DefaultBackoff,
+		warnings:         &[]string{},
+		warningsMutex:    &sync.Mutex{},
+		Clock:            clock,
+		ui:               ui,
+		logger:           logger,
+		PollingEnabled:   true,
+		DialTimeout:      dialTimeout(envDialTimeout),
 	}
 }
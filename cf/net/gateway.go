@@ -2,6 +2,7 @@ package net
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
@@ -14,6 +15,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
@@ -29,8 +31,25 @@ const (
 	JobFailed              = "failed"
 	DefaultPollingThrottle = 5 * time.Second
 	DefaultDialTimeout     = 5 * time.Second
+
+	// MaxResultsPerPage is the largest page the Cloud Controller serves.
+	MaxResultsPerPage = 100
+
+	// DefaultMaxRetries and DefaultReadRetries are the retry budgets the
+	// repository locator gives its gateways.
+	DefaultMaxRetries  = 2
+	DefaultReadRetries = 2
+
+	DefaultReadRetryBackoff    = 500 * time.Millisecond
+	DefaultMaxReadRetryBackoff = 10 * time.Second
 )
 
+// DefaultBackoff is the read retry backoff the gateways start with.
+var DefaultBackoff BackoffStrategy = JitteredExponentialBackoff{
+	Base: DefaultReadRetryBackoff,
+	Max:  DefaultMaxReadRetryBackoff,
+}
+
 type JobResource struct {
 	Entity struct {
 		Status       string
@@ -55,6 +74,25 @@ type tokenRefresher interface {
 type Request struct {
 	HTTPReq      *http.Request
 	SeekableBody io.ReadSeeker
+
+	// BodyFactory makes a fresh copy of the body for each retry, so a body
+	// that was partly read by a failed attempt is never sent again.
+	BodyFactory func() (io.ReadSeeker, error)
+}
+
+// resetBody replaces the body with a fresh one from BodyFactory before the
+// request is made again.
+func (request *Request) resetBody() error {
+	if request.BodyFactory == nil {
+		return nil
+	}
+	body, err := request.BodyFactory()
+	if err != nil {
+		return err
+	}
+	request.SeekableBody = body
+	request.HTTPReq.Body = ioutil.NopCloser(body)
+	return nil
 }
 
 type Gateway struct {
@@ -65,11 +103,69 @@ type Gateway struct {
 	trustedCerts    []tls.Certificate
 	config          coreconfig.Reader
 	warnings        *[]string
+	warningsMutex   *sync.Mutex
 	Clock           func() time.Time
 	transport       *http.Transport
 	ui              terminal.UI
 	logger          trace.Printer
 	DialTimeout     time.Duration
+
+	// TLSHandshakeTimeout limits the TLS handshake separately from the dial.
+	// Zero means no limit.
+	TLSHandshakeTimeout time.Duration
+
+	// ReadRetries is how many more times a GET or HEAD request is attempted
+	// when the connection is reset or closed before a response arrives.
+	// ReadRetryBackoff decides the wait before each retry.
+	ReadRetries      int
+	ReadRetryBackoff BackoffStrategy
+
+	// MaxRetries is how many more times a GET, HEAD, PUT or DELETE request
+	// is attempted after a 5xx response or a reset connection. The body is
+	// recreated for each attempt. RetryBackoff decides the wait before each
+	// retry.
+	MaxRetries   int
+	RetryBackoff BackoffStrategy
+
+	// Headers are added to every request the gateway builds. They cannot
+	// replace the Authorization header, which always comes from the access
+	// token.
+	Headers http.Header
+
+	// RawErrorResponses attaches the sanitized status and body of failed
+	// responses to the returned HTTPError.
+	RawErrorResponses bool
+
+	// BeforeRequest, when set, is called before each request is sent. An
+	// error stops the request and is returned as is.
+	BeforeRequest func() error
+
+	// ResultsPerPage, when positive, is the page size asked for when listing
+	// paginated resources whose path does not already give one. It is capped
+	// at MaxResultsPerPage. Zero leaves it to the Cloud Controller.
+	ResultsPerPage int
+}
+
+// AddDefaultHeader adds a header to every request the gateway builds from
+// now on, alongside any value it already has.
+func (gateway *Gateway) AddDefaultHeader(key, value string) {
+	if gateway.Headers == nil {
+		gateway.Headers = http.Header{}
+	}
+	gateway.Headers.Add(key, value)
+}
+
+// WithHeader returns a copy of the gateway whose requests also carry the
+// header, replacing any default value it has. The gateway itself is left as
+// it was, so the header applies only to calls made through the copy.
+func (gateway Gateway) WithHeader(key, value string) Gateway {
+	headers := http.Header{}
+	for name, values := range gateway.Headers {
+		headers[name] = values
+	}
+	headers.Set(key, value)
+	gateway.Headers = headers
+	return gateway
 }
 
 func (gateway *Gateway) AsyncTimeout() time.Duration {
@@ -81,7 +177,11 @@ func (gateway *Gateway) AsyncTimeout() time.Duration {
 }
 
 func (gateway *Gateway) SetTokenRefresher(auth tokenRefresher) {
-	gateway.authenticator = auth
+	if auth == nil {
+		gateway.authenticator = nil
+		return
+	}
+	gateway.authenticator = newCoalescingTokenRefresher(auth)
 }
 
 func (gateway Gateway) GetResource(url string, resource interface{}) (err error) {
@@ -120,6 +220,16 @@ func (gateway Gateway) UpdateResource(endpoint, apiURL string, body io.ReadSeeke
 	return gateway.createUpdateOrDeleteResource("PUT", endpoint, apiURL, body, false, resource...)
 }
 
+// UpdateResourceIfMatch is UpdateResource sending etag in If-Match, so that
+// the update fails with an errors.HTTPPreconditionFailedError when the
+// resource has changed since etag was read. An empty etag sends no If-Match.
+func (gateway Gateway) UpdateResourceIfMatch(endpoint, apiURL, etag string, body io.ReadSeeker, resource ...interface{}) error {
+	if etag != "" {
+		gateway = gateway.WithHeader("If-Match", etag)
+	}
+	return gateway.UpdateResource(endpoint, apiURL, body, resource...)
+}
+
 func (gateway Gateway) UpdateResourceSync(endpoint, apiURL string, body io.ReadSeeker, resource ...interface{}) error {
 	return gateway.createUpdateOrDeleteResource("PUT", endpoint, apiURL, body, true, resource...)
 }
@@ -138,29 +248,95 @@ func (gateway Gateway) ListPaginatedResources(
 	resource interface{},
 	cb func(interface{}) bool,
 ) error {
-	for path != "" {
+	_, err := gateway.ListPaginatedResourcesWithTotalContext(context.Background(), target, path, resource, cb)
+	return err
+}
+
+// ListPaginatedResourcesWithContext is ListPaginatedResources that stops
+// before fetching the next page once ctx is cancelled and returns the
+// context's error. The resources of earlier pages have already been passed
+// to cb.
+func (gateway Gateway) ListPaginatedResourcesWithContext(
+	ctx context.Context,
+	target string,
+	path string,
+	resource interface{},
+	cb func(interface{}) bool,
+) error {
+	_, err := gateway.ListPaginatedResourcesWithTotalContext(ctx, target, path, resource, cb)
+	return err
+}
+
+// ListPaginatedResourcesWithTotal is ListPaginatedResources that also
+// returns the total_results the first page reported.
+func (gateway Gateway) ListPaginatedResourcesWithTotal(
+	target string,
+	path string,
+	resource interface{},
+	cb func(interface{}) bool,
+) (int, error) {
+	return gateway.ListPaginatedResourcesWithTotalContext(context.Background(), target, path, resource, cb)
+}
+
+// ListPaginatedResourcesWithTotalContext is ListPaginatedResourcesWithTotal
+// that can be cancelled between pages.
+func (gateway Gateway) ListPaginatedResourcesWithTotalContext(
+	ctx context.Context,
+	target string,
+	path string,
+	resource interface{},
+	cb func(interface{}) bool,
+) (int, error) {
+	total := 0
+	path = gateway.withResultsPerPage(path)
+	for page := 0; path != ""; page++ {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
 		pagination := NewPaginatedResources(resource)
 
 		apiErr := gateway.GetResource(fmt.Sprintf("%s%s", target, path), &pagination)
 		if apiErr != nil {
-			return apiErr
+			return total, apiErr
+		}
+		if page == 0 {
+			total = pagination.TotalResults
 		}
 
 		resources, err := pagination.Resources()
 		if err != nil {
-			return fmt.Errorf("%s: %s", T("Error parsing JSON"), err.Error())
+			return total, fmt.Errorf("%s: %s", T("Error parsing JSON"), err.Error())
 		}
 
 		for _, resource := range resources {
 			if !cb(resource) {
-				return nil
+				return total, nil
 			}
 		}
 
 		path = pagination.NextURL
 	}
 
-	return nil
+	return total, nil
+}
+
+// withResultsPerPage asks for the gateway's page size on the first page of a
+// listing. Later pages follow the Cloud Controller's next_url, which keeps
+// the page size.
+func (gateway Gateway) withResultsPerPage(path string) string {
+	perPage := gateway.ResultsPerPage
+	if perPage <= 0 || strings.Contains(path, "results-per-page=") {
+		return path
+	}
+	if perPage > MaxResultsPerPage {
+		perPage = MaxResultsPerPage
+	}
+
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return fmt.Sprintf("%s%sresults-per-page=%d", path, separator, perPage)
 }
 
 func (gateway Gateway) createUpdateOrDeleteResource(verb, endpoint, apiURL string, body io.ReadSeeker, sync bool, optionalResource ...interface{}) error {
@@ -192,7 +368,7 @@ func (gateway Gateway) createUpdateOrDeleteResource(verb, endpoint, apiURL strin
 	return nil
 }
 
-func (gateway Gateway) newRequest(request *http.Request, accessToken string, body io.ReadSeeker) *Request {
+func (gateway Gateway) newRequest(request *http.Request, accessToken string, body io.ReadSeeker, bodyFactory func() (io.ReadSeeker, error)) *Request {
 	if accessToken != "" {
 		request.Header.Set("Authorization", accessToken)
 	}
@@ -201,8 +377,14 @@ func (gateway Gateway) newRequest(request *http.Request, accessToken string, bod
 	request.Header.Set("Connection", "close")
 	request.Header.Set("content-type", "application/json")
 	request.Header.Set("User-Agent", "go-cli "+version.VersionString()+" / "+runtime.GOOS)
+	for name, values := range gateway.Headers {
+		if http.CanonicalHeaderKey(name) == "Authorization" {
+			continue
+		}
+		request.Header[name] = values
+	}
 
-	return &Request{HTTPReq: request, SeekableBody: body}
+	return &Request{HTTPReq: request, SeekableBody: body, BodyFactory: bodyFactory}
 }
 
 func (gateway Gateway) NewRequestForFile(method, fullURL, accessToken string, body *os.File) (*Request, error) {
@@ -227,15 +409,86 @@ func (gateway Gateway) NewRequestForFile(method, fullURL, accessToken string, bo
 		return nil, fmt.Errorf("%s: %s", T("Error building request"), err.Error())
 	}
 
-	return gateway.newRequest(request, accessToken, progressReader), nil
+	bodyFactory := func() (io.ReadSeeker, error) {
+		if _, err := body.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		reader := NewProgressReader(body, gateway.ui, 5*time.Second)
+		reader.SetTotalSize(fileSize)
+		return reader, nil
+	}
+
+	return gateway.newRequest(request, accessToken, progressReader, bodyFactory), nil
 }
 
+// NewRequest builds a request whose body is streamed from body. When the
+// body's length is not already known, it is found by seeking, so that the
+// body is sent with a Content-Length rather than chunked.
 func (gateway Gateway) NewRequest(method, path, accessToken string, body io.ReadSeeker) (*Request, error) {
 	request, err := http.NewRequest(method, path, body)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %s", T("Error building request"), err.Error())
 	}
-	return gateway.newRequest(request, accessToken, body), nil
+	if body != nil && request.ContentLength == 0 {
+		if length, err := remainingLength(body); err == nil && length > 0 {
+			request.ContentLength = length
+		}
+	}
+	var bodyFactory func() (io.ReadSeeker, error)
+	if body != nil {
+		bodyFactory, err = newBodyFactory(body)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", T("Error building request"), err.Error())
+		}
+	}
+	return gateway.newRequest(request, accessToken, body, bodyFactory), nil
+}
+
+// newBodyFactory returns a factory that recreates body as it is now. An
+// in-memory body is copied, so each attempt gets its own reader; any other
+// body is sought back to its current position.
+func newBodyFactory(body io.ReadSeeker) (func() (io.ReadSeeker, error), error) {
+	switch body.(type) {
+	case *bytes.Reader, *strings.Reader:
+		start, err := body.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		content, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = body.Seek(start, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return func() (io.ReadSeeker, error) {
+			return bytes.NewReader(content), nil
+		}, nil
+	default:
+		start, err := body.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		return func() (io.ReadSeeker, error) {
+			_, err := body.Seek(start, io.SeekStart)
+			return body, err
+		}, nil
+	}
+}
+
+// remainingLength returns how many bytes are left to read from body, leaving
+// its position unchanged.
+func remainingLength(body io.Seeker) (int64, error) {
+	current, err := body.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	end, err := body.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	_, err = body.Seek(current, io.SeekStart)
+	return end - current, err
 }
 
 func (gateway Gateway) PerformRequest(request *Request) (*http.Response, error) {
@@ -324,8 +577,16 @@ func (gateway Gateway) PerformPollingRequestForJSONResponse(endpoint string, req
 	return headers, err
 }
 
+// WaitForJob polls the job at jobPath, relative to endpoint, until it
+// finishes, fails or the timeout elapses. A zero timeout waits forever.
+func (gateway Gateway) WaitForJob(endpoint, jobPath string, timeout time.Duration) error {
+	return gateway.waitForJob(endpoint+jobPath, gateway.config.AccessToken(), timeout)
+}
+
 func (gateway Gateway) Warnings() []string {
-	return *gateway.warnings
+	gateway.warningsMutex.Lock()
+	defer gateway.warningsMutex.Unlock()
+	return append([]string{}, *gateway.warnings...)
 }
 
 func (gateway Gateway) waitForJob(jobURL, accessToken string, timeout time.Duration) error {
@@ -357,6 +618,12 @@ func (gateway Gateway) waitForJob(jobURL, accessToken string, timeout time.Durat
 }
 
 func (gateway Gateway) doRequestHandlingAuth(request *Request) (*http.Response, error) {
+	if gateway.BeforeRequest != nil {
+		if err := gateway.BeforeRequest(); err != nil {
+			return nil, err
+		}
+	}
+
 	httpReq := request.HTTPReq
 
 	if request.SeekableBody != nil {
@@ -364,7 +631,7 @@ func (gateway Gateway) doRequestHandlingAuth(request *Request) (*http.Response,
 	}
 
 	// perform request
-	rawResponse, err := gateway.doRequestAndHandlerError(request)
+	rawResponse, err := gateway.doRequestRetryingServerErrors(request)
 	if err == nil || gateway.authenticator == nil {
 		return rawResponse, err
 	}
@@ -380,18 +647,68 @@ func (gateway Gateway) doRequestHandlingAuth(request *Request) (*http.Response,
 
 		// reset the auth token and request body
 		httpReq.Header.Set("Authorization", newToken)
-		if request.SeekableBody != nil {
-			_, _ = request.SeekableBody.Seek(0, 0)
-			httpReq.Body = ioutil.NopCloser(request.SeekableBody)
+		if err = request.resetBody(); err != nil {
+			return rawResponse, err
 		}
 
 		// make the request again
-		rawResponse, err = gateway.doRequestAndHandlerError(request)
+		rawResponse, err = gateway.doRequestRetryingServerErrors(request)
 	}
 
 	return rawResponse, err
 }
 
+// doRequestRetryingServerErrors makes the request again while it fails in a
+// way retryPolicy allows retries for. Once the retries run out, the error
+// says how many attempts were made.
+func (gateway Gateway) doRequestRetryingServerErrors(request *Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		rawResponse, err := gateway.doRequestAndHandlerError(request)
+		if err == nil {
+			return rawResponse, err
+		}
+		retries, backoff := gateway.retryPolicy(request.HTTPReq.Method, err)
+		if attempt > retries {
+			if attempt == 1 {
+				return rawResponse, err
+			}
+			return rawResponse, retriedError(err, attempt)
+		}
+
+		time.Sleep(backoff.NextDelay(attempt))
+
+		if resetErr := request.resetBody(); resetErr != nil {
+			return rawResponse, resetErr
+		}
+	}
+}
+
+// retryPolicy says how many retries a request failing with err gets and how
+// long to wait before each. MaxRetries covers 5xx responses and reset
+// connections; ReadRetries covers reset connections on reads. A reset read
+// gets the larger of the two budgets, never both.
+func (gateway Gateway) retryPolicy(method string, err error) (int, BackoffStrategy) {
+	retries, backoff := 0, gateway.RetryBackoff
+	if isRetryable(method) && isServerError(err) {
+		retries = gateway.MaxRetries
+	}
+	if isIdempotent(method) && isConnectionReset(err) && gateway.ReadRetries > retries {
+		retries, backoff = gateway.ReadRetries, gateway.ReadRetryBackoff
+	}
+	if backoff == nil {
+		backoff = DefaultBackoff
+	}
+	return retries, backoff
+}
+
+func retriedError(err error, attempts int) error {
+	if httpErr, ok := err.(errors.HTTPError); ok {
+		return errors.NewRetriedHTTPError(httpErr, attempts)
+	}
+	return errors.NewNetworkError(T("{{.Err}} (gave up after {{.Attempts}} attempts)",
+		map[string]interface{}{"Err": err.Error(), "Attempts": attempts}))
+}
+
 func (gateway Gateway) doRequestAndHandlerError(request *Request) (*http.Response, error) {
 	rawResponse, err := gateway.doRequest(request.HTTPReq)
 	if err != nil {
@@ -403,6 +720,13 @@ func (gateway Gateway) doRequestAndHandlerError(request *Request) (*http.Respons
 		jsonBytes, _ := ioutil.ReadAll(rawResponse.Body)
 		rawResponse.Body = ioutil.NopCloser(bytes.NewBuffer(jsonBytes))
 		err = gateway.errHandler(rawResponse.StatusCode, jsonBytes)
+		if requestID := rawResponse.Header.Get("X-Vcap-Request-Id"); requestID != "" {
+			gateway.logger.Printf("%s %s\n", terminal.HeaderColor(T("REQUEST ID:")), requestID)
+			err = errors.WithRequestID(err, requestID)
+		}
+		if httpErr, ok := err.(errors.HTTPError); ok && gateway.RawErrorResponses {
+			err = errors.NewRawResponseError(httpErr, rawResponse.Status, trace.Sanitize(string(jsonBytes)))
+		}
 	}
 
 	return rawResponse, err
@@ -437,25 +761,60 @@ func (gateway Gateway) doRequest(request *http.Request) (*http.Response, error)
 
 	header := http.CanonicalHeaderKey("X-Cf-Warnings")
 	rawWarnings := response.Header[header]
+	gateway.warningsMutex.Lock()
 	for _, rawWarning := range rawWarnings {
 		warning, _ := url.QueryUnescape(rawWarning)
 		*gateway.warnings = append(*gateway.warnings, warning)
 	}
+	gateway.warningsMutex.Unlock()
 
 	return response, err
 }
 
+func isIdempotent(method string) bool {
+	return method == "GET" || method == "HEAD"
+}
+
+func isRetryable(method string) bool {
+	return isIdempotent(method) || method == "PUT" || method == "DELETE"
+}
+
+// isServerError reports whether err is a 5xx response or a reset connection.
+func isServerError(err error) bool {
+	if httpErr, ok := err.(errors.HTTPError); ok {
+		return httpErr.StatusCode() >= 500
+	}
+	return isConnectionReset(err)
+}
+
+func isConnectionReset(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := err.Error()
+	return strings.Contains(message, "connection reset by peer") || strings.HasSuffix(message, "EOF")
+}
+
 func makeHTTPTransport(gateway *Gateway) {
 	gateway.transport = &http.Transport{
 		Dial: (&net.Dialer{
 			KeepAlive: 30 * time.Second,
 			Timeout:   gateway.DialTimeout,
 		}).Dial,
-		TLSClientConfig: NewTLSConfig(gateway.trustedCerts, gateway.config.IsSSLDisabled()),
-		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig:     NewTLSConfig(gateway.trustedCerts, gateway.config.IsSSLDisabled()),
+		TLSHandshakeTimeout: gateway.TLSHandshakeTimeout,
+		Proxy:               http.ProxyFromEnvironment,
 	}
 }
 
+// SetTimeouts sets the dial and TLS handshake timeouts. The transport is
+// rebuilt with them on the next request.
+func (gateway *Gateway) SetTimeouts(dial, tlsHandshake time.Duration) {
+	gateway.DialTimeout = dial
+	gateway.TLSHandshakeTimeout = tlsHandshake
+	gateway.transport = nil
+}
+
 func dialTimeout(envDialTimeout string) time.Duration {
 	dialTimeout := DefaultDialTimeout
 	if timeout, err := strconv.Atoi(envDialTimeout); err == nil {
@@ -2,8 +2,10 @@ package net_test
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -13,14 +15,17 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/cli/cf/api/authentication"
+	"code.cloudfoundry.org/cli/cf/api/authentication/authenticationfakes"
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
 	"code.cloudfoundry.org/cli/cf/errors"
 	. "code.cloudfoundry.org/cli/cf/net"
 	"code.cloudfoundry.org/cli/cf/net/netfakes"
 	"code.cloudfoundry.org/cli/cf/terminal/terminalfakes"
+	"code.cloudfoundry.org/cli/cf/trace"
 	"code.cloudfoundry.org/cli/cf/trace/tracefakes"
 	testconfig "code.cloudfoundry.org/cli/util/testhelpers/configuration"
 	testnet "code.cloudfoundry.org/cli/util/testhelpers/net"
@@ -98,6 +103,409 @@ var _ = Describe("Gateway", func() {
 			Expect(apiErr).To(HaveOccurred())
 			Expect(client.DoCallCount()).To(Equal(3))
 		})
+
+		It("builds the transport with the configured timeouts", func() {
+			var transport *http.Transport
+			NewHTTPClient = func(tr *http.Transport, dumper RequestDumper) HTTPClientInterface {
+				transport = tr
+				return client
+			}
+			client.DoReturns(&http.Response{Status: "200 OK", StatusCode: 200}, nil)
+			ccGateway.SetTimeouts(2*time.Second, 3*time.Second)
+
+			request, apiErr := ccGateway.NewRequest("GET", "https://example.com/v2/users", "BEARER my-access-token", nil)
+			Expect(apiErr).ToNot(HaveOccurred())
+			_, apiErr = ccGateway.PerformRequest(request)
+			Expect(apiErr).NotTo(HaveOccurred())
+
+			Expect(ccGateway.DialTimeout).To(Equal(2 * time.Second))
+			Expect(transport.TLSHandshakeTimeout).To(Equal(3 * time.Second))
+		})
+
+		Context("when read retries are configured", func() {
+			BeforeEach(func() {
+				ccGateway.ReadRetries = 1
+				ccGateway.ReadRetryBackoff = ConstantBackoff(time.Millisecond)
+
+				client.DoStub = func(*http.Request) (*http.Response, error) {
+					if client.DoCallCount() <= 3 {
+						return nil, errors.New("read tcp 127.0.0.1:443: connection reset by peer")
+					}
+					return &http.Response{Status: "200 OK", StatusCode: 200}, nil
+				}
+			})
+
+			It("retries a GET after the connection is reset", func() {
+				request, apiErr := ccGateway.NewRequest("GET", "https://example.com/v2/users", "BEARER my-access-token", nil)
+				Expect(apiErr).ToNot(HaveOccurred())
+
+				_, apiErr = ccGateway.PerformRequest(request)
+				Expect(apiErr).NotTo(HaveOccurred())
+				Expect(client.DoCallCount()).To(Equal(4))
+			})
+
+			It("does not retry requests that are not idempotent", func() {
+				request, apiErr := ccGateway.NewRequest("POST", "https://example.com/v2/users", "BEARER my-access-token", nil)
+				Expect(apiErr).ToNot(HaveOccurred())
+
+				_, apiErr = ccGateway.PerformRequest(request)
+				Expect(apiErr).To(HaveOccurred())
+				Expect(client.DoCallCount()).To(Equal(3))
+			})
+
+			It("falls back to the default backoff when none is set", func() {
+				ccGateway.ReadRetryBackoff = nil
+				client.DoStub = func(*http.Request) (*http.Response, error) {
+					if client.DoCallCount() <= 3 {
+						return nil, errors.New("read tcp 127.0.0.1:443: connection reset by peer")
+					}
+					return &http.Response{Status: "200 OK", StatusCode: 200}, nil
+				}
+
+				request, apiErr := ccGateway.NewRequest("GET", "https://example.com/v2/users", "BEARER my-access-token", nil)
+				Expect(apiErr).ToNot(HaveOccurred())
+
+				_, apiErr = ccGateway.PerformRequest(request)
+				Expect(apiErr).NotTo(HaveOccurred())
+				Expect(client.DoCallCount()).To(Equal(4))
+			})
+
+			It("waits as long as the backoff strategy says before each retry", func() {
+				backoff := &recordingBackoff{BackoffStrategy: ConstantBackoff(2 * time.Millisecond)}
+				ccGateway.ReadRetries = 3
+				ccGateway.ReadRetryBackoff = backoff
+				client.DoStub = func(*http.Request) (*http.Response, error) {
+					if client.DoCallCount() <= 9 {
+						return nil, errors.New("read tcp 127.0.0.1:443: connection reset by peer")
+					}
+					return &http.Response{Status: "200 OK", StatusCode: 200}, nil
+				}
+
+				request, apiErr := ccGateway.NewRequest("GET", "https://example.com/v2/users", "BEARER my-access-token", nil)
+				Expect(apiErr).ToNot(HaveOccurred())
+
+				_, apiErr = ccGateway.PerformRequest(request)
+				Expect(apiErr).NotTo(HaveOccurred())
+				Expect(backoff.attempts).To(Equal([]int{1, 2, 3}))
+				Expect(backoff.delays).To(Equal([]time.Duration{2 * time.Millisecond, 2 * time.Millisecond, 2 * time.Millisecond}))
+			})
+		})
+	})
+
+	Describe("retrying server errors", func() {
+		var (
+			oldNewHTTPClient func(tr *http.Transport, dumper RequestDumper) HTTPClientInterface
+			bodies           []string
+		)
+
+		respond := func(statusCode int) *http.Response {
+			return &http.Response{
+				Status:     http.StatusText(statusCode),
+				StatusCode: statusCode,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(`{"code": 1, "description": "oops"}`)),
+			}
+		}
+
+		BeforeEach(func() {
+			client = new(netfakes.FakeHTTPClientInterface)
+			oldNewHTTPClient = NewHTTPClient
+			NewHTTPClient = func(tr *http.Transport, dumper RequestDumper) HTTPClientInterface {
+				return client
+			}
+
+			ccGateway.MaxRetries = 2
+			ccGateway.RetryBackoff = ConstantBackoff(time.Millisecond)
+
+			bodies = nil
+			client.DoStub = func(request *http.Request) (*http.Response, error) {
+				if request.Body != nil {
+					body, _ := ioutil.ReadAll(request.Body)
+					bodies = append(bodies, string(body))
+				}
+				if client.DoCallCount() == 1 {
+					return respond(http.StatusBadGateway), nil
+				}
+				return respond(http.StatusOK), nil
+			}
+		})
+
+		AfterEach(func() {
+			NewHTTPClient = oldNewHTTPClient
+		})
+
+		It("retries a GET after a 5xx response", func() {
+			request, apiErr := ccGateway.NewRequest("GET", "https://example.com/v2/apps", "BEARER my-access-token", nil)
+			Expect(apiErr).ToNot(HaveOccurred())
+
+			_, apiErr = ccGateway.PerformRequest(request)
+			Expect(apiErr).NotTo(HaveOccurred())
+			Expect(client.DoCallCount()).To(Equal(2))
+		})
+
+		It("sends the whole body again when retrying a PUT", func() {
+			request, apiErr := ccGateway.NewRequest("PUT", "https://example.com/v2/apps/app-guid", "BEARER my-access-token", strings.NewReader(`{"name": "app"}`))
+			Expect(apiErr).ToNot(HaveOccurred())
+
+			_, apiErr = ccGateway.PerformRequest(request)
+			Expect(apiErr).NotTo(HaveOccurred())
+			Expect(bodies).To(Equal([]string{`{"name": "app"}`, `{"name": "app"}`}))
+		})
+
+		It("sends a fresh body from the request's body factory on each retry", func() {
+			request, apiErr := ccGateway.NewRequest("PUT", "https://example.com/v2/apps/app-guid", "BEARER my-access-token", nil)
+			Expect(apiErr).ToNot(HaveOccurred())
+
+			made := 0
+			request.BodyFactory = func() (io.ReadSeeker, error) {
+				made++
+				return strings.NewReader(fmt.Sprintf(`{"attempt": %d}`, made+1)), nil
+			}
+			request.SeekableBody = strings.NewReader(`{"attempt": 1}`)
+			request.HTTPReq.Body = ioutil.NopCloser(request.SeekableBody)
+
+			_, apiErr = ccGateway.PerformRequest(request)
+			Expect(apiErr).NotTo(HaveOccurred())
+			Expect(made).To(Equal(1))
+			Expect(bodies).To(Equal([]string{`{"attempt": 1}`, `{"attempt": 2}`}))
+		})
+
+		It("does not resend the part of a body read before the request was built", func() {
+			body := strings.NewReader(`ignored{"name": "app"}`)
+			_, _ = body.Seek(int64(len("ignored")), io.SeekStart)
+			request, apiErr := ccGateway.NewRequest("PUT", "https://example.com/v2/apps/app-guid", "BEARER my-access-token", body)
+			Expect(apiErr).ToNot(HaveOccurred())
+
+			_, apiErr = ccGateway.PerformRequest(request)
+			Expect(apiErr).NotTo(HaveOccurred())
+			Expect(bodies).To(Equal([]string{`{"name": "app"}`, `{"name": "app"}`}))
+		})
+
+		It("does not retry a POST", func() {
+			request, apiErr := ccGateway.NewRequest("POST", "https://example.com/v2/apps", "BEARER my-access-token", strings.NewReader(`{}`))
+			Expect(apiErr).ToNot(HaveOccurred())
+
+			_, apiErr = ccGateway.PerformRequest(request)
+			Expect(apiErr).To(HaveOccurred())
+			Expect(client.DoCallCount()).To(Equal(1))
+		})
+
+		It("does not retry a 4xx response", func() {
+			client.DoStub = func(*http.Request) (*http.Response, error) {
+				return respond(http.StatusNotFound), nil
+			}
+			request, apiErr := ccGateway.NewRequest("DELETE", "https://example.com/v2/apps/app-guid", "BEARER my-access-token", nil)
+			Expect(apiErr).ToNot(HaveOccurred())
+
+			_, apiErr = ccGateway.PerformRequest(request)
+			Expect(apiErr).To(BeAssignableToTypeOf(&errors.HTTPNotFoundError{}))
+			Expect(client.DoCallCount()).To(Equal(1))
+		})
+
+		It("says how many attempts were made once the retries run out", func() {
+			client.DoStub = func(*http.Request) (*http.Response, error) {
+				return respond(http.StatusServiceUnavailable), nil
+			}
+			request, apiErr := ccGateway.NewRequest("GET", "https://example.com/v2/apps", "BEARER my-access-token", nil)
+			Expect(apiErr).ToNot(HaveOccurred())
+
+			_, apiErr = ccGateway.PerformRequest(request)
+			Expect(client.DoCallCount()).To(Equal(3))
+			Expect(apiErr).To(MatchError(ContainSubstring("gave up after 3 attempts")))
+			httpErr, ok := apiErr.(errors.HTTPError)
+			Expect(ok).To(BeTrue())
+			Expect(httpErr.StatusCode()).To(Equal(http.StatusServiceUnavailable))
+		})
+
+		It("retries a reset connection", func() {
+			client.DoStub = func(*http.Request) (*http.Response, error) {
+				if client.DoCallCount() <= 3 {
+					return nil, errors.New("read tcp 127.0.0.1:443: connection reset by peer")
+				}
+				return respond(http.StatusOK), nil
+			}
+			request, apiErr := ccGateway.NewRequest("DELETE", "https://example.com/v2/apps/app-guid", "BEARER my-access-token", nil)
+			Expect(apiErr).ToNot(HaveOccurred())
+
+			_, apiErr = ccGateway.PerformRequest(request)
+			Expect(apiErr).NotTo(HaveOccurred())
+			Expect(client.DoCallCount()).To(Equal(4))
+		})
+
+		It("returns a retryable error once the retries on a reset connection run out", func() {
+			client.DoStub = func(*http.Request) (*http.Response, error) {
+				return nil, errors.New("read tcp 127.0.0.1:443: connection reset by peer")
+			}
+			request, apiErr := ccGateway.NewRequest("GET", "https://example.com/v2/apps", "BEARER my-access-token", nil)
+			Expect(apiErr).ToNot(HaveOccurred())
+
+			_, apiErr = ccGateway.PerformRequest(request)
+			Expect(apiErr).To(MatchError(ContainSubstring("gave up after")))
+			Expect(errors.IsRetryable(apiErr)).To(BeTrue())
+		})
+
+		It("gives a reset read the larger of the read and server error retries, not both", func() {
+			ccGateway.ReadRetries = 3
+			ccGateway.ReadRetryBackoff = ConstantBackoff(time.Millisecond)
+			client.DoStub = func(*http.Request) (*http.Response, error) {
+				return nil, errors.New("read tcp 127.0.0.1:443: connection reset by peer")
+			}
+			request, apiErr := ccGateway.NewRequest("GET", "https://example.com/v2/apps", "BEARER my-access-token", nil)
+			Expect(apiErr).ToNot(HaveOccurred())
+
+			_, apiErr = ccGateway.PerformRequest(request)
+			Expect(apiErr).To(MatchError(ContainSubstring("gave up after 4 attempts")))
+			// Each attempt tries the client up to three times before giving up.
+			Expect(client.DoCallCount()).To(Equal(4 * 3))
+		})
+
+		Describe("retryable errors", func() {
+			performPost := func(statusCode int, body string) error {
+				client.DoStub = func(*http.Request) (*http.Response, error) {
+					response := respond(statusCode)
+					response.Body = ioutil.NopCloser(strings.NewReader(body))
+					return response, nil
+				}
+				request, apiErr := ccGateway.NewRequest("POST", "https://example.com/v2/apps", "BEARER my-access-token", strings.NewReader(`{}`))
+				Expect(apiErr).ToNot(HaveOccurred())
+
+				_, apiErr = ccGateway.PerformRequest(request)
+				Expect(apiErr).To(HaveOccurred())
+				return apiErr
+			}
+
+			It("marks rate limiting and unavailable servers as retryable", func() {
+				for _, statusCode := range []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout} {
+					Expect(errors.IsRetryable(performPost(statusCode, `{"code": 1, "description": "oops"}`))).To(BeTrue(), http.StatusText(statusCode))
+				}
+			})
+
+			It("marks client errors and other server errors as not retryable", func() {
+				for _, statusCode := range []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError} {
+					Expect(errors.IsRetryable(performPost(statusCode, `{"code": 1, "description": "oops"}`))).To(BeFalse(), http.StatusText(statusCode))
+				}
+			})
+
+			It("goes by the error code when it is a known one", func() {
+				Expect(errors.IsRetryable(performPost(http.StatusBadRequest, `{"code": 10013, "description": "Rate Limit Exceeded"}`))).To(BeTrue())
+				Expect(errors.IsRetryable(performPost(http.StatusServiceUnavailable, `{"code": 10003, "description": "You are not authorized"}`))).To(BeFalse())
+			})
+
+			It("marks connection failures as retryable", func() {
+				client.DoStub = func(*http.Request) (*http.Response, error) {
+					return nil, errors.New("read tcp 127.0.0.1:443: i/o error")
+				}
+				request, apiErr := ccGateway.NewRequest("POST", "https://example.com/v2/apps", "BEARER my-access-token", strings.NewReader(`{}`))
+				Expect(apiErr).ToNot(HaveOccurred())
+
+				_, apiErr = ccGateway.PerformRequest(request)
+				Expect(errors.IsRetryable(apiErr)).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("request IDs", func() {
+		var (
+			printer *tracefakes.FakePrinter
+			header  http.Header
+		)
+
+		BeforeEach(func() {
+			ccServer = ghttp.NewServer()
+			config.SetAPIEndpoint(ccServer.URL())
+			printer = new(tracefakes.FakePrinter)
+			ccGateway = NewCloudControllerGateway(config, clock, new(terminalfakes.FakeUI), printer, "")
+
+			header = http.Header{"X-VCAP-Request-ID": []string{"abc-123"}}
+		})
+
+		AfterEach(func() {
+			ccServer.Close()
+		})
+
+		It("ends the message of a failed request with the request ID", func() {
+			ccServer.AppendHandlers(ghttp.RespondWith(http.StatusBadRequest, `{"code": 1002, "description": "invalid relation"}`, header))
+
+			err := ccGateway.UpdateResource(ccServer.URL(), "/v2/spaces/space-guid/developers/user-guid", strings.NewReader(""))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HaveSuffix("(request-id: abc-123)"))
+			Expect(err.(errors.HTTPError).ErrorCode()).To(Equal("1002"))
+		})
+
+		It("keeps the type of not found errors", func() {
+			ccServer.AppendHandlers(ghttp.RespondWith(http.StatusNotFound, `{"code": 10000, "description": "not found"}`, header))
+
+			err := ccGateway.GetResource(ccServer.URL()+"/v2/spaces/space-guid", &struct{}{})
+			Expect(err).To(BeAssignableToTypeOf(&errors.HTTPNotFoundError{}))
+			Expect(err.(*errors.HTTPNotFoundError).RequestID()).To(Equal("abc-123"))
+		})
+
+		It("prints the request ID of a failed request in the trace", func() {
+			ccServer.AppendHandlers(ghttp.RespondWith(http.StatusBadRequest, `{}`, header))
+
+			_ = ccGateway.GetResource(ccServer.URL()+"/v2/spaces/space-guid", &struct{}{})
+
+			var traced []string
+			for i := 0; i < printer.PrintfCallCount(); i++ {
+				format, args := printer.PrintfArgsForCall(i)
+				traced = append(traced, fmt.Sprintf(format, args...))
+			}
+			Expect(strings.Join(traced, "")).To(ContainSubstring("abc-123"))
+		})
+
+		It("leaves the message alone when the server sends no request ID", func() {
+			ccServer.AppendHandlers(ghttp.RespondWith(http.StatusBadRequest, `{"code": 1002, "description": "invalid relation"}`))
+
+			err := ccGateway.GetResource(ccServer.URL()+"/v2/spaces/space-guid", &struct{}{})
+			Expect(err.Error()).NotTo(ContainSubstring("request-id"))
+		})
+	})
+
+	Describe("UpdateResourceIfMatch", func() {
+		BeforeEach(func() {
+			ccServer = ghttp.NewServer()
+			config.SetAPIEndpoint(ccServer.URL())
+		})
+
+		AfterEach(func() {
+			ccServer.Close()
+		})
+
+		It("sends the ETag in If-Match", func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v2/spaces/space-guid"),
+					ghttp.VerifyHeaderKV("If-Match", `"v1"`),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+			)
+
+			err := ccGateway.UpdateResourceIfMatch(ccServer.URL(), "/v2/spaces/space-guid", `"v1"`, strings.NewReader(`{}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ccGateway.Headers).NotTo(HaveKey("If-Match"))
+		})
+
+		It("returns a precondition failed error on a 412", func() {
+			ccServer.AppendHandlers(ghttp.RespondWith(http.StatusPreconditionFailed, `{"code": 10000, "description": "changed"}`))
+
+			err := ccGateway.UpdateResourceIfMatch(ccServer.URL(), "/v2/spaces/space-guid", `"v1"`, strings.NewReader(`{}`))
+			Expect(err).To(BeAssignableToTypeOf(&errors.HTTPPreconditionFailedError{}))
+			Expect(err.(errors.HTTPError).StatusCode()).To(Equal(http.StatusPreconditionFailed))
+		})
+
+		It("sends no If-Match without an ETag", func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.Header).NotTo(HaveKey("If-Match"))
+					},
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+			)
+
+			err := ccGateway.UpdateResourceIfMatch(ccServer.URL(), "/v2/spaces/space-guid", "", strings.NewReader(`{}`))
+			Expect(err).NotTo(HaveOccurred())
+		})
 	})
 
 	Describe("NewRequest", func() {
@@ -106,6 +514,24 @@ var _ = Describe("Gateway", func() {
 			apiErr  error
 		)
 
+		Context("when the body's length is only known by seeking", func() {
+			var body *seekOnlyReader
+
+			BeforeEach(func() {
+				body = &seekOnlyReader{ReadSeeker: strings.NewReader(`{"name": "app"}`)}
+				request, apiErr = ccGateway.NewRequest("PUT", "https://example.com/v2/apps/app-guid", "BEARER my-access-token", body)
+				Expect(apiErr).NotTo(HaveOccurred())
+			})
+
+			It("sets the content length without reading the body", func() {
+				Expect(request.HTTPReq.ContentLength).To(Equal(int64(len(`{"name": "app"}`))))
+
+				contents, err := ioutil.ReadAll(request.HTTPReq.Body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal(`{"name": "app"}`))
+			})
+		})
+
 		Context("when the body is nil", func() {
 			BeforeEach(func() {
 				request, apiErr = ccGateway.NewRequest("GET", "https://example.com/v2/apps", "BEARER my-access-token", nil)
@@ -197,6 +623,127 @@ var _ = Describe("Gateway", func() {
 
 	})
 
+	Describe("ListPaginatedResourcesWithContext", func() {
+		type named struct {
+			Name string `json:"name"`
+		}
+
+		BeforeEach(func() {
+			ccServer = ghttp.NewServer()
+			ccServer.HTTPTestServer.Config.ErrorLog = log.New(&bytes.Buffer{}, "", 0)
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/things"),
+					ghttp.RespondWith(http.StatusOK, `{"next_url": "/v2/things?page=2", "resources": [{"name": "a"}, {"name": "b"}]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/things", "page=2"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [{"name": "c"}]}`),
+				),
+			)
+		})
+
+		AfterEach(func() {
+			ccServer.Close()
+		})
+
+		It("lists every page when the context is not cancelled", func() {
+			names := []string{}
+			err := ccGateway.ListPaginatedResourcesWithContext(context.Background(), ccServer.URL(), "/v2/things", named{}, func(resource interface{}) bool {
+				names = append(names, resource.(named).Name)
+				return true
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{"a", "b", "c"}))
+		})
+
+		It("stops before the next page once the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			names := []string{}
+			err := ccGateway.ListPaginatedResourcesWithContext(ctx, ccServer.URL(), "/v2/things", named{}, func(resource interface{}) bool {
+				names = append(names, resource.(named).Name)
+				cancel()
+				return true
+			})
+			Expect(err).To(Equal(context.Canceled))
+			Expect(names).To(Equal([]string{"a", "b"}))
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Describe("ResultsPerPage", func() {
+		type named struct {
+			Name string `json:"name"`
+		}
+
+		BeforeEach(func() {
+			ccServer = ghttp.NewServer()
+			ccServer.HTTPTestServer.Config.ErrorLog = log.New(&bytes.Buffer{}, "", 0)
+		})
+
+		AfterEach(func() {
+			ccServer.Close()
+		})
+
+		list := func(path string) error {
+			return ccGateway.ListPaginatedResources(ccServer.URL(), path, named{}, func(interface{}) bool { return true })
+		}
+
+		It("asks for the page size on the first page only", func() {
+			ccGateway.ResultsPerPage = 75
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/things", "order-by=name&results-per-page=75"),
+					ghttp.RespondWith(http.StatusOK, `{"next_url": "/v2/things?page=2&results-per-page=75", "resources": [{"name": "a"}]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/things", "page=2&results-per-page=75"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+				),
+			)
+
+			Expect(list("/v2/things?order-by=name")).To(Succeed())
+			Expect(ccServer.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("caps the page size at the Cloud Controller maximum", func() {
+			ccGateway.ResultsPerPage = 500
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/things", "results-per-page=100"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+				),
+			)
+
+			Expect(list("/v2/things")).To(Succeed())
+		})
+
+		It("keeps a page size the path already gives", func() {
+			ccGateway.ResultsPerPage = 75
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/things", "results-per-page=10"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+				),
+			)
+
+			Expect(list("/v2/things?results-per-page=10")).To(Succeed())
+		})
+
+		It("leaves the page size to the Cloud Controller when unset", func() {
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v2/things", ""),
+					ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+				),
+			)
+
+			Expect(list("/v2/things")).To(Succeed())
+		})
+	})
+
 	Describe("CRUD methods", func() {
 		Describe("Delete", func() {
 			var apiServer *httptest.Server
@@ -291,6 +838,107 @@ var _ = Describe("Gateway", func() {
 		})
 	})
 
+	Describe("tracing request bodies", func() {
+		var (
+			uaaServer *ghttp.Server
+			traced    *bytes.Buffer
+		)
+
+		BeforeEach(func() {
+			uaaServer = ghttp.NewServer()
+			uaaServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusCreated, `{"id": "user-guid"}`),
+				ghttp.RespondWith(http.StatusOK, `{"status": "ok"}`),
+			)
+			traced = new(bytes.Buffer)
+			uaaGateway = NewUAAGateway(config, new(terminalfakes.FakeUI), trace.NewWriterPrinter(traced, false), "")
+		})
+
+		AfterEach(func() {
+			uaaServer.Close()
+		})
+
+		It("never prints the plaintext passwords", func() {
+			createBody := `{"userName":"jiro","emails":[{"value":"jiro"}],"password":"lean,\"sushi\"","name":{"givenName":"jiro","familyName":"jiro"}}`
+			err := uaaGateway.CreateResource(uaaServer.URL(), "/Users", strings.NewReader(createBody))
+			Expect(err).NotTo(HaveOccurred())
+
+			changeBody := `{"password":"new-sushi","oldPassword":"lean,\"sushi\""}`
+			err = uaaGateway.UpdateResourceSync(uaaServer.URL(), "/Users/user-guid/password", strings.NewReader(changeBody))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(traced.String()).To(ContainSubstring("POST /Users"))
+			Expect(traced.String()).To(ContainSubstring("PUT /Users/user-guid/password"))
+			Expect(traced.String()).To(ContainSubstring(`"password":"[PRIVATE DATA HIDDEN]"`))
+			Expect(traced.String()).NotTo(ContainSubstring("sushi"))
+		})
+	})
+
+	Describe("custom headers", func() {
+		var (
+			server *ghttp.Server
+			traced *bytes.Buffer
+		)
+
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+			traced = new(bytes.Buffer)
+			ccGateway = NewCloudControllerGateway(config, time.Now, new(terminalfakes.FakeUI), trace.NewWriterPrinter(traced, false), "")
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("sends default headers on every request and shows them in the trace", func() {
+			ccGateway.AddDefaultHeader("X-Correlation-Id", "abc-123")
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("X-Correlation-Id", "abc-123"),
+					ghttp.RespondWith(http.StatusOK, `{}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("X-Correlation-Id", "abc-123"),
+					ghttp.RespondWith(http.StatusOK, `{}`),
+				),
+			)
+
+			Expect(ccGateway.GetResource(server.URL()+"/v2/info", &struct{}{})).To(Succeed())
+			Expect(ccGateway.GetResource(server.URL()+"/v2/info", &struct{}{})).To(Succeed())
+			Expect(traced.String()).To(ContainSubstring("X-Correlation-Id: abc-123"))
+		})
+
+		It("overrides a default header for calls made through WithHeader only", func() {
+			ccGateway.AddDefaultHeader("X-Correlation-Id", "abc-123")
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("X-Correlation-Id", "def-456"),
+					ghttp.RespondWith(http.StatusOK, `{}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("X-Correlation-Id", "abc-123"),
+					ghttp.RespondWith(http.StatusOK, `{}`),
+				),
+			)
+
+			Expect(ccGateway.WithHeader("X-Correlation-Id", "def-456").GetResource(server.URL()+"/v2/info", &struct{}{})).To(Succeed())
+			Expect(ccGateway.GetResource(server.URL()+"/v2/info", &struct{}{})).To(Succeed())
+		})
+
+		It("never replaces the Authorization header", func() {
+			config.SetAccessToken("bearer my-token")
+			ccGateway.AddDefaultHeader("Authorization", "bearer proxy-token")
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("Authorization", "bearer my-token"),
+					ghttp.RespondWith(http.StatusOK, `{}`),
+				),
+			)
+
+			Expect(ccGateway.GetResource(server.URL()+"/v2/info", &struct{}{})).To(Succeed())
+		})
+	})
+
 	Describe("making an async request", func() {
 		var (
 			jobStatus     string
@@ -472,6 +1120,53 @@ var _ = Describe("Gateway", func() {
 			Expect(config.RefreshToken()).To(Equal("new-refresh-token"))
 		})
 
+		It("refreshes the token once when many requests fail at the same time", func() {
+			const requestCount = 10
+			var arrived sync.WaitGroup
+			arrived.Add(requestCount)
+
+			apiServer := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				if request.Header.Get("Authorization") == "bearer new-access-token" {
+					writer.WriteHeader(http.StatusOK)
+					return
+				}
+				arrived.Done()
+				arrived.Wait()
+				writer.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprintln(writer, `{ "code": 1000, "description": "Auth token is invalid" }`)
+			}))
+			defer apiServer.Close()
+			ccGateway.SetTrustedCerts(apiServer.TLS.Certificates)
+
+			auth := new(authenticationfakes.FakeRepository)
+			auth.RefreshAuthTokenStub = func() (string, error) {
+				time.Sleep(100 * time.Millisecond)
+				return "bearer new-access-token", nil
+			}
+			ccGateway.SetTokenRefresher(auth)
+
+			var finished sync.WaitGroup
+			errs := make(chan error, requestCount)
+			for i := 0; i < requestCount; i++ {
+				finished.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer finished.Done()
+					request, err := ccGateway.NewRequest("GET", apiServer.URL+"/v2/foo", "bearer initial-access-token", nil)
+					Expect(err).NotTo(HaveOccurred())
+					_, err = ccGateway.PerformRequest(request)
+					errs <- err
+				}()
+			}
+			finished.Wait()
+			close(errs)
+
+			for err := range errs {
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(auth.RefreshAuthTokenCallCount()).To(Equal(1))
+		})
+
 		It("refreshes the token when CC requests fail", func() {
 			apiServer := httptest.NewTLSServer(refreshTokenAPIEndPoint(
 				`{ "code": 1000, "description": "Auth token is invalid" }`,
@@ -701,3 +1396,22 @@ func createAuthenticationRepository(apiServer *httptest.Server, authServer *http
 
 	return config, authenticator
 }
+
+// seekOnlyReader hides the concrete reader type from http.NewRequest, so
+// the length can only be found by seeking.
+type seekOnlyReader struct {
+	io.ReadSeeker
+}
+
+type recordingBackoff struct {
+	BackoffStrategy
+	attempts []int
+	delays   []time.Duration
+}
+
+func (backoff *recordingBackoff) NextDelay(attempt int) time.Duration {
+	delay := backoff.BackoffStrategy.NextDelay(attempt)
+	backoff.attempts = append(backoff.attempts, attempt)
+	backoff.delays = append(backoff.delays, delay)
+	return delay
+}
@@ -5,6 +5,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"syscall"
 
 	"code.cloudfoundry.org/cli/cf/errors"
@@ -103,6 +104,13 @@ var _ = Describe("HTTP Client", func() {
 	})
 
 	Describe("WrapNetworkErrors", func() {
+		It("replaces timeouts with NetworkTimeoutErrors naming the host", func() {
+			err, ok := WrapNetworkErrors("uaa.example.com", &url.Error{Op: "Get", URL: "https://uaa.example.com", Err: os.ErrDeadlineExceeded}).(*errors.NetworkTimeoutError)
+			Expect(ok).To(BeTrue())
+			Expect(err.Host).To(Equal("uaa.example.com"))
+			Expect(err.Error()).To(ContainSubstring("Error performing request"))
+		})
+
 		It("replaces http unknown authority errors with InvalidSSLCert errors", func() {
 			err, ok := WrapNetworkErrors("example.com", &url.Error{Err: x509.UnknownAuthorityError{}}).(*errors.InvalidSSLCert)
 			Expect(ok).To(BeTrue())
@@ -153,6 +161,17 @@ var _ = Describe("HTTP Client", func() {
 			Expect(ok).To(BeFalse())
 		})
 
+		It("returns errors that exit with the backend code", func() {
+			err := WrapNetworkErrors("example.com", &url.Error{Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("tcp-dial-error")}})
+			Expect(errors.ExitCode(err)).To(Equal(errors.ExitCodeBackend))
+
+			err = WrapNetworkErrors("example.com", errors.New("whatever"))
+			Expect(errors.ExitCode(err)).To(Equal(errors.ExitCodeBackend))
+
+			err = WrapNetworkErrors("uaa.example.com", &url.Error{Op: "Get", URL: "https://uaa.example.com", Err: os.ErrDeadlineExceeded})
+			Expect(errors.ExitCode(err)).To(Equal(errors.ExitCodeBackend))
+		})
+
 		It("returns an error with a tip when it is a tcp dial error", func() {
 			err := WrapNetworkErrors("example.com", &url.Error{Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("tcp-dial-error")}})
 			Expect(err).To(HaveOccurred())
@@ -12,6 +12,7 @@ func NewPaginatedResources(exampleResource interface{}) PaginatedResources {
 }
 
 type PaginatedResources struct {
+	TotalResults   int             `json:"total_results"`
 	NextURL        string          `json:"next_url"`
 	ResourcesBytes json.RawMessage `json:"resources"`
 	resourceType   reflect.Type
@@ -3,6 +3,7 @@ package net
 import (
 	"encoding/json"
 	"net/http"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
@@ -28,14 +29,17 @@ func errorHandler(statusCode int, body []byte) error {
 
 func NewRoutingAPIGateway(config coreconfig.Reader, clock func() time.Time, ui terminal.UI, logger trace.Printer, envDialTimeout string) Gateway {
 	return Gateway{
-		errHandler:      errorHandler,
-		config:          config,
-		PollingThrottle: DefaultPollingThrottle,
-		warnings:        &[]string{},
-		Clock:           clock,
-		ui:              ui,
-		logger:          logger,
-		PollingEnabled:  true,
-		DialTimeout:     dialTimeout(envDialTimeout),
+		errHandler:       errorHandler,
+		config:           config,
+		PollingThrottle:  DefaultPollingThrottle,
+		ReadRetryBackoff: DefaultBackoff,
+		RetryBackoff:     DefaultBackoff,
+		warnings:         &[]string{},
+		warningsMutex:    &sync.Mutex{},
+		Clock:            clock,
+		ui:               ui,
+		logger:           logger,
+		PollingEnabled:   true,
+		DialTimeout:      dialTimeout(envDialTimeout),
 	}
 }
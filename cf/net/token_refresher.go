@@ -0,0 +1,43 @@
+package net
+
+import "sync"
+
+// coalescingTokenRefresher lets concurrent callers share one in-flight token
+// refresh, so a burst of 401s results in a single request to UAA.
+type coalescingTokenRefresher struct {
+	refresher tokenRefresher
+
+	mutex    sync.Mutex
+	inFlight *tokenRefresh
+}
+
+type tokenRefresh struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+func newCoalescingTokenRefresher(refresher tokenRefresher) *coalescingTokenRefresher {
+	return &coalescingTokenRefresher{refresher: refresher}
+}
+
+func (c *coalescingTokenRefresher) RefreshAuthToken() (string, error) {
+	c.mutex.Lock()
+	if refresh := c.inFlight; refresh != nil {
+		c.mutex.Unlock()
+		<-refresh.done
+		return refresh.token, refresh.err
+	}
+	refresh := &tokenRefresh{done: make(chan struct{})}
+	c.inFlight = refresh
+	c.mutex.Unlock()
+
+	refresh.token, refresh.err = c.refresher.RefreshAuthToken()
+
+	c.mutex.Lock()
+	c.inFlight = nil
+	c.mutex.Unlock()
+	close(refresh.done)
+
+	return refresh.token, refresh.err
+}
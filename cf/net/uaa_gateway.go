@@ -2,6 +2,7 @@ package net
 
 import (
 	"encoding/json"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/cli/cf/configuration/coreconfig"
@@ -28,14 +29,17 @@ var uaaErrorHandler = func(statusCode int, body []byte) error {
 
 func NewUAAGateway(config coreconfig.Reader, ui terminal.UI, logger trace.Printer, envDialTimeout string) Gateway {
 	return Gateway{
-		errHandler:      uaaErrorHandler,
-		config:          config,
-		PollingThrottle: DefaultPollingThrottle,
-		warnings:        &[]string{},
-		Clock:           time.Now,
-		ui:              ui,
-		logger:          logger,
-		PollingEnabled:  false,
-		DialTimeout:     dialTimeout(envDialTimeout),
+		errHandler:       uaaErrorHandler,
+		config:           config,
+		PollingThrottle:  DefaultPollingThrottle,
+		ReadRetryBackoff: DefaultBackoff,
+		RetryBackoff:     DefaultBackoff,
+		warnings:         &[]string{},
+		warningsMutex:    &sync.Mutex{},
+		Clock:            time.Now,
+		ui:               ui,
+		logger:           logger,
+		PollingEnabled:   false,
+		DialTimeout:      dialTimeout(envDialTimeout),
 	}
 }
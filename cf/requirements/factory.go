@@ -23,6 +23,9 @@ type Factory interface {
 	NewOrganizationRequirement(name string) OrganizationRequirement
 	NewDomainRequirement(name string) DomainRequirement
 	NewUserRequirement(username string, wantGUID bool) UserRequirement
+	NewUserRequirementWithEmailFallback(username string) UserRequirement
+	NewUserRequirementWithPrefixMatch(username string, choose UserChooser) UserRequirement
+	NewUserGUIDRequirement(userGUID string) UserRequirement
 	NewBuildpackRequirement(buildpack string) BuildpackRequirement
 	NewAPIEndpointRequirement() Requirement
 	NewMinAPIVersionRequirement(commandName string, requiredVersion semver.Version) Requirement
@@ -127,6 +130,25 @@ func (f apiRequirementFactory) NewUserRequirement(username string, wantGUID bool
 	)
 }
 
+func (f apiRequirementFactory) NewUserRequirementWithEmailFallback(username string) UserRequirement {
+	return NewUserRequirementWithEmailFallback(
+		username,
+		f.repoLocator.GetUserRepository(),
+	)
+}
+
+func (f apiRequirementFactory) NewUserRequirementWithPrefixMatch(username string, choose UserChooser) UserRequirement {
+	return NewUserRequirementWithPrefixMatch(
+		username,
+		f.repoLocator.GetUserRepository(),
+		choose,
+	)
+}
+
+func (f apiRequirementFactory) NewUserGUIDRequirement(userGUID string) UserRequirement {
+	return NewUserGUIDRequirement(userGUID)
+}
+
 func (f apiRequirementFactory) NewBuildpackRequirement(buildpack string) BuildpackRequirement {
 	return NewBuildpackRequirement(
 		buildpack,
@@ -98,6 +98,31 @@ type FakeFactory struct {
 	newUserRequirementReturns struct {
 		result1 requirements.UserRequirement
 	}
+	NewUserRequirementWithEmailFallbackStub        func(username string) requirements.UserRequirement
+	newUserRequirementWithEmailFallbackMutex       sync.RWMutex
+	newUserRequirementWithEmailFallbackArgsForCall []struct {
+		username string
+	}
+	newUserRequirementWithEmailFallbackReturns struct {
+		result1 requirements.UserRequirement
+	}
+	NewUserRequirementWithPrefixMatchStub        func(username string, choose requirements.UserChooser) requirements.UserRequirement
+	newUserRequirementWithPrefixMatchMutex       sync.RWMutex
+	newUserRequirementWithPrefixMatchArgsForCall []struct {
+		username string
+		choose   requirements.UserChooser
+	}
+	newUserRequirementWithPrefixMatchReturns struct {
+		result1 requirements.UserRequirement
+	}
+	NewUserGUIDRequirementStub        func(userGUID string) requirements.UserRequirement
+	newUserGUIDRequirementMutex       sync.RWMutex
+	newUserGUIDRequirementArgsForCall []struct {
+		userGUID string
+	}
+	newUserGUIDRequirementReturns struct {
+		result1 requirements.UserRequirement
+	}
 	NewBuildpackRequirementStub        func(buildpack string) requirements.BuildpackRequirement
 	newBuildpackRequirementMutex       sync.RWMutex
 	newBuildpackRequirementArgsForCall []struct {
@@ -518,6 +543,106 @@ func (fake *FakeFactory) NewUserRequirementReturns(result1 requirements.UserRequ
 	}{result1}
 }
 
+func (fake *FakeFactory) NewUserRequirementWithEmailFallback(username string) requirements.UserRequirement {
+	fake.newUserRequirementWithEmailFallbackMutex.Lock()
+	fake.newUserRequirementWithEmailFallbackArgsForCall = append(fake.newUserRequirementWithEmailFallbackArgsForCall, struct {
+		username string
+	}{username})
+	fake.recordInvocation("NewUserRequirementWithEmailFallback", []interface{}{username})
+	fake.newUserRequirementWithEmailFallbackMutex.Unlock()
+	if fake.NewUserRequirementWithEmailFallbackStub != nil {
+		return fake.NewUserRequirementWithEmailFallbackStub(username)
+	} else {
+		return fake.newUserRequirementWithEmailFallbackReturns.result1
+	}
+}
+
+func (fake *FakeFactory) NewUserRequirementWithEmailFallbackCallCount() int {
+	fake.newUserRequirementWithEmailFallbackMutex.RLock()
+	defer fake.newUserRequirementWithEmailFallbackMutex.RUnlock()
+	return len(fake.newUserRequirementWithEmailFallbackArgsForCall)
+}
+
+func (fake *FakeFactory) NewUserRequirementWithEmailFallbackArgsForCall(i int) string {
+	fake.newUserRequirementWithEmailFallbackMutex.RLock()
+	defer fake.newUserRequirementWithEmailFallbackMutex.RUnlock()
+	return fake.newUserRequirementWithEmailFallbackArgsForCall[i].username
+}
+
+func (fake *FakeFactory) NewUserRequirementWithEmailFallbackReturns(result1 requirements.UserRequirement) {
+	fake.NewUserRequirementWithEmailFallbackStub = nil
+	fake.newUserRequirementWithEmailFallbackReturns = struct {
+		result1 requirements.UserRequirement
+	}{result1}
+}
+
+func (fake *FakeFactory) NewUserRequirementWithPrefixMatch(username string, choose requirements.UserChooser) requirements.UserRequirement {
+	fake.newUserRequirementWithPrefixMatchMutex.Lock()
+	fake.newUserRequirementWithPrefixMatchArgsForCall = append(fake.newUserRequirementWithPrefixMatchArgsForCall, struct {
+		username string
+		choose   requirements.UserChooser
+	}{username, choose})
+	fake.recordInvocation("NewUserRequirementWithPrefixMatch", []interface{}{username, choose})
+	fake.newUserRequirementWithPrefixMatchMutex.Unlock()
+	if fake.NewUserRequirementWithPrefixMatchStub != nil {
+		return fake.NewUserRequirementWithPrefixMatchStub(username, choose)
+	} else {
+		return fake.newUserRequirementWithPrefixMatchReturns.result1
+	}
+}
+
+func (fake *FakeFactory) NewUserRequirementWithPrefixMatchCallCount() int {
+	fake.newUserRequirementWithPrefixMatchMutex.RLock()
+	defer fake.newUserRequirementWithPrefixMatchMutex.RUnlock()
+	return len(fake.newUserRequirementWithPrefixMatchArgsForCall)
+}
+
+func (fake *FakeFactory) NewUserRequirementWithPrefixMatchArgsForCall(i int) (string, requirements.UserChooser) {
+	fake.newUserRequirementWithPrefixMatchMutex.RLock()
+	defer fake.newUserRequirementWithPrefixMatchMutex.RUnlock()
+	return fake.newUserRequirementWithPrefixMatchArgsForCall[i].username, fake.newUserRequirementWithPrefixMatchArgsForCall[i].choose
+}
+
+func (fake *FakeFactory) NewUserRequirementWithPrefixMatchReturns(result1 requirements.UserRequirement) {
+	fake.NewUserRequirementWithPrefixMatchStub = nil
+	fake.newUserRequirementWithPrefixMatchReturns = struct {
+		result1 requirements.UserRequirement
+	}{result1}
+}
+
+func (fake *FakeFactory) NewUserGUIDRequirement(userGUID string) requirements.UserRequirement {
+	fake.newUserGUIDRequirementMutex.Lock()
+	fake.newUserGUIDRequirementArgsForCall = append(fake.newUserGUIDRequirementArgsForCall, struct {
+		userGUID string
+	}{userGUID})
+	fake.recordInvocation("NewUserGUIDRequirement", []interface{}{userGUID})
+	fake.newUserGUIDRequirementMutex.Unlock()
+	if fake.NewUserGUIDRequirementStub != nil {
+		return fake.NewUserGUIDRequirementStub(userGUID)
+	} else {
+		return fake.newUserGUIDRequirementReturns.result1
+	}
+}
+
+func (fake *FakeFactory) NewUserGUIDRequirementCallCount() int {
+	fake.newUserGUIDRequirementMutex.RLock()
+	defer fake.newUserGUIDRequirementMutex.RUnlock()
+	return len(fake.newUserGUIDRequirementArgsForCall)
+}
+
+func (fake *FakeFactory) NewUserGUIDRequirementArgsForCall(i int) string {
+	fake.newUserGUIDRequirementMutex.RLock()
+	defer fake.newUserGUIDRequirementMutex.RUnlock()
+	return fake.newUserGUIDRequirementArgsForCall[i].userGUID
+}
+
+func (fake *FakeFactory) NewUserGUIDRequirementReturns(result1 requirements.UserRequirement) {
+	fake.NewUserGUIDRequirementStub = nil
+	fake.newUserGUIDRequirementReturns = struct {
+		result1 requirements.UserRequirement
+	}{result1}
+}
+
 func (fake *FakeFactory) NewBuildpackRequirement(buildpack string) requirements.BuildpackRequirement {
 	fake.newBuildpackRequirementMutex.Lock()
 	fake.newBuildpackRequirementArgsForCall = append(fake.newBuildpackRequirementArgsForCall, struct {
@@ -745,6 +870,12 @@ func (fake *FakeFactory) Invocations() map[string][][]interface{} {
 	defer fake.newDomainRequirementMutex.RUnlock()
 	fake.newUserRequirementMutex.RLock()
 	defer fake.newUserRequirementMutex.RUnlock()
+	fake.newUserRequirementWithEmailFallbackMutex.RLock()
+	defer fake.newUserRequirementWithEmailFallbackMutex.RUnlock()
+	fake.newUserRequirementWithPrefixMatchMutex.RLock()
+	defer fake.newUserRequirementWithPrefixMatchMutex.RUnlock()
+	fake.newUserGUIDRequirementMutex.RLock()
+	defer fake.newUserGUIDRequirementMutex.RUnlock()
 	fake.newBuildpackRequirementMutex.RLock()
 	defer fake.newBuildpackRequirementMutex.RUnlock()
 	fake.newAPIEndpointRequirementMutex.RLock()
@@ -1,7 +1,11 @@
 package requirements
 
 import (
+	"regexp"
+
 	"code.cloudfoundry.org/cli/cf/api"
+	"code.cloudfoundry.org/cli/cf/errors"
+	. "code.cloudfoundry.org/cli/cf/i18n"
 	"code.cloudfoundry.org/cli/cf/models"
 )
 
@@ -12,10 +16,16 @@ type UserRequirement interface {
 	GetUser() models.UserFields
 }
 
+// UserChooser picks one of matches, the users whose names start with
+// username, when no user is named username exactly.
+type UserChooser func(username string, matches []models.UserFields) (models.UserFields, error)
+
 type userAPIRequirement struct {
 	username string
 	userRepo api.UserRepository
 	wantGUID bool
+	byEmail  bool
+	choose   UserChooser
 
 	user models.UserFields
 }
@@ -33,10 +43,43 @@ func NewUserRequirement(
 	return req
 }
 
+// NewUserRequirementWithEmailFallback is NewUserRequirement for a user who
+// may be given by email. The user is always looked up, and when no user has
+// the given username, the user with that email is used instead.
+func NewUserRequirementWithEmailFallback(
+	username string,
+	userRepo api.UserRepository,
+) *userAPIRequirement {
+	req := NewUserRequirement(username, userRepo, true)
+	req.byEmail = true
+
+	return req
+}
+
+// NewUserRequirementWithPrefixMatch is NewUserRequirement for a user whose
+// name may be mistyped. The user is always looked up, and when no user has
+// the given username, choose picks from the users whose names start with it.
+func NewUserRequirementWithPrefixMatch(
+	username string,
+	userRepo api.UserRepository,
+	choose UserChooser,
+) *userAPIRequirement {
+	req := NewUserRequirement(username, userRepo, true)
+	req.choose = choose
+
+	return req
+}
+
 func (req *userAPIRequirement) Execute() error {
 	if req.wantGUID {
 		var err error
 		req.user, err = req.userRepo.FindByUsername(req.username)
+		if _, notFound := err.(*errors.ModelNotFoundError); notFound && req.byEmail {
+			req.user, err = req.userRepo.FindByEmail(req.username)
+		}
+		if _, notFound := err.(*errors.ModelNotFoundError); notFound && req.choose != nil {
+			req.user, err = req.chooseByPrefix(err)
+		}
 		if err != nil {
 			return err
 		}
@@ -50,3 +93,41 @@ func (req *userAPIRequirement) Execute() error {
 func (req *userAPIRequirement) GetUser() models.UserFields {
 	return req.user
 }
+
+// chooseByPrefix offers the users whose names start with the username,
+// returning notFoundErr when there are none.
+func (req *userAPIRequirement) chooseByPrefix(notFoundErr error) (models.UserFields, error) {
+	matches, err := req.userRepo.FindAllByUsernamePrefix(req.username)
+	if _, notFound := err.(*errors.ModelNotFoundError); notFound {
+		return models.UserFields{}, notFoundErr
+	}
+	if err != nil {
+		return models.UserFields{}, err
+	}
+	return req.choose(req.username, matches)
+}
+
+var guidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+type userGUIDRequirement struct {
+	userGUID string
+}
+
+// NewUserGUIDRequirement is a UserRequirement for a user known only by
+// GUID. It does not look the user up, so nothing is sent to UAA; it only
+// checks that userGUID looks like a GUID.
+func NewUserGUIDRequirement(userGUID string) *userGUIDRequirement {
+	return &userGUIDRequirement{userGUID: userGUID}
+}
+
+func (req *userGUIDRequirement) Execute() error {
+	if !guidPattern.MatchString(req.userGUID) {
+		return errors.NewInvalidInputError(T("{{.Arg}} is not a user GUID. Leave out --guid to give a username.",
+			map[string]interface{}{"Arg": req.userGUID}))
+	}
+	return nil
+}
+
+func (req *userGUIDRequirement) GetUser() models.UserFields {
+	return models.UserFields{GUID: req.userGUID, Username: req.userGUID}
+}
@@ -3,6 +3,7 @@ package requirements_test
 import (
 	"errors"
 
+	cferrors "code.cloudfoundry.org/cli/cf/errors"
 	"code.cloudfoundry.org/cli/cf/models"
 	"code.cloudfoundry.org/cli/cf/requirements"
 
@@ -67,6 +68,90 @@ var _ = Describe("UserRequirement", func() {
 			})
 		})
 
+		Context("when falling back to the user's email", func() {
+			BeforeEach(func() {
+				userRequirement = requirements.NewUserRequirementWithEmailFallback("jiro@example.com", userRepo)
+			})
+
+			It("uses the user with the username when there is one", func() {
+				user := models.UserFields{Username: "jiro@example.com", GUID: "the-guid"}
+				userRepo.FindByUsernameReturns(user, nil)
+
+				Expect(userRequirement.Execute()).To(Succeed())
+				Expect(userRequirement.GetUser()).To(Equal(user))
+				Expect(userRepo.FindByEmailCallCount()).To(BeZero())
+			})
+
+			It("looks the user up by email when no user has the username", func() {
+				user := models.UserFields{Username: "jiro", GUID: "the-guid"}
+				userRepo.FindByUsernameReturns(models.UserFields{}, cferrors.NewNotFoundError(cferrors.UserResource, "jiro@example.com"))
+				userRepo.FindByEmailReturns(user, nil)
+
+				Expect(userRequirement.Execute()).To(Succeed())
+				Expect(userRepo.FindByEmailArgsForCall(0)).To(Equal("jiro@example.com"))
+				Expect(userRequirement.GetUser()).To(Equal(user))
+			})
+
+			It("does not fall back when the username lookup fails for another reason", func() {
+				userError := errors.New("some error")
+				userRepo.FindByUsernameReturns(models.UserFields{}, userError)
+
+				Expect(userRequirement.Execute()).To(Equal(userError))
+				Expect(userRepo.FindByEmailCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when choosing from users whose names start with the username", func() {
+			var (
+				chosenFrom []models.UserFields
+				notFound   error
+			)
+
+			BeforeEach(func() {
+				chosenFrom = nil
+				notFound = cferrors.NewNotFoundError(cferrors.UserResource, "jir")
+				userRequirement = requirements.NewUserRequirementWithPrefixMatch("jir", userRepo, func(username string, matches []models.UserFields) (models.UserFields, error) {
+					chosenFrom = matches
+					return matches[len(matches)-1], nil
+				})
+			})
+
+			It("uses the user with the username when there is one", func() {
+				user := models.UserFields{Username: "jir", GUID: "jir-guid"}
+				userRepo.FindByUsernameReturns(user, nil)
+
+				Expect(userRequirement.Execute()).To(Succeed())
+				Expect(userRequirement.GetUser()).To(Equal(user))
+				Expect(userRepo.FindAllByUsernamePrefixCallCount()).To(BeZero())
+			})
+
+			It("uses the chosen user when no user has the username", func() {
+				matches := []models.UserFields{{Username: "jiro", GUID: "jiro-guid"}, {Username: "jirka", GUID: "jirka-guid"}}
+				userRepo.FindByUsernameReturns(models.UserFields{}, notFound)
+				userRepo.FindAllByUsernamePrefixReturns(matches, nil)
+
+				Expect(userRequirement.Execute()).To(Succeed())
+				Expect(userRepo.FindAllByUsernamePrefixArgsForCall(0)).To(Equal("jir"))
+				Expect(chosenFrom).To(Equal(matches))
+				Expect(userRequirement.GetUser()).To(Equal(matches[1]))
+			})
+
+			It("reports the username as not found when no name starts with it either", func() {
+				userRepo.FindByUsernameReturns(models.UserFields{}, notFound)
+				userRepo.FindAllByUsernamePrefixReturns(nil, cferrors.NewNotFoundError(cferrors.UserResource, "jir"))
+
+				Expect(userRequirement.Execute()).To(Equal(notFound))
+				Expect(chosenFrom).To(BeNil())
+			})
+
+			It("does not search by prefix when the username lookup fails for another reason", func() {
+				userRepo.FindByUsernameReturns(models.UserFields{}, errors.New("uaa-error"))
+
+				Expect(userRequirement.Execute()).To(MatchError("uaa-error"))
+				Expect(userRepo.FindAllByUsernamePrefixCallCount()).To(BeZero())
+			})
+		})
+
 		Context("when wantGUID is false", func() {
 			BeforeEach(func() {
 				userRequirement = requirements.NewUserRequirement("the-username", userRepo, false)
@@ -84,4 +169,20 @@ var _ = Describe("UserRequirement", func() {
 			})
 		})
 	})
+
+	Describe("NewUserGUIDRequirement", func() {
+		It("passes for a GUID without looking the user up", func() {
+			userRequirement := requirements.NewUserGUIDRequirement("2a4e1b3c-5d6f-4a7b-8c9d-0e1f2a3b4c5d")
+
+			Expect(userRequirement.Execute()).To(Succeed())
+			Expect(userRequirement.GetUser().GUID).To(Equal("2a4e1b3c-5d6f-4a7b-8c9d-0e1f2a3b4c5d"))
+		})
+
+		It("fails with an invalid input error for anything else", func() {
+			err := requirements.NewUserGUIDRequirement("alice@example.com").Execute()
+
+			Expect(err).To(BeAssignableToTypeOf(&cferrors.InvalidInputError{}))
+			Expect(err.Error()).To(ContainSubstring("alice@example.com is not a user GUID"))
+		})
+	})
 })
@@ -9,21 +9,29 @@ import (
 
 var LoggingToStdout bool
 
+// Sanitize hides credentials in traced requests and responses: the
+// Authorization header, password-like form fields, and the value of any JSON
+// key naming a token, password, passcode or secret.
 func Sanitize(input string) string {
 	re := regexp.MustCompile(`(?m)^Authorization: .*`)
 	sanitized := re.ReplaceAllString(input, "Authorization: "+PrivateDataPlaceholder())
 
-	re = regexp.MustCompile(`password=[^&]*&`)
-	sanitized = re.ReplaceAllString(sanitized, "password="+PrivateDataPlaceholder()+"&")
+	re = regexp.MustCompile(`\b(password|passcode|client_secret)=[^&\s]*`)
+	sanitized = re.ReplaceAllString(sanitized, "$1="+PrivateDataPlaceholder())
 
 	sanitized = sanitizeJSON("token", sanitized)
 	sanitized = sanitizeJSON("password", sanitized)
+	sanitized = sanitizeJSON("passcode", sanitized)
+	sanitized = sanitizeJSON("secret", sanitized)
 
 	return sanitized
 }
 
+// sanitizeJSON hides the string value of every JSON key containing
+// propertySubstring. Values are matched as whole JSON strings, so escaped
+// quotes and commas inside them are hidden too.
 func sanitizeJSON(propertySubstring string, json string) string {
-	regex := regexp.MustCompile(fmt.Sprintf(`(?i)"([^"]*%s[^"]*)":\s*"[^\,]*"`, propertySubstring))
+	regex := regexp.MustCompile(fmt.Sprintf(`(?i)"([^"]*%s[^"]*)":\s*"(?:[^"\\]|\\.)*"`, propertySubstring))
 	return regex.ReplaceAllString(json, fmt.Sprintf(`"$1":"%s"`, PrivateDataPlaceholder()))
 }
 
@@ -90,6 +90,24 @@ PUT /Users/user-guid-goes-here/password HTTP/1.1
 				Expect(Sanitize(request)).To(Equal(expected))
 			})
 
+			It("hides passwords containing commas in the JSON-formatted request body", func() {
+				request := `{"password":"lean,sushi","oldPassword":"old\\,\"pass"}`
+
+				Expect(Sanitize(request)).To(Equal(`{"password":"[PRIVATE DATA HIDDEN]","oldPassword":"[PRIVATE DATA HIDDEN]"}`))
+			})
+
+			It("hides a password that ends the query args", func() {
+				request := "grant_type=password&username=admin&password=leansushi"
+
+				Expect(Sanitize(request)).To(Equal("grant_type=password&username=admin&password=[PRIVATE DATA HIDDEN]"))
+			})
+
+			It("hides passcodes and client secrets", func() {
+				request := `client_id=cf&client_secret=shh&passcode=123456 {"passcode":"123456","client_secret":"shh"}`
+
+				Expect(Sanitize(request)).To(Equal(`client_id=cf&client_secret=[PRIVATE DATA HIDDEN]&passcode=[PRIVATE DATA HIDDEN] {"passcode":"[PRIVATE DATA HIDDEN]","client_secret":"[PRIVATE DATA HIDDEN]"}`))
+			})
+
 			It("hides create-user passwords", func() {
 				request := `
 REQUEST: [2014-03-07T12:15:08-08:00]
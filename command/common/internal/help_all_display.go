@@ -76,9 +76,10 @@ var HelpCategoryList = []HelpCategory{
 	{
 		CategoryName: "USER ADMIN:",
 		CommandList: [][]string{
-			{"create-user", "delete-user"},
-			{"org-users", "set-org-role", "unset-org-role"},
-			{"space-users", "set-space-role", "unset-space-role"},
+			{"create-user", "create-users-from-csv", "delete-user", "update-user-password", "reconcile-users", "list-all-users"},
+			{"org-users", "set-org-role", "unset-org-role", "user-memberships"},
+			{"space-users", "set-space-role", "unset-space-role", "diff-space-users"},
+			{"set-role-for-users"},
 		},
 	},
 	{
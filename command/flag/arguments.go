@@ -202,6 +202,17 @@ type RenameSpaceArgs struct {
 	NewSpaceName string `positional-arg-name:"NEW_SPACE_NAME" required:"true" description:"The new space name"`
 }
 
+type DiffSpaceUsersArgs struct {
+	SpaceA string `positional-arg-name:"SPACE_A" required:"true" description:"The first space"`
+	SpaceB string `positional-arg-name:"SPACE_B" required:"true" description:"The second space"`
+}
+
+type SetRoleForUsersArgs struct {
+	Organization string   `positional-arg-name:"ORG" required:"true" description:"The organization"`
+	Role         string   `positional-arg-name:"ROLE" required:"true" description:"The role"`
+	Usernames    []string `positional-arg-name:"USERNAME" required:"1" description:"The users to assign the role to"`
+}
+
 type SetOrgQuotaArgs struct {
 	Organization string `positional-arg-name:"ORG" required:"true" description:"The organization"`
 	Quota        string `positional-arg-name:"QUOTA" required:"true" description:"The quota"`
@@ -277,6 +288,10 @@ type SecurityGroupArgs struct {
 	PathToJsonRules PathWithExistenceCheck `positional-arg-name:"PATH_TO_JSON_RULES_FILE" required:"true" description:"Path to file of JSON describing security group rules"`
 }
 
+type CreateUsersFromCSVArgs struct {
+	PathToCSV PathWithExistenceCheck `positional-arg-name:"PATH_TO_CSV" required:"true" description:"Path to a CSV file of username,password lines"`
+}
+
 type AddPluginRepoArgs struct {
 	PluginRepoName string `positional-arg-name:"REPO_NAME" required:"true" description:"The plugin repo name"`
 	PluginRepoURL  string `positional-arg-name:"URL" required:"true" description:"The URL to the plugin repo"`
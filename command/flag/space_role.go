@@ -14,20 +14,26 @@ func (SpaceRole) Complete(prefix string) []flags.Completion {
 	return completions([]string{"SpaceManager", "SpaceDeveloper", "SpaceAuditor"}, prefix, false)
 }
 
+// UnmarshalFlag accepts a single space role or a comma-separated list of
+// them, in any case.
 func (s *SpaceRole) UnmarshalFlag(val string) error {
-	switch strings.ToLower(val) {
-	case "spaceauditor":
-		s.Role = "SpaceAuditor"
-	case "spacedeveloper":
-		s.Role = "SpaceDeveloper"
-	case "spacemanager":
-		s.Role = "SpaceManager"
-	default:
-		return &flags.Error{
-			Type:    flags.ErrRequired,
-			Message: `ROLE must be "SpaceManager", "SpaceDeveloper" and "SpaceAuditor"`,
+	var roles []string
+	for _, role := range strings.Split(val, ",") {
+		switch strings.ToLower(strings.TrimSpace(role)) {
+		case "spaceauditor":
+			roles = append(roles, "SpaceAuditor")
+		case "spacedeveloper":
+			roles = append(roles, "SpaceDeveloper")
+		case "spacemanager":
+			roles = append(roles, "SpaceManager")
+		default:
+			return &flags.Error{
+				Type:    flags.ErrRequired,
+				Message: `ROLE must be "SpaceManager", "SpaceDeveloper" and "SpaceAuditor"`,
+			}
 		}
 	}
 
+	s.Role = strings.Join(roles, ",")
 	return nil
 }
@@ -59,6 +59,18 @@ var _ = Describe("SpaceRole", func() {
 			Expect(spaceRole).To(Equal(SpaceRole{Role: "SpaceAuditor"}))
 		})
 
+		It("accepts a comma-separated list of roles", func() {
+			err := spaceRole.UnmarshalFlag("spacemanager,SpaceAuditor")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(spaceRole).To(Equal(SpaceRole{Role: "SpaceManager,SpaceAuditor"}))
+		})
+
+		It("errors when any role in a list is unknown", func() {
+			err := spaceRole.UnmarshalFlag("SpaceManager,OrgManager")
+			Expect(err).To(HaveOccurred())
+			Expect(spaceRole.Role).To(BeEmpty())
+		})
+
 		It("errors on anything else", func() {
 			err := spaceRole.UnmarshalFlag("I AM A BANANANANANANANANA")
 			Expect(err).To(MatchError(&flags.Error{
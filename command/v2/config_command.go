@@ -9,11 +9,13 @@ import (
 )
 
 type ConfigCommand struct {
-	AsyncTimeout int               `long:"async-timeout" description:"Timeout for async HTTP requests"`
-	Color        flag.Color        `long:"color" description:"Enable or disable color"`
-	Locale       flag.Locale       `long:"locale" description:"Set default locale. If LOCALE is 'CLEAR', previous locale is deleted."`
-	Trace        flag.PathWithBool `long:"trace" description:"Trace HTTP requests"`
-	usage        interface{}       `usage:"CF_NAME config [--async-timeout TIMEOUT_IN_MINUTES] [--trace (true | false | path/to/file)] [--color (true | false)] [--locale (LOCALE | CLEAR)]"`
+	AsyncTimeout   int               `long:"async-timeout" description:"Timeout for async HTTP requests"`
+	Color          flag.Color        `long:"color" description:"Enable or disable color"`
+	Locale         flag.Locale       `long:"locale" description:"Set default locale. If LOCALE is 'CLEAR', previous locale is deleted."`
+	ResultsPerPage int               `long:"results-per-page" description:"Number of results to ask for per page when listing, up to 100, 0 to use the default"`
+	Trace          flag.PathWithBool `long:"trace" description:"Trace HTTP requests"`
+	UAADialTimeout int               `long:"uaa-dial-timeout" description:"Timeout for connecting to UAA when looking up users, 0 to use the default"`
+	usage          interface{}       `usage:"CF_NAME config [--async-timeout TIMEOUT_IN_MINUTES] [--uaa-dial-timeout TIMEOUT_IN_SECONDS] [--results-per-page COUNT] [--trace (true | false | path/to/file)] [--color (true | false)] [--locale (LOCALE | CLEAR)]"`
 }
 
 func (ConfigCommand) Setup(config command.Config, ui command.UI) error {
@@ -0,0 +1,24 @@
+package v2
+
+import (
+	"os"
+
+	"code.cloudfoundry.org/cli/cf/cmd"
+	"code.cloudfoundry.org/cli/command"
+	"code.cloudfoundry.org/cli/command/flag"
+)
+
+type CreateUsersFromCSVCommand struct {
+	RequiredArgs    flag.CreateUsersFromCSVArgs `positional-args:"yes"`
+	usage           interface{}                 `usage:"CF_NAME create-users-from-csv PATH_TO_CSV\n\n   The file has one user per line, as username,password. A first line of username,password is skipped."`
+	relatedCommands interface{}                 `related_commands:"create-user, set-org-role, set-space-role"`
+}
+
+func (CreateUsersFromCSVCommand) Setup(config command.Config, ui command.UI) error {
+	return nil
+}
+
+func (CreateUsersFromCSVCommand) Execute(args []string) error {
+	cmd.Main(os.Getenv("CF_TRACE"), os.Args)
+	return nil
+}
@@ -11,8 +11,11 @@ import (
 type DeleteUserCommand struct {
 	RequiredArgs    flag.Username `positional-args:"yes"`
 	Force           bool          `short:"f" description:"Force deletion without confirmation"`
-	usage           interface{}   `usage:"CF_NAME delete-user USERNAME [-f]"`
-	relatedCommands interface{}   `related_commands:"org-users"`
+	GUID            bool          `long:"guid" description:"Treat USERNAME as a user GUID and skip looking the user up"`
+	Async           bool          `long:"async" description:"Delete the user from the Cloud Controller without waiting, and leave removing the UAA user to reconcile-users"`
+	Interactive     bool          `long:"interactive" description:"When no user has the given username, choose from the users whose names start with it"`
+	usage           interface{}   `usage:"CF_NAME delete-user USERNAME [-f] [--guid] [--async] [--interactive]"`
+	relatedCommands interface{}   `related_commands:"org-users, reconcile-users"`
 }
 
 func (DeleteUserCommand) Setup(config command.Config, ui command.UI) error {
@@ -0,0 +1,24 @@
+package v2
+
+import (
+	"os"
+
+	"code.cloudfoundry.org/cli/cf/cmd"
+	"code.cloudfoundry.org/cli/command"
+	"code.cloudfoundry.org/cli/command/flag"
+)
+
+type DiffSpaceUsersCommand struct {
+	RequiredArgs    flag.DiffSpaceUsersArgs `positional-args:"yes"`
+	usage           interface{}             `usage:"CF_NAME diff-space-users SPACE_A SPACE_B"`
+	relatedCommands interface{}             `related_commands:"space-users, set-space-role, unset-space-role"`
+}
+
+func (DiffSpaceUsersCommand) Setup(config command.Config, ui command.UI) error {
+	return nil
+}
+
+func (DiffSpaceUsersCommand) Execute(args []string) error {
+	cmd.Main(os.Getenv("CF_TRACE"), os.Args)
+	return nil
+}
@@ -0,0 +1,22 @@
+package v2
+
+import (
+	"os"
+
+	"code.cloudfoundry.org/cli/cf/cmd"
+	"code.cloudfoundry.org/cli/command"
+)
+
+type ListAllUsersCommand struct {
+	usage           interface{} `usage:"CF_NAME list-all-users\n\n   Users are printed as they are read from UAA, so a large foundation starts listing straight away."`
+	relatedCommands interface{} `related_commands:"org-users, space-users, user-memberships"`
+}
+
+func (ListAllUsersCommand) Setup(config command.Config, ui command.UI) error {
+	return nil
+}
+
+func (ListAllUsersCommand) Execute(args []string) error {
+	cmd.Main(os.Getenv("CF_TRACE"), os.Args)
+	return nil
+}
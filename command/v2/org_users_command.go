@@ -11,6 +11,10 @@ import (
 type OrgUsersCommand struct {
 	RequiredArgs    flag.Organization `positional-args:"yes"`
 	AllUsers        bool              `short:"a" description:"List all users in the org"`
+	JSON            bool              `long:"json" description:"Print the users and their roles as JSON"`
+	FieldStyle      string            `long:"field-style" description:"Key casing of the JSON output: camel (default) or snake"`
+	CountOnly       bool              `long:"count-only" description:"Print only the number of users with each role"`
+	Detailed        bool              `long:"detailed" description:"Show each user's identity provider origin, such as uaa or ldap"`
 	usage           interface{}       `usage:"CF_NAME org-users ORG"`
 	relatedCommands interface{}       `related_commands:"orgs"`
 }
@@ -0,0 +1,25 @@
+package v2
+
+import (
+	"os"
+
+	"code.cloudfoundry.org/cli/cf/cmd"
+	"code.cloudfoundry.org/cli/command"
+)
+
+type ReconcileUsersCommand struct {
+	Force           bool        `short:"f" description:"Force deletion without confirmation"`
+	DeleteCCOnly    bool        `long:"delete-cc-only" description:"Delete the listed users that only the Cloud Controller has a record of"`
+	DeleteUAAOnly   bool        `long:"delete-uaa-only" description:"Delete the listed users that only UAA has a record of"`
+	usage           interface{} `usage:"CF_NAME reconcile-users [--delete-cc-only] [--delete-uaa-only] [-f]"`
+	relatedCommands interface{} `related_commands:"delete-user"`
+}
+
+func (ReconcileUsersCommand) Setup(config command.Config, ui command.UI) error {
+	return nil
+}
+
+func (ReconcileUsersCommand) Execute(args []string) error {
+	cmd.Main(os.Getenv("CF_TRACE"), os.Args)
+	return nil
+}
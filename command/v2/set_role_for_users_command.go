@@ -0,0 +1,26 @@
+package v2
+
+import (
+	"os"
+
+	"code.cloudfoundry.org/cli/cf/cmd"
+	"code.cloudfoundry.org/cli/command"
+	"code.cloudfoundry.org/cli/command/flag"
+)
+
+type SetRoleForUsersCommand struct {
+	RequiredArgs    flag.SetRoleForUsersArgs `positional-args:"yes"`
+	Space           string                   `short:"s" description:"Assign a space role in the specified space of the org"`
+	ContinueOnError bool                     `long:"continue-on-error" description:"Keep assigning the role to the remaining users after one fails"`
+	usage           interface{}              `usage:"CF_NAME set-role-for-users ORG ROLE USERNAME... [-s SPACE] [--continue-on-error]\n\n   ROLE is an org role as taken by set-org-role or, with -s, a space role as taken by set-space-role.\n   Users are assigned the role in turn, stopping at the first that fails unless --continue-on-error is given."`
+	relatedCommands interface{}              `related_commands:"set-org-role, set-space-role, org-users, space-users"`
+}
+
+func (SetRoleForUsersCommand) Setup(config command.Config, ui command.UI) error {
+	return nil
+}
+
+func (SetRoleForUsersCommand) Execute(args []string) error {
+	cmd.Main(os.Getenv("CF_TRACE"), os.Args)
+	return nil
+}
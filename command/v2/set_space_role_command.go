@@ -10,7 +10,10 @@ import (
 
 type SetSpaceRoleCommand struct {
 	RequiredArgs    flag.SetSpaceRoleArgs `positional-args:"yes"`
-	usage           interface{}           `usage:"CF_NAME set-space-role USERNAME ORG SPACE ROLE\n\nROLES:\n   'SpaceManager' - Invite and manage users, and enable features for a given space\n   'SpaceDeveloper' - Create and manage apps and services, and see logs and reports\n   'SpaceAuditor' - View logs, reports, and settings on this space"`
+	DryRun          bool                  `long:"dry-run" description:"Print the requests that would be sent without changing any roles"`
+	ByEmail         bool                  `long:"by-email" description:"Find the user by email when no user has the given username"`
+	Interactive     bool                  `long:"interactive" description:"When no user has the given username, choose from the users whose names start with it"`
+	usage           interface{}           `usage:"CF_NAME set-space-role USERNAME ORG SPACE ROLE[,ROLE...]\n\nROLES:\n   'SpaceManager' - Invite and manage users, and enable features for a given space\n   'SpaceDeveloper' - Create and manage apps and services, and see logs and reports\n   'SpaceAuditor' - View logs, reports, and settings on this space"`
 	relatedCommands interface{}           `related_commands:"space-users"`
 }
 
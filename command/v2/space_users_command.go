@@ -10,6 +10,8 @@ import (
 
 type SpaceUsersCommand struct {
 	RequiredArgs    flag.OrgSpace `positional-args:"yes"`
+	JSON            bool          `long:"json" description:"Print the users and their roles as JSON"`
+	FieldStyle      string        `long:"field-style" description:"Key casing of the JSON output: camel (default) or snake"`
 	usage           interface{}   `usage:"CF_NAME space-users ORG SPACE"`
 	relatedCommands interface{}   `related_commands:"org-users, set-space-role, unset-space-role, orgs, spaces"`
 }
@@ -10,6 +10,9 @@ import (
 
 type UnsetOrgRoleCommand struct {
 	RequiredArgs    flag.SetOrgRoleArgs `positional-args:"yes"`
+	DryRun          bool                `long:"dry-run" description:"Print the requests that would be sent without changing any roles"`
+	GUID            bool                `long:"guid" description:"Treat USERNAME as a user GUID and skip looking the user up"`
+	Interactive     bool                `long:"interactive" description:"When no user has the given username, choose from the users whose names start with it"`
 	usage           interface{}         `usage:"CF_NAME unset-org-role USERNAME ORG ROLE\n\nROLES:\n   'OrgManager' - Invite and manage users, select and change plans, and set spending limits\n   'BillingManager' - Create and manage the billing account and payment info\n   'OrgAuditor' - Read-only access to org info and reports"`
 	relatedCommands interface{}         `related_commands:"org-users, delete-user"`
 }
@@ -10,6 +10,8 @@ import (
 
 type UnsetSpaceRoleCommand struct {
 	RequiredArgs    flag.SetSpaceRoleArgs `positional-args:"yes"`
+	DryRun          bool                  `long:"dry-run" description:"Print the requests that would be sent without changing any roles"`
+	Interactive     bool                  `long:"interactive" description:"When no user has the given username, choose from the users whose names start with it"`
 	usage           interface{}           `usage:"CF_NAME unset-space-role USERNAME ORG SPACE ROLE\n\nROLES:\n   'SpaceManager' - Invite and manage users, and enable features for a given space\n   'SpaceDeveloper' - Create and manage apps and services, and see logs and reports\n   'SpaceAuditor' - View logs, reports, and settings on this space"`
 	relatedCommands interface{}           `related_commands:"space-users"`
 }
@@ -0,0 +1,24 @@
+package v2
+
+import (
+	"os"
+
+	"code.cloudfoundry.org/cli/cf/cmd"
+	"code.cloudfoundry.org/cli/command"
+	"code.cloudfoundry.org/cli/command/flag"
+)
+
+type UpdateUserPasswordCommand struct {
+	RequiredArgs    flag.Username `positional-args:"yes"`
+	usage           interface{}   `usage:"CF_NAME update-user-password USERNAME\n\n   Resetting another user's password requires the uaa.admin or password.write scope."`
+	relatedCommands interface{}   `related_commands:"passwd, create-user"`
+}
+
+func (UpdateUserPasswordCommand) Setup(config command.Config, ui command.UI) error {
+	return nil
+}
+
+func (UpdateUserPasswordCommand) Execute(args []string) error {
+	cmd.Main(os.Getenv("CF_TRACE"), os.Args)
+	return nil
+}
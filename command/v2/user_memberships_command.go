@@ -0,0 +1,25 @@
+package v2
+
+import (
+	"os"
+
+	"code.cloudfoundry.org/cli/cf/cmd"
+	"code.cloudfoundry.org/cli/command"
+	"code.cloudfoundry.org/cli/command/flag"
+)
+
+type UserMembershipsCommand struct {
+	RequiredArgs    flag.Username `positional-args:"yes"`
+	Org             string        `short:"o" description:"Only show memberships in the specified org"`
+	usage           interface{}   `usage:"CF_NAME user-memberships USERNAME [-o ORG]"`
+	relatedCommands interface{}   `related_commands:"org-users, space-users, set-org-role, set-space-role"`
+}
+
+func (UserMembershipsCommand) Setup(config command.Config, ui command.UI) error {
+	return nil
+}
+
+func (UserMembershipsCommand) Execute(args []string) error {
+	cmd.Main(os.Getenv("CF_TRACE"), os.Args)
+	return nil
+}